@@ -0,0 +1,396 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/access_role"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// GetEmployeeBankAccount - GET /api/employee/:id/bank-account
+// The account an employee's salary is paid to. Employees can view their own.
+func (h *HandlerFunc) GetEmployeeBankAccount(c *gin.Context) {
+	role := c.GetString("role")
+	userID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+	if access_role.Admin_SuperAdmin_Hr(role, "") != nil && userID != employeeID {
+		utils.RespondWithError(c, http.StatusForbidden, "you can only view your own bank account")
+		return
+	}
+	account, err := h.Query.GetEmployeeBankAccount(employeeID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "no bank account on file")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, 500, "failed to fetch bank account: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"employee_id": employeeID, "bank_account": account})
+}
+
+// UpdateEmployeeBankAccount - PUT /api/employee/:id/bank-account
+// SUPERADMIN/ADMIN/HR set the account an employee's salary is paid to.
+func (h *HandlerFunc) UpdateEmployeeBankAccount(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can update bank accounts"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+
+	var input models.BankAccountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	if err := h.Validator.Struct(input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	account, err := service.NormalizeBankAccount(input)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var employee string
+	if err := h.Query.DB.Get(&employee, "SELECT full_name FROM Tbl_Employee WHERE id=$1", employeeID); err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	} else if err != nil {
+		utils.RespondWithError(c, 500, "failed to fetch employee: "+err.Error())
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.UpsertEmployeeBankAccountTx(tx, employeeID, account, empID); err != nil {
+			return utils.CustomErr(c, 500, "failed to update bank account: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentBankAccount, constant.ActionUpdate, empID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "failed to update bank account: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "bank account updated successfully",
+		"employee_id":  employeeID,
+		"bank_account": account,
+	})
+}
+
+// GetCompanyBankAccount - GET /api/payroll/bank-account
+// The account salaries are paid from
+func (h *HandlerFunc) GetCompanyBankAccount(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can view the salary account"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	account, err := h.Query.GetCompanyBankAccount()
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "The company salary account is not set up")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch salary account: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"bank_account": account})
+}
+
+// UpdateCompanyBankAccount - PUT /api/payroll/bank-account
+// SUPERADMIN sets the account salaries are paid from, the debit account of bank files
+func (h *HandlerFunc) UpdateCompanyBankAccount(c *gin.Context) {
+	if c.GetString("role") != constant.ROLE_SUPER_ADMIN {
+		utils.RespondWithError(c, http.StatusForbidden, "Only SUPERADMIN can update the salary account")
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var input models.CompanyBankAccountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if err := h.Validator.Struct(input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	account, err := service.NormalizeCompanyBankAccount(input)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.UpsertCompanyBankAccountTx(tx, account, empID); err != nil {
+			return utils.CustomErr(c, 500, "Failed to update salary account: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentBankAccount, constant.ActionUpdate, empID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to update salary account: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Salary account updated successfully", "bank_account": account})
+}
+
+// bankFileContentType is the media type a bank file is served with
+func bankFileContentType(format string) string {
+	switch format {
+	case models.BankFileCSV:
+		return "text/csv"
+	case models.BankFilePain001:
+		return "application/xml"
+	}
+	return "text/plain"
+}
+
+// serveBankFile sends a bank file as a download with its control totals in headers
+func serveBankFile(c *gin.Context, f models.BankFile) {
+	c.Header("Content-Disposition", "attachment; filename="+f.FileName)
+	c.Header("X-Bank-File-Id", f.ID.String())
+	c.Header("X-Entry-Count", strconv.Itoa(f.EntryCount))
+	c.Header("X-Control-Total", strconv.FormatFloat(f.ControlTotal, 'f', 2, 64))
+	c.Header("X-Checksum", f.Checksum)
+	c.Data(http.StatusOK, bankFileContentType(f.Format), []byte(f.Content))
+}
+
+// GenerateBankFile - POST /api/payroll/:id/bank-file
+// Generates the bank salary transfer file of a FINALIZED payroll run in the requested format
+// (CSV, NACHA, PAIN001 or NEFT) from the employees' bank accounts: one transfer per employee
+// of the net pay of their issued payslips. Each payslip gets its transfer's payment reference
+// and the run is marked PAID. Missing bank details are reported together.
+func (h *HandlerFunc) GenerateBankFile(c *gin.Context) {
+	if c.GetString("role") != constant.ROLE_SUPER_ADMIN {
+		utils.RespondWithError(c, http.StatusForbidden, "Only SUPERADMIN can generate bank files")
+		return
+	}
+	actorID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	runID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid payroll run ID")
+		return
+	}
+	var input models.BankFileInput
+	if err := c.ShouldBindJSON(&input); err != nil && err != io.EOF {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	format := strings.ToUpper(strings.TrimSpace(input.Format))
+	if !service.IsValidBankFileFormat(format) {
+		utils.RespondWithError(c, http.StatusBadRequest, "format must be CSV, NACHA, PAIN001 or NEFT")
+		return
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	execution := today
+	if input.ExecutionDate != "" {
+		execution, err = time.Parse("2006-01-02", input.ExecutionDate)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "execution_date must be YYYY-MM-DD")
+			return
+		}
+		if execution.Before(today) {
+			utils.RespondWithError(c, http.StatusBadRequest, "execution_date cannot be in the past")
+			return
+		}
+	}
+
+	run, err := h.Query.GetPayrollRun(runID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "Payroll run not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch payroll run: "+err.Error())
+		return
+	}
+	if run.Status != models.PayrollStatusFinalized {
+		utils.RespondWithError(c, http.StatusBadRequest, fmt.Sprintf("Cannot generate a bank file for a payroll run with status %s. The run must be %s.",
+			run.Status, models.PayrollStatusFinalized))
+		return
+	}
+
+	var payer *models.CompanyBankAccount
+	account, err := h.Query.GetCompanyBankAccount()
+	if err != nil && err != sql.ErrNoRows {
+		utils.RespondWithError(c, 500, "Failed to fetch salary account: "+err.Error())
+		return
+	}
+	if err == nil {
+		payer = &account
+	}
+	payslips, err := h.Query.GetRunPayslipPayments(runID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch payslips: "+err.Error())
+		return
+	}
+	payments := service.BankPaymentsFromPayslips(payslips, run.Month, run.Year)
+	if len(payments) == 0 {
+		utils.RespondWithError(c, http.StatusBadRequest, "The payroll run has no salaries to pay")
+		return
+	}
+	if violations := service.BankFileViolations(format, payer, payments); len(violations) > 0 {
+		utils.RespondWithViolations(c, http.StatusUnprocessableEntity,
+			fmt.Sprintf("Bank details needed for a %s file are missing", format), violations)
+		return
+	}
+
+	var file models.BankFile
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		locked, err := h.Query.GetPayrollRunTx(tx, runID)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch payroll run: "+err.Error())
+		}
+		if locked.Status != models.PayrollStatusFinalized || !locked.UpdatedAt.Equal(run.UpdatedAt) {
+			return utils.CustomErr(c, http.StatusConflict, "The payroll run changed while the bank file was generated; generate it again")
+		}
+		// The file is built from the locked payslips and bank accounts it marks as paid
+		payslips, err := h.Query.GetRunPayslipPaymentsTx(tx, runID)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch payslips: "+err.Error())
+		}
+		payments := service.BankPaymentsFromPayslips(payslips, run.Month, run.Year)
+		if len(payments) == 0 || len(service.BankFileViolations(format, payer, payments)) > 0 {
+			return utils.CustomErr(c, http.StatusConflict, "Bank details changed while the bank file was generated; generate it again")
+		}
+		file, err = service.BuildBankFile(format, payer, payments, run.Month, run.Year, execution, now)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusBadRequest, "Failed to generate bank file: "+err.Error())
+		}
+		file.PayrollRunID = runID
+		file.GeneratedBy = &actorID
+		file.CreatedAt = now
+
+		for _, p := range payments {
+			if err := h.Query.SetPayslipPaymentReferenceTx(tx, p.PayslipIDs, p.Reference); err != nil {
+				return utils.CustomErr(c, 500, "Failed to record payment reference: "+err.Error())
+			}
+		}
+		file.ID, err = h.Query.InsertBankFileTx(tx, file)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to store bank file: "+err.Error())
+		}
+		comments := fmt.Sprintf("%s bank file %s: %d transfers, total %.2f, SHA-256 %s",
+			format, file.FileName, file.EntryCount, file.ControlTotal, file.Checksum)
+		if err := h.Query.TransitionPayrollRunTx(tx, locked, models.PayrollStatusPaid, models.PayrollActionPay, actorID, comments); err != nil {
+			return utils.CustomErr(c, 500, "Failed to update payroll run: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentPayroll, constant.ActionPay, actorID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to generate bank file: "+err.Error())
+		return
+	}
+	serveBankFile(c, file)
+}
+
+// GetPayrollBankFiles - GET /api/payroll/:id/bank-files
+// The bank files generated for a payroll run, without their content
+func (h *HandlerFunc) GetPayrollBankFiles(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can view bank files"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	runID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid payroll run ID")
+		return
+	}
+	files, err := h.Query.GetRunBankFiles(runID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch bank files: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"payroll_run_id": runID, "bank_files": files})
+}
+
+// DownloadBankFile - GET /api/payroll/bank-files/:id
+// Downloads a generated bank file again
+func (h *HandlerFunc) DownloadBankFile(c *gin.Context) {
+	if c.GetString("role") != constant.ROLE_SUPER_ADMIN {
+		utils.RespondWithError(c, http.StatusForbidden, "Only SUPERADMIN can download bank files")
+		return
+	}
+	fileID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid bank file ID")
+		return
+	}
+	file, err := h.Query.GetBankFile(fileID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "Bank file not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch bank file: "+err.Error())
+		return
+	}
+	serveBankFile(c, file)
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+)
+
+// feedWindowDays is how far back and ahead leave feeds publish events
+const feedWindowDays = 365
+
+// GetCalendarFeeds - GET /api/calendar-feeds
+// Returns the subscription URLs for the current user, creating the secret token on first use.
+func (h *HandlerFunc) GetCalendarFeeds(c *gin.Context) {
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	token, err := h.Query.GetCalendarFeedToken(empID)
+	if err == sql.ErrNoRows {
+		if token, err = utils.GenerateFeedToken(); err == nil {
+			err = h.Query.SaveCalendarFeedToken(empID, token)
+		}
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get calendar feed token: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"feeds": calendarFeedURLs(c, token)})
+}
+
+// RotateCalendarFeedToken - POST /api/calendar-feeds/rotate
+// Issues a new secret token; previously shared feed URLs stop working.
+func (h *HandlerFunc) RotateCalendarFeedToken(c *gin.Context) {
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	token, err := utils.GenerateFeedToken()
+	if err == nil {
+		err = h.Query.SaveCalendarFeedToken(empID, token)
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to rotate calendar feed token: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Calendar feed token rotated. Re-subscribe using the new URLs",
+		"feeds":   calendarFeedURLs(c, token),
+	})
+}
+
+// GetMyLeavesFeed - GET /api/ics/:token/my-leaves.ics (public, token authenticated)
+func (h *HandlerFunc) GetMyLeavesFeed(c *gin.Context) {
+	emp, ok := h.feedOwner(c)
+	if !ok {
+		return
+	}
+	h.serveLeaveFeed(c, "My Leaves", []uuid.UUID{emp.ID}, false)
+}
+
+// GetTeamLeavesFeed - GET /api/ics/:token/team-leaves.ics (public, token authenticated)
+// Includes direct reports and colleagues sharing the same manager.
+func (h *HandlerFunc) GetTeamLeavesFeed(c *gin.Context) {
+	emp, ok := h.feedOwner(c)
+	if !ok {
+		return
+	}
+	ids, err := h.Query.GetFeedTeamMemberIDs(emp.ID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch team: "+err.Error())
+		return
+	}
+	h.serveLeaveFeed(c, "Team Leaves", ids, true)
+}
+
+// GetHolidaysFeed - GET /api/ics/:token/holidays.ics (public, token authenticated)
+func (h *HandlerFunc) GetHolidaysFeed(c *gin.Context) {
+	if _, ok := h.feedOwner(c); !ok {
+		return
+	}
+	holidays, err := h.Query.GetAllHolidays()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch holidays: "+err.Error())
+		return
+	}
+
+	cal := service.NewICSCalendar("Company Holidays", feedDomain(c))
+	for _, holiday := range holidays {
+		cal.AddHoliday(holiday)
+	}
+	writeICS(c, cal)
+}
+
+// feedOwner resolves the :token path param to an active employee
+func (h *HandlerFunc) feedOwner(c *gin.Context) (models.CalendarEmployee, bool) {
+	emp, err := h.Query.GetEmployeeByFeedToken(c.Param("token"))
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "Calendar feed not found")
+		return emp, false
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to resolve calendar feed: "+err.Error())
+		return emp, false
+	}
+	return emp, true
+}
+
+func (h *HandlerFunc) serveLeaveFeed(c *gin.Context, name string, employeeIDs []uuid.UUID, withName bool) {
+	now := time.Now()
+	leaves, err := h.Query.GetFeedLeaves(employeeIDs, now.AddDate(0, 0, -feedWindowDays), now.AddDate(0, 0, feedWindowDays))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch leaves: "+err.Error())
+		return
+	}
+
+	cal := service.NewICSCalendar(name, feedDomain(c))
+	for _, l := range leaves {
+		cal.AddLeave(l, withName)
+	}
+	writeICS(c, cal)
+}
+
+func writeICS(c *gin.Context, cal *service.ICSCalendar) {
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(cal.String()))
+}
+
+// calendarFeedURLs builds absolute feed URLs from the incoming request
+func calendarFeedURLs(c *gin.Context, token string) gin.H {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	base := scheme + "://" + c.Request.Host + "/api/ics/" + token
+	return gin.H{
+		"my_leaves":   base + "/my-leaves.ics",
+		"team_leaves": base + "/team-leaves.ics",
+		"holidays":    base + "/holidays.ics",
+	}
+}
+
+// feedDomain returns the host name used in event UIDs
+func feedDomain(c *gin.Context) string {
+	host := c.Request.Host
+	if i := strings.LastIndex(host, ":"); i > 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	if host == "" {
+		return "leave-management"
+	}
+	return host
+}
@@ -0,0 +1,422 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/access_role"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// RequestCompOff - POST /api/comp-off
+// Employee logs a weekend or holiday they worked on to earn compensatory off.
+// The request must be made within the comp-off validity window of the worked date.
+func (h *HandlerFunc) RequestCompOff(c *gin.Context) {
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	empStatus, err := h.Query.GetEmployeeStatus(empID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to verify employee status")
+		return
+	}
+	if empStatus == "deactive" {
+		utils.RespondWithError(c, 403, "Your account is deactivated. You cannot request comp-off")
+		return
+	}
+
+	var input models.CompOffInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if err := h.Validator.Struct(input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	days, err := service.CompOffDays(input.DayTimingID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Normalize to UTC midnight to avoid timezone issues
+	input.WorkDate = time.Date(input.WorkDate.Year(), input.WorkDate.Month(), input.WorkDate.Day(), 0, 0, 0, 0, time.UTC)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if input.WorkDate.After(today) {
+		utils.RespondWithError(c, http.StatusBadRequest, "Comp-off can only be requested for a date already worked")
+		return
+	}
+
+	var requestID uuid.UUID
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		settings, err := h.Query.GetCompOffSettingsTx(tx)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch comp-off settings: "+err.Error())
+		}
+		if settings.LeaveTypeID == nil {
+			return utils.CustomErr(c, 400, "Comp-off is not enabled. Ask an admin to configure the comp-off leave type")
+		}
+		if input.WorkDate.Before(today.AddDate(0, 0, -settings.ValidityDays)) {
+			return utils.CustomErr(c, 400, fmt.Sprintf("Comp-off must be requested within %d days of the date worked", settings.ValidityDays))
+		}
+
+		weekday := input.WorkDate.Weekday()
+		if weekday != time.Saturday && weekday != time.Sunday {
+			isHoliday, err := h.Query.IsHolidayTx(tx, input.WorkDate)
+			if err != nil {
+				return utils.CustomErr(c, 500, "Failed to check holidays: "+err.Error())
+			}
+			if !isHoliday {
+				return utils.CustomErr(c, 400, "Comp-off can only be earned for working on a weekend or company holiday")
+			}
+		}
+
+		exists, err := h.Query.HasCompOffForDateTx(tx, empID, input.WorkDate)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to check existing requests: "+err.Error())
+		}
+		if exists {
+			return utils.CustomErr(c, 400, "A comp-off request already exists for this date")
+		}
+
+		id, err := h.Query.InsertCompOffRequest(tx, empID, input, days)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to create comp-off request: "+err.Error())
+		}
+		requestID = id
+
+		data := models.NewCommon(constant.ComponentCompOff, constant.ActionCreate, empID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to create comp-off request: "+err.Error())
+		return
+	}
+
+	go func() {
+		recipients, err := h.Query.GetAdminAndEmployeeEmail(empID)
+		if err != nil || len(recipients) == 0 {
+			return
+		}
+		empDetails, err := h.Query.GetEmployeeDetailsForNotification(empID)
+		if err != nil {
+			fmt.Printf("Failed to get employee details for notification: %v\n", err)
+			return
+		}
+		utils.SendCompOffRequestEmail(recipients, empDetails.FullName, input.WorkDate.Format("2006-01-02"), days, input.Reason)
+	}()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Comp-off request submitted successfully",
+		"request_id": requestID,
+		"work_date":  input.WorkDate.Format("2006-01-02"),
+		"days":       days,
+	})
+}
+
+// GetMyCompOffRequests - GET /api/comp-off/my
+func (h *HandlerFunc) GetMyCompOffRequests(c *gin.Context) {
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	requests, err := h.Query.GetCompOffRequests(&empID, nil, c.Query("status"))
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch comp-off requests: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": requests})
+}
+
+// GetCompOffRequests - GET /api/comp-off?status=Pending
+// SUPERADMIN/ADMIN/HR see all requests, MANAGER sees requests of direct reports
+func (h *HandlerFunc) GetCompOffRequests(c *gin.Context) {
+	role := c.GetString("role")
+	userID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var managerID *uuid.UUID
+	switch role {
+	case constant.ROLE_SUPER_ADMIN, constant.ROLE_ADMIN, constant.ROLE_HR:
+	case constant.ROLE_MANAGER:
+		managerID = &userID
+	default:
+		utils.RespondWithError(c, http.StatusForbidden, "Not authorized to view comp-off requests")
+		return
+	}
+
+	requests, err := h.Query.GetCompOffRequests(nil, managerID, c.Query("status"))
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch comp-off requests: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": requests})
+}
+
+// ActionCompOff - POST /api/comp-off/:id/action
+// MANAGER (direct reports) or SUPERADMIN/ADMIN/HR approve or reject a pending request.
+// Approval credits the configured comp-off leave type, valid for comp_off_validity_days.
+func (h *HandlerFunc) ActionCompOff(c *gin.Context) {
+	role := c.GetString("role")
+	approverID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if role == constant.ROLE_EMPLOYEE {
+		utils.RespondWithError(c, http.StatusForbidden, "Employees cannot approve comp-off requests")
+		return
+	}
+
+	requestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid request ID")
+		return
+	}
+
+	var input models.CompOffActionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid payload: "+err.Error())
+		return
+	}
+	input.Action = strings.ToUpper(input.Action)
+	input.Comment = strings.TrimSpace(input.Comment)
+	if input.Action != constant.LEAVE_APPROVE && input.Action != constant.LEAVE_REJECT {
+		utils.RespondWithError(c, http.StatusBadRequest, "Action must be APPROVE or REJECT")
+		return
+	}
+
+	var request models.CompOffRequest
+	var expiresOn time.Time
+	status := models.CompOffRejected
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		request, err = h.Query.GetCompOffRequestTx(tx, requestID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, 404, "Comp-off request not found")
+		}
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch comp-off request: "+err.Error())
+		}
+		if request.EmployeeID == approverID {
+			return utils.CustomErr(c, 403, "You cannot approve your own comp-off request")
+		}
+		if request.Status != models.CompOffPending {
+			return utils.CustomErr(c, 400, fmt.Sprintf("Cannot process comp-off request with status: %s", request.Status))
+		}
+
+		if role == constant.ROLE_MANAGER {
+			var managerID uuid.UUID
+			err := tx.Get(&managerID, "SELECT COALESCE(manager_id, '00000000-0000-0000-0000-000000000000') FROM Tbl_Employee WHERE id=$1", request.EmployeeID)
+			if err != nil {
+				return utils.CustomErr(c, 500, "Failed to verify reporting relationship")
+			}
+			if managerID != approverID {
+				return utils.CustomErr(c, 403, "You can only approve comp-off of employees who report to you")
+			}
+		}
+
+		if input.Action == constant.LEAVE_REJECT {
+			if err := h.Query.UpdateCompOffStatus(tx, requestID, approverID, models.CompOffRejected, input.Comment); err != nil {
+				return utils.CustomErr(c, 500, "Failed to reject comp-off request: "+err.Error())
+			}
+			data := models.NewCommon(constant.ComponentCompOff, constant.ActionRejection, approverID)
+			if err := h.Query.AddLog(data, tx); err != nil {
+				return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+			}
+			return nil
+		}
+
+		settings, err := h.Query.GetCompOffSettingsTx(tx)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch comp-off settings: "+err.Error())
+		}
+		if settings.LeaveTypeID == nil {
+			return utils.CustomErr(c, 400, "Comp-off is not enabled. Configure the comp-off leave type first")
+		}
+
+		now := time.Now()
+		expiresOn = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, settings.ValidityDays)
+		reason := fmt.Sprintf("Comp-off for work on %s (expires %s)", request.WorkDate.Format("2006-01-02"), expiresOn.Format("2006-01-02"))
+		adjustmentID, err := h.Query.InsertLeaveAdjustment(tx, request.EmployeeID, *settings.LeaveTypeID, request.Days, reason, approverID.String(), now.Year())
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to record leave adjustment: "+err.Error())
+		}
+		ref := models.LedgerRef{AdjustmentID: &adjustmentID, Note: reason, CreatedBy: &approverID}
+		if _, err := service.ApplyBalanceAdjustment(h.Query, tx, request.EmployeeID, *settings.LeaveTypeID, now.Year(), request.Days, ref); err != nil {
+			return utils.CustomErr(c, 500, err.Error())
+		}
+		if err := h.Query.ApproveCompOffRequest(tx, requestID, approverID, input.Comment, *settings.LeaveTypeID, expiresOn); err != nil {
+			return utils.CustomErr(c, 500, "Failed to approve comp-off request: "+err.Error())
+		}
+
+		data := models.NewCommon(constant.ComponentCompOff, constant.ActionApproval, approverID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		status = models.CompOffApproved
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to process comp-off request: "+err.Error())
+		return
+	}
+
+	go func() {
+		empDetails, err := h.Query.GetEmployeeDetailsForNotification(request.EmployeeID)
+		if err != nil {
+			fmt.Printf("Failed to get employee details for notification: %v\n", err)
+			return
+		}
+		var approverName string
+		h.Query.DB.Get(&approverName, "SELECT full_name FROM Tbl_Employee WHERE id=$1", approverID)
+		utils.SendCompOffDecisionEmail(
+			empDetails.Email,
+			empDetails.FullName,
+			request.WorkDate.Format("2006-01-02"),
+			request.Days,
+			status,
+			approverName,
+			input.Comment,
+			expiresOn.Format("2006-01-02"),
+		)
+	}()
+
+	response := gin.H{
+		"message": "Comp-off request " + strings.ToLower(status),
+		"status":  status,
+	}
+	if status == models.CompOffApproved {
+		response["credited_days"] = request.Days
+		response["expires_on"] = expiresOn.Format("2006-01-02")
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CancelCompOff - DELETE /api/comp-off/:id
+// Employee cancels their own pending request
+func (h *HandlerFunc) CancelCompOff(c *gin.Context) {
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	requestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid request ID")
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		request, err := h.Query.GetCompOffRequestTx(tx, requestID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, 404, "Comp-off request not found")
+		}
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch comp-off request: "+err.Error())
+		}
+		if request.EmployeeID != empID {
+			return utils.CustomErr(c, 403, "You can only cancel your own comp-off requests")
+		}
+		if request.Status != models.CompOffPending {
+			return utils.CustomErr(c, 400, fmt.Sprintf("Cannot cancel comp-off request with status: %s", request.Status))
+		}
+		if err := h.Query.UpdateCompOffStatus(tx, requestID, empID, models.CompOffCancelled, ""); err != nil {
+			return utils.CustomErr(c, 500, "Failed to cancel comp-off request: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentCompOff, constant.ActionCancel, empID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to cancel comp-off request: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comp-off request cancelled successfully"})
+}
+
+// RunCompOffLapse - POST /api/comp-off/lapse?dry_run=true
+// SUPERADMIN/ADMIN/HR run the comp-off lapse job now (it also runs daily).
+// With dry_run the lapses are calculated and returned without being saved.
+func (h *HandlerFunc) RunCompOffLapse(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can run the comp-off lapse"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	dryRun := c.Query("dry_run") == "true"
+
+	tx, err := h.Query.DB.Beginx()
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	lapses, err := service.LapseExpiredCompOff(h.Query, tx, time.Now())
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to lapse comp-off: "+err.Error())
+		return
+	}
+
+	if !dryRun {
+		data := models.NewCommon(constant.ComponentCompOff, constant.ActionRun, empID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			utils.RespondWithError(c, 500, "Failed to create log: "+err.Error())
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			utils.RespondWithError(c, 500, "Transaction commit failed")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comp-off lapse completed",
+		"dry_run": dryRun,
+		"lapses":  lapses,
+	})
+}
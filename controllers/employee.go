@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/access_role"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
@@ -133,6 +134,33 @@ func (h *HandlerFunc) CreateEmployee(c *gin.Context) {
 		return
 	}
 
+	// VALIDATE GENDER
+	if input.Gender != nil {
+		gender, err := service.NormalizeGender(*input.Gender)
+		if err != nil {
+			utils.RespondWithError(c, 400, err.Error())
+			return
+		}
+		input.Gender = &gender
+	}
+
+	if input.Department != nil {
+		department := strings.TrimSpace(*input.Department)
+		input.Department = &department
+		if department == "" {
+			input.Department = nil
+		}
+	}
+
+	employmentType := models.EmploymentFullTime
+	if input.EmploymentType != nil {
+		employmentType, err = service.NormalizeEmploymentType(*input.EmploymentType)
+		if err != nil {
+			utils.RespondWithError(c, 400, err.Error())
+			return
+		}
+	}
+
 	// SET DEFAULT SALARY TO 0 IF NOT PROVIDED
 	if input.Salary == nil {
 		zeroSalary := 0.0
@@ -144,6 +172,8 @@ func (h *HandlerFunc) CreateEmployee(c *gin.Context) {
 		input.FullName, input.Email,
 		roleID, hash,
 		input.Salary, input.JoiningDate,
+		input.Gender, input.Department,
+		employmentType,
 	)
 	if err != nil {
 		utils.RespondWithError(c, 500, "failed to create employee")
@@ -434,11 +464,14 @@ func (h *HandlerFunc) UpdateEmployeeInfo(c *gin.Context) {
 
 	// 4️ Bind input JSON
 	var input struct {
-		FullName    *string    `json:"full_name"`
-		Email       *string    `json:"email"`
-		Salary      *float64   `json:"salary"`
-		JoiningDate *time.Time `json:"joining_date"`
-		EndingDate  *time.Time `json:"ending_date"`
+		FullName       *string    `json:"full_name"`
+		Email          *string    `json:"email"`
+		Salary         *float64   `json:"salary"`
+		JoiningDate    *time.Time `json:"joining_date"`
+		EndingDate     *time.Time `json:"ending_date"`
+		Gender         *string    `json:"gender"`
+		Department     *string    `json:"department"`
+		EmploymentType *string    `json:"employment_type"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		utils.RespondWithError(c, 400, "invalid input: "+err.Error())
@@ -455,6 +488,13 @@ func (h *HandlerFunc) UpdateEmployeeInfo(c *gin.Context) {
 		return
 	}
 
+	// Gender, department and employment type can be set by admins and HR (used for leave
+	// eligibility, entitlements and team calendars)
+	if (input.Gender != nil || input.Department != nil || input.EmploymentType != nil) && !isAdmin && role != constant.ROLE_HR {
+		utils.RespondWithError(c, 403, "only SUPERADMIN, ADMIN and HR can update gender, department and employment type")
+		return
+	}
+
 	// Check if trying to update someone else's name
 	if input.FullName != nil && !isSelf && !isAdmin {
 		utils.RespondWithError(c, 403, "you can only update your own name")
@@ -507,8 +547,39 @@ func (h *HandlerFunc) UpdateEmployeeInfo(c *gin.Context) {
 		finalEndingDate = input.EndingDate
 	}
 
+	finalGender := existingEmp.Gender
+	if input.Gender != nil {
+		gender, err := service.NormalizeGender(*input.Gender)
+		if err != nil {
+			utils.RespondWithError(c, 400, err.Error())
+			return
+		}
+		finalGender = &gender
+	}
+
+	finalDepartment := existingEmp.Department
+	if input.Department != nil {
+		department := strings.TrimSpace(*input.Department)
+		finalDepartment = &department
+		if department == "" {
+			finalDepartment = nil
+		}
+	}
+
+	finalEmploymentType := models.EmploymentFullTime
+	if existingEmp.EmploymentType != nil {
+		finalEmploymentType = *existingEmp.EmploymentType
+	}
+	if input.EmploymentType != nil {
+		finalEmploymentType, err = service.NormalizeEmploymentType(*input.EmploymentType)
+		if err != nil {
+			utils.RespondWithError(c, 400, err.Error())
+			return
+		}
+	}
+
 	// 8️ Update employee info
-	err = h.Query.UpdateEmployeeInfo(empID, finalName, finalEmail, finalSalary, finalJoiningDate, finalEndingDate)
+	err = h.Query.UpdateEmployeeInfo(empID, finalName, finalEmail, finalSalary, finalJoiningDate, finalEndingDate, finalGender, finalDepartment, finalEmploymentType)
 	if err != nil {
 		utils.RespondWithError(c, 500, "failed to update employee: "+err.Error())
 		return
@@ -18,8 +18,6 @@ import (
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
 )
 
-// AdminAddLeave - POST /api/leaves/admin-add
-
 func (h *HandlerFunc) ApplyLeave(c *gin.Context) {
 
 	// Extract Employee Info
@@ -52,9 +50,10 @@ func (h *HandlerFunc) ApplyLeave(c *gin.Context) {
 		return
 	}
 
-	// Bind Input
+	// Bind Input (JSON, or multipart with "data" and "attachments")
 	var input models.LeaveInput
-	if err := c.ShouldBindJSON(&input); err != nil {
+	files, err := bindLeaveRequest(c, &input)
+	if err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
 		return
 	}
@@ -96,24 +95,23 @@ func (h *HandlerFunc) ApplyLeave(c *gin.Context) {
 		return
 	}
 
+	// Supporting documents uploaded with the request
+	attachments, err := storeLeaveAttachments(c, files, employeeID)
+	if err != nil {
+		utils.RespondWithError(c, 400, err.Error())
+		return
+	}
+
 	// Final Leave ID to return
 	var leaveID uuid.UUID
 	var Days float64
+	var Hours *float64
+	var violations []models.PolicyViolation
+	var availableBalance, balanceAfterLeave float64
 
 	// Execute Transaction
 	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
 
-		// Working days with timing consideration
-		leaveDays, err := service.CalculateWorkingDaysWithTiming(h.Query, tx, input.StartDate, input.EndDate, *input.LeaveTimingID)
-		if err != nil {
-			return utils.CustomErr(c, 500, "Failed to calculate leave days: "+err.Error())
-		}
-		if leaveDays <= 0 {
-			return utils.CustomErr(c, 400, "Leave days must be greater than 0")
-		}
-		input.Days = &leaveDays
-		Days = leaveDays
-
 		// Leave Type
 		leaveType, err := h.Query.GetLeaveTypeByIdTx(tx, input.LeaveTypeID)
 		if err == sql.ErrNoRows {
@@ -123,20 +121,53 @@ func (h *HandlerFunc) ApplyLeave(c *gin.Context) {
 			return utils.CustomErr(c, 500, "Failed to fetch leave type: "+err.Error())
 		}
 
-		// Leave Balance
-		balance, err := h.Query.GetLeaveBalance(tx, employeeID, input.LeaveTypeID)
+		// Working days with timing consideration (hours for HOUR leave types)
+		qty, err := service.CalculateLeaveQuantity(h.Query, tx, leaveType, input.StartDate, input.EndDate, *input.LeaveTimingID, input.StartDayTimingID, input.EndDayTimingID, input.StartTime, input.EndTime)
+		if err != nil {
+			return utils.CustomErr(c, 400, "Failed to calculate leave days: "+err.Error())
+		}
+		if qty.Balance <= 0 {
+			return utils.CustomErr(c, 400, "Leave days must be greater than 0")
+		}
+		input.Days = &qty.Days
+		Days = qty.Days
+		Hours = qty.Hours
+
+		// Leave Balance of the year the leave is taken in
+		balance, err := h.Query.GetLeaveBalance(tx, employeeID, input.LeaveTypeID, input.StartDate.Year())
 		if err == sql.ErrNoRows {
-			balance = float64(leaveType.DefaultEntitlement)
-			if err := h.Query.CreateLeaveBalance(tx, employeeID, input.LeaveTypeID, leaveType.DefaultEntitlement); err != nil {
+			balance, err = h.Query.CreateLeaveBalance(tx, employeeID, input.LeaveTypeID, input.StartDate.Year())
+			if err != nil {
 				return utils.CustomErr(c, 500, "Failed to create leave balance: "+err.Error())
 			}
 		} else if err != nil {
 			return utils.CustomErr(c, 500, "Failed to fetch leave balance: "+err.Error())
 		}
 
-		// Check balance
-		if balance < leaveDays {
-			return utils.CustomErr(c, 400, "Insufficient leave balance")
+		// Future leaves are checked against the balance projected for their start date
+		// (future accruals, leaves awaiting approval, year-end carry-forward)
+		if service.StartsAfterToday(input.StartDate) {
+			projection, err := service.ProjectLeaveBalance(h.Query, tx, employeeID, leaveType, input.StartDate)
+			if err != nil {
+				return utils.CustomErr(c, 400, "Failed to project leave balance: "+err.Error())
+			}
+			balance = projection.ProjectedBalance
+		}
+		availableBalance = balance
+		balanceAfterLeave = balance - qty.Balance
+
+		// Leave policy (notice, consecutive days, eligibility, blackouts, balance)
+		violations, err = service.CheckLeavePolicy(h.Query, tx, employeeID, leaveType, input.StartDate, input.EndDate, qty, balance, len(attachments) > 0)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to check leave policy: "+err.Error())
+		}
+		coverage, err := service.CheckTeamCoverage(h.Query, tx, employeeID, input.StartDate, input.EndDate)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to check team coverage: "+err.Error())
+		}
+		violations = append(violations, coverage...)
+		if service.HasBlockingViolation(violations) {
+			return utils.CustomErr(c, http.StatusUnprocessableEntity, "Leave request violates leave policy")
 		}
 
 		// Overlapping Leave
@@ -156,12 +187,16 @@ func (h *HandlerFunc) ApplyLeave(c *gin.Context) {
 		}
 
 		// Insert Leave
-		id, err := h.Query.InsertLeave(tx, employeeID, input.LeaveTypeID, *input.LeaveTimingID, input.StartDate, input.EndDate, leaveDays, input.Reason)
+		id, err := h.Query.InsertLeave(tx, employeeID, input.LeaveTypeID, *input.LeaveTimingID, input.StartDate, input.EndDate, qty, input.Reason)
 		if err != nil {
 			return utils.CustomErr(c, 500, "Failed to apply leave: "+err.Error())
 		}
 		leaveID = id
 
+		if err := h.insertLeaveAttachments(tx, leaveID, attachments); err != nil {
+			return utils.CustomErr(c, 500, "Failed to save attachments: "+err.Error())
+		}
+
 		// Log Entry
 		data := &models.Common{
 			Component:  constant.ComponentLeave,
@@ -175,9 +210,17 @@ func (h *HandlerFunc) ApplyLeave(c *gin.Context) {
 		return nil // IMPORTANT FIX
 	})
 
-	// If transaction returned an error, stop (CustomErr already responded)
 	if err != nil {
-		utils.RespondWithError(c, 500, "Failed to update settings: "+err.Error())
+		removeAttachmentFiles(attachments)
+		if service.HasBlockingViolation(violations) {
+			utils.RespondWithViolations(c, http.StatusUnprocessableEntity, "Leave request violates leave policy", violations)
+			return
+		}
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to apply leave: "+err.Error())
 		return
 	}
 
@@ -236,7 +279,327 @@ func (h *HandlerFunc) ApplyLeave(c *gin.Context) {
 		"message":  "Leave applied successfully",
 		"leave_id": leaveID,
 		"days":     Days,
-		"reason":   input.Reason,
+		"hours":    Hours,
+		"reason":      input.Reason,
+		"available_balance":   availableBalance,
+		"balance_after_leave": balanceAfterLeave,
+		"warnings":    violations,
+		"attachments": withDownloadURLs(attachments),
+	})
+}
+
+// AdminAddLeave - POST /api/leaves/admin-add
+// Records leave on behalf of an employee (e.g. sick and unable to log in).
+// SUPERADMIN/ADMIN/HR can add leave for anyone; MANAGER only for direct reports
+// and only when allow_manager_add_leave is enabled in company settings.
+// auto_approve (default true) marks the leave APPROVED and deducts balance immediately.
+// The leave is checked against the leave policy and team coverage like an application;
+// SUPERADMIN/ADMIN/HR can add it despite blocking violations with override=true, which is logged.
+func (h *HandlerFunc) AdminAddLeave(c *gin.Context) {
+	// 1️ Get current user info
+	role := c.GetString("role")
+	currentUserID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	// 2️ Permission check
+	if role != constant.ROLE_SUPER_ADMIN && role != constant.ROLE_ADMIN && role != constant.ROLE_HR && role != constant.ROLE_MANAGER {
+		utils.RespondWithError(c, http.StatusForbidden, "Not permitted to add leave on behalf of employees")
+		return
+	}
+
+	if role == constant.ROLE_MANAGER {
+		allowed, err := h.Query.ChackManagerPermission()
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get manager permission")
+			return
+		}
+		if !allowed {
+			utils.RespondWithError(c, http.StatusForbidden, "Managers are not allowed to add leave on behalf of employees")
+			return
+		}
+	}
+
+	// 3️ Bind Input (JSON, or multipart with "data" and "attachments")
+	var input models.LeaveInput
+	files, err := bindLeaveRequest(c, &input)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if input.EmployeeID == uuid.Nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "employee_id is required")
+		return
+	}
+	if input.EmployeeID == currentUserID {
+		utils.RespondWithError(c, http.StatusBadRequest, "Use /api/leaves/apply to apply for your own leave")
+		return
+	}
+	if input.Override && role == constant.ROLE_MANAGER {
+		utils.RespondWithError(c, http.StatusForbidden, "Managers cannot override the leave policy")
+		return
+	}
+
+	// 4️ Validate target employee
+	empStatus, err := h.Query.GetEmployeeStatus(input.EmployeeID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "Employee not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to verify employee status")
+		return
+	}
+	if empStatus == "deactive" {
+		utils.RespondWithError(c, http.StatusForbidden, "Employee account is deactivated. Cannot add leave")
+		return
+	}
+
+	if role == constant.ROLE_MANAGER {
+		var managerID uuid.UUID
+		err = h.Query.DB.Get(&managerID, "SELECT COALESCE(manager_id, '00000000-0000-0000-0000-000000000000') FROM Tbl_Employee WHERE id=$1", input.EmployeeID)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to verify reporting relationship")
+			return
+		}
+		if managerID != currentUserID {
+			utils.RespondWithError(c, http.StatusForbidden, "You can only add leave for employees who report to you")
+			return
+		}
+	}
+
+	// Set default timing to Full Day (ID 3) if not provided
+	if input.LeaveTimingID == nil {
+		defaultTiming := 3
+		input.LeaveTimingID = &defaultTiming
+	}
+	if *input.LeaveTimingID < 1 || *input.LeaveTimingID > 3 {
+		utils.RespondWithError(c, 400, "Invalid leave timing ID. Must be 1 (First Half), 2 (Second Half), or 3 (Full Day)")
+		return
+	}
+
+	// Validate Reason
+	input.Reason = strings.TrimSpace(input.Reason)
+	if len(input.Reason) < 10 {
+		utils.RespondWithError(c, 400, "Leave reason must be at least 10 characters long")
+		return
+	}
+	if len(input.Reason) > 500 {
+		utils.RespondWithError(c, 400, "Leave reason is too long. Maximum 500 characters allowed")
+		return
+	}
+	if input.EndDate.Before(input.StartDate) {
+		utils.RespondWithError(c, 400, "End date cannot be earlier than start date")
+		return
+	}
+
+	// Pre-approve by default
+	autoApprove := true
+	if input.AutoApprove != nil {
+		autoApprove = *input.AutoApprove
+	}
+	status := "Pending"
+	input.AppliedByID = &currentUserID
+	input.ApprovedByID = nil
+	if autoApprove {
+		status = "APPROVED"
+		input.ApprovedByID = &currentUserID
+	}
+
+	attachments, err := storeLeaveAttachments(c, files, currentUserID)
+	if err != nil {
+		utils.RespondWithError(c, 400, err.Error())
+		return
+	}
+
+	var leaveID uuid.UUID
+	var Days float64
+	var Hours *float64
+	var violations []models.PolicyViolation
+
+	// 5️ Execute Transaction
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+
+		leaveType, err := h.Query.GetLeaveTypeByIdTx(tx, input.LeaveTypeID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, 400, "Invalid leave type")
+		}
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch leave type: "+err.Error())
+		}
+
+		qty, err := service.CalculateLeaveQuantity(h.Query, tx, leaveType, input.StartDate, input.EndDate, *input.LeaveTimingID, input.StartDayTimingID, input.EndDayTimingID, input.StartTime, input.EndTime)
+		if err != nil {
+			return utils.CustomErr(c, 400, "Failed to calculate leave days: "+err.Error())
+		}
+		if qty.Balance <= 0 {
+			return utils.CustomErr(c, 400, "Leave days must be greater than 0")
+		}
+		Days = qty.Days
+		Hours = qty.Hours
+		unit := "days"
+		if leaveType.Unit == models.LeaveUnitHour {
+			unit = "hours"
+		}
+
+		// The leave is deducted from the balance of the year it is taken in, which must still be open
+		year := input.StartDate.Year()
+		if autoApprove {
+			closed, err := h.Query.YearEndClosedTx(tx, year)
+			if err != nil {
+				return utils.CustomErr(c, 500, "failed to check year-end close: "+err.Error())
+			}
+			if closed {
+				return utils.CustomErr(c, 409, fmt.Sprintf("leave balances of %d are closed by the year-end close; leaves of that year can no longer be approved", year))
+			}
+		}
+		balance, err := h.Query.GetLeaveBalance(tx, input.EmployeeID, input.LeaveTypeID, year)
+		if err == sql.ErrNoRows {
+			balance, err = h.Query.CreateLeaveBalance(tx, input.EmployeeID, input.LeaveTypeID, year)
+			if err != nil {
+				return utils.CustomErr(c, 500, "Failed to create leave balance: "+err.Error())
+			}
+		} else if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch leave balance: "+err.Error())
+		}
+
+		if !service.BalanceAllows(leaveType, balance, qty.Balance) {
+			return utils.CustomErr(c, 400, fmt.Sprintf("Insufficient leave balance. Available: %.1f %s, Required: %.1f %s", balance, unit, qty.Balance, unit))
+		}
+
+		// Leave policy and team coverage, as for an application
+		violations, err = service.CheckLeavePolicy(h.Query, tx, input.EmployeeID, leaveType, input.StartDate, input.EndDate, qty, balance, len(attachments) > 0)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to check leave policy: "+err.Error())
+		}
+		coverage, err := service.CheckTeamCoverage(h.Query, tx, input.EmployeeID, input.StartDate, input.EndDate)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to check team coverage: "+err.Error())
+		}
+		violations = append(violations, coverage...)
+		if service.HasBlockingViolation(violations) {
+			if !input.Override {
+				return utils.CustomErr(c, http.StatusUnprocessableEntity, "Leave request violates leave policy")
+			}
+			override := models.NewCommon(constant.ComponentLeave, constant.ActionOverride, currentUserID)
+			if err := h.Query.AddLog(override, tx); err != nil {
+				return utils.CustomErr(c, 500, "Failed to create leave log: "+err.Error())
+			}
+		}
+
+		// Approval needs the supporting document the leave type requires
+		if autoApprove && len(attachments) == 0 && service.AttachmentRequired(leaveType, qty.Days) {
+			return utils.CustomErr(c, http.StatusUnprocessableEntity, fmt.Sprintf("%s over %.1f days requires a supporting document. Attach it or set auto_approve to false", leaveType.Name, *leaveType.AttachmentRequiredAfterDays))
+		}
+
+		overlaps, err := h.Query.GetOverlappingLeaves(tx, input.EmployeeID, input.StartDate, input.EndDate)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to check overlapping leave")
+		}
+		if len(overlaps) > 0 {
+			ov := overlaps[0]
+			return utils.CustomErr(c, 400, fmt.Sprintf(
+				"Overlapping leave exists: %s from %s to %s (Status: %s)",
+				ov.LeaveType,
+				ov.StartDate.Format("2006-01-02"),
+				ov.EndDate.Format("2006-01-02"),
+				ov.Status,
+			))
+		}
+
+		id, err := h.Query.InsertLeaveOnBehalf(tx, input, *input.LeaveTimingID, qty, status)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to add leave: "+err.Error())
+		}
+		leaveID = id
+
+		if err := h.insertLeaveAttachments(tx, leaveID, attachments); err != nil {
+			return utils.CustomErr(c, 500, "Failed to save attachments: "+err.Error())
+		}
+
+		if autoApprove {
+			if err := h.Query.DeductLeaveBalance(tx, input.EmployeeID, input.LeaveTypeID, year, qty.Balance, leaveID, &currentUserID); err != nil {
+				return utils.CustomErr(c, 500, "Failed to update leave balance: "+err.Error())
+			}
+		}
+
+		data := models.NewCommon(constant.ComponentLeave, constant.ActionCreate, currentUserID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create leave log: "+err.Error())
+		}
+		return nil
+	})
+
+	if err != nil {
+		removeAttachmentFiles(attachments)
+		if !input.Override && service.HasBlockingViolation(violations) {
+			utils.RespondWithViolations(c, http.StatusUnprocessableEntity, "Leave request violates leave policy", violations)
+			return
+		}
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to add leave: "+err.Error())
+		return
+	}
+
+	// 6️ Notifications (async)
+	go func() {
+		leaveType, _ := h.Query.GetLeaveTypeById(input.LeaveTypeID)
+
+		empDetails, err := h.Query.GetEmployeeDetailsForNotification(input.EmployeeID)
+		if err != nil {
+			fmt.Printf("Failed to get employee details for notification: %v\n", err)
+			return
+		}
+
+		if !autoApprove {
+			recipients, _ := h.Query.GetAdminAndEmployeeEmail(input.EmployeeID)
+			if len(recipients) > 0 {
+				utils.SendLeaveApplicationEmail(
+					recipients,
+					empDetails.FullName,
+					leaveType.Name,
+					input.StartDate.Format("2006-01-02"),
+					input.EndDate.Format("2006-01-02"),
+					Days,
+					input.Reason,
+				)
+			}
+			return
+		}
+
+		var addedByName string
+		h.Query.DB.Get(&addedByName, "SELECT full_name FROM Tbl_Employee WHERE id=$1", currentUserID)
+
+		if err := utils.SendLeaveAddedByAdminEmail(
+			empDetails.Email,
+			empDetails.FullName,
+			leaveType.Name,
+			input.StartDate.Format("2006-01-02"),
+			input.EndDate.Format("2006-01-02"),
+			Days,
+			addedByName,
+			role,
+		); err != nil {
+			fmt.Printf("Failed to send leave added email: %v\n", err)
+		}
+	}()
+
+	c.JSON(200, gin.H{
+		"message":    "Leave added successfully",
+		"leave_id":   leaveID,
+		"employee":   input.EmployeeID,
+		"days":       Days,
+		"hours":      Hours,
+		"status":      status,
+		"applied_by":  currentUserID,
+		"attachments": withDownloadURLs(attachments),
+		"warnings":    violations,
+		"override":    input.Override && service.HasBlockingViolation(violations),
 	})
 }
 
@@ -293,6 +656,26 @@ func (s *HandlerFunc) AdminAddLeavePolicy(c *gin.Context) {
 		utils.RespondWithError(c, http.StatusBadRequest, "leave_count must be greater than 0")
 		return
 	}
+	if input.Unit == nil {
+		defaultUnit := models.LeaveUnitDay
+		input.Unit = &defaultUnit
+	}
+	*input.Unit = strings.ToUpper(strings.TrimSpace(*input.Unit))
+	if *input.Unit != models.LeaveUnitDay && *input.Unit != models.LeaveUnitHour {
+		utils.RespondWithError(c, http.StatusBadRequest, "unit must be DAY or HOUR")
+		return
+	}
+	var policy models.LeaveType
+	if err := service.ApplyLeaveTypePolicyInput(&policy, input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.EntitlementRules != nil {
+		if err := service.NormalizeEntitlementRules(*input.EntitlementRules); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 	var leave models.LeaveType
 
 	err = common.ExecuteTransaction(c, s.Query.DB, func(tx *sqlx.Tx) error {
@@ -303,8 +686,30 @@ func (s *HandlerFunc) AdminAddLeavePolicy(c *gin.Context) {
 		Leave.Name = input.Name
 		Leave.IsPaid = *input.IsPaid
 		Leave.DefaultEntitlement = *input.DefaultEntitlement
+		Leave.Unit = *input.Unit
+
+		if err := s.Query.UpdateLeaveTypePolicy(tx, Leave.ID, policy); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "Failed to save leave policy rules: "+err.Error())
+		}
+		Leave.MinNoticeDays = policy.MinNoticeDays
+		Leave.MaxConsecutiveDays = policy.MaxConsecutiveDays
+		Leave.AllowedGenders = policy.AllowedGenders
+		Leave.MinTenureMonths = policy.MinTenureMonths
+		Leave.AttachmentRequiredAfterDays = policy.AttachmentRequiredAfterDays
+		Leave.AllowNegativeBalance = policy.AllowNegativeBalance
+		Leave.MaxNegativeBalance = policy.MaxNegativeBalance
+		Leave.AccrualFrequency = policy.AccrualFrequency
+		Leave.CarryForwardMax = policy.CarryForwardMax
+		Leave.Encashable = policy.Encashable
+		Leave.EncashMax = policy.EncashMax
 		leave = Leave
 
+		if input.EntitlementRules != nil {
+			if err := s.Query.ReplaceLeaveEntitlementRules(tx, Leave.ID, *input.EntitlementRules); err != nil {
+				return utils.CustomErr(c, http.StatusInternalServerError, "Failed to save entitlement rules: "+err.Error())
+			}
+		}
+
 		// Log Entry
 		data := &models.Common{
 			Component:  constant.ComponentLeaveType,
@@ -436,6 +841,28 @@ func (s *HandlerFunc) ActionLeave(c *gin.Context) {
 		}
 	}
 
+	// Approval is blocked until a required supporting document is uploaded
+	if body.Action == constant.LEAVE_APPROVE {
+		leaveType, err := s.Query.GetLeaveTypeByIdTx(tx, leave.LeaveTypeID)
+		if err != nil {
+			utils.RespondWithError(c, 500, "Failed to fetch leave type: "+err.Error())
+			return
+		}
+		ok, err := s.checkApprovalAttachment(tx, leaveType, leaveID, leave.Days)
+		if err != nil {
+			utils.RespondWithError(c, 500, "Failed to fetch attachments: "+err.Error())
+			return
+		}
+		if !ok {
+			utils.RespondWithViolations(c, http.StatusUnprocessableEntity, "A supporting document is required before this leave can be approved", []models.PolicyViolation{{
+				Code:     models.ViolationAttachmentRequired,
+				Message:  fmt.Sprintf("%s over %.1f days requires a supporting document", leaveType.Name, *leaveType.AttachmentRequiredAfterDays),
+				Blocking: true,
+			}})
+			return
+		}
+	}
+
 	// ========================================
 	// REJECT ACTION - Two-Step Process
 	// ========================================
@@ -596,22 +1023,52 @@ func (s *HandlerFunc) ActionLeave(c *gin.Context) {
 	// APPROVE ACTION
 	// ========================================
 
-	// Check balance before any approval
-	var currentBalance float64
-	err = tx.Get(&currentBalance, `
-		SELECT closing 
-		FROM Tbl_Leave_balance 
-		WHERE employee_id=$1 AND leave_type_id=$2 
-		AND year = EXTRACT(YEAR FROM CURRENT_DATE)
-	`, leave.EmployeeID, leave.LeaveTypeID)
+	// The leave is deducted from the balance of the year it is taken in, which must still be open
+	leaveYear := leave.StartDate.Year()
+	closed, err := s.Query.YearEndClosedTx(tx, leaveYear)
+	if err != nil {
+		utils.RespondWithError(c, 500, "failed to check year-end close: "+err.Error())
+		return
+	}
+	if closed {
+		utils.RespondWithError(c, 409, fmt.Sprintf("leave balances of %d are closed by the year-end close; leaves of that year can no longer be approved", leaveYear))
+		return
+	}
 
+	// Check balance before any approval
+	currentBalance, err := s.Query.GetLeaveBalance(tx, leave.EmployeeID, leave.LeaveTypeID, leaveYear)
+	if err == sql.ErrNoRows {
+		currentBalance, err = s.Query.GetOpeningEntitlementTx(tx, leave.EmployeeID, leave.LeaveTypeID, leaveYear)
+	}
 	if err != nil {
 		utils.RespondWithError(c, 500, "Failed to fetch leave balance: "+err.Error())
 		return
 	}
 
-	if currentBalance < leave.Days {
-		utils.RespondWithError(c, 400, fmt.Sprintf("Cannot approve: Insufficient leave balance. Available: %.1f days, Required: %.1f days", currentBalance, leave.Days))
+	leaveType, err := s.Query.GetLeaveTypeByIdTx(tx, leave.LeaveTypeID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch leave type: "+err.Error())
+		return
+	}
+
+	required := leave.BalanceQuantity()
+
+	// Future leaves are checked against the balance projected for their start date, as when
+	// they were applied. The projection deducts the leaves awaiting approval, this one included
+	if service.StartsAfterToday(leave.StartDate) {
+		projection, err := service.ProjectLeaveBalance(s.Query, tx, leave.EmployeeID, leaveType, leave.StartDate)
+		if err != nil {
+			utils.RespondWithError(c, 400, "Failed to project leave balance: "+err.Error())
+			return
+		}
+		currentBalance = projection.ProjectedBalance + required
+	}
+	if !service.BalanceAllows(leaveType, currentBalance, required) {
+		unit := "days"
+		if leave.Hours != nil {
+			unit = "hours"
+		}
+		utils.RespondWithError(c, 400, fmt.Sprintf("Cannot approve: Insufficient leave balance. Available: %.1f %s, Required: %.1f %s", currentBalance, unit, required, unit))
 		return
 	}
 
@@ -696,8 +1153,7 @@ func (s *HandlerFunc) ActionLeave(c *gin.Context) {
 		}
 
 		// Deduct from leave balance
-		_, err = tx.Exec(`UPDATE Tbl_Leave_balance SET used = used + $3, closing = closing - $3, updated_at = NOW() WHERE employee_id=$1 AND leave_type_id=$2`,
-			leave.EmployeeID, leave.LeaveTypeID, leave.Days)
+		err = s.Query.DeductLeaveBalance(tx, leave.EmployeeID, leave.LeaveTypeID, leaveYear, required, leaveID, &approverID)
 		if err != nil {
 			utils.RespondWithError(c, 500, "Failed to update leave balance: "+err.Error())
 			return
@@ -988,6 +1444,8 @@ func (h *HandlerFunc) CancelLeave(c *gin.Context) {
 // Two-level withdrawal approval system:
 // 1. MANAGER initiates withdrawal → Status: WITHDRAWAL_PENDING (no balance restoration)
 // 2. ADMIN/SUPERADMIN finalizes → Status: WITHDRAWN (balance restored)
+// Cancellation requests raised by the employee (RequestLeaveCancellation) are also
+// WITHDRAWAL_PENDING and can be finalized by the employee's MANAGER or ADMIN/SUPERADMIN.
 func (h *HandlerFunc) WithdrawLeave(c *gin.Context) {
 	// 1️ Get current user info
 	role := c.GetString("role")
@@ -1038,7 +1496,8 @@ func (h *HandlerFunc) WithdrawLeave(c *gin.Context) {
 	// 6️ Fetch leave details
 	var leave models.Leave
 	err = tx.Get(&leave, `
-		SELECT id, employee_id, leave_type_id, start_date, end_date, days, status, created_at
+		SELECT id, employee_id, leave_type_id, start_date, end_date, days, hours, status, created_at,
+			withdrawal_requested_by, withdrawal_reason
 		FROM Tbl_Leave 
 		WHERE id=$1 
 		FOR UPDATE
@@ -1072,8 +1531,9 @@ func (h *HandlerFunc) WithdrawLeave(c *gin.Context) {
 			return
 		}
 
-		// Manager can only act on APPROVED leaves
-		if leave.Status != "APPROVED" {
+		// Manager can act on APPROVED leaves, or finalize a cancellation requested by the employee
+		employeeRequested := leave.Status == "WITHDRAWAL_PENDING" && leave.WithdrawalRequestedBy != nil && *leave.WithdrawalRequestedBy == leave.EmployeeID
+		if leave.Status != "APPROVED" && !employeeRequested {
 			utils.RespondWithError(c, 400, fmt.Sprintf("cannot withdraw leave with status: %s. Only approved leaves can be withdrawn", leave.Status))
 			return
 		}
@@ -1091,7 +1551,7 @@ func (h *HandlerFunc) WithdrawLeave(c *gin.Context) {
 	// ========================================
 	// MANAGER WITHDRAWAL REQUEST (First Level)
 	// ========================================
-	if role == "MANAGER" {
+	if role == "MANAGER" && leave.Status == "APPROVED" {
 		withdrawalReason := input.Reason
 		if withdrawalReason == "" {
 			withdrawalReason = "Withdrawal requested by Manager"
@@ -1100,7 +1560,8 @@ func (h *HandlerFunc) WithdrawLeave(c *gin.Context) {
 		// Update status to WITHDRAWAL_PENDING
 		_, err = tx.Exec(`
 			UPDATE Tbl_Leave 
-			SET status='WITHDRAWAL_PENDING', reason=$1, approved_by=$2, updated_at=NOW() 
+			SET status='WITHDRAWAL_PENDING', reason=$1, approved_by=$2, updated_at=NOW(),
+			    withdrawal_requested_by=$2, withdrawal_reason=$1, withdrawal_requested_at=NOW()
 			WHERE id=$3
 		`, withdrawalReason, currentUserID, leaveID)
 		if err != nil {
@@ -1159,13 +1620,28 @@ func (h *HandlerFunc) WithdrawLeave(c *gin.Context) {
 
 	// ========================================
 	// ADMIN/SUPERADMIN FINAL WITHDRAWAL (Second Level)
+	// (MANAGER reaches here only for cancellations requested by the employee)
 	// ========================================
-	if role == "ADMIN" || role == "SUPERADMIN" {
+	if role == "ADMIN" || role == "SUPERADMIN" || role == "MANAGER" {
 		withdrawalReason := input.Reason
+		if withdrawalReason == "" && leave.WithdrawalReason != nil {
+			withdrawalReason = *leave.WithdrawalReason
+		}
 		if withdrawalReason == "" {
 			withdrawalReason = fmt.Sprintf("Withdrawn by %s", role)
 		}
 
+		// The balance is restored to the year the leave was taken in, which must still be open
+		closed, err := h.Query.YearEndClosedTx(tx, leave.StartDate.Year())
+		if err != nil {
+			utils.RespondWithError(c, 500, "failed to check year-end close: "+err.Error())
+			return
+		}
+		if closed {
+			utils.RespondWithError(c, 409, fmt.Sprintf("leave balances of %d are closed by the year-end close; leaves of that year can no longer be withdrawn", leave.StartDate.Year()))
+			return
+		}
+
 		// Update status to WITHDRAWN
 		_, err = tx.Exec(`
 			UPDATE Tbl_Leave 
@@ -1178,11 +1654,7 @@ func (h *HandlerFunc) WithdrawLeave(c *gin.Context) {
 		}
 
 		// Restore leave balance (reverse the deduction)
-		_, err = tx.Exec(`
-			UPDATE Tbl_Leave_balance 
-			SET used = used - $1, closing = closing + $1, updated_at = NOW()
-			WHERE employee_id=$2 AND leave_type_id=$3 AND year = EXTRACT(YEAR FROM CURRENT_DATE)
-		`, leave.Days, leave.EmployeeID, leave.LeaveTypeID)
+		err = h.Query.RestoreLeaveBalance(tx, leave.EmployeeID, leave.LeaveTypeID, leave.StartDate.Year(), leave.BalanceQuantity(), leaveID, &currentUserID)
 		if err != nil {
 			utils.RespondWithError(c, 500, "failed to restore leave balance: "+err.Error())
 			return
@@ -1272,6 +1744,7 @@ func (h *HandlerFunc) WithdrawLeave(c *gin.Context) {
 			"status":            "WITHDRAWN",
 			"leave_id":          leaveID,
 			"days_restored":     leave.Days,
+			"hours_restored":    leave.Hours,
 			"withdrawal_by":     currentUserID,
 			"withdrawal_role":   role,
 			"withdrawal_reason": withdrawalReason,
@@ -1323,6 +1796,11 @@ func (h *HandlerFunc) GetManagerLeaveHistory(c *gin.Context) {
 			l.start_date,
 			l.end_date,
 			l.days,
+			l.hours,
+			l.start_half_id,
+			l.end_half_id,
+			l.start_time,
+			l.end_time,
 			COALESCE(l.reason, '') AS reason,
 			l.status,
 			l.created_at AS applied_at,
@@ -1389,6 +1867,11 @@ func (h *HandlerFunc) GetLeaveByID(c *gin.Context) {
 			l.start_date,
 			l.end_date,
 			l.days,
+			l.hours,
+			l.start_half_id,
+			l.end_half_id,
+			l.start_time,
+			l.end_time,
 			COALESCE(l.reason, '') AS reason,
 			l.status,
 			l.created_at AS applied_at,
@@ -1447,9 +1930,16 @@ func (h *HandlerFunc) GetLeaveByID(c *gin.Context) {
 		return
 	}
 
+	attachments, err := h.Query.GetLeaveAttachments(leaveID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch attachments: "+err.Error())
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Leave details fetched successfully",
-		"data":    result,
+		"message":     "Leave details fetched successfully",
+		"data":        result,
+		"attachments": withDownloadURLs(attachments),
 	})
 }
 
@@ -1636,6 +2126,19 @@ func (h *HandlerFunc) UpdateLeavePolicy(c *gin.Context) {
 		utils.RespondWithError(c, http.StatusBadRequest, "Default entitlement cannot be negative")
 		return
 	}
+	if input.Unit != nil {
+		*input.Unit = strings.ToUpper(strings.TrimSpace(*input.Unit))
+		if *input.Unit != models.LeaveUnitDay && *input.Unit != models.LeaveUnitHour {
+			utils.RespondWithError(c, http.StatusBadRequest, "unit must be DAY or HOUR")
+			return
+		}
+	}
+	if input.EntitlementRules != nil {
+		if err := service.NormalizeEntitlementRules(*input.EntitlementRules); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 
 	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
 		// Check if leave type exists and get old default entitlement
@@ -1647,6 +2150,20 @@ func (h *HandlerFunc) UpdateLeavePolicy(c *gin.Context) {
 			return utils.CustomErr(c, http.StatusInternalServerError, "Failed to fetch leave type: "+err.Error())
 		}
 
+		// Keep the existing unit unless a change is requested; the unit cannot
+		// change once leaves exist since balances are stored in that unit
+		if input.Unit == nil {
+			input.Unit = &oldLeaveType.Unit
+		} else if *input.Unit != oldLeaveType.Unit {
+			var leaveCount int
+			if err := tx.Get(&leaveCount, "SELECT COUNT(*) FROM Tbl_Leave WHERE leave_type_id = $1", leaveTypeID); err != nil {
+				return utils.CustomErr(c, http.StatusInternalServerError, "Failed to check existing leaves: "+err.Error())
+			}
+			if leaveCount > 0 {
+				return utils.CustomErr(c, http.StatusConflict, "Cannot change unit of a leave type that already has leave requests")
+			}
+		}
+
 		// Get old and new default entitlement values
 		oldDefaultEntitlement := oldLeaveType.DefaultEntitlement
 		newDefaultEntitlement := *input.DefaultEntitlement
@@ -1657,11 +2174,55 @@ func (h *HandlerFunc) UpdateLeavePolicy(c *gin.Context) {
 			return utils.CustomErr(c, http.StatusInternalServerError, "Failed to update leave type: "+err.Error())
 		}
 
-		// Update leave balances if default entitlement changed
-		if oldDefaultEntitlement != newDefaultEntitlement {
+		// Policy rules: omitted fields keep their current value
+		policy := oldLeaveType
+		if err := service.ApplyLeaveTypePolicyInput(&policy, input); err != nil {
+			return utils.CustomErr(c, http.StatusBadRequest, err.Error())
+		}
+
+		// The comp-off leave type keeps a balance of comp-off credits only
+		compOff, err := h.Query.GetCompOffSettingsTx(tx)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "Failed to fetch comp-off settings: "+err.Error())
+		}
+		if compOff.LeaveTypeID != nil && *compOff.LeaveTypeID == leaveTypeID {
+			updated := policy
+			updated.DefaultEntitlement = newDefaultEntitlement
+			var tiers []float64
+			if input.EntitlementRules != nil {
+				for _, rule := range *input.EntitlementRules {
+					tiers = append(tiers, rule.Entitlement)
+				}
+			} else {
+				rules, err := h.Query.GetLeaveEntitlementRules(leaveTypeID)
+				if err != nil {
+					return utils.CustomErr(c, http.StatusInternalServerError, "Failed to fetch entitlement rules: "+err.Error())
+				}
+				for _, rule := range rules {
+					tiers = append(tiers, rule.Entitlement)
+				}
+			}
+			if err := service.ValidateCompOffLeaveType(updated, tiers); err != nil {
+				return utils.CustomErr(c, http.StatusBadRequest, err.Error())
+			}
+		}
+
+		if err := h.Query.UpdateLeaveTypePolicy(tx, leaveTypeID, policy); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "Failed to update leave policy rules: "+err.Error())
+		}
+
+		if input.EntitlementRules != nil {
+			if err := h.Query.ReplaceLeaveEntitlementRules(tx, leaveTypeID, *input.EntitlementRules); err != nil {
+				return utils.CustomErr(c, http.StatusInternalServerError, "Failed to save entitlement rules: "+err.Error())
+			}
+		}
+
+		// Recompute leave balances if the default entitlement or the entitlement tiers changed.
+		// Accruing leave types pick up the new entitlement from their next accrual instead
+		entitlementChanged := oldDefaultEntitlement != newDefaultEntitlement || input.EntitlementRules != nil
+		if entitlementChanged && policy.AccrualFrequency == models.AccrualNone && oldLeaveType.AccrualFrequency == models.AccrualNone {
 			currentYear := time.Now().Year()
-			err = h.Query.UpdateLeaveBalancesForEntitlementChange(tx, leaveTypeID, oldDefaultEntitlement, newDefaultEntitlement, currentYear)
-			if err != nil {
+			if _, err := h.Query.RecomputeLeaveEntitlements(tx, leaveTypeID, currentYear, employeeID); err != nil {
 				return utils.CustomErr(c, http.StatusInternalServerError, "Failed to update leave balances: "+err.Error())
 			}
 		}
@@ -1679,6 +2240,10 @@ func (h *HandlerFunc) UpdateLeavePolicy(c *gin.Context) {
 	})
 
 	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
 		utils.RespondWithError(c, 500, "Failed to update leave policy: "+err.Error())
 		return
 	}
@@ -1819,19 +2384,55 @@ func (h *HandlerFunc) EditMyLeave(c *gin.Context) {
 	}
 
 	// 4. Execute Transaction
+	var violations []models.PolicyViolation
 	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
-		newDays, err := service.CalculateWorkingDaysWithTiming(h.Query, tx, input.StartDate, input.EndDate, id)
+		leaveType, err := h.Query.GetLeaveTypeByIdTx(tx, input.LeaveTypeID)
+		if err != nil {
+			return fmt.Errorf("invalid leave type: %v", err)
+		}
+		qty, err := service.CalculateLeaveQuantity(h.Query, tx, leaveType, input.StartDate, input.EndDate, id, input.StartDayTimingID, input.EndDayTimingID, input.StartTime, input.EndTime)
+		if err != nil {
+			return err
+		}
+
+		// Pending leaves are not yet deducted, so the closing balance is what remains
+		balance, err := h.Query.GetLeaveBalance(tx, empID, input.LeaveTypeID, input.StartDate.Year())
+		if err == sql.ErrNoRows {
+			balance, err = h.Query.GetOpeningEntitlementTx(tx, empID, input.LeaveTypeID, input.StartDate.Year())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch leave balance: %v", err)
+		}
+
+		attachmentCount, err := h.Query.CountLeaveAttachmentsTx(tx, leaveID)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch attachments: "+err.Error())
+		}
+		violations, err = service.CheckLeavePolicy(h.Query, tx, empID, leaveType, input.StartDate, input.EndDate, qty, balance, attachmentCount > 0)
+		if err != nil {
+			return err
+		}
+		coverage, err := service.CheckTeamCoverage(h.Query, tx, empID, input.StartDate, input.EndDate)
 		if err != nil {
 			return err
 		}
-		return h.Query.UpdatePendingLeave(tx, leaveID, empID, input, newDays)
+		violations = append(violations, coverage...)
+		if service.HasBlockingViolation(violations) {
+			return fmt.Errorf("leave request violates leave policy")
+		}
+
+		return h.Query.UpdatePendingLeave(tx, leaveID, empID, input, qty)
 	})
 
 	if err != nil {
+		if service.HasBlockingViolation(violations) {
+			utils.RespondWithViolations(c, http.StatusUnprocessableEntity, "Leave request violates leave policy", violations)
+			return
+		}
 		// This will trigger if the leave is no longer PENDING
 		utils.RespondWithError(c, 403, err.Error())
 		return
 	}
 
-	c.JSON(200, gin.H{"message": "Leave updated successfully"})
+	c.JSON(200, gin.H{"message": "Leave updated successfully", "warnings": violations})
 }
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/access_role"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// RunLeaveAccrual - POST /api/leave-accruals/run?year=2026&dry_run=true
+// SUPERADMIN/ADMIN/HR credit accruing leave types for the completed periods of the year
+// (defaults to the current year). Already credited periods are skipped; dry_run=true returns
+// the credits without saving them.
+func (h *HandlerFunc) RunLeaveAccrual(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can run leave accruals"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	dryRun := c.Query("dry_run") == "true"
+
+	year := time.Now().Year()
+	if yearStr := c.Query("year"); yearStr != "" {
+		year, err = strconv.Atoi(yearStr)
+		if err != nil || year < 2000 || year > time.Now().Year() {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid year")
+			return
+		}
+	}
+
+	tx, err := h.Query.DB.Beginx()
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	accruals, err := service.RunLeaveAccruals(h.Query, tx, year, time.Now(), &empID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to run leave accruals: "+err.Error())
+		return
+	}
+
+	if !dryRun {
+		data := models.NewCommon(constant.ComponentLeaveAccrual, constant.ActionRun, empID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			utils.RespondWithError(c, 500, "Failed to create log: "+err.Error())
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			utils.RespondWithError(c, 500, "Transaction commit failed")
+			return
+		}
+	}
+
+	total := 0.0
+	for _, a := range accruals {
+		total += a.Amount
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Leave accrual completed",
+		"dry_run":  dryRun,
+		"year":     year,
+		"count":    len(accruals),
+		"total":    total,
+		"accruals": accruals,
+	})
+}
+
+// GetLeaveAccruals - GET /api/leave-accruals?employee_id=&leave_type_id=&year=
+// Accrual ledger. SUPERADMIN/ADMIN/HR see everyone, other roles only their own entries.
+func (h *HandlerFunc) GetLeaveAccruals(c *gin.Context) {
+	role := c.GetString("role")
+	userID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var employeeID *uuid.UUID
+	if idStr := c.Query("employee_id"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid employee_id")
+			return
+		}
+		employeeID = &id
+	}
+	if access_role.Admin_SuperAdmin_Hr(role, "") != nil {
+		if employeeID != nil && *employeeID != userID {
+			utils.RespondWithError(c, http.StatusForbidden, "you can only view your own accruals")
+			return
+		}
+		employeeID = &userID
+	}
+
+	var leaveTypeID, year *int
+	if ltStr := c.Query("leave_type_id"); ltStr != "" {
+		id, err := strconv.Atoi(ltStr)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid leave_type_id")
+			return
+		}
+		leaveTypeID = &id
+	}
+	if yearStr := c.Query("year"); yearStr != "" {
+		y, err := strconv.Atoi(yearStr)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid year")
+			return
+		}
+		year = &y
+	}
+
+	accruals, err := h.Query.GetLeaveAccruals(employeeID, leaveTypeID, year)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch leave accruals: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": accruals})
+}
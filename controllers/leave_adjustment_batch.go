@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/access_role"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// BulkAdjustLeaveBalances - POST /api/leave-balances/bulk-adjust?dry_run=true
+// SUPERADMIN/ADMIN/HR adjust one leave type for every employee matched by the filter (all,
+// department, designation, role or an uploaded list of employee IDs/emails) in one transaction.
+// dry_run=true returns the resulting balances without saving them.
+func (h *HandlerFunc) BulkAdjustLeaveBalances(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can adjust leave balances"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	dryRun := c.Query("dry_run") == "true"
+
+	var input models.BulkAdjustmentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	input.Filter.Type = strings.ToUpper(strings.TrimSpace(input.Filter.Type))
+	input.Filter.Value = strings.TrimSpace(input.Filter.Value)
+	if err := h.Validator.Struct(input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	switch input.Filter.Type {
+	case models.BulkFilterDepartment, models.BulkFilterDesignation, models.BulkFilterRole:
+		if input.Filter.Value == "" {
+			utils.RespondWithError(c, http.StatusBadRequest, "filter value is required for "+input.Filter.Type)
+			return
+		}
+	case models.BulkFilterEmployees:
+		if len(input.Filter.EmployeeIDs) == 0 && len(input.Filter.Emails) == 0 {
+			utils.RespondWithError(c, http.StatusBadRequest, "employee_ids or emails are required for EMPLOYEES")
+			return
+		}
+	}
+
+	year := time.Now().Year()
+	if input.Year != nil {
+		year = *input.Year
+		if year < 2000 || year > time.Now().Year()+1 {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid year")
+			return
+		}
+	}
+
+	tx, err := h.Query.DB.Beginx()
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	leaveType, err := h.Query.GetLeaveTypeByIdTx(tx, input.LeaveTypeID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid leave type")
+		return
+	}
+	closed, err := h.Query.YearEndClosedTx(tx, year)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to check year-end close: "+err.Error())
+		return
+	}
+	if closed {
+		utils.RespondWithError(c, http.StatusConflict, "balances of a year closed by the year-end close cannot be adjusted")
+		return
+	}
+	// Comp-off days are only added through comp-off requests, which expire
+	compOff, err := h.Query.GetCompOffSettingsTx(tx)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch comp-off settings: "+err.Error())
+		return
+	}
+	if input.Quantity > 0 && compOff.LeaveTypeID != nil && *compOff.LeaveTypeID == leaveType.ID {
+		utils.RespondWithError(c, http.StatusBadRequest, "comp-off balances cannot be increased by adjustment; raise comp-off requests instead")
+		return
+	}
+
+	targets, err := h.Query.GetBulkAdjustmentTargetsTx(tx, input.Filter)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch employees: "+err.Error())
+		return
+	}
+	if len(targets) == 0 {
+		utils.RespondWithError(c, http.StatusBadRequest, "no active employees match the filter")
+		return
+	}
+
+	batchID, lines, err := service.ApplyBulkAdjustment(h.Query, tx, input, year, targets, empID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to apply bulk adjustment: "+err.Error())
+		return
+	}
+
+	response := gin.H{
+		"dry_run":        dryRun,
+		"year":           year,
+		"leave_type_id":  leaveType.ID,
+		"leave_type":     leaveType.Name,
+		"quantity":       input.Quantity,
+		"employee_count": len(lines),
+		"unmatched":      service.UnmatchedBulkTargets(input.Filter, targets),
+		"lines":          lines,
+	}
+	if dryRun {
+		for i := range lines {
+			lines[i].AdjustmentID = nil
+		}
+		response["message"] = "Bulk adjustment preview"
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	data := models.NewCommon(constant.ComponentLeaveBalance, constant.ActionUpdate, empID)
+	if err := h.Query.AddLog(data, tx); err != nil {
+		utils.RespondWithError(c, 500, "Failed to create log: "+err.Error())
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		utils.RespondWithError(c, 500, "Transaction commit failed")
+		return
+	}
+
+	response["message"] = "Bulk adjustment applied"
+	response["batch_id"] = batchID
+	c.JSON(http.StatusOK, response)
+}
+
+// GetLeaveAdjustmentBatches - GET /api/leave-balances/bulk-adjustments
+func (h *HandlerFunc) GetLeaveAdjustmentBatches(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can view bulk adjustments"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	batches, err := h.Query.GetLeaveAdjustmentBatches()
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch bulk adjustments: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"batches": batches})
+}
+
+// GetLeaveAdjustmentBatch - GET /api/leave-balances/bulk-adjustments/:id
+// A bulk adjustment with its per-employee adjustments and reversals.
+func (h *HandlerFunc) GetLeaveAdjustmentBatch(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can view bulk adjustments"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	batchID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid batch ID")
+		return
+	}
+	batch, err := h.Query.GetLeaveAdjustmentBatch(batchID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "Bulk adjustment not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch bulk adjustment: "+err.Error())
+		return
+	}
+	adjustments, err := h.Query.GetBatchLeaveAdjustments(batchID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch batch adjustments: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"batch":       batch,
+		"adjustments": adjustments,
+	})
+}
+
+// ReverseLeaveAdjustmentBatch - POST /api/leave-balances/bulk-adjustments/:id/reverse
+// SUPERADMIN/ADMIN/HR undo a bulk adjustment with opposite adjustments for every employee.
+func (h *HandlerFunc) ReverseLeaveAdjustmentBatch(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can reverse bulk adjustments"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	batchID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid batch ID")
+		return
+	}
+	var input struct {
+		Reason string `json:"reason" validate:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if err := h.Validator.Struct(input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "reason is required")
+		return
+	}
+
+	tx, err := h.Query.DB.Beginx()
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	batch, err := h.Query.GetLeaveAdjustmentBatchForUpdateTx(tx, batchID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "Bulk adjustment not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch bulk adjustment: "+err.Error())
+		return
+	}
+	if batch.Status != models.AdjustmentBatchApplied {
+		utils.RespondWithError(c, http.StatusConflict, "bulk adjustment is already reversed")
+		return
+	}
+	closed, err := h.Query.YearEndClosedTx(tx, batch.Year)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to check year-end close: "+err.Error())
+		return
+	}
+	if closed {
+		utils.RespondWithError(c, http.StatusConflict, "balances of a year closed by the year-end close cannot be adjusted")
+		return
+	}
+
+	lines, err := service.ReverseBulkAdjustment(h.Query, tx, batch, input.Reason, empID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to reverse bulk adjustment: "+err.Error())
+		return
+	}
+
+	data := models.NewCommon(constant.ComponentLeaveBalance, constant.ActionReverse, empID)
+	if err := h.Query.AddLog(data, tx); err != nil {
+		utils.RespondWithError(c, 500, "Failed to create log: "+err.Error())
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		utils.RespondWithError(c, 500, "Transaction commit failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Bulk adjustment reversed",
+		"batch_id": batchID,
+		"lines":    lines,
+	})
+}
@@ -0,0 +1,366 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+const (
+	leaveAttachmentDir      = "uploads/leave_attachments"
+	maxLeaveAttachmentSize  = 5 << 20 // 5 MB per file
+	maxLeaveAttachmentFiles = 5
+)
+
+// allowedAttachmentTypes maps accepted file extensions to their content type
+var allowedAttachmentTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".doc":  "application/msword",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+}
+
+// bindLeaveRequest binds a leave request sent either as JSON or as multipart/form-data.
+// Multipart requests carry the JSON payload in the "data" field and files in "attachments".
+func bindLeaveRequest(c *gin.Context, input interface{}) ([]*multipart.FileHeader, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		return nil, c.ShouldBindJSON(input)
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+	data := form.Value["data"]
+	if len(data) == 0 {
+		return nil, fmt.Errorf("data field is required")
+	}
+	if err := json.Unmarshal([]byte(data[0]), input); err != nil {
+		return nil, err
+	}
+	if err := binding.Validator.ValidateStruct(input); err != nil {
+		return nil, err
+	}
+	return form.File["attachments"], nil
+}
+
+// storeLeaveAttachments validates uploaded files and saves them to disk.
+// The returned attachments still need LeaveID set before they are inserted.
+func storeLeaveAttachments(c *gin.Context, files []*multipart.FileHeader, uploadedBy uuid.UUID) ([]models.LeaveAttachment, error) {
+	if len(files) > maxLeaveAttachmentFiles {
+		return nil, fmt.Errorf("a maximum of %d attachments is allowed per upload", maxLeaveAttachmentFiles)
+	}
+	for _, file := range files {
+		ext := strings.ToLower(filepath.Ext(file.Filename))
+		if _, ok := allowedAttachmentTypes[ext]; !ok {
+			return nil, fmt.Errorf("%s: unsupported file type. Allowed: pdf, jpg, jpeg, png, doc, docx", file.Filename)
+		}
+		if file.Size > maxLeaveAttachmentSize {
+			return nil, fmt.Errorf("%s: file exceeds the 5 MB limit", file.Filename)
+		}
+	}
+	if err := os.MkdirAll(leaveAttachmentDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory: %v", err)
+	}
+
+	var saved []models.LeaveAttachment
+	for _, file := range files {
+		ext := strings.ToLower(filepath.Ext(file.Filename))
+		path := filepath.Join(leaveAttachmentDir, uuid.New().String()+ext)
+		if err := c.SaveUploadedFile(file, path); err != nil {
+			removeAttachmentFiles(saved)
+			return nil, fmt.Errorf("failed to save %s", file.Filename)
+		}
+		saved = append(saved, models.LeaveAttachment{
+			FileName:    filepath.Base(file.Filename),
+			FilePath:    path,
+			ContentType: allowedAttachmentTypes[ext],
+			SizeBytes:   file.Size,
+			UploadedBy:  uploadedBy,
+		})
+	}
+	return saved, nil
+}
+
+// insertLeaveAttachments records saved files against a leave
+func (h *HandlerFunc) insertLeaveAttachments(tx *sqlx.Tx, leaveID uuid.UUID, attachments []models.LeaveAttachment) error {
+	for i := range attachments {
+		attachments[i].LeaveID = leaveID
+		id, err := h.Query.InsertLeaveAttachment(tx, attachments[i])
+		if err != nil {
+			return err
+		}
+		attachments[i].ID = id
+	}
+	return nil
+}
+
+// removeAttachmentFiles deletes stored files, used when the surrounding transaction fails
+func removeAttachmentFiles(attachments []models.LeaveAttachment) {
+	for _, att := range attachments {
+		os.Remove(att.FilePath)
+	}
+}
+
+// withDownloadURLs fills the download URL of each attachment
+func withDownloadURLs(attachments []models.LeaveAttachment) []models.LeaveAttachment {
+	for i := range attachments {
+		attachments[i].DownloadURL = fmt.Sprintf("/api/leaves/%s/attachments/%s", attachments[i].LeaveID, attachments[i].ID)
+	}
+	return attachments
+}
+
+// canAccessLeave applies the GetLeaveByID visibility rules: employees see their own
+// leaves, managers their own and their team's, HR/ADMIN/SUPERADMIN everything
+func (h *HandlerFunc) canAccessLeave(c *gin.Context, leaveEmployeeID uuid.UUID) bool {
+	role := c.GetString("role")
+	userID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return false
+	}
+
+	switch role {
+	case constant.ROLE_SUPER_ADMIN, constant.ROLE_ADMIN, constant.ROLE_HR:
+		return true
+	case constant.ROLE_MANAGER:
+		if leaveEmployeeID == userID {
+			return true
+		}
+		var managerID uuid.UUID
+		err = h.Query.DB.Get(&managerID, "SELECT COALESCE(manager_id, '00000000-0000-0000-0000-000000000000') FROM Tbl_Employee WHERE id = $1", leaveEmployeeID)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to verify manager relationship")
+			return false
+		}
+		if managerID != userID {
+			utils.RespondWithError(c, http.StatusForbidden, "You can only access leaves of your team members or your own leaves")
+			return false
+		}
+		return true
+	case constant.ROLE_EMPLOYEE:
+		if leaveEmployeeID != userID {
+			utils.RespondWithError(c, http.StatusForbidden, "You can only access your own leave applications")
+			return false
+		}
+		return true
+	}
+	utils.RespondWithError(c, http.StatusForbidden, "Invalid role")
+	return false
+}
+
+// UploadLeaveAttachments - POST /api/leaves/:id/attachments
+// Multipart upload (field "attachments") of supporting documents for an existing leave.
+// Allowed for anyone who can view the leave, while it is still pending or approved.
+func (h *HandlerFunc) UploadLeaveAttachments(c *gin.Context) {
+	userID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	leaveID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid leave ID")
+		return
+	}
+
+	leave, err := h.Query.GetLeaveByIdNoLock(leaveID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "Leave not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch leave: "+err.Error())
+		return
+	}
+	if !h.canAccessLeave(c, leave.EmployeeID) {
+		return
+	}
+	switch leave.Status {
+	case "REJECTED", "MANAGER_REJECTED", "CANCELLED", "WITHDRAWN":
+		utils.RespondWithError(c, http.StatusBadRequest, fmt.Sprintf("Cannot add attachments to a leave with status: %s", leave.Status))
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["attachments"]) == 0 {
+		utils.RespondWithError(c, http.StatusBadRequest, "At least one file is required in the attachments field")
+		return
+	}
+
+	attachments, err := storeLeaveAttachments(c, form.File["attachments"], userID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.insertLeaveAttachments(tx, leaveID, attachments); err != nil {
+			return utils.CustomErr(c, 500, "Failed to save attachments: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentLeave, constant.ActionUpdate, userID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create leave log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		removeAttachmentFiles(attachments)
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to save attachments: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Attachments uploaded successfully",
+		"attachments": withDownloadURLs(attachments),
+	})
+}
+
+// DownloadLeaveAttachment - GET /api/leaves/:id/attachments/:attachmentId
+func (h *HandlerFunc) DownloadLeaveAttachment(c *gin.Context) {
+	leaveID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid leave ID")
+		return
+	}
+	attachmentID, err := uuid.Parse(c.Param("attachmentId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid attachment ID")
+		return
+	}
+
+	leave, err := h.Query.GetLeaveByIdNoLock(leaveID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "Leave not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch leave: "+err.Error())
+		return
+	}
+	if !h.canAccessLeave(c, leave.EmployeeID) {
+		return
+	}
+
+	att, err := h.Query.GetLeaveAttachment(leaveID, attachmentID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "Attachment not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch attachment: "+err.Error())
+		return
+	}
+	if _, err := os.Stat(att.FilePath); err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Attachment file is missing")
+		return
+	}
+
+	c.Header("Content-Type", att.ContentType)
+	c.FileAttachment(att.FilePath, att.FileName)
+}
+
+// DeleteLeaveAttachment - DELETE /api/leaves/:id/attachments/:attachmentId
+// The uploader or HR/ADMIN/SUPERADMIN can remove an attachment while the leave awaits approval.
+func (h *HandlerFunc) DeleteLeaveAttachment(c *gin.Context) {
+	role := c.GetString("role")
+	userID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	leaveID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid leave ID")
+		return
+	}
+	attachmentID, err := uuid.Parse(c.Param("attachmentId"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid attachment ID")
+		return
+	}
+
+	leave, err := h.Query.GetLeaveByIdNoLock(leaveID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "Leave not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch leave: "+err.Error())
+		return
+	}
+	if leave.Status != "Pending" && leave.Status != "MANAGER_APPROVED" {
+		utils.RespondWithError(c, http.StatusBadRequest, fmt.Sprintf("Cannot remove attachments from a leave with status: %s", leave.Status))
+		return
+	}
+
+	att, err := h.Query.GetLeaveAttachment(leaveID, attachmentID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "Attachment not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch attachment: "+err.Error())
+		return
+	}
+	isAdmin := role == constant.ROLE_SUPER_ADMIN || role == constant.ROLE_ADMIN || role == constant.ROLE_HR
+	if !isAdmin && att.UploadedBy != userID {
+		utils.RespondWithError(c, http.StatusForbidden, "You can only remove attachments you uploaded")
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.DeleteLeaveAttachment(tx, attachmentID); err != nil {
+			return utils.CustomErr(c, 500, "Failed to delete attachment: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentLeave, constant.ActionDelete, userID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create leave log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to delete attachment: "+err.Error())
+		return
+	}
+	os.Remove(att.FilePath)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment removed successfully"})
+}
+
+// checkApprovalAttachment reports whether a leave satisfies its leave type's supporting
+// document requirement (always true when no document is required for its length)
+func (h *HandlerFunc) checkApprovalAttachment(tx *sqlx.Tx, leaveType models.LeaveType, leaveID uuid.UUID, days float64) (bool, error) {
+	if !service.AttachmentRequired(leaveType, days) {
+		return true, nil
+	}
+	count, err := h.Query.CountLeaveAttachmentsTx(tx, leaveID)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
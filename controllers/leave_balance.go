@@ -1,13 +1,13 @@
 package controllers
 
 import (
-	"database/sql"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
 )
@@ -37,8 +37,8 @@ func (s *HandlerFunc) GetLeaveBalances(c *gin.Context) {
 	// 3. Get current year for filtering
 	currentYear := time.Now().Year()
 
-	// 4. Fetch all leave types with their default entitlements (repository layer)
-	leaveTypes, err := s.Query.GetAllLeaveTypesWithEntitlements()
+	// 4. Fetch all leave types with the employee's entitlements (repository layer)
+	leaveTypes, err := s.Query.GetAllLeaveTypesWithEntitlements(employeeID, currentYear)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError,
 			"Failed to fetch leave types: "+err.Error())
@@ -60,6 +60,7 @@ func (s *HandlerFunc) GetLeaveBalances(c *gin.Context) {
 			LeaveTypeID:       lt.LeaveTypeID,
 			LeaveTypeName:     lt.LeaveTypeName,
 			DefaultEntitlement: lt.DefaultEntitlement,
+			Unit:               lt.Unit,
 		}
 	}
 
@@ -154,46 +155,34 @@ func (s *HandlerFunc) AdjustLeaveBalance(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	// 5️ Fetch or create leave balance (repository layer)
-	balance, err := s.Query.GetLeaveBalanceForAdjustment(tx, employeeID, input.LeaveTypeID, currentYear)
-
-	if err == sql.ErrNoRows {
-		// 5A: Fetch default entitlement (repository layer)
-		defaultEntitlement, err := s.Query.GetDefaultEntitlementByLeaveTypeID(tx, input.LeaveTypeID)
-		if err != nil {
-			utils.RespondWithError(c, 500, "Failed to fetch leave type: "+err.Error())
-			return
-		}
-
-		// 5B: Create balance row (repository layer)
-		balance, err = s.Query.CreateLeaveBalanceForAdjustment(tx, employeeID, input.LeaveTypeID, currentYear, defaultEntitlement)
-		if err != nil {
-			utils.RespondWithError(c, 500, "Failed to create leave balance: "+err.Error())
-			return
-		}
-
-	} else if err != nil {
-		utils.RespondWithError(c, 500, "Failed to fetch leave balance: "+err.Error())
+	// Comp-off days are only added through comp-off requests, which expire
+	compOff, err := s.Query.GetCompOffSettingsTx(tx)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch comp-off settings: "+err.Error())
+		return
+	}
+	if input.Quantity > 0 && compOff.LeaveTypeID != nil && *compOff.LeaveTypeID == input.LeaveTypeID {
+		utils.RespondWithError(c, 400, "Comp-off balances cannot be increased by adjustment; raise a comp-off request instead")
 		return
 	}
 
-	// 6️ Apply adjustment
-	newAdjusted := balance.Adjusted + input.Quantity
-	newClosing := balance.Opening + balance.Accrued - balance.Used + newAdjusted
-
-	// Update leave balance (repository layer)
-	err = s.Query.UpdateLeaveBalanceAdjustment(tx, balance.ID, newAdjusted, newClosing)
+	// 5️ Insert into adjustment log (repository layer)
+	adjustmentID, err := s.Query.InsertLeaveAdjustment(tx, employeeID, input.LeaveTypeID, input.Quantity, input.Reason, c.GetString("user_id"), currentYear)
 	if err != nil {
-		utils.RespondWithError(c, 500, "Failed to update leave balance: "+err.Error())
+		utils.RespondWithError(c, 500, "Failed to record leave adjustment: "+err.Error())
 		return
 	}
 
-	// 7️ Insert into adjustment log (repository layer)
-	err = s.Query.InsertLeaveAdjustment(tx, employeeID, input.LeaveTypeID, input.Quantity, input.Reason, c.GetString("user_id"), currentYear)
+	// 6️ Apply adjustment to the balance (created if missing) and post it to the ledger
+	adjustedBy, _ := uuid.Parse(c.GetString("user_id"))
+	ref := models.LedgerRef{AdjustmentID: &adjustmentID, Note: input.Reason, CreatedBy: &adjustedBy}
+	balance, err := service.ApplyBalanceAdjustment(s.Query, tx, employeeID, input.LeaveTypeID, currentYear, input.Quantity, ref)
 	if err != nil {
-		utils.RespondWithError(c, 500, "Failed to record leave adjustment: "+err.Error())
+		utils.RespondWithError(c, 500, err.Error())
 		return
 	}
+	newAdjusted := balance.Adjusted
+	newClosing := balance.Closing
 
 	// 8️ Commit
 	if err := tx.Commit(); err != nil {
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/access_role"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// AddLeaveBlackout - POST /api/settings/blackouts
+// SUPERADMIN/ADMIN/HR add a blackout period (e.g. release week) during which leave cannot be applied
+func (s *HandlerFunc) AddLeaveBlackout(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can add blackout periods"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, "Access Denied")
+		return
+	}
+
+	var input models.LeaveBlackoutInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if err := s.Validator.Struct(input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+
+	// Normalize dates to UTC midnight to avoid timezone issues
+	input.StartDate = time.Date(input.StartDate.Year(), input.StartDate.Month(), input.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	input.EndDate = time.Date(input.EndDate.Year(), input.EndDate.Month(), input.EndDate.Day(), 0, 0, 0, 0, time.UTC)
+	if input.EndDate.Before(input.StartDate) {
+		utils.RespondWithError(c, http.StatusBadRequest, "End date cannot be earlier than start date")
+		return
+	}
+
+	var blackoutID int
+	err = common.ExecuteTransaction(c, s.Query.DB, func(tx *sqlx.Tx) error {
+		if input.LeaveTypeID != nil {
+			if _, err := s.Query.GetLeaveTypeByIdTx(tx, *input.LeaveTypeID); err != nil {
+				return utils.CustomErr(c, http.StatusBadRequest, "Invalid leave type")
+			}
+		}
+
+		id, err := s.Query.AddLeaveBlackout(tx, input, empID)
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "Failed to add blackout period: "+err.Error())
+		}
+		blackoutID = id
+
+		data := models.NewCommon(constant.ComponentLeaveType, constant.ActionCreate, empID)
+		if err := s.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "Failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Blackout period added successfully",
+		"id":         blackoutID,
+		"start_date": input.StartDate.Format("2006-01-02"),
+		"end_date":   input.EndDate.Format("2006-01-02"),
+	})
+}
+
+// GetLeaveBlackouts - GET /api/settings/blackouts
+func (s *HandlerFunc) GetLeaveBlackouts(c *gin.Context) {
+	blackouts, err := s.Query.GetAllLeaveBlackouts()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch blackout periods: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, blackouts)
+}
+
+// DeleteLeaveBlackout - DELETE /api/settings/blackouts/:id
+func (s *HandlerFunc) DeleteLeaveBlackout(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can delete blackout periods"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid blackout ID")
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, "Access Denied")
+		return
+	}
+
+	err = common.ExecuteTransaction(c, s.Query.DB, func(tx *sqlx.Tx) error {
+		err := s.Query.DeleteLeaveBlackout(tx, id)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, http.StatusNotFound, "Blackout period not found")
+		}
+		if err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "Failed to delete blackout period: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentLeaveType, constant.ActionDelete, empID)
+		if err := s.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, http.StatusInternalServerError, "Failed to log action: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Blackout period deleted successfully",
+	})
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// maxCalendarDays limits the range of a single calendar request
+const maxCalendarDays = 366
+
+// GetLeaveCalendar - GET /api/leaves/calendar
+// Query params: scope=team|department|company, start_date, end_date (YYYY-MM-DD, default current month),
+// manager_id (team scope, SUPERADMIN/ADMIN/HR only), department (department scope)
+// SUPERADMIN/ADMIN/HR can view any scope. MANAGER sees their direct reports (team) or own department.
+// EMPLOYEE sees their own team (same manager) or own department.
+func (h *HandlerFunc) GetLeaveCalendar(c *gin.Context) {
+	role := c.GetString("role")
+	currentUserID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	isAdmin := role == constant.ROLE_SUPER_ADMIN || role == constant.ROLE_ADMIN || role == constant.ROLE_HR
+
+	// 1️ Date range
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, -1)
+	if s := c.Query("start_date"); s != "" {
+		if start, err = time.Parse("2006-01-02", s); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid start_date. Use YYYY-MM-DD")
+			return
+		}
+	}
+	if s := c.Query("end_date"); s != "" {
+		if end, err = time.Parse("2006-01-02", s); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid end_date. Use YYYY-MM-DD")
+			return
+		}
+	}
+	if end.Before(start) {
+		utils.RespondWithError(c, http.StatusBadRequest, "end_date cannot be earlier than start_date")
+		return
+	}
+	if end.Sub(start).Hours()/24 >= maxCalendarDays {
+		utils.RespondWithError(c, http.StatusBadRequest, "Date range cannot exceed one year")
+		return
+	}
+
+	// 2️ Scope and permissions
+	scope := strings.ToLower(c.DefaultQuery("scope", "team"))
+	teamInfo, err := h.Query.GetEmployeeTeamInfo(currentUserID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch employee details: "+err.Error())
+		return
+	}
+
+	var employees []models.CalendarEmployee
+	switch scope {
+	case "team":
+		managerID := currentUserID
+		if role == constant.ROLE_EMPLOYEE {
+			if teamInfo.ManagerID == nil {
+				utils.RespondWithError(c, http.StatusBadRequest, "You are not assigned to a team")
+				return
+			}
+			managerID = *teamInfo.ManagerID
+		}
+		if m := c.Query("manager_id"); m != "" {
+			if !isAdmin {
+				utils.RespondWithError(c, http.StatusForbidden, "Only SUPERADMIN, ADMIN and HR can view other teams")
+				return
+			}
+			if managerID, err = uuid.Parse(m); err != nil {
+				utils.RespondWithError(c, http.StatusBadRequest, "Invalid manager_id")
+				return
+			}
+		}
+		employees, err = h.Query.GetEmployeesByManager(managerID)
+
+	case "department":
+		department := strings.TrimSpace(c.Query("department"))
+		if department == "" {
+			if teamInfo.Department == nil {
+				utils.RespondWithError(c, http.StatusBadRequest, "department is required")
+				return
+			}
+			department = *teamInfo.Department
+		}
+		if !isAdmin && (teamInfo.Department == nil || *teamInfo.Department != department) {
+			utils.RespondWithError(c, http.StatusForbidden, "You can only view your own department")
+			return
+		}
+		employees, err = h.Query.GetEmployeesByDepartment(department)
+
+	case "company":
+		if !isAdmin {
+			utils.RespondWithError(c, http.StatusForbidden, "Only SUPERADMIN, ADMIN and HR can view the company calendar")
+			return
+		}
+		employees, err = h.Query.GetActiveEmployees()
+
+	default:
+		utils.RespondWithError(c, http.StatusBadRequest, "scope must be team, department or company")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch employees: "+err.Error())
+		return
+	}
+
+	// 3️ Leaves, holidays and working hours
+	ids := make([]uuid.UUID, len(employees))
+	for i, e := range employees {
+		ids[i] = e.ID
+	}
+	leaves, err := h.Query.GetCalendarLeaves(h.Query.DB, ids, start, end)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch leaves: "+err.Error())
+		return
+	}
+	holidays, err := h.Query.GetHolidaysBetween(start, end)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch holidays: "+err.Error())
+		return
+	}
+	wh, err := h.Query.GetWorkingHours()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch working hours: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"scope":      scope,
+		"start_date": start.Format("2006-01-02"),
+		"end_date":   end.Format("2006-01-02"),
+		"employees":  employees,
+		"holidays":   holidays,
+		"days":       service.BuildLeaveCalendar(start, end, len(employees), leaves, holidays, wh.HoursPerDay),
+	})
+}
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// RequestLeaveCancellation - POST /api/leaves/:id/cancel-request
+// An employee asks to cancel their own approved leave that has not started yet. The leave
+// moves to WITHDRAWAL_PENDING and is finalized (or rejected) by their MANAGER or ADMIN/SUPERADMIN
+// through WithdrawLeave / RejectLeaveWithdrawal.
+// Leaves that already started need an HR override: HR raises the request with hr_override=true.
+func (h *HandlerFunc) RequestLeaveCancellation(c *gin.Context) {
+	role := c.GetString("role")
+	currentUserID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	leaveID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid leave ID")
+		return
+	}
+
+	var input struct {
+		Reason     string `json:"reason" validate:"required,min=5,max=500"`
+		HROverride bool   `json:"hr_override"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if err := h.Validator.Struct(input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if input.HROverride && role != constant.ROLE_HR {
+		utils.RespondWithError(c, http.StatusForbidden, "only HR can override the cancellation rules")
+		return
+	}
+
+	var leave models.Leave
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		leave, err = h.Query.GetLeaveById(tx, leaveID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, 404, "leave request not found")
+		}
+		if err != nil {
+			return utils.CustomErr(c, 500, "failed to fetch leave: "+err.Error())
+		}
+		if leave.Status != "APPROVED" {
+			return utils.CustomErr(c, 400, fmt.Sprintf("cannot request cancellation of leave with status: %s. Pending leaves can be cancelled directly", leave.Status))
+		}
+
+		if input.HROverride {
+			if leave.EmployeeID == currentUserID {
+				return utils.CustomErr(c, 403, "HR override cannot be used on your own leave")
+			}
+		} else {
+			if leave.EmployeeID != currentUserID {
+				return utils.CustomErr(c, 403, "you can only request cancellation of your own leave")
+			}
+			now := time.Now()
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+			start := time.Date(leave.StartDate.Year(), leave.StartDate.Month(), leave.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+			if !start.After(today) {
+				return utils.CustomErr(c, 400, "this leave has already started. Please contact HR to cancel it")
+			}
+		}
+
+		if err := h.Query.RequestLeaveWithdrawal(tx, leaveID, currentUserID, input.Reason); err != nil {
+			return utils.CustomErr(c, 500, "failed to request cancellation: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentLeave, constant.ActionCancel, currentUserID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "failed to create leave log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "failed to request cancellation: "+err.Error())
+		return
+	}
+
+	go func() {
+		recipients, err := h.Query.GetAdminAndEmployeeEmail(leave.EmployeeID)
+		if err != nil || len(recipients) == 0 {
+			return
+		}
+		empDetails, err := h.Query.GetEmployeeDetailsForNotification(leave.EmployeeID)
+		if err != nil {
+			fmt.Printf("Failed to get employee details for notification: %v\n", err)
+			return
+		}
+		var requestedByName, leaveTypeName string
+		h.Query.DB.Get(&requestedByName, "SELECT full_name FROM Tbl_Employee WHERE id=$1", currentUserID)
+		h.Query.DB.Get(&leaveTypeName, "SELECT name FROM Tbl_Leave_type WHERE id=$1", leave.LeaveTypeID)
+		utils.SendLeaveCancellationRequestEmail(
+			recipients,
+			empDetails.FullName,
+			leaveTypeName,
+			leave.StartDate.Format("2006-01-02"),
+			leave.EndDate.Format("2006-01-02"),
+			leave.Days,
+			requestedByName,
+			role,
+			input.Reason,
+		)
+	}()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "cancellation request submitted. Pending approval from your manager or admin",
+		"status":            "WITHDRAWAL_PENDING",
+		"leave_id":          leaveID,
+		"requested_by":      currentUserID,
+		"withdrawal_reason": input.Reason,
+		"hr_override":       input.HROverride,
+	})
+}
+
+// RejectLeaveWithdrawal - POST /api/leaves/:id/withdraw/reject
+// Rejects a pending withdrawal/cancellation request; the leave goes back to APPROVED.
+// ADMIN/SUPERADMIN can reject any request, MANAGER only employee requests of direct reports,
+// and the requester can retract their own request.
+func (h *HandlerFunc) RejectLeaveWithdrawal(c *gin.Context) {
+	role := c.GetString("role")
+	currentUserID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	leaveID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid leave ID")
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	c.ShouldBindJSON(&input)
+	input.Reason = strings.TrimSpace(input.Reason)
+
+	var leave models.Leave
+	retracted := false
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		leave, err = h.Query.GetLeaveById(tx, leaveID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, 404, "leave request not found")
+		}
+		if err != nil {
+			return utils.CustomErr(c, 500, "failed to fetch leave: "+err.Error())
+		}
+		if leave.Status != "WITHDRAWAL_PENDING" {
+			return utils.CustomErr(c, 400, fmt.Sprintf("leave has no pending withdrawal request (status: %s)", leave.Status))
+		}
+
+		retracted = leave.WithdrawalRequestedBy != nil && *leave.WithdrawalRequestedBy == currentUserID
+		if !retracted {
+			switch role {
+			case constant.ROLE_ADMIN, constant.ROLE_SUPER_ADMIN:
+				if leave.EmployeeID == currentUserID {
+					return utils.CustomErr(c, 403, "you cannot act on your own leave")
+				}
+			case constant.ROLE_MANAGER:
+				employeeRequested := leave.WithdrawalRequestedBy != nil && *leave.WithdrawalRequestedBy == leave.EmployeeID
+				if !employeeRequested {
+					return utils.CustomErr(c, 403, "only ADMIN/SUPERADMIN can reject this withdrawal request")
+				}
+				var managerID uuid.UUID
+				err := tx.Get(&managerID, "SELECT COALESCE(manager_id, '00000000-0000-0000-0000-000000000000') FROM Tbl_Employee WHERE id=$1", leave.EmployeeID)
+				if err != nil {
+					return utils.CustomErr(c, 500, "failed to verify manager relationship")
+				}
+				if managerID != currentUserID {
+					return utils.CustomErr(c, 403, "managers can only act on leaves of their team members")
+				}
+			default:
+				return utils.CustomErr(c, 403, "not permitted to reject withdrawal requests")
+			}
+		}
+
+		if err := h.Query.RejectLeaveWithdrawal(tx, leaveID); err != nil {
+			return utils.CustomErr(c, 500, "failed to reject withdrawal request: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentLeave, constant.ActionRejection, currentUserID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "failed to create leave log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "failed to reject withdrawal request: "+err.Error())
+		return
+	}
+
+	if !retracted {
+		go func() {
+			empDetails, err := h.Query.GetEmployeeDetailsForNotification(leave.EmployeeID)
+			if err != nil {
+				fmt.Printf("Failed to get employee details for notification: %v\n", err)
+				return
+			}
+			var rejectedByName, leaveTypeName string
+			h.Query.DB.Get(&rejectedByName, "SELECT full_name FROM Tbl_Employee WHERE id=$1", currentUserID)
+			h.Query.DB.Get(&leaveTypeName, "SELECT name FROM Tbl_Leave_type WHERE id=$1", leave.LeaveTypeID)
+			utils.SendLeaveCancellationRequestRejectedEmail(
+				empDetails.Email,
+				empDetails.FullName,
+				leaveTypeName,
+				leave.StartDate.Format("2006-01-02"),
+				leave.EndDate.Format("2006-01-02"),
+				leave.Days,
+				rejectedByName,
+				input.Reason,
+			)
+		}()
+	}
+
+	message := "withdrawal request rejected. Leave remains approved"
+	if retracted {
+		message = "withdrawal request retracted. Leave remains approved"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":  message,
+		"status":   "APPROVED",
+		"leave_id": leaveID,
+	})
+}
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/access_role"
+)
+
+// GetLeaveEntitlementRules - GET /api/leaves/policy/:id/entitlement-rules
+// Entitlement tiers of a leave type (set with entitlement_rules on the leave policy).
+func (h *HandlerFunc) GetLeaveEntitlementRules(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can view entitlement rules"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	leaveTypeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid leave type ID")
+		return
+	}
+	leaveType, err := h.Query.GetLeaveTypeById(leaveTypeID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Leave type not found")
+		return
+	}
+	rules, err := h.Query.GetLeaveEntitlementRules(leaveTypeID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch entitlement rules: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"leave_type_id":       leaveType.ID,
+		"leave_type":          leaveType.Name,
+		"default_entitlement": leaveType.DefaultEntitlement,
+		"rules":               rules,
+	})
+}
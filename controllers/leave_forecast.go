@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// GetBalanceProjection - GET /api/leave-balances/projection/:id?date=2026-12-20&leave_type_id=
+// Expected balance on a future date including future accruals, leaves awaiting approval and
+// the year-end carry-forward rules (all leave types unless leave_type_id is given).
+// Employees can only view their own projection.
+func (h *HandlerFunc) GetBalanceProjection(c *gin.Context) {
+	role := c.GetString("role")
+	userID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+	if role == constant.ROLE_EMPLOYEE && userID != employeeID {
+		utils.RespondWithError(c, http.StatusForbidden, "Employees can only view their own projection")
+		return
+	}
+
+	target := time.Now()
+	if dateStr := c.Query("date"); dateStr != "" {
+		target, err = time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "date must be YYYY-MM-DD")
+			return
+		}
+	}
+	leaveTypeID, ok := optionalIntQuery(c, "leave_type_id")
+	if !ok {
+		return
+	}
+
+	var leaveTypes []models.LeaveType
+	if leaveTypeID != nil {
+		lt, err := h.Query.GetLeaveTypeById(*leaveTypeID)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusNotFound, "Leave type not found")
+			return
+		}
+		leaveTypes = append(leaveTypes, lt)
+	} else {
+		leaveTypes, err = h.Query.GetAllLeaveType()
+		if err != nil {
+			utils.RespondWithError(c, 500, "Failed to fetch leave types: "+err.Error())
+			return
+		}
+	}
+
+	// Read-only: nothing is saved
+	tx, err := h.Query.DB.Beginx()
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	projections := make([]models.BalanceProjection, 0, len(leaveTypes))
+	for _, lt := range leaveTypes {
+		projection, err := service.ProjectLeaveBalance(h.Query, tx, employeeID, lt, target)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		projections = append(projections, projection)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"employee_id": employeeID,
+		"target_date": target.Format("2006-01-02"),
+		"projections": projections,
+	})
+}
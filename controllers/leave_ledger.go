@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/access_role"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// optionalIntQuery reads an optional integer query parameter
+func optionalIntQuery(c *gin.Context, name string) (*int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid "+name)
+		return nil, false
+	}
+	return &n, true
+}
+
+// GetLeaveLedger - GET /api/leave-balances/ledger/:id?year=&leave_type_id=
+// Balance movements of an employee. Employees can only view their own ledger.
+func (h *HandlerFunc) GetLeaveLedger(c *gin.Context) {
+	role := c.GetString("role")
+	userID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+	if role == constant.ROLE_EMPLOYEE && userID != employeeID {
+		utils.RespondWithError(c, http.StatusForbidden, "Employees can only view their own ledger")
+		return
+	}
+
+	year, ok := optionalIntQuery(c, "year")
+	if !ok {
+		return
+	}
+	leaveTypeID, ok := optionalIntQuery(c, "leave_type_id")
+	if !ok {
+		return
+	}
+
+	entries, err := h.Query.GetLeaveLedger(employeeID, leaveTypeID, year)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch leave ledger: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"employee_id": employeeID,
+		"entries":     entries,
+	})
+}
+
+// ReconcileLeaveBalances - GET /api/leave-balances/reconcile?year=&employee_id=
+// SUPERADMIN/ADMIN/HR list balances whose Tbl_Leave_balance totals disagree with the ledger.
+func (h *HandlerFunc) ReconcileLeaveBalances(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can reconcile leave balances"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+
+	year, ok := optionalIntQuery(c, "year")
+	if !ok {
+		return
+	}
+	var employeeID *uuid.UUID
+	if idStr := c.Query("employee_id"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid employee_id")
+			return
+		}
+		employeeID = &id
+	}
+
+	mismatches, err := h.Query.GetLedgerMismatches(year, employeeID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to reconcile leave balances: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"reconciled": len(mismatches) == 0,
+		"mismatches": mismatches,
+	})
+}
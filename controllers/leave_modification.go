@@ -0,0 +1,313 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// ModifyLeave - POST /api/leaves/:id/modify
+// Shortens (one segment) or splits (several segments) an approved leave, e.g. when an
+// employee returns early. Segments must lie within the original period and not overlap.
+// The first segment keeps the original leave ID; further segments become new approved
+// leaves linked to it. Only the difference in days is restored to the balance.
+// SUPERADMIN/ADMIN/HR can modify any leave; MANAGER only leaves of direct reports.
+func (h *HandlerFunc) ModifyLeave(c *gin.Context) {
+	role := c.GetString("role")
+	currentUserID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if role != constant.ROLE_SUPER_ADMIN && role != constant.ROLE_ADMIN && role != constant.ROLE_HR && role != constant.ROLE_MANAGER {
+		utils.RespondWithError(c, http.StatusForbidden, "only SUPERADMIN, ADMIN, HR and MANAGER can modify approved leaves")
+		return
+	}
+	if role == constant.ROLE_MANAGER {
+		hasPermission, err := h.Query.ChackManagerPermission()
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "failed to check manager permission")
+			return
+		}
+		if !hasPermission {
+			utils.RespondWithError(c, http.StatusForbidden, "MANAGER does not have permission to modify leaves")
+			return
+		}
+	}
+
+	leaveID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid leave ID")
+		return
+	}
+
+	var input models.LeaveModifyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if err := h.Validator.Struct(input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+
+	// Normalize segment dates and order them
+	for i := range input.Segments {
+		seg := &input.Segments[i]
+		seg.StartDate = time.Date(seg.StartDate.Year(), seg.StartDate.Month(), seg.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+		seg.EndDate = time.Date(seg.EndDate.Year(), seg.EndDate.Month(), seg.EndDate.Day(), 0, 0, 0, 0, time.UTC)
+		if seg.EndDate.Before(seg.StartDate) {
+			utils.RespondWithError(c, http.StatusBadRequest, "Segment end date cannot be earlier than start date")
+			return
+		}
+		if seg.LeaveTimingID == nil {
+			full := 3
+			seg.LeaveTimingID = &full
+		}
+		if *seg.LeaveTimingID < 1 || *seg.LeaveTimingID > 3 {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid leave timing ID. Must be 1 (First Half), 2 (Second Half), or 3 (Full Day)")
+			return
+		}
+	}
+	sort.Slice(input.Segments, func(i, j int) bool {
+		return input.Segments[i].StartDate.Before(input.Segments[j].StartDate)
+	})
+	for i := 1; i < len(input.Segments); i++ {
+		if !input.Segments[i].StartDate.After(input.Segments[i-1].EndDate) {
+			utils.RespondWithError(c, http.StatusBadRequest, "Segments cannot overlap or share a date")
+			return
+		}
+	}
+
+	action, verb := models.LeaveModificationShorten, "shortened"
+	if len(input.Segments) > 1 {
+		action, verb = models.LeaveModificationSplit, "split"
+	}
+
+	var leave models.Leave
+	var leaveType models.LeaveType
+	var modification models.LeaveModification
+	var periods []string
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		leave, err = h.Query.GetLeaveById(tx, leaveID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, 404, "leave request not found")
+		}
+		if err != nil {
+			return utils.CustomErr(c, 500, "failed to fetch leave: "+err.Error())
+		}
+		if leave.EmployeeID == currentUserID {
+			return utils.CustomErr(c, 403, "you cannot modify your own leave. Please contact your manager or admin")
+		}
+		if leave.Status != "APPROVED" {
+			return utils.CustomErr(c, 400, fmt.Sprintf("cannot modify leave with status: %s. Only approved leaves can be shortened or split", leave.Status))
+		}
+		if role == constant.ROLE_MANAGER {
+			var managerID uuid.UUID
+			err := tx.Get(&managerID, "SELECT COALESCE(manager_id, '00000000-0000-0000-0000-000000000000') FROM Tbl_Employee WHERE id=$1", leave.EmployeeID)
+			if err != nil {
+				return utils.CustomErr(c, 500, "failed to verify manager relationship")
+			}
+			if managerID != currentUserID {
+				return utils.CustomErr(c, 403, "managers can only modify leaves of their team members")
+			}
+		}
+
+		leaveType, err = h.Query.GetLeaveTypeByIdTx(tx, leave.LeaveTypeID)
+		if err != nil {
+			return utils.CustomErr(c, 500, "failed to fetch leave type: "+err.Error())
+		}
+		if leaveType.Unit == models.LeaveUnitHour {
+			return utils.CustomErr(c, 400, "hourly leaves cannot be shortened or split. Withdraw the leave instead")
+		}
+		// Restored days go back to the year the leave was taken in, which must still be open
+		closed, err := h.Query.YearEndClosedTx(tx, leave.StartDate.Year())
+		if err != nil {
+			return utils.CustomErr(c, 500, "failed to check year-end close: "+err.Error())
+		}
+		if closed {
+			return utils.CustomErr(c, 409, fmt.Sprintf("leave balances of %d are closed by the year-end close; leaves of that year can no longer be modified", leave.StartDate.Year()))
+		}
+
+		origStart := time.Date(leave.StartDate.Year(), leave.StartDate.Month(), leave.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+		origEnd := time.Date(leave.EndDate.Year(), leave.EndDate.Month(), leave.EndDate.Day(), 0, 0, 0, 0, time.UTC)
+
+		// Recalculate every remaining segment
+		quantities := make([]models.LeaveQuantity, len(input.Segments))
+		newDays, newBalance := 0.0, 0.0
+		for i, seg := range input.Segments {
+			if seg.StartDate.Before(origStart) || seg.EndDate.After(origEnd) {
+				return utils.CustomErr(c, 400, fmt.Sprintf("segment %s to %s is outside the original leave period %s to %s",
+					seg.StartDate.Format("2006-01-02"), seg.EndDate.Format("2006-01-02"),
+					origStart.Format("2006-01-02"), origEnd.Format("2006-01-02")))
+			}
+			qty, err := service.CalculateLeaveQuantity(h.Query, tx, leaveType, seg.StartDate, seg.EndDate, *seg.LeaveTimingID, seg.StartDayTimingID, seg.EndDayTimingID, nil, nil)
+			if err != nil {
+				return utils.CustomErr(c, 400, "failed to calculate leave days: "+err.Error())
+			}
+			if qty.Balance <= 0 {
+				return utils.CustomErr(c, 400, fmt.Sprintf("segment %s to %s has no working days",
+					seg.StartDate.Format("2006-01-02"), seg.EndDate.Format("2006-01-02")))
+			}
+			quantities[i] = qty
+			newDays += qty.Days
+			newBalance += qty.Balance
+			periods = append(periods, fmt.Sprintf("%s to %s (%.1f)", seg.StartDate.Format("2006-01-02"), seg.EndDate.Format("2006-01-02"), qty.Days))
+		}
+
+		restored := leave.BalanceQuantity() - newBalance
+		if restored <= 0 {
+			return utils.CustomErr(c, 400, fmt.Sprintf("the new period(s) must be shorter than the original leave (%.1f days, requested %.1f)", leave.Days, newDays))
+		}
+
+		// First segment keeps the original leave, the rest become linked approved leaves
+		first := input.Segments[0]
+		if err := h.Query.UpdateLeavePeriod(tx, leaveID, first.StartDate, first.EndDate, *first.LeaveTimingID, quantities[0]); err != nil {
+			return utils.CustomErr(c, 500, "failed to update leave: "+err.Error())
+		}
+		resultIDs := []string{leaveID.String()}
+		for i := 1; i < len(input.Segments); i++ {
+			seg := input.Segments[i]
+			segInput := models.LeaveInput{
+				EmployeeID:   leave.EmployeeID,
+				LeaveTypeID:  leave.LeaveTypeID,
+				StartDate:    seg.StartDate,
+				EndDate:      seg.EndDate,
+				Reason:       leave.Reason,
+				AppliedByID:  leave.AppliedByID,
+				ApprovedByID: leave.ApprovedByID,
+			}
+			id, err := h.Query.InsertLeaveOnBehalf(tx, segInput, *seg.LeaveTimingID, quantities[i], "APPROVED")
+			if err != nil {
+				return utils.CustomErr(c, 500, "failed to create leave segment: "+err.Error())
+			}
+			if err := h.Query.SetParentLeave(tx, id, leaveID); err != nil {
+				return utils.CustomErr(c, 500, "failed to link leave segment: "+err.Error())
+			}
+			resultIDs = append(resultIDs, id.String())
+		}
+
+		if err := h.Query.RestoreLeaveBalance(tx, leave.EmployeeID, leave.LeaveTypeID, leave.StartDate.Year(), restored, leaveID, &currentUserID); err != nil {
+			return utils.CustomErr(c, 500, "failed to restore leave balance: "+err.Error())
+		}
+
+		modification = models.LeaveModification{
+			LeaveID:        leaveID,
+			Action:         action,
+			OldStartDate:   leave.StartDate,
+			OldEndDate:     leave.EndDate,
+			OldDays:        leave.Days,
+			OldHours:       leave.Hours,
+			NewPeriods:     strings.Join(periods, ", "),
+			NewDays:        newDays,
+			Restored:       restored,
+			ResultLeaveIDs: resultIDs,
+			Reason:         &input.Reason,
+			ModifiedBy:     &currentUserID,
+		}
+		if err := h.Query.InsertLeaveModification(tx, modification); err != nil {
+			return utils.CustomErr(c, 500, "failed to record leave history: "+err.Error())
+		}
+
+		data := models.NewCommon(constant.ComponentLeave, constant.ActionUpdate, currentUserID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "failed to create leave log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "failed to modify leave: "+err.Error())
+		return
+	}
+
+	go func() {
+		empDetails, err := h.Query.GetEmployeeDetailsForNotification(leave.EmployeeID)
+		if err != nil {
+			fmt.Printf("Failed to fetch employee details: %v\n", err)
+			return
+		}
+		var modifiedByName string
+		h.Query.DB.Get(&modifiedByName, "SELECT full_name FROM Tbl_Employee WHERE id=$1", currentUserID)
+
+		oldStart := leave.StartDate.Format("2006-01-02")
+		oldEnd := leave.EndDate.Format("2006-01-02")
+		if err := utils.SendLeaveModifiedEmail(empDetails.Email, empDetails.FullName, leaveType.Name, oldStart, oldEnd, leave.Days,
+			modification.NewPeriods, modification.NewDays, modification.Restored, modifiedByName, input.Reason); err != nil {
+			fmt.Printf("Failed to send leave modification email: %v\n", err)
+		}
+
+		var hrEmails []string
+		h.Query.DB.Select(&hrEmails, `
+			SELECT e.email
+			FROM Tbl_Employee e
+			JOIN Tbl_Role r ON e.role_id = r.id
+			WHERE r.type = 'HR' AND e.status = 'active'
+		`)
+		if len(hrEmails) > 0 {
+			utils.SendLeaveModifiedEmailToHR(hrEmails, empDetails.FullName, empDetails.Email, leaveType.Name, oldStart, oldEnd, leave.Days,
+				modification.NewPeriods, modification.NewDays, modification.Restored, modifiedByName, input.Reason)
+		}
+	}()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   fmt.Sprintf("leave %s successfully and balance restored", verb),
+		"action":    action,
+		"leave_id":  leaveID,
+		"leave_ids": modification.ResultLeaveIDs,
+		"periods":   periods,
+		"old_days":  leave.Days,
+		"new_days":  modification.NewDays,
+		"restored":  modification.Restored,
+	})
+}
+
+// GetLeaveHistory - GET /api/leaves/:id/history
+// Shorten/split history of a leave (same access as GetLeaveByID)
+func (h *HandlerFunc) GetLeaveHistory(c *gin.Context) {
+	leaveID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid leave ID")
+		return
+	}
+	leave, err := h.Query.GetLeaveByIdNoLock(leaveID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "Leave not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch leave: "+err.Error())
+		return
+	}
+	if !h.canAccessLeave(c, leave.EmployeeID) {
+		return
+	}
+
+	history, err := h.Query.GetLeaveModifications(leaveID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch leave history: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"leave_id":        leaveID,
+		"parent_leave_id": leave.ParentLeaveID,
+		"history":         history,
+	})
+}
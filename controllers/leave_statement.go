@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// GetLeaveStatement - GET /api/leave-balances/statement/:id?year=&format=json|pdf|csv
+// Opening balance, accruals, leaves taken, adjustments and closing balance of an employee
+// for a year. Employees can only view their own statement.
+func (h *HandlerFunc) GetLeaveStatement(c *gin.Context) {
+	role := c.GetString("role")
+	userID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+	if role == constant.ROLE_EMPLOYEE && userID != employeeID {
+		utils.RespondWithError(c, http.StatusForbidden, "Employees can only view their own statement")
+		return
+	}
+
+	year := time.Now().Year()
+	if yearStr := c.Query("year"); yearStr != "" {
+		year, err = strconv.Atoi(yearStr)
+		if err != nil || year < 2000 || year > 2100 {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid year")
+			return
+		}
+	}
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "json" && format != "pdf" && format != "csv" {
+		utils.RespondWithError(c, http.StatusBadRequest, "format must be json, pdf or csv")
+		return
+	}
+
+	employee, err := h.Query.GetEmployeeByID(employeeID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Employee not found")
+		return
+	}
+	balances, err := h.Query.GetStatementBalances(employeeID, year)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch leave balances: "+err.Error())
+		return
+	}
+	entries, err := h.Query.GetStatementEntries(employeeID, year)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch leave ledger: "+err.Error())
+		return
+	}
+
+	statement := models.LeaveStatement{
+		EmployeeID:   employeeID,
+		EmployeeName: employee.FullName,
+		Email:        employee.Email,
+		Year:         year,
+		GeneratedAt:  time.Now(),
+		Sections:     service.BuildLeaveStatement(balances, entries),
+	}
+
+	switch format {
+	case "pdf":
+		h.writeLeaveStatementPDF(c, statement)
+	case "csv":
+		writeLeaveStatementCSV(c, statement)
+	default:
+		c.JSON(http.StatusOK, statement)
+	}
+}
+
+// statementFilename is the download name of a statement
+func statementFilename(s models.LeaveStatement, ext string) string {
+	name := strings.ReplaceAll(strings.TrimSpace(s.EmployeeName), " ", "_")
+	if name == "" {
+		name = s.EmployeeID.String()
+	}
+	return fmt.Sprintf("leave_statement_%s_%d.%s", name, s.Year, ext)
+}
+
+func formatQty(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func writeLeaveStatementCSV(c *gin.Context, s models.LeaveStatement) {
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename="+statementFilename(s, "csv"))
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"Leave Type", "Unit", "Date", "Entry Type", "Description", "Quantity", "Balance"})
+	for _, sec := range s.Sections {
+		w.Write([]string{sec.LeaveType, sec.Unit, "", "OPENING", "Opening balance", "", formatQty(sec.Opening)})
+		for _, l := range sec.Lines {
+			w.Write([]string{sec.LeaveType, sec.Unit, l.Date.Format("2006-01-02"), l.EntryType, l.Description, formatQty(l.Quantity), formatQty(l.Balance)})
+		}
+		w.Write([]string{sec.LeaveType, sec.Unit, "", "CLOSING", "Closing balance", "", formatQty(sec.Closing)})
+	}
+	w.Flush()
+}
+
+func (h *HandlerFunc) writeLeaveStatementPDF(c *gin.Context, s models.LeaveStatement) {
+	config := h.loadPDFConfig()
+	r, g, b := config.PrimaryColor[0], config.PrimaryColor[1], config.PrimaryColor[2]
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AddPage()
+
+	// Header, same layout as the payslip
+	pdf.SetFillColor(r, g, b)
+	pdf.Rect(0, 0, 210, 4, "F")
+	if config.LogoPath != "" {
+		pdf.Image(config.LogoPath, 15, 10, 30, 0, false, "", 0, "")
+	}
+	pdf.SetY(12)
+	pdf.SetFont("Arial", "B", 18)
+	pdf.SetTextColor(50, 50, 50)
+	pdf.CellFormat(0, 10, strings.ToUpper(config.CompanyName), "", 1, "R", false, 0, "")
+	pdf.SetFont("Arial", "", 10)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.CellFormat(0, 5, "Leave Balance Statement: "+fmt.Sprint(s.Year), "", 1, "R", false, 0, "")
+	pdf.SetY(45)
+
+	// Employee information
+	pdf.SetFillColor(245, 245, 245)
+	pdf.SetFont("Arial", "B", 10)
+	pdf.SetTextColor(r, g, b)
+	pdf.CellFormat(0, 10, "  EMPLOYEE INFORMATION", "L", 1, "L", true, 0, "")
+	pdf.Ln(2)
+	pdf.SetFont("Arial", "", 9)
+	pdf.SetTextColor(50, 50, 50)
+	pdf.CellFormat(30, 7, "Employee Name:", "", 0, "L", false, 0, "")
+	pdf.SetFont("Arial", "B", 9)
+	pdf.CellFormat(70, 7, s.EmployeeName, "", 0, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	pdf.CellFormat(30, 7, "Email:", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 7, s.Email, "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	if len(s.Sections) == 0 {
+		pdf.SetFont("Arial", "I", 9)
+		pdf.CellFormat(0, 8, "No leave balances for this year.", "", 1, "L", false, 0, "")
+	}
+
+	for _, sec := range s.Sections {
+		pdf.SetFillColor(245, 245, 245)
+		pdf.SetFont("Arial", "B", 10)
+		pdf.SetTextColor(r, g, b)
+		pdf.CellFormat(0, 9, fmt.Sprintf("  %s (%s)", strings.ToUpper(sec.LeaveType), sec.Unit), "L", 1, "L", true, 0, "")
+		pdf.Ln(1)
+
+		pdf.SetTextColor(50, 50, 50)
+		pdf.SetFont("Arial", "B", 8)
+		pdf.CellFormat(25, 7, "Date", "1", 0, "C", false, 0, "")
+		pdf.CellFormat(105, 7, "Description", "1", 0, "L", false, 0, "")
+		pdf.CellFormat(25, 7, "Quantity", "1", 0, "R", false, 0, "")
+		pdf.CellFormat(25, 7, "Balance", "1", 1, "R", false, 0, "")
+
+		pdf.SetFont("Arial", "", 8)
+		pdf.CellFormat(25, 7, "", "1", 0, "C", false, 0, "")
+		pdf.CellFormat(105, 7, "Opening balance", "1", 0, "L", false, 0, "")
+		pdf.CellFormat(25, 7, "", "1", 0, "R", false, 0, "")
+		pdf.CellFormat(25, 7, formatQty(sec.Opening), "1", 1, "R", false, 0, "")
+		for _, l := range sec.Lines {
+			pdf.CellFormat(25, 7, l.Date.Format("02-Jan-2006"), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(105, 7, l.Description, "1", 0, "L", false, 0, "")
+			pdf.CellFormat(25, 7, formatQty(l.Quantity), "1", 0, "R", false, 0, "")
+			pdf.CellFormat(25, 7, formatQty(l.Balance), "1", 1, "R", false, 0, "")
+		}
+
+		pdf.SetFont("Arial", "B", 8)
+		pdf.CellFormat(130, 7, fmt.Sprintf("Accrued %s | Used %s | Adjusted %s", formatQty(sec.Accrued), formatQty(sec.Used), formatQty(sec.Adjusted)), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(25, 7, "Closing", "1", 0, "R", false, 0, "")
+		pdf.SetTextColor(r, g, b)
+		pdf.CellFormat(25, 7, formatQty(sec.Closing), "1", 1, "R", false, 0, "")
+		pdf.Ln(5)
+	}
+
+	pdf.Ln(5)
+	pdf.SetFont("Arial", "I", 8)
+	pdf.SetTextColor(150, 150, 150)
+	pdf.CellFormat(0, 5, "This is a computer-generated document and does not require a signature.", "", 1, "C", false, 0, "")
+	pdf.CellFormat(0, 5, "Generated on "+s.GeneratedAt.Format("02-Jan-2006"), "", 1, "C", false, 0, "")
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename="+statementFilename(s, "pdf"))
+	pdf.Output(c.Writer)
+}
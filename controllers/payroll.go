@@ -3,6 +3,7 @@ package controllers
 import (
 	"database/sql"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,26 +11,71 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/jung-kurt/gofpdf"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
-	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
 )
 
 // PayrollPreview represents preview data for a payroll run
 type PayrollPreview struct {
-	EmployeeID   uuid.UUID `json:"employee_id"`
-	Employee     string    `json:"employee"`
-	BasicSalary  float64   `json:"basic_salary"`
-	WorkingDays  int       `json:"working_days"`
-	PaidLeaves   float64   `json:"paid_leaves"`
-	UnpaidLeaves float64   `json:"unpaid_leaves"`
-	Deductions   float64   `json:"deductions"`
-	NetSalary    float64   `json:"net_salary"`
+	PayslipID    uuid.UUID            `json:"payslip_id"` // the draft payslip
+	EmployeeID   uuid.UUID            `json:"employee_id"`
+	Employee     string               `json:"employee"`
+	BasicSalary  float64              `json:"basic_salary"` // gross monthly earnings of the salary structure
+	WorkingDays  int                  `json:"working_days"`
+	DayRateBasis string               `json:"day_rate_basis"` // what working_days counts
+	PaidLeaves   float64              `json:"paid_leaves"`
+	UnpaidLeaves float64              `json:"unpaid_leaves"`
+	Deductions   float64              `json:"deductions"`
+	Encashment   float64              `json:"leave_encashment"` // pending year-end leave encashment paid with this run
+	NetSalary    float64              `json:"net_salary"`
+	Lines        []models.PayslipLine `json:"lines"` // earning and deduction components
+	// EmployerContributions are the employer's statutory contributions, not deducted from pay
+	EmployerContributions float64 `json:"employer_contributions"`
+	PayslipType           string  `json:"payslip_type"`
+	// Proration is the employed part of a joining or exit month, nil for a whole month
+	Proration *models.SalaryProration `json:"proration,omitempty"`
+	// Correction is the correction a CORRECTION payslip pays
+	Correction *models.PayslipCorrection `json:"correction,omitempty"`
 }
 
-// RunPayroll handles payroll preview
+// newPayrollPreview returns the preview of a computed payslip
+func newPayrollPreview(p models.PayslipRecord, breakdown models.SalaryBreakdown) PayrollPreview {
+	return PayrollPreview{
+		EmployeeID:   p.EmployeeID,
+		Employee:     p.Employee,
+		BasicSalary:  p.BasicSalary,
+		WorkingDays:  p.WorkingDays,
+		DayRateBasis: p.DayRateBasis,
+		PaidLeaves:   p.PaidLeaves,
+		UnpaidLeaves: p.UnpaidLeaves,
+		Deductions:   p.Deductions,
+		Encashment:   p.Encashment,
+		NetSalary:    p.NetSalary,
+		Lines:        p.Lines,
+
+		EmployerContributions: breakdown.EmployerContributions,
+		PayslipType:           p.PayslipType,
+		Proration:             p.Proration(),
+	}
+}
+
+// salaryStructureFor returns the salary structure assigned to an employee, nil when none is
+func salaryStructureFor(structures map[uuid.UUID]models.SalaryStructure, structureID *uuid.UUID) *models.SalaryStructure {
+	if structureID == nil {
+		return nil
+	}
+	if s, ok := structures[*structureID]; ok {
+		return &s
+	}
+	return nil
+}
+
+// RunPayroll handles payroll preview. The preview is stored as the draft payslips of the
+// month's DRAFT run; running payroll again refreshes the same draft until it is submitted.
 func (h *HandlerFunc) RunPayroll(c *gin.Context) {
 	roleRaw, _ := c.Get("role")
 	role := roleRaw.(string)
@@ -37,6 +83,11 @@ func (h *HandlerFunc) RunPayroll(c *gin.Context) {
 		utils.RespondWithError(c, 403, "Not authorized to run payroll")
 		return
 	}
+	preparerID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, 401, err.Error())
+		return
+	}
 
 	var input struct {
 		Month int `json:"month" validate:"required"`
@@ -54,96 +105,141 @@ func (h *HandlerFunc) RunPayroll(c *gin.Context) {
 		return
 	}
 
-	// --- Check if payroll already exists ---
+	// --- Fetch active employees, working days, leaves and pending leave encashment ---
+	inputs, err := h.loadPayrollInputs(input.Month, input.Year)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch payroll data: "+err.Error())
+		return
+	}
+	workingDays := inputs.WorkingDays
 
-	existingRun, err := h.Query.GetExitstingpayload(input)
-	if err == nil {
-		// Payroll run exists
-		status := strings.ToUpper(strings.TrimSpace(existingRun.Status))
+	rules, err := h.loadPayrollRules(input.Month, input.Year)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch payroll rules: "+err.Error())
+		return
+	}
 
-		if status == "FINALIZED" {
-			utils.RespondWithError(c, 400, "Payroll for this month and year is already finalized. Cannot run payroll again.")
+	totalPayroll := 0.0
+	totalDeductions := 0.0
+	previews := []PayrollPreview{}
+	var drafts []models.PayslipRecord
+
+	for _, emp := range inputs.Employees {
+		// Leave days of this specific month only, cross-month leaves handled
+		draft, breakdown, err := rules.computePayslip(emp, workingDays, inputs.Leaves[emp.ID], inputs.Encashments[emp.ID], inputs.Prorations[emp.ID])
+		if err != nil {
+			utils.RespondWithError(c, 500, err.Error())
 			return
 		}
+		draft.LeaveFingerprint = inputs.Fingerprints[emp.ID]
+		draft.DayRateBasis = inputs.DayRateBasis
 
-		// if status == "PREVIEW" {
-		// 	utils.RespondWithError(c, 400, fmt.Sprintf("Payroll for this month and year already exists with status PREVIEW (ID: %s). Please finalize or delete the existing payroll run before creating a new one.", existingRun.ID))
-		// 	return
-		// }
+		previews = append(previews, newPayrollPreview(draft, breakdown))
+		drafts = append(drafts, draft)
+		totalPayroll += draft.NetSalary
+		totalDeductions += draft.Deductions
 	}
-	// --- Fetch active employees ---
-	employees, err := h.Query.GetEmployeeByMonthAndYear(input)
+
+	// --- Corrections of withdrawn payslips of earlier months ---
+	corrections, err := h.Query.GetCorrectionsForRun(input.Month, input.Year)
 	if err != nil {
-		utils.RespondWithError(c, 500, "Failed to fetch employees: "+err.Error())
+		utils.RespondWithError(c, 500, "Failed to fetch payslip corrections: "+err.Error())
 		return
 	}
-	// --- Fetch working days ---
-	workingDays := h.Query.GetCompanyCurrWorkingDays()
+	for i := range corrections {
+		draft, breakdown, err := h.computeCorrection(corrections[i])
+		if err != nil {
+			utils.RespondWithError(c, 500, err.Error())
+			return
+		}
+		preview := newPayrollPreview(draft, breakdown)
+		preview.Correction = &corrections[i]
 
-	totalPayroll := 0.0
-	totalDeductions := 0.0
-	var previews []PayrollPreview
+		previews = append(previews, preview)
+		drafts = append(drafts, draft)
+		totalPayroll += draft.NetSalary
+		totalDeductions += draft.Deductions
+	}
 
-	for _, emp := range employees {
-		// Set default salary to 0 if null
-		salary := 0.0
-		if emp.Salary != nil {
-			salary = *emp.Salary
+	// --- Store the preview as the draft payslips of the month's DRAFT run ---
+	var runID uuid.UUID
+	refreshed := false
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		runs, err := h.Query.GetPayrollRunsForMonthTx(tx, input.Month, input.Year)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch payroll runs: "+err.Error())
+		}
+		for _, run := range runs {
+			switch run.Status {
+			case models.PayrollStatusDraft:
+				runID, refreshed = run.ID, true
+			case models.PayrollStatusSubmitted, models.PayrollStatusApproved:
+				return utils.CustomErr(c, 400, fmt.Sprintf("Payroll for this month and year is %s. It must be rejected back to DRAFT before payroll can be run again.", run.Status))
+			default:
+				return utils.CustomErr(c, 400, "Payroll for this month and year is already finalized. Cannot run payroll again.")
+			}
 		}
 
-		// Calculate absent days for this specific month only
-		// Handle cross-month leaves correctly
-		/*absentDays := service.CalculateAbsentDaysForMonth(h.Query.DB, emp.ID, input.Month, input.Year)
-		if absentDays < 0 {
-			utils.RespondWithError(c, 500, "Failed to calculate absent days")
-			return
-		}*/
-
-		leaveSummary := service.CalculateAbsentDaysForMonth(h.Query.DB, emp.ID, input.Month, input.Year)
-
-		deduction := salary / float64(workingDays) * leaveSummary.UnpaidDays
-		net := salary - deduction
-
-		previews = append(previews, PayrollPreview{
-			EmployeeID:  emp.ID,
-			Employee:    emp.FullName,
-			BasicSalary: salary,
-			WorkingDays: workingDays,
-			//AbsentDays:  absentDays,
-			PaidLeaves:   leaveSummary.PaidDays,   // Added for UI/Design
-			UnpaidLeaves: leaveSummary.UnpaidDays, // Renamed from AbsentDays
-			Deductions:   deduction,
-			NetSalary:    net,
-		})
+		var fromStatus *string
+		if refreshed {
+			draft := models.PayrollStatusDraft
+			fromStatus = &draft
+			if err := h.Query.DeleteRunPayslipsTx(tx, runID); err != nil {
+				return utils.CustomErr(c, 500, "Failed to clear draft payslips: "+err.Error())
+			}
+			if err := h.Query.MarkPayrollRunPreparedTx(tx, runID, preparerID); err != nil {
+				return utils.CustomErr(c, 500, "Failed to update payroll run: "+err.Error())
+			}
+		} else {
+			if runID, err = h.Query.InsertPayrollRunTx(tx, input.Month, input.Year, preparerID); err != nil {
+				return utils.CustomErr(c, 500, "Failed to create payroll run: "+err.Error())
+			}
+		}
 
-		totalPayroll += net
-		totalDeductions += deduction
-	}
+		for i, draft := range drafts {
+			payslipID, err := h.Query.InsertPayslipTx(tx, runID, draft)
+			if err != nil {
+				return utils.CustomErr(c, 500, "Failed to store draft payslip: "+err.Error())
+			}
+			previews[i].PayslipID = payslipID
+		}
 
-	// --- Create payroll run record ---
-	runID := uuid.New()
-	_, err = h.Query.DB.Exec(
-		`INSERT INTO Tbl_Payroll_run (id, month, year, status) VALUES ($1,$2,$3,$4)`,
-		runID, input.Month, input.Year, "PREVIEW",
-	)
+		if err := h.Query.InsertPayrollRunActionTx(tx, runID, models.PayrollActionRun, fromStatus, models.PayrollStatusDraft, preparerID, ""); err != nil {
+			return utils.CustomErr(c, 500, "Failed to record payroll run: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentPayroll, constant.ActionRun, preparerID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
 	if err != nil {
-		utils.RespondWithError(c, 500, "Failed to create payroll run: "+err.Error())
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to store payroll preview: "+err.Error())
 		return
 	}
 
 	c.JSON(200, gin.H{
-		"payroll_run_id":   runID,
-		"month":            input.Month,
-		"year":             input.Year,
-		"total_payroll":    totalPayroll,
-		"total_deductions": totalDeductions,
-		"employees_count":  len(employees),
-		"payroll_preview":  previews,
+		"payroll_run_id":    runID,
+		"status":            models.PayrollStatusDraft,
+		"refreshed":         refreshed, // the month's open run already existed
+		"month":             input.Month,
+		"year":              input.Year,
+		"total_payroll":     totalPayroll,
+		"total_deductions":  totalDeductions,
+		"employees_count":   len(inputs.Employees),
+		"corrections_count": len(corrections),
+		"payroll_preview":   previews,
 	})
 }
 
 // FinalizePayroll - generates payslips
-// Only SUPERADMIN can finalize payroll
+// Only SUPERADMIN can finalize an APPROVED payroll. The reviewed draft payslips are promoted as
+// they are; when leave data changed since the preview the changes are returned with 409 unless
+// acknowledge_changes=true is passed.
 func (h *HandlerFunc) FinalizePayroll(c *gin.Context) {
 	// --- Role Check - Only SUPERADMIN ---
 	roleRaw, _ := c.Get("role")
@@ -152,6 +248,11 @@ func (h *HandlerFunc) FinalizePayroll(c *gin.Context) {
 		utils.RespondWithError(c, 403, "Only SUPERADMIN can finalize payroll")
 		return
 	}
+	finalizerID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, 401, err.Error())
+		return
+	}
 
 	// --- Parse Payroll Run ID ---
 	runID, err := uuid.Parse(c.Param("id"))
@@ -161,125 +262,95 @@ func (h *HandlerFunc) FinalizePayroll(c *gin.Context) {
 	}
 
 	// --- Fetch Payroll Run Data ---
-	var run struct {
-		Month  int    `db:"month"`
-		Year   int    `db:"year"`
-		Status string `db:"status"`
-	}
-	err = h.Query.DB.Get(&run,
-		`SELECT month, year, status FROM Tbl_Payroll_run WHERE id=$1`, runID)
+	run, err := h.Query.GetPayrollRun(runID)
 	if err != nil {
 		utils.RespondWithError(c, 404, "Payroll run not found")
 		return
 	}
 
 	// --- Block if Already Finalized ---
-	if strings.ToUpper(strings.TrimSpace(run.Status)) == "FINALIZED" {
+	if !run.IsOpen() {
 		utils.RespondWithError(c, 400, "Payroll is already finalized. Cannot finalize again. Finalized payrolls are locked and cannot be modified.")
 		return
 	}
 
-	// --- Block if not in PREVIEW status ---
-	if strings.ToUpper(strings.TrimSpace(run.Status)) != "PREVIEW" {
-		utils.RespondWithError(c, 400, fmt.Sprintf("Cannot finalize payroll with status: %s. Only PREVIEW payrolls can be finalized.", run.Status))
+	// --- Block if not approved ---
+	if run.Status != models.PayrollStatusApproved {
+		utils.RespondWithError(c, 400, fmt.Sprintf("Cannot finalize payroll with status: %s. Only APPROVED payrolls can be finalized.", run.Status))
 		return
 	}
 
-	// --- Fetch working days ---
-	var workingDays int
-	err = h.Query.DB.Get(&workingDays,
-		`SELECT working_days_per_month FROM Tbl_Company_Settings ORDER BY created_at DESC LIMIT 1`)
-	if err != nil || workingDays <= 0 {
-		workingDays = 22 // fallback default
-	}
-
-	// --- Transaction Start ---
-	tx, err := h.Query.DB.Beginx()
+	// --- The reviewed draft payslips ---
+	drafts, err := h.Query.GetRunPayslips(runID)
 	if err != nil {
-		utils.RespondWithError(c, 500, "Failed to start transaction")
+		utils.RespondWithError(c, 500, "Failed to fetch draft payslips: "+err.Error())
 		return
 	}
-	defer tx.Rollback()
-
-	// --- Fetch Only Employees Belonging To The Payroll Run Period ---
-	var employees []struct {
-		ID       uuid.UUID `db:"id"`
-		FullName string    `db:"full_name"`
-		Salary   *float64  `db:"salary"`
-	}
-
-	err = tx.Select(&employees, `
-        SELECT e.id, e.full_name, e.salary
-        FROM Tbl_Employee e
-        JOIN Tbl_Payroll_run r ON r.id = $1
-        WHERE e.status='active'
-          AND (
-               EXTRACT(YEAR FROM e.joining_date) < r.year
-               OR (EXTRACT(YEAR FROM e.joining_date) = r.year
-                   AND EXTRACT(MONTH FROM e.joining_date) <= r.month)
-              )
-	`, runID)
 
+	// --- Flag leave data changed since the preview ---
+	changes, err := h.payrollDraftChanges(run, drafts)
 	if err != nil {
-		utils.RespondWithError(c, 500, "Failed to fetch payroll employees: "+err.Error())
+		utils.RespondWithError(c, 500, "Failed to compare draft payslips: "+err.Error())
+		return
+	}
+	if len(changes) > 0 && c.Query("acknowledge_changes") != "true" {
+		utils.RespondWithViolations(c, http.StatusConflict,
+			"Payroll data changed since the preview. Run payroll again to refresh the draft, or finalize with acknowledge_changes=true to pay the previewed amounts.",
+			changes)
 		return
 	}
 
-	// --- Generate Payslips ---
-	var payslipIDs []uuid.UUID
-
-	for _, emp := range employees {
-		// Set default salary to 0 if null
-		salary := 0.0
-		if emp.Salary != nil {
-			salary = *emp.Salary
-		}
-
-		// Calculate absent days for this specific month only
-		// Handle cross-month leaves correctly
-		/*
-			absentDays := service.CalculateAbsentDaysForMonth(h.Query.DB, emp.ID, run.Month, run.Year)
-			if absentDays < 0 {
-				utils.RespondWithError(c, 500, "Failed to calculate absent days")
-				return
-			}*/
-		leaveSummary := service.CalculateAbsentDaysForMonth(h.Query.DB, emp.ID, run.Month, run.Year)
-
-		deduction := salary / float64(workingDays) * leaveSummary.UnpaidDays
-		net := salary - deduction
-
-		pID := uuid.New()
-		_, err = tx.Exec(`
-			INSERT INTO Tbl_Payslip 
-			(id, payroll_run_id, employee_id, basic_salary, working_days, paid_leaves,unpaid_leaves, deduction_amount, net_salary)
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
-		`, pID, runID, emp.ID, salary, workingDays, leaveSummary.PaidDays, leaveSummary.UnpaidDays, deduction, net)
-
+	payslipIDs := []uuid.UUID{}
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		// The draft must not have been refreshed or finalized since it was read
+		locked, err := h.Query.GetPayrollRunTx(tx, runID)
 		if err != nil {
-			utils.RespondWithError(c, 500, "Payslip insert failed: "+err.Error())
-			return
+			return utils.CustomErr(c, 500, "Failed to fetch payroll run: "+err.Error())
+		}
+		if locked.Status != models.PayrollStatusApproved || !locked.UpdatedAt.Equal(run.UpdatedAt) {
+			return utils.CustomErr(c, http.StatusConflict, "Payroll run changed while finalizing. Review the payroll run and try again.")
 		}
 
-		payslipIDs = append(payslipIDs, pID)
-	}
+		for _, draft := range drafts {
+			// Pay out the pending year-end leave encashment included in the draft; corrections
+			// carry the encashment their original payslip claimed
+			if draft.PayslipType == models.PayslipTypeRegular && draft.Encashment > 0 {
+				encashment, err := h.Query.ClaimPendingEncashmentTx(tx, draft.EmployeeID, runID)
+				if err != nil {
+					return utils.CustomErr(c, 500, "Failed to claim leave encashment: "+err.Error())
+				}
+				if math.Abs(encashment-draft.Encashment) >= 0.005 {
+					return utils.CustomErr(c, http.StatusConflict, fmt.Sprintf("Leave encashment of %s changed since the preview. Run payroll again before finalizing.", draft.Employee))
+				}
+			}
+			payslipIDs = append(payslipIDs, draft.ID)
+		}
 
-	// --- Mark Payroll Run Finalized ---
-	_, err = tx.Exec(`UPDATE Tbl_Payroll_run SET status='FINALIZED', updated_at=NOW() WHERE id=$1`, runID)
+		// --- Mark Payroll Run Finalized ---
+		if err := h.Query.TransitionPayrollRunTx(tx, locked, models.PayrollStatusFinalized, models.PayrollActionFinalize, finalizerID, ""); err != nil {
+			return utils.CustomErr(c, 500, "Failed to update payroll run: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentPayroll, constant.ActionFinalize, finalizerID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
 	if err != nil {
-		utils.RespondWithError(c, 500, "Failed to update payroll run: "+err.Error())
-		return
-	}
-
-	if err = tx.Commit(); err != nil {
-		utils.RespondWithError(c, 500, "Failed to commit: "+err.Error())
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to finalize payroll: "+err.Error())
 		return
 	}
 
 	// --- Success Response ---
 	c.JSON(http.StatusOK, gin.H{
-		"message":        "Payroll finalized successfully",
-		"payroll_run_id": runID,
-		"payslips":       payslipIDs,
+		"message":              "Payroll finalized successfully",
+		"payroll_run_id":       runID,
+		"payslips":             payslipIDs,
+		"acknowledged_changes": changes,
 	})
 }
 
@@ -302,10 +373,15 @@ type PayslipReportData struct {
 	Year         int
 	BasicSalary  float64
 	WorkingDays  int
+	DayRateBasis string // what WorkingDays counts, models.DayRate*
 	PaidLeaves   float64
 	UnpaidLeaves float64
 	Deductions   float64
+	Encashment   float64
 	NetSalary    float64
+	LossOfPay    float64 // deduction for unpaid leave days
+	// Proration is the employed part of a joining or exit month, nil for a whole month
+	Proration *models.SalaryProration
 }
 
 // renderTable handles the repetitive task of drawing headers and rows
@@ -417,6 +493,16 @@ func renderEmployeeSection(pdf *gofpdf.Fpdf, d PayslipReportData, config PDFConf
 	pdf.CellFormat(70, 7, d.Email, "", 0, "L", false, 0, "")
 	pdf.CellFormat(30, 7, "Working Days:", "", 0, "L", false, 0, "")
 	pdf.CellFormat(0, 7, fmt.Sprint(d.WorkingDays), "", 1, "L", false, 0, "")
+
+	// Row 3 - only for a joining or exit month
+	if d.Proration != nil {
+		basis := "calendar days"
+		if d.Proration.Basis == models.ProrationWorkingDays {
+			basis = "working days"
+		}
+		pdf.CellFormat(30, 7, "Prorated Pay:", "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 7, fmt.Sprintf("%v of %v %s", d.Proration.PayableDays, d.Proration.PeriodDays, basis), "", 1, "L", false, 0, "")
+	}
 	pdf.Ln(8)
 }
 
@@ -465,9 +551,32 @@ func renderAttendanceSummary(pdf *gofpdf.Fpdf, d PayslipReportData, config PDFCo
 	pdf.CellFormat(45, 8, fmt.Sprintf("%.1f", d.UnpaidLeaves), "1", 0, "C", false, 0, "")
 	pdf.CellFormat(45, 8, fmt.Sprintf("%.1f", presentDays), "1", 1, "C", false, 0, "")
 
+	// How the per-day rate of unpaid leave days was computed
+	pdf.Ln(2)
+	pdf.SetFont("Arial", "", 8)
+	pdf.SetTextColor(100, 100, 100)
+	calculation := "Per Day Rate = Monthly Salary / " + dayRateBasisText(d.DayRateBasis, d.WorkingDays, d.Month)
+	if d.UnpaidLeaves > 0 && d.LossOfPay > 0 {
+		calculation += fmt.Sprintf("\nLoss of Pay = Per Day Rate x Unpaid Leaves = %.2f x %.1f = %.2f",
+			d.LossOfPay/d.UnpaidLeaves, d.UnpaidLeaves, d.LossOfPay)
+	}
+	pdf.MultiCell(0, 5, calculation, "", "L", false)
+
 	pdf.Ln(5)
 }
 
+// dayRateBasisText describes the days a month's salary was divided by for the per-day rate
+func dayRateBasisText(basis string, days int, month string) string {
+	switch basis {
+	case models.DayRateWorkingDays:
+		return fmt.Sprintf("%d working days in %s (weekdays less holidays)", days, month)
+	case models.DayRateCalendarDays:
+		return fmt.Sprintf("%d calendar days in %s", days, month)
+	default:
+		return fmt.Sprintf("%d working days per month", days)
+	}
+}
+
 /*
 func renderAttendanceSummary(pdf *gofpdf.Fpdf, d PayslipReportData) {
 	pdf.SetFont("Arial", "B", 10)
@@ -496,20 +605,7 @@ func (h *HandlerFunc) GetPayslipPDF(c *gin.Context) {
 	}
 
 	// 1. FETCH BRANDING
-	var settings models.CompanySettings
-	err = h.Query.DB.Get(&settings, `SELECT company_name, logo_path, primary_color FROM Tbl_Company_Settings LIMIT 1`)
-
-	// If no settings found or name is the generic "Company", override it
-	if err != nil || settings.CompanyName == "" || strings.EqualFold(settings.CompanyName, "Company") {
-		settings.CompanyName = "ZENITHIVE"
-	}
-
-	r, g, b := HexToRGB(settings.PrimaryColor)
-	config := PDFConfig{
-		PrimaryColor: []int{r, g, b},
-		CompanyName:  settings.CompanyName,
-		LogoPath:     settings.LogoPath,
-	}
+	config := h.loadPDFConfig()
 
 	// 2. FETCH PAYSLIP DATA
 	var p struct {
@@ -520,20 +616,27 @@ func (h *HandlerFunc) GetPayslipPDF(c *gin.Context) {
 		Year         int       `db:"year"`
 		BasicSalary  float64   `db:"basic_salary"`
 		WorkingDays  int       `db:"working_days"`
+		DayRateBasis string    `db:"day_rate_basis"`
 		PaidLeaves   float64   `db:"paid_leaves"`
 		UnpaidLeaves float64   `db:"unpaid_leaves"`
 		Deductions   float64   `db:"deduction_amount"`
+		Encashment   float64   `db:"encashment_amount"`
 		NetSalary    float64   `db:"net_salary"`
+		// Proration of a joining or exit month, nil for a whole month
+		ProrationBasis *string  `db:"proration_basis"`
+		PayableDays    *float64 `db:"payable_days"`
+		PeriodDays     *float64 `db:"period_days"`
 	}
 
 	err = h.Query.DB.Get(&p, `
         SELECT e.id as employee_id, e.full_name, e.email, p.basic_salary, 
-               p.working_days,p.paid_leaves, p.unpaid_leaves, p.deduction_amount, p.net_salary,
+               p.working_days, COALESCE(p.day_rate_basis, 'FIXED') AS day_rate_basis, p.paid_leaves, p.unpaid_leaves, p.deduction_amount, p.net_salary, p.encashment_amount,
+               p.proration_basis, p.payable_days, p.period_days,
                pr.month, pr.year
         FROM Tbl_Payslip p
         JOIN Tbl_Employee e ON e.id = p.employee_id
         JOIN Tbl_Payroll_run pr ON pr.id = p.payroll_run_id
-        WHERE p.id = $1`, payslipID)
+        WHERE p.id = $1 AND pr.status IN ('FINALIZED', 'PAID') AND p.status = 'ISSUED'`, payslipID) // draft and withdrawn payslips are not issued
 
 	if err != nil {
 		utils.RespondWithError(c, 404, "Payslip not found")
@@ -549,31 +652,67 @@ func (h *HandlerFunc) GetPayslipPDF(c *gin.Context) {
 		Year:         p.Year,
 		BasicSalary:  p.BasicSalary,
 		WorkingDays:  p.WorkingDays,
+		DayRateBasis: p.DayRateBasis,
 		PaidLeaves:   p.PaidLeaves,
 		UnpaidLeaves: p.UnpaidLeaves,
 		Deductions:   p.Deductions,
+		Encashment:   p.Encashment,
 		NetSalary:    p.NetSalary,
+		Proration:    models.PayslipRecord{ProrationBasis: p.ProrationBasis, PayableDays: p.PayableDays, PeriodDays: p.PeriodDays}.Proration(),
 	}
 
 	// 3. GENERATE PDF
 	pdf := gofpdf.New("P", "mm", "A4", "")
 	pdf.SetMargins(15, 15, 15)
 	pdf.AddPage()
-	pdf.SetAutoPageBreak(false, 0)
+	// Long salary breakdowns continue on a second page
+	pdf.SetAutoPageBreak(true, 30)
 
 	renderHeaderSection(pdf, data, config)
 	renderEmployeeSection(pdf, data, config)
 
-	// Using the lineItem approach for the tables
-	earnings := []lineItem{{description: "Basic Salary", amount: data.BasicSalary}}
-	deductions := []lineItem{{description: fmt.Sprintf("Absent Leave (%v Days)", data.UnpaidLeaves), amount: data.Deductions}}
+	// Using the lineItem approach for the tables: one row per salary component, or the
+	// single basic salary and absent leave rows of payslips finalized before salary structures
+	lines, err := h.Query.GetPayslipLines(payslipID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch payslip lines: "+err.Error())
+		return
+	}
+	var earnings, deductions, contributions []lineItem
+	for _, line := range lines {
+		item := lineItem{description: line.Name, amount: line.Amount}
+		if line.Code == models.SalaryCodeLOP {
+			data.LossOfPay = line.Amount
+		}
+		switch line.Kind {
+		case models.SalaryDeduction:
+			deductions = append(deductions, item)
+		case models.SalaryEmployer:
+			contributions = append(contributions, item)
+		default:
+			earnings = append(earnings, item)
+		}
+	}
+	if len(lines) == 0 {
+		earnings = []lineItem{{description: "Basic Salary", amount: data.BasicSalary}}
+		deductions = []lineItem{{description: fmt.Sprintf("Absent Leave (%v Days)", data.UnpaidLeaves), amount: data.Deductions}}
+		data.LossOfPay = data.Deductions
+	}
+	if data.Encashment > 0 {
+		earnings = append(earnings, lineItem{description: "Leave Encashment", amount: data.Encashment})
+	}
 
-	renderTable(pdf, "EARNINGS", earnings, r, g, b)
+	renderTable(pdf, "EARNINGS", earnings, config.PrimaryColor[0], config.PrimaryColor[1], config.PrimaryColor[2])
 	renderTable(pdf, "DEDUCTIONS", deductions, 231, 76, 60) // Red for deductions
+	if len(contributions) > 0 {
+		// Employer contributions do not reduce the net payable
+		renderTable(pdf, "EMPLOYER CONTRIBUTIONS", contributions, 127, 140, 141)
+	}
 
 	renderSummarySection(pdf, data, config)
 
 	renderAttendanceSummary(pdf, data, config)
+	pdf.SetAutoPageBreak(false, 0)
 	renderFooterSection(pdf, data)
 
 	// 4. SERVE FILE
@@ -582,6 +721,24 @@ func (h *HandlerFunc) GetPayslipPDF(c *gin.Context) {
 	pdf.Output(c.Writer)
 }
 
+// loadPDFConfig reads the company branding used on generated documents
+func (h *HandlerFunc) loadPDFConfig() PDFConfig {
+	var settings models.CompanySettings
+	err := h.Query.DB.Get(&settings, `SELECT company_name, logo_path, primary_color FROM Tbl_Company_Settings LIMIT 1`)
+
+	// If no settings found or name is the generic "Company", override it
+	if err != nil || settings.CompanyName == "" || strings.EqualFold(settings.CompanyName, "Company") {
+		settings.CompanyName = "ZENITHIVE"
+	}
+
+	r, g, b := HexToRGB(settings.PrimaryColor)
+	return PDFConfig{
+		PrimaryColor: []int{r, g, b},
+		CompanyName:  settings.CompanyName,
+		LogoPath:     settings.LogoPath,
+	}
+}
+
 func HexToRGB(hex string) (int, int, int) {
 	hex = strings.TrimPrefix(hex, "#")
 	if len(hex) != 6 {
@@ -899,6 +1056,8 @@ func (h *HandlerFunc) GetFinalizedPayslips(c *gin.Context) {
 		PDFPath         string    `json:"pdf_path"`
 		Calculation     string    `json:"calculation"`
 		CreatedAt       string    `json:"created_at"`
+		Status          string    `json:"status"`       // ISSUED or WITHDRAWN
+		PayslipType     string    `json:"payslip_type"` // REGULAR or CORRECTION
 	}
 
 	var result []FullPayslipResponse
@@ -921,6 +1080,8 @@ func (h *HandlerFunc) GetFinalizedPayslips(c *gin.Context) {
 			&slip.PDFPath,
 			&slip.Calculation,
 			&slip.CreatedAt,
+			&slip.Status,
+			&slip.PayslipType,
 		)
 		if err != nil {
 			utils.RespondWithError(c, 500, "Scan failed: "+err.Error())
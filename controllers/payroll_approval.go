@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// payrollTransition is one step of the payroll run workflow
+type payrollTransition struct {
+	action    string   // models.PayrollAction*
+	from      []string // statuses the run may be in
+	to        string
+	logAction string // constant.Action*
+	// checker transitions must be made by a user other than the run's preparer and submitter
+	checker       bool
+	needsComments bool
+}
+
+// transitionPayrollRun moves the payroll run of the :id param through a workflow step and
+// logs it. It responds itself and returns false on failure.
+func (h *HandlerFunc) transitionPayrollRun(c *gin.Context, t payrollTransition) (models.PayrollRun, string, bool) {
+	actorID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return models.PayrollRun{}, "", false
+	}
+	runID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid payroll run ID")
+		return models.PayrollRun{}, "", false
+	}
+	var input models.PayrollRunActionInput
+	if err := c.ShouldBindJSON(&input); err != nil && err != io.EOF {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return models.PayrollRun{}, "", false
+	}
+	input.Comments = strings.TrimSpace(input.Comments)
+	if t.needsComments && input.Comments == "" {
+		utils.RespondWithError(c, http.StatusBadRequest, "Comments are required")
+		return models.PayrollRun{}, "", false
+	}
+
+	var run models.PayrollRun
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		run, err = h.Query.GetPayrollRunTx(tx, runID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, http.StatusNotFound, "Payroll run not found")
+		}
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch payroll run: "+err.Error())
+		}
+
+		allowed := false
+		for _, status := range t.from {
+			allowed = allowed || run.Status == status
+		}
+		if !allowed {
+			return utils.CustomErr(c, http.StatusBadRequest, fmt.Sprintf("Cannot %s a payroll run with status %s. The run must be %s.",
+				strings.ToLower(t.action), run.Status, strings.Join(t.from, " or ")))
+		}
+		if t.checker && ((run.PreparedBy != nil && *run.PreparedBy == actorID) || (run.SubmittedBy != nil && *run.SubmittedBy == actorID)) {
+			return utils.CustomErr(c, http.StatusForbidden, "A payroll run must be reviewed by a user other than the one who prepared or submitted it")
+		}
+
+		if err := h.Query.TransitionPayrollRunTx(tx, run, t.to, t.action, actorID, input.Comments); err != nil {
+			return utils.CustomErr(c, 500, "Failed to update payroll run: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentPayroll, t.logAction, actorID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return run, "", false
+		}
+		utils.RespondWithError(c, 500, "Failed to update payroll run: "+err.Error())
+		return run, "", false
+	}
+	return run, input.Comments, true
+}
+
+// notifyPayrollPreparer emails the preparer of a payroll run that it was approved or rejected
+func (h *HandlerFunc) notifyPayrollPreparer(run models.PayrollRun, status, actorID, comments string) {
+	if run.PreparedBy == nil {
+		return
+	}
+	preparer, err := h.Query.GetEmployeeDetailsForNotification(*run.PreparedBy)
+	if err != nil {
+		fmt.Printf("Failed to get employee details for notification: %v\n", err)
+		return
+	}
+	var actorName string
+	h.Query.DB.Get(&actorName, "SELECT full_name FROM Tbl_Employee WHERE id=$1", actorID)
+	utils.SendPayrollRunDecisionEmail(preparer.Email, preparer.FullName, run.Month, run.Year, status, actorName, comments)
+}
+
+// SubmitPayroll - POST /api/payroll/:id/submit
+// Submits a DRAFT payroll run for approval and emails the approvers
+func (h *HandlerFunc) SubmitPayroll(c *gin.Context) {
+	role := c.GetString("role")
+	if role != constant.ROLE_SUPER_ADMIN && role != constant.ROLE_ADMIN {
+		utils.RespondWithError(c, http.StatusForbidden, "Not authorized to submit payroll")
+		return
+	}
+	run, comments, ok := h.transitionPayrollRun(c, payrollTransition{
+		action:    models.PayrollActionSubmit,
+		from:      []string{models.PayrollStatusDraft},
+		to:        models.PayrollStatusSubmitted,
+		logAction: constant.ActionSubmit,
+	})
+	if !ok {
+		return
+	}
+
+	submitterID, _ := common.GetEmployeeId(c)
+	go func() {
+		exclude := []uuid.UUID{submitterID}
+		if run.PreparedBy != nil {
+			exclude = append(exclude, *run.PreparedBy)
+		}
+		recipients, err := h.Query.GetPayrollApproverEmails(exclude...)
+		if err != nil || len(recipients) == 0 {
+			return
+		}
+		payslips, err := h.Query.GetRunPayslips(run.ID)
+		if err != nil {
+			fmt.Printf("Failed to get payslips for notification: %v\n", err)
+			return
+		}
+		totalNet := 0.0
+		for _, p := range payslips {
+			totalNet += p.NetSalary
+		}
+		var submitterName string
+		h.Query.DB.Get(&submitterName, "SELECT full_name FROM Tbl_Employee WHERE id=$1", submitterID)
+		utils.SendPayrollApprovalRequestEmail(recipients, run.Month, run.Year, len(payslips), totalNet, submitterName, comments)
+	}()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Payroll submitted for approval",
+		"payroll_run_id": run.ID,
+		"status":         models.PayrollStatusSubmitted,
+	})
+}
+
+// ApprovePayroll - POST /api/payroll/:id/approve
+// Approves a SUBMITTED payroll run with comments; the approver must not have prepared or
+// submitted it
+func (h *HandlerFunc) ApprovePayroll(c *gin.Context) {
+	role := c.GetString("role")
+	if role != constant.ROLE_SUPER_ADMIN && role != constant.ROLE_ADMIN {
+		utils.RespondWithError(c, http.StatusForbidden, "Not authorized to approve payroll")
+		return
+	}
+	run, comments, ok := h.transitionPayrollRun(c, payrollTransition{
+		action:        models.PayrollActionApprove,
+		from:          []string{models.PayrollStatusSubmitted},
+		to:            models.PayrollStatusApproved,
+		logAction:     constant.ActionApproval,
+		checker:       true,
+		needsComments: true,
+	})
+	if !ok {
+		return
+	}
+
+	approverID := c.GetString("user_id")
+	go h.notifyPayrollPreparer(run, models.PayrollStatusApproved, approverID, comments)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Payroll approved",
+		"payroll_run_id": run.ID,
+		"status":         models.PayrollStatusApproved,
+	})
+}
+
+// RejectPayroll - POST /api/payroll/:id/reject
+// Sends a SUBMITTED or APPROVED payroll run back to DRAFT with comments
+func (h *HandlerFunc) RejectPayroll(c *gin.Context) {
+	role := c.GetString("role")
+	if role != constant.ROLE_SUPER_ADMIN && role != constant.ROLE_ADMIN {
+		utils.RespondWithError(c, http.StatusForbidden, "Not authorized to reject payroll")
+		return
+	}
+	run, comments, ok := h.transitionPayrollRun(c, payrollTransition{
+		action:        models.PayrollActionReject,
+		from:          []string{models.PayrollStatusSubmitted, models.PayrollStatusApproved},
+		to:            models.PayrollStatusDraft,
+		logAction:     constant.ActionRejection,
+		checker:       true,
+		needsComments: true,
+	})
+	if !ok {
+		return
+	}
+
+	rejectorID := c.GetString("user_id")
+	go h.notifyPayrollPreparer(run, "REJECTED", rejectorID, comments)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Payroll rejected and returned to draft",
+		"payroll_run_id": run.ID,
+		"status":         models.PayrollStatusDraft,
+	})
+}
+
+// MarkPayrollPaid - POST /api/payroll/:id/paid
+// Records that the salaries of a FINALIZED payroll run were paid
+func (h *HandlerFunc) MarkPayrollPaid(c *gin.Context) {
+	if c.GetString("role") != constant.ROLE_SUPER_ADMIN {
+		utils.RespondWithError(c, http.StatusForbidden, "Only SUPERADMIN can mark payroll as paid")
+		return
+	}
+	run, _, ok := h.transitionPayrollRun(c, payrollTransition{
+		action:    models.PayrollActionPay,
+		from:      []string{models.PayrollStatusFinalized},
+		to:        models.PayrollStatusPaid,
+		logAction: constant.ActionPay,
+	})
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Payroll marked as paid",
+		"payroll_run_id": run.ID,
+		"status":         models.PayrollStatusPaid,
+	})
+}
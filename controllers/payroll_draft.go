@@ -0,0 +1,331 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+)
+
+// payrollInputs is what the payslips of a month are computed from
+type payrollInputs struct {
+	Employees   []repositories.EmpMonthlyData
+	WorkingDays int
+	// DayRateBasis is what WorkingDays counts, models.DayRate*
+	DayRateBasis string
+	Leaves       map[uuid.UUID]service.LeaveSummary
+	// Fingerprints identify each employee's approved leave records of the month
+	Fingerprints map[uuid.UUID]string
+	// Encashments are the pending year-end leave encashments paid with the run
+	Encashments map[uuid.UUID]float64
+	// Prorations are the employed part of the month of employees joining or leaving in it
+	Prorations map[uuid.UUID]*models.SalaryProration
+}
+
+// loadPayrollInputs reads the eligible employees, working days, leaves, pending leave
+// encashment and joining or exit prorations of a payroll month
+func (h *HandlerFunc) loadPayrollInputs(month, year int) (payrollInputs, error) {
+	in := payrollInputs{Leaves: map[uuid.UUID]service.LeaveSummary{}}
+	var err error
+	in.Employees, err = h.Query.GetEmployeeByMonthAndYear(struct {
+		Month int `json:"month" validate:"required"`
+		Year  int `json:"year" validate:"required"`
+	}{Month: month, Year: year})
+	if err != nil {
+		return in, err
+	}
+	if in.WorkingDays, in.DayRateBasis, err = h.payrollWorkingDays(month, year); err != nil {
+		return in, err
+	}
+	if in.Fingerprints, err = h.Query.GetLeaveFingerprints(month, year); err != nil {
+		return in, err
+	}
+	if in.Encashments, err = h.Query.GetPendingEncashments(); err != nil {
+		return in, err
+	}
+	if in.Prorations, err = h.employmentProrations(in.Employees, month, year); err != nil {
+		return in, err
+	}
+	for _, emp := range in.Employees {
+		in.Leaves[emp.ID] = service.CalculateAbsentDaysForMonth(h.Query.DB, emp.ID, month, year)
+	}
+	return in, nil
+}
+
+// payrollWorkingDays returns the days a month's salary is divided by for the per-day rate on
+// the company's per-day rate basis, and the basis
+func (h *HandlerFunc) payrollWorkingDays(month, year int) (int, string, error) {
+	basis := h.Query.GetPayrollDayRateBasis()
+	fixedDays := h.Query.GetCompanyCurrWorkingDays()
+	if basis == models.DayRateFixed {
+		return fixedDays, basis, nil
+	}
+	firstDay := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	holidays, err := h.Query.GetHolidaysBetween(firstDay, firstDay.AddDate(0, 1, -1))
+	if err != nil {
+		return 0, basis, err
+	}
+	return service.MonthWorkingDays(basis, fixedDays, month, year, holidays), basis, nil
+}
+
+// employmentProrations prorates the salary of the employees joining or leaving in a payroll
+// month on the company's proration basis
+func (h *HandlerFunc) employmentProrations(employees []repositories.EmpMonthlyData, month, year int) (map[uuid.UUID]*models.SalaryProration, error) {
+	firstDay := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	holidays, err := h.Query.GetHolidaysBetween(firstDay, firstDay.AddDate(0, 1, -1))
+	if err != nil {
+		return nil, err
+	}
+	basis := h.Query.GetPayrollProrationBasis()
+	prorations := map[uuid.UUID]*models.SalaryProration{}
+	for _, emp := range employees {
+		if proration := service.EmploymentProration(basis, emp.JoiningDate, emp.EndingDate, month, year, holidays); proration != nil {
+			prorations[emp.ID] = proration
+		}
+	}
+	return prorations, nil
+}
+
+// draftInputs returns the inputs of each employee's payslip as a payslip record without amounts
+func (in payrollInputs) draftInputs() []models.PayslipRecord {
+	records := make([]models.PayslipRecord, 0, len(in.Employees))
+	for _, emp := range in.Employees {
+		record := models.PayslipRecord{
+			EmployeeID:       emp.ID,
+			Employee:         emp.FullName,
+			WorkingDays:      in.WorkingDays,
+			DayRateBasis:     in.DayRateBasis,
+			PaidLeaves:       in.Leaves[emp.ID].PaidDays,
+			UnpaidLeaves:     in.Leaves[emp.ID].UnpaidDays,
+			Encashment:       in.Encashments[emp.ID],
+			LeaveFingerprint: in.Fingerprints[emp.ID],
+			PayslipType:      models.PayslipTypeRegular,
+		}
+		record.SetProration(in.Prorations[emp.ID])
+		records = append(records, record)
+	}
+	return records
+}
+
+// payrollDraftChanges lists what changed in the inputs of a run's draft payslips since the preview
+func (h *HandlerFunc) payrollDraftChanges(run models.PayrollRun, drafts []models.PayslipRecord) ([]models.PayrollDraftChange, error) {
+	in, err := h.loadPayrollInputs(run.Month, run.Year)
+	if err != nil {
+		return nil, err
+	}
+	current := in.draftInputs()
+	corrections, err := h.Query.GetCorrectionsForRun(run.Month, run.Year)
+	if err != nil {
+		return nil, err
+	}
+	for i := range corrections {
+		current = append(current, models.PayslipRecord{
+			EmployeeID:   corrections[i].EmployeeID,
+			Employee:     corrections[i].Employee,
+			PayslipType:  models.PayslipTypeCorrection,
+			CorrectionID: &corrections[i].ID,
+		})
+	}
+	return service.DiffPayrollDraft(drafts, current), nil
+}
+
+// GetPayrollRun - GET /api/payroll/:id
+// A payroll run with its payslips (draft payslips until the run is finalized) and its history
+func (h *HandlerFunc) GetPayrollRun(c *gin.Context) {
+	role := c.GetString("role")
+	if role != "SUPERADMIN" && role != "ADMIN" {
+		utils.RespondWithError(c, http.StatusForbidden, "Not authorized to view payroll runs")
+		return
+	}
+	runID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid payroll run ID")
+		return
+	}
+	run, err := h.Query.GetPayrollRun(runID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Payroll run not found")
+		return
+	}
+	payslips, err := h.Query.GetRunPayslips(runID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch payslips: "+err.Error())
+		return
+	}
+	history, err := h.Query.GetPayrollRunActions(runID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch payroll run history: "+err.Error())
+		return
+	}
+
+	totalPayroll, totalDeductions := 0.0, 0.0
+	for _, p := range payslips {
+		totalPayroll += p.NetSalary
+		totalDeductions += p.Deductions
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"payroll_run":      run,
+		"total_payroll":    totalPayroll,
+		"total_deductions": totalDeductions,
+		"employees_count":  len(payslips),
+		"payslips":         payslips,
+		"history":          history,
+	})
+}
+
+// GetPayrollDraftChanges - GET /api/payroll/:id/changes
+// What changed in the employees, working days, leaves and leave encashment of an open run
+// since its draft payslips were computed
+func (h *HandlerFunc) GetPayrollDraftChanges(c *gin.Context) {
+	role := c.GetString("role")
+	if role != "SUPERADMIN" && role != "ADMIN" {
+		utils.RespondWithError(c, http.StatusForbidden, "Not authorized to view payroll runs")
+		return
+	}
+	runID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid payroll run ID")
+		return
+	}
+	run, err := h.Query.GetPayrollRun(runID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Payroll run not found")
+		return
+	}
+	if !run.IsOpen() {
+		utils.RespondWithError(c, http.StatusBadRequest, "Only payroll runs not yet finalized have draft payslips to compare")
+		return
+	}
+	drafts, err := h.Query.GetRunPayslips(runID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to fetch draft payslips: "+err.Error())
+		return
+	}
+	changes, err := h.payrollDraftChanges(run, drafts)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to compare draft payslips: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"payroll_run_id": runID,
+		"has_changes":    len(changes) > 0,
+		"changes":        changes,
+	})
+}
+
+// payrollRules are the salary structures and statutory rules the payslips of a month are
+// computed with
+type payrollRules struct {
+	month, year int
+	structures  map[uuid.UUID]models.SalaryStructure
+	statutory   map[string]models.StatutoryRule
+	ytds        map[uuid.UUID]models.StatutoryYTD
+}
+
+// loadPayrollRules reads the salary structures, the statutory rules in force in a month and the
+// year-to-date payslips before it
+func (h *HandlerFunc) loadPayrollRules(month, year int) (payrollRules, error) {
+	rules := payrollRules{month: month, year: year}
+	var err error
+	if rules.structures, err = h.Query.GetSalaryStructureMap(); err != nil {
+		return rules, err
+	}
+	if rules.statutory, err = h.Query.GetStatutoryRulesInForce(time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		return rules, err
+	}
+	rules.ytds, err = h.Query.GetStatutoryYTDs(month, year, service.FinancialYearStart(month, year))
+	return rules, err
+}
+
+// computePayslip computes an employee's regular payslip of the month, prorated when they
+// joined or left during it
+func (r payrollRules) computePayslip(emp repositories.EmpMonthlyData, workingDays int, leave service.LeaveSummary, encashment float64, proration *models.SalaryProration) (models.PayslipRecord, models.SalaryBreakdown, error) {
+	// Set default salary to 0 if null
+	salary := 0.0
+	if emp.Salary != nil {
+		salary = *emp.Salary
+	}
+	breakdown, err := service.ComputeSalary(salaryStructureFor(r.structures, emp.SalaryStructureID), salary, workingDays, leave, proration)
+	if err != nil {
+		return models.PayslipRecord{}, breakdown, fmt.Errorf("Failed to compute salary of %s: %v", emp.FullName, err)
+	}
+	statutory := service.StatutoryInput{Month: r.month, Year: r.year, Profile: emp.StatutoryProfile, YTD: r.ytds[emp.ID], OtherTaxable: encashment, EndingDate: emp.EndingDate}
+	if err := service.ApplyStatutoryDeductions(r.statutory, statutory, &breakdown); err != nil {
+		return models.PayslipRecord{}, breakdown, fmt.Errorf("Failed to compute statutory deductions of %s: %v", emp.FullName, err)
+	}
+
+	record := models.PayslipRecord{
+		EmployeeID:   emp.ID,
+		Employee:     emp.FullName,
+		BasicSalary:  breakdown.Gross,
+		WorkingDays:  workingDays,
+		PaidLeaves:   leave.PaidDays,
+		UnpaidLeaves: leave.UnpaidDays,
+		Deductions:   breakdown.Deductions,
+		Encashment:   encashment,
+		NetSalary:    breakdown.Gross - breakdown.Deductions + encashment,
+		PayslipType:  models.PayslipTypeRegular,
+		Lines:        breakdown.Lines,
+	}
+	record.SetProration(proration)
+	return record, breakdown, nil
+}
+
+// computeCorrection recomputes the pay period of a withdrawn payslip with the employee's
+// current salary and leaves and the period's working days, less what was already paid for it
+func (h *HandlerFunc) computeCorrection(pc models.PayslipCorrection) (models.PayslipRecord, models.SalaryBreakdown, error) {
+	fail := func(what string, err error) (models.PayslipRecord, models.SalaryBreakdown, error) {
+		return models.PayslipRecord{}, models.SalaryBreakdown{}, fmt.Errorf("Failed to fetch %s for the correction of %s: %v", what, pc.Employee, err)
+	}
+	emp, err := h.Query.GetPayrollEmployee(pc.EmployeeID)
+	if err != nil {
+		return fail("employee", err)
+	}
+	rules, err := h.loadPayrollRules(pc.Month, pc.Year)
+	if err != nil {
+		return fail("payroll rules", err)
+	}
+	encashment, err := h.Query.GetPayslipEncashment(pc.PayslipID)
+	if err != nil {
+		return fail("leave encashment", err)
+	}
+	paid, err := h.Query.GetPaidForPeriod(pc.EmployeeID, pc.Month, pc.Year)
+	if err != nil {
+		return fail("payments", err)
+	}
+
+	prorations, err := h.employmentProrations([]repositories.EmpMonthlyData{emp}, pc.Month, pc.Year)
+	if err != nil {
+		return fail("proration", err)
+	}
+	workingDays, dayRateBasis, err := h.payrollWorkingDays(pc.Month, pc.Year)
+	if err != nil {
+		return fail("working days", err)
+	}
+
+	leave := service.CalculateAbsentDaysForMonth(h.Query.DB, pc.EmployeeID, pc.Month, pc.Year)
+	record, breakdown, err := rules.computePayslip(emp, workingDays, leave, encashment, prorations[emp.ID])
+	if err != nil {
+		return record, breakdown, err
+	}
+	if paid != 0 {
+		record.Lines = append(record.Lines, models.PayslipLine{
+			Code:   models.SalaryCodePreviouslyPaid,
+			Name:   fmt.Sprintf("Previously Paid (%s %d)", time.Month(pc.Month), pc.Year),
+			Kind:   models.SalaryDeduction,
+			Amount: paid,
+		})
+		record.Deductions += paid
+		record.NetSalary -= paid
+	}
+	record.DayRateBasis = dayRateBasis
+	record.PayslipType = models.PayslipTypeCorrection
+	record.CorrectionID = &pc.ID
+	return record, breakdown, nil
+}
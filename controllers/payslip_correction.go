@@ -0,0 +1,286 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// queuePayslipCorrectionTx queues a correction of a payslip's original for the next payroll run.
+// The correction deducts what was paid for the period, which is only known once the payslip's
+// run is marked PAID: a FINALIZED run may or may not have been paid outside the system.
+func (h *HandlerFunc) queuePayslipCorrectionTx(c *gin.Context, tx *sqlx.Tx, p repositories.PayslipForWithdrawal, reason string, actorID uuid.UUID) (uuid.UUID, error) {
+	if p.RunStatus != models.PayrollStatusPaid {
+		return uuid.Nil, utils.CustomErr(c, http.StatusBadRequest, "The payroll run of "+p.Employee+"'s payslip must be marked PAID before it can be corrected; withdraw without correct=true and queue the correction once the run is paid")
+	}
+	open, err := h.Query.HasOpenCorrectionTx(tx, p.OriginalID)
+	if err != nil {
+		return uuid.Nil, utils.CustomErr(c, 500, "Failed to check payslip corrections: "+err.Error())
+	}
+	if open {
+		return uuid.Nil, utils.CustomErr(c, http.StatusBadRequest, "The payslip of "+p.Employee+" already has a correction pending or paid; withdraw that correction payslip first")
+	}
+	id, err := h.Query.InsertPayslipCorrectionTx(tx, p.OriginalID, reason, actorID)
+	if err != nil {
+		return uuid.Nil, utils.CustomErr(c, 500, "Failed to queue payslip correction: "+err.Error())
+	}
+	return id, nil
+}
+
+// withdrawPayslipsTx withdraws payslips of finalized runs, optionally queuing their correction
+func (h *HandlerFunc) withdrawPayslipsTx(c *gin.Context, tx *sqlx.Tx, payslips []repositories.PayslipForWithdrawal, input models.PayslipWithdrawInput, actorID uuid.UUID) ([]models.WithdrawnPayslip, error) {
+	withdrawn := []models.WithdrawnPayslip{}
+	for _, p := range payslips {
+		if err := h.Query.WithdrawPayslipTx(tx, p.ID, actorID, input.Reason); err != nil {
+			return nil, utils.CustomErr(c, 500, "Failed to withdraw payslip: "+err.Error())
+		}
+		if input.Correct {
+			id, err := h.queuePayslipCorrectionTx(c, tx, p, input.Reason, actorID)
+			if err != nil {
+				return nil, err
+			}
+			p.CorrectionID = &id
+		}
+		withdrawn = append(withdrawn, p.WithdrawnPayslip)
+	}
+
+	data := models.NewCommon(constant.ComponentPayroll, constant.ActionWithdrawal, actorID)
+	if err := h.Query.AddLog(data, tx); err != nil {
+		return nil, utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+	}
+	if input.Correct {
+		data := models.NewCommon(constant.ComponentPayroll, constant.ActionCorrect, actorID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return nil, utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+	}
+	return withdrawn, nil
+}
+
+// notifyPayslipWithdrawals emails each employee whose payslip was withdrawn
+func (h *HandlerFunc) notifyPayslipWithdrawals(withdrawn []models.WithdrawnPayslip, actorID uuid.UUID, role, reason string) {
+	var actorName string
+	h.Query.DB.Get(&actorName, "SELECT full_name FROM Tbl_Employee WHERE id=$1", actorID)
+	for _, p := range withdrawn {
+		utils.SendPayslipWithdrawalEmail(p.Email, p.Employee, p.Month, p.Year, p.NetSalary, actorName, role, reason)
+	}
+}
+
+// bindPayslipWithdrawal checks the caller is SUPERADMIN and reads a withdrawal request
+func (h *HandlerFunc) bindPayslipWithdrawal(c *gin.Context) (uuid.UUID, uuid.UUID, models.PayslipWithdrawInput, bool) {
+	var input models.PayslipWithdrawInput
+	if c.GetString("role") != constant.ROLE_SUPER_ADMIN {
+		utils.RespondWithError(c, http.StatusForbidden, "Only SUPERADMIN can withdraw payslips")
+		return uuid.Nil, uuid.Nil, input, false
+	}
+	actorID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return uuid.Nil, uuid.Nil, input, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid ID")
+		return uuid.Nil, uuid.Nil, input, false
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return uuid.Nil, uuid.Nil, input, false
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if err := h.Validator.Struct(input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "A reason is required")
+		return uuid.Nil, uuid.Nil, input, false
+	}
+	return actorID, id, input, true
+}
+
+// WithdrawPayslip - POST /api/payroll/payslips/:id/withdraw
+// Withdraws a payslip of a finalized run, keeping it for audit. With correct=true (paid runs
+// only) the pay period is recomputed by a correction payslip in the next payroll run.
+func (h *HandlerFunc) WithdrawPayslip(c *gin.Context) {
+	actorID, payslipID, input, ok := h.bindPayslipWithdrawal(c)
+	if !ok {
+		return
+	}
+
+	var withdrawn []models.WithdrawnPayslip
+	err := common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		p, err := h.Query.GetPayslipForWithdrawalTx(tx, payslipID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, http.StatusNotFound, "Payslip not found")
+		}
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch payslip: "+err.Error())
+		}
+		if p.RunStatus != models.PayrollStatusFinalized && p.RunStatus != models.PayrollStatusPaid {
+			return utils.CustomErr(c, http.StatusBadRequest, "Only payslips of finalized payroll runs can be withdrawn; run the payroll again to change a draft")
+		}
+		if p.Status == models.PayslipStatusWithdrawn {
+			return utils.CustomErr(c, http.StatusBadRequest, "Payslip is already withdrawn")
+		}
+		withdrawn, err = h.withdrawPayslipsTx(c, tx, []repositories.PayslipForWithdrawal{p}, input, actorID)
+		return err
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to withdraw payslip: "+err.Error())
+		return
+	}
+
+	go h.notifyPayslipWithdrawals(withdrawn, actorID, c.GetString("role"), input.Reason)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payslip withdrawn",
+		"payslip": withdrawn[0],
+	})
+}
+
+// WithdrawPayrollRun - POST /api/payroll/:id/withdraw
+// Withdraws every issued payslip of a finalized run; the run keeps its status and the
+// withdrawal is recorded in its history
+func (h *HandlerFunc) WithdrawPayrollRun(c *gin.Context) {
+	actorID, runID, input, ok := h.bindPayslipWithdrawal(c)
+	if !ok {
+		return
+	}
+
+	var withdrawn []models.WithdrawnPayslip
+	err := common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		run, err := h.Query.GetPayrollRunTx(tx, runID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, http.StatusNotFound, "Payroll run not found")
+		}
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch payroll run: "+err.Error())
+		}
+		if run.Status != models.PayrollStatusFinalized && run.Status != models.PayrollStatusPaid {
+			return utils.CustomErr(c, http.StatusBadRequest, "Only finalized payroll runs can be withdrawn; run the payroll again to change a draft")
+		}
+		payslips, err := h.Query.GetIssuedRunPayslipsTx(tx, runID)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch payslips: "+err.Error())
+		}
+		if len(payslips) == 0 {
+			return utils.CustomErr(c, http.StatusBadRequest, "Payroll run has no issued payslips to withdraw")
+		}
+		if withdrawn, err = h.withdrawPayslipsTx(c, tx, payslips, input, actorID); err != nil {
+			return err
+		}
+		if err := h.Query.InsertPayrollRunActionTx(tx, runID, models.PayrollActionWithdraw, &run.Status, run.Status, actorID, input.Reason); err != nil {
+			return utils.CustomErr(c, 500, "Failed to record payroll run withdrawal: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to withdraw payroll run: "+err.Error())
+		return
+	}
+
+	go h.notifyPayslipWithdrawals(withdrawn, actorID, c.GetString("role"), input.Reason)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Payroll run payslips withdrawn",
+		"payroll_run_id": runID,
+		"payslips":       withdrawn,
+	})
+}
+
+// CorrectPayslip - POST /api/payroll/payslips/:id/correct
+// Queues a correction of a withdrawn payslip of a PAID run for the next payroll run
+func (h *HandlerFunc) CorrectPayslip(c *gin.Context) {
+	if c.GetString("role") != constant.ROLE_SUPER_ADMIN {
+		utils.RespondWithError(c, http.StatusForbidden, "Only SUPERADMIN can correct payslips")
+		return
+	}
+	actorID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	payslipID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid payslip ID")
+		return
+	}
+	var input models.PayslipCorrectionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if err := h.Validator.Struct(input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "A reason is required")
+		return
+	}
+
+	var correctionID uuid.UUID
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		p, err := h.Query.GetPayslipForWithdrawalTx(tx, payslipID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, http.StatusNotFound, "Payslip not found")
+		}
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch payslip: "+err.Error())
+		}
+		if p.Status != models.PayslipStatusWithdrawn {
+			return utils.CustomErr(c, http.StatusBadRequest, "Only withdrawn payslips can be corrected; withdraw the payslip with correct=true instead")
+		}
+		if correctionID, err = h.queuePayslipCorrectionTx(c, tx, p, input.Reason, actorID); err != nil {
+			return err
+		}
+		data := models.NewCommon(constant.ComponentPayroll, constant.ActionCorrect, actorID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to queue payslip correction: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Payslip correction queued for the next payroll run",
+		"correction_id": correctionID,
+	})
+}
+
+// GetPayslipCorrections - GET /api/payroll/corrections
+// Corrections not yet paid by a finalized payroll run
+func (h *HandlerFunc) GetPayslipCorrections(c *gin.Context) {
+	role := c.GetString("role")
+	if role != constant.ROLE_SUPER_ADMIN && role != constant.ROLE_ADMIN {
+		utils.RespondWithError(c, http.StatusForbidden, "Not authorized to view payslip corrections")
+		return
+	}
+	corrections, err := h.Query.GetPendingPayslipCorrections()
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch payslip corrections: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"total": len(corrections),
+		"data":  corrections,
+	})
+}
@@ -0,0 +1,345 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/access_role"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// canManageSalaryStructures reports whether the role may change salary structures; the same
+// roles that run payroll
+func canManageSalaryStructures(role string) bool {
+	return role == constant.ROLE_SUPER_ADMIN || role == constant.ROLE_ADMIN
+}
+
+// GetSalaryStructures - GET /api/payroll/salary-structures
+func (h *HandlerFunc) GetSalaryStructures(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can view salary structures"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	structures, err := h.Query.GetSalaryStructures()
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch salary structures: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"salary_structures": structures})
+}
+
+// GetSalaryStructure - GET /api/payroll/salary-structures/:id
+func (h *HandlerFunc) GetSalaryStructure(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can view salary structures"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid salary structure ID")
+		return
+	}
+	structure, err := h.Query.GetSalaryStructure(id)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "Salary structure not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch salary structure: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, structure)
+}
+
+// CreateSalaryStructure - POST /api/payroll/salary-structures
+// Components are evaluated in dependency order: percentages and formulas may refer to any
+// other component code, and to CTC, WORKING_DAYS, PAID_LEAVES and UNPAID_LEAVES.
+func (h *HandlerFunc) CreateSalaryStructure(c *gin.Context) {
+	h.saveSalaryStructure(c, nil)
+}
+
+// UpdateSalaryStructure - PUT /api/payroll/salary-structures/:id
+// Replaces the name, description and components. Finalized payslips keep their lines.
+func (h *HandlerFunc) UpdateSalaryStructure(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid salary structure ID")
+		return
+	}
+	h.saveSalaryStructure(c, &id)
+}
+
+func (h *HandlerFunc) saveSalaryStructure(c *gin.Context, id *uuid.UUID) {
+	role := c.GetString("role")
+	if !canManageSalaryStructures(role) {
+		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN and SUPERADMIN can manage salary structures")
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var input models.SalaryStructureInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if err := h.Validator.Struct(input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	components, err := service.NormalizeSalaryComponents(input.Components)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var structureID uuid.UUID
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		excludeID := uuid.Nil
+		if id != nil {
+			excludeID = *id
+		}
+		exists, err := h.Query.SalaryStructureNameExistsTx(tx, input.Name, excludeID)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to check salary structure name: "+err.Error())
+		}
+		if exists {
+			return utils.CustomErr(c, http.StatusConflict, "A salary structure with this name already exists")
+		}
+
+		action := constant.ActionCreate
+		if id == nil {
+			structureID, err = h.Query.InsertSalaryStructure(tx, input.Name, input.Description)
+			if err != nil {
+				return utils.CustomErr(c, 500, "Failed to create salary structure: "+err.Error())
+			}
+		} else {
+			action = constant.ActionUpdate
+			structureID = *id
+			updated, err := h.Query.UpdateSalaryStructure(tx, structureID, input.Name, input.Description)
+			if err != nil {
+				return utils.CustomErr(c, 500, "Failed to update salary structure: "+err.Error())
+			}
+			if updated == 0 {
+				return utils.CustomErr(c, http.StatusNotFound, "Salary structure not found")
+			}
+		}
+		if err := h.Query.ReplaceSalaryComponents(tx, structureID, components); err != nil {
+			return utils.CustomErr(c, 500, "Failed to save salary components: "+err.Error())
+		}
+
+		data := models.NewCommon(constant.ComponentSalaryStructure, action, empID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to save salary structure: "+err.Error())
+		return
+	}
+
+	structure, err := h.Query.GetSalaryStructure(structureID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch salary structure: "+err.Error())
+		return
+	}
+	status := http.StatusOK
+	if id == nil {
+		status = http.StatusCreated
+	}
+	c.JSON(status, structure)
+}
+
+// DeleteSalaryStructure - DELETE /api/payroll/salary-structures/:id
+// Employees and designations using the structure are paid their salary as basic salary
+// until another structure is assigned.
+func (h *HandlerFunc) DeleteSalaryStructure(c *gin.Context) {
+	role := c.GetString("role")
+	if !canManageSalaryStructures(role) {
+		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN and SUPERADMIN can manage salary structures")
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid salary structure ID")
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		deleted, err := h.Query.DeleteSalaryStructure(tx, id)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to delete salary structure: "+err.Error())
+		}
+		if deleted == 0 {
+			return utils.CustomErr(c, http.StatusNotFound, "Salary structure not found")
+		}
+		data := models.NewCommon(constant.ComponentSalaryStructure, constant.ActionDelete, empID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to delete salary structure: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Salary structure deleted successfully"})
+}
+
+// UpdateEmployeeSalaryStructure - PATCH /api/employee/:id/salary-structure
+// Sets the employee's own salary structure; null falls back to the designation's structure.
+func (h *HandlerFunc) UpdateEmployeeSalaryStructure(c *gin.Context) {
+	role := c.GetString("role")
+	if !canManageSalaryStructures(role) {
+		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN and SUPERADMIN can assign salary structures")
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+	if _, err := h.Query.GetEmployeeByID(employeeID); err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	}
+	input, ok := h.bindSalaryStructureAssignment(c)
+	if !ok {
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.AssignEmployeeSalaryStructure(tx, employeeID, input.SalaryStructureID); err != nil {
+			return utils.CustomErr(c, 500, "failed to assign salary structure: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentSalaryStructure, constant.ActionUpdate, empID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "failed to assign salary structure: "+err.Error())
+		return
+	}
+
+	message := "employee salary structure updated successfully"
+	if input.SalaryStructureID == nil {
+		message = "employee salary structure removed successfully"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":             message,
+		"employee_id":         employeeID,
+		"salary_structure_id": input.SalaryStructureID,
+	})
+}
+
+// UpdateDesignationSalaryStructure - PATCH /api/designations/:id/salary-structure
+// Sets the salary structure of employees with the designation and no structure of their own.
+func (h *HandlerFunc) UpdateDesignationSalaryStructure(c *gin.Context) {
+	role := c.GetString("role")
+	if !canManageSalaryStructures(role) {
+		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN and SUPERADMIN can assign salary structures")
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	designationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid designation ID")
+		return
+	}
+	if _, err := h.Query.GetDesignationByID(designationID); err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "designation not found")
+		return
+	}
+	input, ok := h.bindSalaryStructureAssignment(c)
+	if !ok {
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.AssignDesignationSalaryStructure(tx, designationID, input.SalaryStructureID); err != nil {
+			return utils.CustomErr(c, 500, "failed to assign salary structure: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentSalaryStructure, constant.ActionUpdate, empID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "failed to assign salary structure: "+err.Error())
+		return
+	}
+
+	message := "designation salary structure updated successfully"
+	if input.SalaryStructureID == nil {
+		message = "designation salary structure removed successfully"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":             message,
+		"designation_id":      designationID,
+		"salary_structure_id": input.SalaryStructureID,
+	})
+}
+
+// bindSalaryStructureAssignment reads an assignment request and checks the structure exists
+func (h *HandlerFunc) bindSalaryStructureAssignment(c *gin.Context) (models.SalaryStructureAssignInput, bool) {
+	var input models.SalaryStructureAssignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return input, false
+	}
+	if input.SalaryStructureID != nil {
+		if _, err := h.Query.GetSalaryStructure(*input.SalaryStructureID); err != nil {
+			utils.RespondWithError(c, http.StatusNotFound, "salary structure not found")
+			return input, false
+		}
+	}
+	return input, true
+}
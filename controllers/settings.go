@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
@@ -119,6 +121,105 @@ func (h *HandlerFunc) UpdateCompanySettings(c *gin.Context) {
 		CompanyName:          c.PostForm("CompanyName"),
 		PrimaryColor:         c.PostForm("PrimaryColor"),
 		SecondaryColor:       c.PostForm("SecondaryColor"),
+		WorkStartTime:        strings.TrimSpace(c.PostForm("WorkStartTime")),
+		WorkEndTime:          strings.TrimSpace(c.PostForm("WorkEndTime")),
+	}
+
+	// 2A. Working hours (optional - empty keeps the current value)
+	if hoursStr := c.PostForm("WorkingHoursPerDay"); hoursStr != "" {
+		hours, err := strconv.ParseFloat(hoursStr, 64)
+		if err != nil || hours <= 0 || hours > 24 {
+			utils.RespondWithError(c, 400, "WorkingHoursPerDay must be between 0 and 24")
+			return
+		}
+		input.WorkingHoursPerDay = hours
+	}
+
+	for _, t := range []string{input.WorkStartTime, input.WorkEndTime} {
+		if t == "" {
+			continue
+		}
+		if _, err := service.ParseClockTime(t); err != nil {
+			utils.RespondWithError(c, 400, err.Error())
+			return
+		}
+	}
+	if input.WorkStartTime != "" && input.WorkEndTime != "" {
+		start, _ := service.ParseClockTime(input.WorkStartTime)
+		end, _ := service.ParseClockTime(input.WorkEndTime)
+		if end <= start {
+			utils.RespondWithError(c, 400, "WorkEndTime must be after WorkStartTime")
+			return
+		}
+	}
+
+	// 2B. Team coverage rule (optional - empty keeps the current value)
+	if pctStr := c.PostForm("CoverageThresholdPercent"); pctStr != "" {
+		pct, err := strconv.ParseFloat(pctStr, 64)
+		if err != nil || pct < 0 || pct > 100 {
+			utils.RespondWithError(c, 400, "CoverageThresholdPercent must be between 0 and 100")
+			return
+		}
+		input.CoverageThresholdPercent = &pct
+	}
+	if blockStr := c.PostForm("CoverageBlock"); blockStr != "" {
+		block := blockStr == "true"
+		input.CoverageBlock = &block
+	}
+
+	// 2C. Comp-off (optional - empty keeps the current value, CompOffLeaveTypeID 0 disables comp-off)
+	if typeStr := c.PostForm("CompOffLeaveTypeID"); typeStr != "" {
+		typeID, err := strconv.Atoi(typeStr)
+		if err != nil || typeID < 0 {
+			utils.RespondWithError(c, 400, "CompOffLeaveTypeID must be a valid leave type ID")
+			return
+		}
+		if typeID > 0 {
+			leaveType, err := h.Query.GetLeaveTypeById(typeID)
+			if err != nil {
+				utils.RespondWithError(c, 400, "CompOffLeaveTypeID must be a valid leave type ID")
+				return
+			}
+			rules, err := h.Query.GetLeaveEntitlementRules(typeID)
+			if err != nil {
+				utils.RespondWithError(c, 500, "Failed to fetch entitlement rules: "+err.Error())
+				return
+			}
+			tiers := make([]float64, 0, len(rules))
+			for _, rule := range rules {
+				tiers = append(tiers, rule.Entitlement)
+			}
+			if err := service.ValidateCompOffLeaveType(leaveType, tiers); err != nil {
+				utils.RespondWithError(c, 400, err.Error())
+				return
+			}
+		}
+		input.CompOffLeaveTypeID = &typeID
+	}
+	if daysStr := c.PostForm("CompOffValidityDays"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 1 || days > 366 {
+			utils.RespondWithError(c, 400, "CompOffValidityDays must be between 1 and 366")
+			return
+		}
+		input.CompOffValidityDays = &days
+	}
+
+	// 2D. Payroll proration basis of joining and exit months (optional - empty keeps the current value)
+	if basis := strings.ToUpper(strings.TrimSpace(c.PostForm("PayrollProrationBasis"))); basis != "" {
+		if !models.IsValidProrationBasis(basis) {
+			utils.RespondWithError(c, 400, "PayrollProrationBasis must be CALENDAR_DAYS or WORKING_DAYS")
+			return
+		}
+		input.PayrollProrationBasis = &basis
+	}
+	// Per-day rate basis: FIXED divides by WorkingDaysPerMonth (optional - empty keeps the current value)
+	if basis := strings.ToUpper(strings.TrimSpace(c.PostForm("PayrollDayRateBasis"))); basis != "" {
+		if !models.IsValidDayRateBasis(basis) {
+			utils.RespondWithError(c, 400, "PayrollDayRateBasis must be FIXED, WORKING_DAYS or CALENDAR_DAYS")
+			return
+		}
+		input.PayrollDayRateBasis = &basis
 	}
 
 	// 3. Handle Logo File Upload
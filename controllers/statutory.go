@@ -0,0 +1,324 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/access_role"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// GetStatutoryRules - GET /api/payroll/statutory-rules?kind=TDS|EPF|ESI|PT
+// Every version of the statutory rules, newest first per rule set.
+func (h *HandlerFunc) GetStatutoryRules(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can view statutory rules"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	rules, err := h.Query.GetStatutoryRules(strings.ToUpper(strings.TrimSpace(c.Query("kind"))))
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch statutory rules: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"statutory_rules": rules})
+}
+
+// CreateStatutoryRule - POST /api/payroll/statutory-rules
+// Adds a version of a rule set (TDS per regime, EPF, ESI, PT per state). Versions are not
+// edited: a change in the rules is a new version effective from the month it applies to.
+func (h *HandlerFunc) CreateStatutoryRule(c *gin.Context) {
+	role := c.GetString("role")
+	if !canManageSalaryStructures(role) {
+		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN and SUPERADMIN can manage statutory rules")
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var input models.StatutoryRuleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if err := h.Validator.Struct(input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	effectiveFrom, err := time.Parse("2006-01-02", input.EffectiveFrom)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "effective_from must be YYYY-MM-DD")
+		return
+	}
+	if err := service.NormalizeStatutoryRule(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var ruleID uuid.UUID
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		exists, err := h.Query.StatutoryRuleVersionExistsTx(tx, input.Kind, input.Code, effectiveFrom)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to check statutory rules: "+err.Error())
+		}
+		if exists {
+			return utils.CustomErr(c, http.StatusConflict, fmt.Sprintf("A %s %s rule effective from %s already exists", input.Kind, input.Code, input.EffectiveFrom))
+		}
+		ruleID, err = h.Query.InsertStatutoryRule(tx, input, effectiveFrom, empID)
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to create statutory rule: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentStatutory, constant.ActionCreate, empID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to create statutory rule: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":        "Statutory rule created successfully",
+		"id":             ruleID,
+		"kind":           input.Kind,
+		"code":           input.Code,
+		"effective_from": input.EffectiveFrom,
+	})
+}
+
+// DeleteStatutoryRule - DELETE /api/payroll/statutory-rules/:id
+// Only versions that are not in effect yet can be deleted.
+func (h *HandlerFunc) DeleteStatutoryRule(c *gin.Context) {
+	role := c.GetString("role")
+	if !canManageSalaryStructures(role) {
+		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN and SUPERADMIN can manage statutory rules")
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid statutory rule ID")
+		return
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		rule, err := h.Query.GetStatutoryRuleTx(tx, ruleID)
+		if err == sql.ErrNoRows {
+			return utils.CustomErr(c, http.StatusNotFound, "Statutory rule not found")
+		}
+		if err != nil {
+			return utils.CustomErr(c, 500, "Failed to fetch statutory rule: "+err.Error())
+		}
+		if !rule.EffectiveFrom.After(time.Now()) {
+			return utils.CustomErr(c, http.StatusBadRequest, "Statutory rules already in effect cannot be deleted; add a new version instead")
+		}
+		if err := h.Query.DeleteStatutoryRule(tx, ruleID); err != nil {
+			return utils.CustomErr(c, 500, "Failed to delete statutory rule: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentStatutory, constant.ActionDelete, empID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "Failed to delete statutory rule: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Statutory rule deleted successfully"})
+}
+
+// GetEmployeeStatutoryProfile - GET /api/employee/:id/statutory
+// Tax regime, professional tax state and PF membership. Employees can view their own.
+func (h *HandlerFunc) GetEmployeeStatutoryProfile(c *gin.Context) {
+	role := c.GetString("role")
+	userID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+	if access_role.Admin_SuperAdmin_Hr(role, "") != nil && userID != employeeID {
+		utils.RespondWithError(c, http.StatusForbidden, "you can only view your own statutory details")
+		return
+	}
+	profile, err := h.Query.GetStatutoryProfile(employeeID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, 500, "failed to fetch statutory details: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"employee_id": employeeID, "statutory": profile})
+}
+
+// UpdateEmployeeStatutoryProfile - PATCH /api/employee/:id/statutory
+// SUPERADMIN/ADMIN/HR set the tax regime, professional tax state and PF membership.
+func (h *HandlerFunc) UpdateEmployeeStatutoryProfile(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can update statutory details"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid employee ID")
+		return
+	}
+	profile, err := h.Query.GetStatutoryProfile(employeeID)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "employee not found")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, 500, "failed to fetch statutory details: "+err.Error())
+		return
+	}
+
+	var input models.StatutoryProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid input: "+err.Error())
+		return
+	}
+	if input.TaxRegime != nil {
+		regime, err := service.NormalizeTaxRegime(*input.TaxRegime)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		profile.TaxRegime = regime
+	}
+	if input.PTState != nil {
+		state := strings.ToUpper(strings.TrimSpace(*input.PTState))
+		profile.PTState = nil
+		if state != "" {
+			rules, err := h.Query.GetStatutoryRules(models.StatutoryPT)
+			if err != nil {
+				utils.RespondWithError(c, 500, "failed to fetch statutory rules: "+err.Error())
+				return
+			}
+			found := false
+			for _, rule := range rules {
+				found = found || rule.Code == state
+			}
+			if !found {
+				utils.RespondWithError(c, http.StatusBadRequest, "no professional tax rules for state "+state)
+				return
+			}
+			profile.PTState = &state
+		}
+	}
+	if input.PFEnabled != nil {
+		profile.PFEnabled = *input.PFEnabled
+	}
+
+	err = common.ExecuteTransaction(c, h.Query.DB, func(tx *sqlx.Tx) error {
+		if err := h.Query.UpdateStatutoryProfile(tx, employeeID, profile); err != nil {
+			return utils.CustomErr(c, 500, "failed to update statutory details: "+err.Error())
+		}
+		data := models.NewCommon(constant.ComponentStatutory, constant.ActionUpdate, empID)
+		if err := h.Query.AddLog(data, tx); err != nil {
+			return utils.CustomErr(c, 500, "Failed to create log: "+err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			utils.RespondWithError(c, appErr.Code, appErr.Message)
+			return
+		}
+		utils.RespondWithError(c, 500, "failed to update statutory details: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "statutory details updated successfully",
+		"employee_id": employeeID,
+		"statutory":   profile,
+	})
+}
+
+// GetPayrollYTD - GET /api/payroll/ytd/:id?fy=2025
+// Year-to-date totals of an employee's finalized payslips for the financial year starting in
+// April of fy (the current financial year by default), per component and statutory code.
+// Employees, managers and HR can only view their own.
+func (h *HandlerFunc) GetPayrollYTD(c *gin.Context) {
+	role := c.GetString("role")
+	userID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid employee ID")
+		return
+	}
+	if role != constant.ROLE_SUPER_ADMIN && role != constant.ROLE_ADMIN && userID != employeeID {
+		utils.RespondWithError(c, http.StatusForbidden, "You can only view your own payroll summary")
+		return
+	}
+
+	now := time.Now()
+	fy := service.FinancialYearStart(int(now.Month()), now.Year())
+	if fyStr := c.Query("fy"); fyStr != "" {
+		fy, err = strconv.Atoi(fyStr)
+		if err != nil || fy < 2000 || fy > 2100 {
+			utils.RespondWithError(c, http.StatusBadRequest, "fy must be the year the financial year starts in")
+			return
+		}
+	}
+
+	employee, err := h.Query.GetEmployeeByID(employeeID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Employee not found")
+		return
+	}
+	summary, err := h.Query.GetPayrollYTDSummary(employeeID, fy)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch payroll summary: "+err.Error())
+		return
+	}
+	summary.EmployeeID = employeeID
+	summary.EmployeeName = employee.FullName
+	summary.FinancialYear = fmt.Sprintf("%d-%02d", fy, (fy+1)%100)
+	c.JSON(http.StatusOK, summary)
+}
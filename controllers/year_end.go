@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/access_role"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/common"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// yearEndTotals sums the carried forward, encashed and lapsed leave of year-end lines
+func yearEndTotals(lines []models.YearEndLine) gin.H {
+	var carried, encashed, lapsed, amount float64
+	for _, l := range lines {
+		carried += l.CarriedForward
+		encashed += l.Encashed
+		lapsed += l.Lapsed
+		amount += l.EncashAmount
+	}
+	return gin.H{
+		"carried_forward": carried,
+		"encashed":        encashed,
+		"lapsed":          lapsed,
+		"encash_amount":   amount,
+	}
+}
+
+// parseYearParam reads the :year path parameter
+func parseYearParam(c *gin.Context) (int, bool) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil || year < 2000 || year > time.Now().Year() {
+		utils.RespondWithError(c, http.StatusBadRequest, "invalid year")
+		return 0, false
+	}
+	return year, true
+}
+
+// PreviewYearEnd - GET /api/year-end/:year/preview
+// SUPERADMIN/ADMIN/HR preview the carry-forward, encashment and lapse of every balance.
+func (h *HandlerFunc) PreviewYearEnd(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can preview the year-end close"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	year, ok := parseYearParam(c)
+	if !ok {
+		return
+	}
+
+	tx, err := h.Query.DB.Beginx()
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	closed, err := h.Query.YearEndClosedTx(tx, year)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to check year-end close: "+err.Error())
+		return
+	}
+	lines, err := service.PreviewYearEnd(h.Query, tx, year)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to preview year-end close: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"year":      year,
+		"finalized": closed,
+		"totals":    yearEndTotals(lines),
+		"lines":     lines,
+	})
+}
+
+// FinalizeYearEnd - POST /api/year-end/:year/finalize
+// SUPERADMIN/ADMIN close a finished year: carried forward leave becomes part of next year's
+// opening balance and encashment is paid with the next payroll run. A year can be closed once.
+func (h *HandlerFunc) FinalizeYearEnd(c *gin.Context) {
+	role := c.GetString("role")
+	if role != constant.ROLE_SUPER_ADMIN && role != constant.ROLE_ADMIN {
+		utils.RespondWithError(c, http.StatusForbidden, "only ADMIN and SUPERADMIN can finalize the year-end close")
+		return
+	}
+	empID, err := common.GetEmployeeId(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	year, ok := parseYearParam(c)
+	if !ok {
+		return
+	}
+	if year >= time.Now().Year() {
+		utils.RespondWithError(c, http.StatusBadRequest, "only a finished year can be closed")
+		return
+	}
+
+	tx, err := h.Query.DB.Beginx()
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	closed, err := h.Query.YearEndClosedTx(tx, year)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to check year-end close: "+err.Error())
+		return
+	}
+	if closed {
+		utils.RespondWithError(c, http.StatusConflict, "year-end close for this year is already finalized")
+		return
+	}
+
+	lines, err := service.FinalizeYearEnd(h.Query, tx, year, empID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to finalize year-end close: "+err.Error())
+		return
+	}
+
+	data := models.NewCommon(constant.ComponentYearEnd, constant.ActionFinalize, empID)
+	if err := h.Query.AddLog(data, tx); err != nil {
+		utils.RespondWithError(c, 500, "Failed to create log: "+err.Error())
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		utils.RespondWithError(c, 500, "Transaction commit failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Year-end close finalized",
+		"year":    year,
+		"totals":  yearEndTotals(lines),
+		"lines":   lines,
+	})
+}
+
+// GetYearEndClose - GET /api/year-end/:year
+// Finalized year-end close with its lines and whether each encashment has been paid.
+func (h *HandlerFunc) GetYearEndClose(c *gin.Context) {
+	role := c.GetString("role")
+	if err := access_role.Admin_SuperAdmin_Hr(role, "only ADMIN, SUPERADMIN, and HR can view the year-end close"); err != nil {
+		utils.RespondWithError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	year, ok := parseYearParam(c)
+	if !ok {
+		return
+	}
+
+	close, err := h.Query.GetYearEndClose(year)
+	if err == sql.ErrNoRows {
+		utils.RespondWithError(c, http.StatusNotFound, "year-end close not finalized for this year")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch year-end close: "+err.Error())
+		return
+	}
+	lines, err := h.Query.GetYearEndCloseLines(close.ID)
+	if err != nil {
+		utils.RespondWithError(c, 500, "Failed to fetch year-end lines: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"close":  close,
+		"totals": yearEndTotals(lines),
+		"lines":  lines,
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/pkg/database"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/routes"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/service"
 )
 
 func main() {
@@ -28,6 +29,16 @@ func main() {
 	models.InitValidator()
 	routes.SetupRoutes(r, handlerFunc)
 
+	// Background jobs
+	service.StartDailyJob("comp-off lapse", 1, func() error {
+		_, err := service.RunCompOffLapse(repo)
+		return err
+	})
+	service.StartDailyJob("leave accrual", 2, func() error {
+		_, err := service.RunScheduledLeaveAccruals(repo)
+		return err
+	})
+
 	fmt.Printf("Starting server on port %s\n", env.APP_PORT)
 
 	// Start the Gin server
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bank payment file formats
+const (
+	BankFileCSV     = "CSV"     // generic CSV with a control record
+	BankFileNACHA   = "NACHA"   // US ACH PPD credits
+	BankFilePain001 = "PAIN001" // ISO 20022 pain.001.001.03 credit transfer
+	BankFileNEFT    = "NEFT"    // Indian bank bulk NEFT upload
+)
+
+// Bank account types, used by NACHA transaction codes
+const (
+	BankAccountSavings  = "SAVINGS"
+	BankAccountChecking = "CHECKING"
+)
+
+// BankAccount is an account salaries are paid to or from. Which identifiers are needed depends
+// on the file format: IFSC for NEFT, ABA routing number for NACHA, IBAN or account number for
+// pain.001.
+type BankAccount struct {
+	AccountHolderName string  `json:"account_holder_name" db:"account_holder_name"`
+	AccountNumber     string  `json:"account_number" db:"account_number"`
+	AccountType       string  `json:"account_type" db:"account_type"`
+	BankName          *string `json:"bank_name" db:"bank_name"`
+	IFSCCode          *string `json:"ifsc_code" db:"ifsc_code"`
+	RoutingNumber     *string `json:"routing_number" db:"routing_number"`
+	IBAN              *string `json:"iban" db:"iban"`
+	BIC               *string `json:"bic" db:"bic"`
+}
+
+// EmployeeBankAccount is the account an employee's salary is paid to
+type EmployeeBankAccount struct {
+	EmployeeID uuid.UUID `json:"employee_id" db:"employee_id"`
+	BankAccount
+	UpdatedBy *uuid.UUID `json:"updated_by" db:"updated_by"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// CompanyBankAccount is the account salaries are paid from
+type CompanyBankAccount struct {
+	BankAccount
+	// ACHCompanyID identifies the company in NACHA batches
+	ACHCompanyID *string    `json:"ach_company_id" db:"ach_company_id"`
+	Currency     string     `json:"currency" db:"currency"`
+	UpdatedBy    *uuid.UUID `json:"updated_by" db:"updated_by"`
+	UpdatedAt    *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// BankAccountInput creates or replaces a bank account
+type BankAccountInput struct {
+	AccountHolderName string  `json:"account_holder_name" validate:"required"`
+	AccountNumber     string  `json:"account_number" validate:"required"`
+	AccountType       string  `json:"account_type"` // defaults to SAVINGS
+	BankName          *string `json:"bank_name,omitempty"`
+	IFSCCode          *string `json:"ifsc_code,omitempty"`
+	RoutingNumber     *string `json:"routing_number,omitempty"`
+	IBAN              *string `json:"iban,omitempty"`
+	BIC               *string `json:"bic,omitempty"`
+}
+
+// CompanyBankAccountInput creates or replaces the company's salary account
+type CompanyBankAccountInput struct {
+	BankAccountInput
+	ACHCompanyID *string `json:"ach_company_id,omitempty"`
+	Currency     string  `json:"currency"` // defaults to INR
+}
+
+// BankFileInput generates a bank payment file for a finalized payroll run
+type BankFileInput struct {
+	Format string `json:"format" validate:"required"`
+	// ExecutionDate is when the bank should pay, YYYY-MM-DD; defaults to today
+	ExecutionDate string `json:"execution_date"`
+}
+
+// BankPayment is one salary transfer of a bank file: the net pay of an employee's issued
+// payslips in the run
+type BankPayment struct {
+	EmployeeID uuid.UUID    `json:"employee_id"`
+	Employee   string       `json:"employee"`
+	Reference  string       `json:"payment_reference"`
+	Amount     float64      `json:"amount"`
+	Account    *BankAccount `json:"-"`
+	PayslipIDs []uuid.UUID  `json:"payslip_ids"`
+}
+
+// RunPayslipPayment is an issued payslip of a payroll run with its employee's bank account
+type RunPayslipPayment struct {
+	PayslipID  uuid.UUID `db:"payslip_id"`
+	EmployeeID uuid.UUID `db:"employee_id"`
+	Employee   string    `db:"full_name"`
+	NetSalary  float64   `db:"net_salary"`
+	// HasAccount is false when the employee has no bank account on file
+	HasAccount bool `db:"has_account"`
+	BankAccount
+}
+
+// BankFile is a generated bank payment file
+type BankFile struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	PayrollRunID uuid.UUID  `json:"payroll_run_id" db:"payroll_run_id"`
+	Format       string     `json:"format" db:"format"`
+	FileName     string     `json:"file_name" db:"file_name"`
+	Content      string     `json:"-" db:"content"`
+	EntryCount   int        `json:"entry_count" db:"entry_count"`
+	ControlTotal float64    `json:"control_total" db:"control_total"`
+	Checksum     string     `json:"checksum" db:"checksum"` // SHA-256 of the content
+	GeneratedBy  *uuid.UUID `json:"generated_by" db:"generated_by"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// BankFileViolation is an employee or the company missing a bank detail a format needs
+type BankFileViolation struct {
+	EmployeeID *uuid.UUID `json:"employee_id,omitempty"` // nil for the company account
+	Employee   string     `json:"employee,omitempty"`
+	Message    string     `json:"message"`
+}
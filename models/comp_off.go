@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comp-off request statuses
+const (
+	CompOffPending   = "Pending"
+	CompOffApproved  = "APPROVED"
+	CompOffRejected  = "REJECTED"
+	CompOffCancelled = "CANCELLED"
+)
+
+// CompOffRequest is a request to earn leave for working on a weekend or holiday
+type CompOffRequest struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	EmployeeID    uuid.UUID  `json:"employee_id" db:"employee_id"`
+	EmployeeName  string     `json:"employee_name" db:"employee_name"`
+	WorkDate      time.Time  `json:"work_date" db:"work_date"`
+	Days          float64    `json:"days" db:"days"`
+	Reason        string     `json:"reason" db:"reason"`
+	Status        string     `json:"status" db:"status"`
+	ActionBy      *uuid.UUID `json:"action_by,omitempty" db:"action_by"`
+	ActionByName  *string    `json:"action_by_name,omitempty" db:"action_by_name"`
+	ActionComment *string    `json:"action_comment,omitempty" db:"action_comment"`
+	ActionAt      *time.Time `json:"action_at,omitempty" db:"action_at"`
+	LeaveTypeID   *int       `json:"leave_type_id,omitempty" db:"leave_type_id"`
+	ExpiresOn     *time.Time `json:"expires_on,omitempty" db:"expires_on"`
+	LapsedDays    float64    `json:"lapsed_days" db:"lapsed_days"`
+	LapsedAt      *time.Time `json:"lapsed_at,omitempty" db:"lapsed_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CompOffInput is an employee's comp-off request
+type CompOffInput struct {
+	WorkDate    time.Time `json:"work_date" validate:"required"`
+	DayTimingID *int      `json:"day_timing_id,omitempty"` // 1 First Half, 2 Second Half, 3 Full Day (default)
+	Reason      string    `json:"reason" validate:"required,min=10,max=500"`
+}
+
+// CompOffActionInput approves or rejects a comp-off request
+type CompOffActionInput struct {
+	Action  string `json:"action" validate:"required"` // APPROVE/REJECT
+	Comment string `json:"comment,omitempty" validate:"max=500"`
+}
+
+// CompOffSettings is the company comp-off configuration
+type CompOffSettings struct {
+	LeaveTypeID  *int `db:"comp_off_leave_type_id"`
+	ValidityDays int  `db:"comp_off_validity_days"`
+}
+
+// CompOffLapse is the unused comp-off credit of one employee and year that lapsed
+type CompOffLapse struct {
+	EmployeeID  uuid.UUID `json:"employee_id"`
+	LeaveTypeID int       `json:"leave_type_id"`
+	Year        int       `json:"year"` // year of the balance the credits were booked to
+	Lapsed      float64   `json:"lapsed"`
+	Credits     int       `json:"credits"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Leave type accrual frequencies
+const (
+	AccrualNone      = "NONE"
+	AccrualMonthly   = "MONTHLY"
+	AccrualQuarterly = "QUARTERLY"
+	AccrualPayPeriod = "PAY_PERIOD" // payroll month, credited once that month's payroll is finalized
+)
+
+// AccrualPeriodsPerYear is the number of accrual periods in a year per frequency
+var AccrualPeriodsPerYear = map[string]int{
+	AccrualMonthly:   12,
+	AccrualQuarterly: 4,
+	AccrualPayPeriod: 12,
+}
+
+// LeaveAccrual is one accrual ledger entry
+type LeaveAccrual struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	EmployeeID    uuid.UUID  `json:"employee_id" db:"employee_id"`
+	EmployeeName  string     `json:"employee_name" db:"employee_name"`
+	LeaveTypeID   int        `json:"leave_type_id" db:"leave_type_id"`
+	LeaveTypeName string     `json:"leave_type" db:"leave_type"`
+	Year          int        `json:"year" db:"year"`
+	Frequency     string     `json:"frequency" db:"frequency"`
+	PeriodStart   time.Time  `json:"period_start" db:"period_start"`
+	PeriodEnd     time.Time  `json:"period_end" db:"period_end"`
+	Entitlement   float64    `json:"entitlement" db:"entitlement"`
+	Proration     float64    `json:"proration" db:"proration"`
+	Amount        float64    `json:"amount" db:"amount"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// AccrualEmployee is the employment window used to prorate accruals
+type AccrualEmployee struct {
+	ID          uuid.UUID  `db:"id"`
+	FullName    string     `db:"full_name"`
+	JoiningDate *time.Time `db:"joining_date"`
+	EndingDate  *time.Time `db:"ending_date"`
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bulk adjustment filter types
+const (
+	BulkFilterAll         = "ALL"
+	BulkFilterDepartment  = "DEPARTMENT"
+	BulkFilterDesignation = "DESIGNATION"
+	BulkFilterRole        = "ROLE"
+	BulkFilterEmployees   = "EMPLOYEES"
+)
+
+// Bulk adjustment batch statuses
+const (
+	AdjustmentBatchApplied  = "APPLIED"
+	AdjustmentBatchReversed = "REVERSED"
+)
+
+// BulkAdjustmentFilter selects the employees of a bulk adjustment. Value is the department,
+// designation (ID or name) or role; EMPLOYEES uses the uploaded employee IDs and emails.
+type BulkAdjustmentFilter struct {
+	Type        string      `json:"type" validate:"required,oneof=ALL DEPARTMENT DESIGNATION ROLE EMPLOYEES"`
+	Value       string      `json:"value"`
+	EmployeeIDs []uuid.UUID `json:"employee_ids"`
+	Emails      []string    `json:"emails"`
+}
+
+// BulkAdjustmentInput is the request body of a bulk balance adjustment
+type BulkAdjustmentInput struct {
+	LeaveTypeID int                  `json:"leave_type_id" validate:"required"`
+	Year        *int                 `json:"year"`                         // defaults to the current year
+	Quantity    float64              `json:"quantity" validate:"required"` // +ve or -ve, per employee
+	Reason      string               `json:"reason" validate:"required"`
+	Filter      BulkAdjustmentFilter `json:"filter" validate:"required"`
+}
+
+// BulkAdjustmentTarget is an employee matched by a bulk adjustment filter
+type BulkAdjustmentTarget struct {
+	EmployeeID   uuid.UUID `db:"id"`
+	EmployeeName string    `db:"full_name"`
+	Email        string    `db:"email"`
+}
+
+// BulkAdjustmentLine is the effect of a bulk adjustment (or its reversal) on one employee
+type BulkAdjustmentLine struct {
+	AdjustmentID    *uuid.UUID `json:"adjustment_id,omitempty" db:"id"`
+	EmployeeID      uuid.UUID  `json:"employee_id" db:"employee_id"`
+	EmployeeName    string     `json:"employee_name" db:"employee_name"`
+	Quantity        float64    `json:"quantity" db:"quantity"`
+	PreviousClosing float64    `json:"previous_closing" db:"-"`
+	NewClosing      float64    `json:"new_closing" db:"-"`
+}
+
+// LeaveAdjustmentBatch is a bulk adjustment applied to many employees at once
+type LeaveAdjustmentBatch struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	LeaveTypeID    int        `json:"leave_type_id" db:"leave_type_id"`
+	LeaveTypeName  string     `json:"leave_type" db:"leave_type"`
+	Year           int        `json:"year" db:"year"`
+	Quantity       float64    `json:"quantity" db:"quantity"`
+	Reason         string     `json:"reason" db:"reason"`
+	FilterType     string     `json:"filter_type" db:"filter_type"`
+	FilterValue    *string    `json:"filter_value,omitempty" db:"filter_value"`
+	EmployeeCount  int        `json:"employee_count" db:"employee_count"`
+	Status         string     `json:"status" db:"status"`
+	CreatedBy      *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedByName  *string    `json:"created_by_name,omitempty" db:"created_by_name"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	ReversedBy     *uuid.UUID `json:"reversed_by,omitempty" db:"reversed_by"`
+	ReversedAt     *time.Time `json:"reversed_at,omitempty" db:"reversed_at"`
+	ReversalReason *string    `json:"reversal_reason,omitempty" db:"reversal_reason"`
+}
+
+// LeaveAdjustmentRecord is a stored adjustment of a batch
+type LeaveAdjustmentRecord struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	EmployeeID   uuid.UUID  `json:"employee_id" db:"employee_id"`
+	EmployeeName string     `json:"employee_name" db:"employee_name"`
+	Quantity     float64    `json:"quantity" db:"quantity"`
+	Reason       *string    `json:"reason" db:"reason"`
+	ReversalOf   *uuid.UUID `json:"reversal_of,omitempty" db:"reversal_of"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaveAttachment is a supporting document uploaded for a leave request
+type LeaveAttachment struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	LeaveID     uuid.UUID `json:"leave_id" db:"leave_id"`
+	FileName    string    `json:"file_name" db:"file_name"`
+	FilePath    string    `json:"-" db:"file_path"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	SizeBytes   int64     `json:"size_bytes" db:"size_bytes"`
+	UploadedBy  uuid.UUID `json:"uploaded_by" db:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	DownloadURL string    `json:"download_url" db:"-"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarEmployee is an employee included in a leave calendar
+type CalendarEmployee struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	FullName string    `json:"full_name" db:"full_name"`
+}
+
+// CalendarLeave is a leave overlapping a calendar range
+type CalendarLeave struct {
+	ID           uuid.UUID `db:"id"`
+	EmployeeID   uuid.UUID `db:"employee_id"`
+	EmployeeName string    `db:"employee_name"`
+	LeaveType    string    `db:"leave_type"`
+	Status       string    `db:"status"`
+	StartDate    time.Time `db:"start_date"`
+	EndDate      time.Time `db:"end_date"`
+	Days         float64   `db:"days"`
+	Hours        *float64  `db:"hours"`
+	HalfID       int       `db:"half_id"`
+	StartHalfID  *int      `db:"start_half_id"`
+	EndHalfID    *int      `db:"end_half_id"`
+	StartTime    *string   `db:"start_time"`
+	EndTime      *string   `db:"end_time"`
+}
+
+// CalendarAbsence is one employee's absence on a calendar day
+type CalendarAbsence struct {
+	LeaveID      uuid.UUID `json:"leave_id"`
+	EmployeeID   uuid.UUID `json:"employee_id"`
+	EmployeeName string    `json:"employee_name"`
+	LeaveType    string    `json:"leave_type"`
+	Status       string    `json:"status"`
+	Portion      float64   `json:"portion"` // fraction of the day (0.5 = half day)
+	StartTime    *string   `json:"start_time,omitempty"`
+	EndTime      *string   `json:"end_time,omitempty"`
+}
+
+// CalendarDay is the absence summary for a single date
+type CalendarDay struct {
+	Date          string            `json:"date"`
+	Weekday       string            `json:"weekday"`
+	IsWeekend     bool              `json:"is_weekend"`
+	Holiday       *string           `json:"holiday,omitempty"`
+	Absences      []CalendarAbsence `json:"absences"`
+	AbsentCount   int               `json:"absent_count"`
+	AbsentPercent float64           `json:"absent_percent"`
+}
+
+// FeedLeave is a leave published in an ICS calendar feed
+type FeedLeave struct {
+	ID           uuid.UUID `db:"id"`
+	EmployeeName string    `db:"employee_name"`
+	LeaveType    string    `db:"leave_type"`
+	Status       string    `db:"status"`
+	StartDate    time.Time `db:"start_date"`
+	EndDate      time.Time `db:"end_date"`
+	HalfID       int       `db:"half_id"`
+	StartHalfID  *int      `db:"start_half_id"`
+	EndHalfID    *int      `db:"end_half_id"`
+	StartTime    *string   `db:"start_time"`
+	EndTime      *string   `db:"end_time"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Employment types
+const (
+	EmploymentFullTime = "FULL_TIME"
+	EmploymentPartTime = "PART_TIME"
+	EmploymentContract = "CONTRACT"
+	EmploymentIntern   = "INTERN"
+)
+
+// LeaveEntitlementRule is an entitlement tier of a leave type
+type LeaveEntitlementRule struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	LeaveTypeID     int        `json:"leave_type_id" db:"leave_type_id"`
+	MinTenureMonths int        `json:"min_tenure_months" db:"min_tenure_months"`
+	MaxTenureMonths *int       `json:"max_tenure_months" db:"max_tenure_months"` // exclusive, nil = no upper bound
+	DesignationID   *uuid.UUID `json:"designation_id" db:"designation_id"`       // nil = any designation
+	DesignationName *string    `json:"designation_name,omitempty" db:"designation_name"`
+	EmploymentType  *string    `json:"employment_type" db:"employment_type"` // nil = any employment type
+	Entitlement     float64    `json:"entitlement" db:"entitlement"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// LeaveEntitlementRuleInput is one entitlement tier in a leave policy request
+type LeaveEntitlementRuleInput struct {
+	MinTenureMonths int        `json:"min_tenure_months"`
+	MaxTenureMonths *int       `json:"max_tenure_months,omitempty"`
+	DesignationID   *uuid.UUID `json:"designation_id,omitempty"`
+	EmploymentType  *string    `json:"employment_type,omitempty"`
+	Entitlement     float64    `json:"entitlement"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Balance projection line types
+const (
+	ProjectionCurrent      = "CURRENT"
+	ProjectionAccrual      = "ACCRUAL"
+	ProjectionPendingLeave = "PENDING_LEAVE"
+	ProjectionCarryForward = "CARRY_FORWARD"
+	ProjectionLapse        = "LAPSE"
+	ProjectionEntitlement  = "ENTITLEMENT"
+)
+
+// ProjectedLeave is a leave taken into account by a balance projection
+type ProjectedLeave struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	Status    string    `json:"status" db:"status"`
+	StartDate time.Time `json:"start_date" db:"start_date"`
+	EndDate   time.Time `json:"end_date" db:"end_date"`
+	Quantity  float64   `json:"quantity" db:"quantity"` // in the unit of the leave type
+}
+
+// BalanceProjectionLine is one expected movement up to the target date, with the balance after it
+type BalanceProjectionLine struct {
+	Date        time.Time `json:"date"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Quantity    float64   `json:"quantity"`
+	Balance     float64   `json:"balance"`
+}
+
+// BalanceProjection is the expected balance of a leave type on a future date
+type BalanceProjection struct {
+	EmployeeID       uuid.UUID               `json:"employee_id"`
+	LeaveTypeID      int                     `json:"leave_type_id"`
+	LeaveType        string                  `json:"leave_type"`
+	Unit             string                  `json:"unit"`
+	TargetDate       time.Time               `json:"target_date"`
+	CurrentBalance   float64                 `json:"current_balance"`
+	FutureAccruals   float64                 `json:"future_accruals"`
+	PendingLeaves    float64                 `json:"pending_leaves"`
+	CarryForward     float64                 `json:"carry_forward"`
+	Lapsed           float64                 `json:"lapsed"` // lapsed or encashed at year-end
+	NewEntitlement   float64                 `json:"new_entitlement"`
+	ProjectedBalance float64                 `json:"projected_balance"`
+	Lines            []BalanceProjectionLine `json:"lines"`
+	ApprovedLeaves   []ProjectedLeave        `json:"approved_leaves"` // already deducted from the current balance
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Leave ledger entry types
+const (
+	LedgerGrant        = "GRANT"
+	LedgerAccrual      = "ACCRUAL"
+	LedgerConsumption  = "CONSUMPTION"
+	LedgerRestoration  = "RESTORATION"
+	LedgerAdjustment   = "ADJUSTMENT"
+	LedgerCarryForward = "CARRY_FORWARD"
+	LedgerLapse        = "LAPSE"
+	LedgerEncashment   = "ENCASHMENT"
+)
+
+// LeaveLedgerEntry is one immutable balance movement. Quantity is the signed effect on closing.
+type LeaveLedgerEntry struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	EmployeeID    uuid.UUID  `json:"employee_id" db:"employee_id"`
+	LeaveTypeID   int        `json:"leave_type_id" db:"leave_type_id"`
+	LeaveTypeName string     `json:"leave_type" db:"leave_type"`
+	Year          int        `json:"year" db:"year"`
+	EntryType     string     `json:"entry_type" db:"entry_type"`
+	Quantity      float64    `json:"quantity" db:"quantity"`
+	LeaveID       *uuid.UUID `json:"leave_id,omitempty" db:"leave_id"`
+	AdjustmentID  *uuid.UUID `json:"adjustment_id,omitempty" db:"adjustment_id"`
+	Reference     *string    `json:"reference,omitempty" db:"reference"`
+	Note          *string    `json:"note,omitempty" db:"note"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedByName *string    `json:"created_by_name,omitempty" db:"created_by_name"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// LedgerRef describes the source of a balance movement
+type LedgerRef struct {
+	EntryType    string // defaults to ADJUSTMENT
+	LeaveID      *uuid.UUID
+	AdjustmentID *uuid.UUID
+	Reference    string
+	Note         string
+	CreatedBy    *uuid.UUID
+}
+
+// LedgerMismatch is a balance whose stored totals disagree with its ledger
+type LedgerMismatch struct {
+	EmployeeID     uuid.UUID `json:"employee_id" db:"employee_id"`
+	EmployeeName   string    `json:"employee_name" db:"employee_name"`
+	LeaveTypeID    int       `json:"leave_type_id" db:"leave_type_id"`
+	LeaveTypeName  string    `json:"leave_type" db:"leave_type"`
+	Year           int       `json:"year" db:"year"`
+	Closing        float64   `json:"closing" db:"closing"`
+	LedgerClosing  float64   `json:"ledger_closing" db:"ledger_closing"`
+	Used           float64   `json:"used" db:"used"`
+	LedgerUsed     float64   `json:"ledger_used" db:"ledger_used"`
+	Difference     float64   `json:"difference" db:"difference"`
+	LedgerEntries  int       `json:"ledger_entries" db:"ledger_entries"`
+	BalanceRows    int       `json:"balance_rows" db:"balance_rows"`       // more than one row for the same year is drift too
+	TotalsMismatch bool      `json:"totals_mismatch" db:"totals_mismatch"` // closing <> opening + accrued - used + adjusted
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Leave modification actions
+const (
+	LeaveModificationShorten = "SHORTEN"
+	LeaveModificationSplit   = "SPLIT"
+)
+
+// LeaveSegmentInput is one remaining period of a shortened or split leave
+type LeaveSegmentInput struct {
+	StartDate        time.Time `json:"start_date" validate:"required"`
+	EndDate          time.Time `json:"end_date" validate:"required"`
+	LeaveTimingID    *int      `json:"leave_timing_id,omitempty"` // Single-day segments, defaults to 3 - Full Day
+	StartDayTimingID *int      `json:"start_day_timing_id,omitempty"`
+	EndDayTimingID   *int      `json:"end_day_timing_id,omitempty"`
+}
+
+// LeaveModifyInput shortens (one segment) or splits (several segments) an approved leave
+type LeaveModifyInput struct {
+	Segments []LeaveSegmentInput `json:"segments" validate:"required,min=1,max=10,dive"`
+	Reason   string              `json:"reason" validate:"required,min=5,max=500"`
+}
+
+// LeaveModification is a history record of a shortened or split leave
+type LeaveModification struct {
+	ID             uuid.UUID      `json:"id" db:"id"`
+	LeaveID        uuid.UUID      `json:"leave_id" db:"leave_id"`
+	Action         string         `json:"action" db:"action"`
+	OldStartDate   time.Time      `json:"old_start_date" db:"old_start_date"`
+	OldEndDate     time.Time      `json:"old_end_date" db:"old_end_date"`
+	OldDays        float64        `json:"old_days" db:"old_days"`
+	OldHours       *float64       `json:"old_hours,omitempty" db:"old_hours"`
+	NewPeriods     string         `json:"new_periods" db:"new_periods"`
+	NewDays        float64        `json:"new_days" db:"new_days"`
+	Restored       float64        `json:"restored" db:"restored"`
+	ResultLeaveIDs pq.StringArray `json:"result_leave_ids" db:"result_leave_ids"`
+	Reason         *string        `json:"reason,omitempty" db:"reason"`
+	ModifiedBy     *uuid.UUID     `json:"modified_by,omitempty" db:"modified_by"`
+	ModifiedByName *string        `json:"modified_by_name,omitempty" db:"modified_by_name"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaveBlackout is a company blackout period during which leave cannot be applied
+type LeaveBlackout struct {
+	ID          int        `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	StartDate   time.Time  `json:"start_date" db:"start_date"`
+	EndDate     time.Time  `json:"end_date" db:"end_date"`
+	LeaveTypeID *int       `json:"leave_type_id,omitempty" db:"leave_type_id"` // nil = applies to all leave types
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+type LeaveBlackoutInput struct {
+	Name        string    `json:"name" validate:"required,min=2,max=100"`
+	StartDate   time.Time `json:"start_date" validate:"required"`
+	EndDate     time.Time `json:"end_date" validate:"required"`
+	LeaveTypeID *int      `json:"leave_type_id,omitempty"`
+}
+
+// Employee genders
+const (
+	GenderMale   = "MALE"
+	GenderFemale = "FEMALE"
+	GenderOther  = "OTHER"
+)
+
+// Leave policy violation codes
+const (
+	ViolationMinNotice           = "MIN_NOTICE"
+	ViolationMaxConsecutiveDays  = "MAX_CONSECUTIVE_DAYS"
+	ViolationGenderNotAllowed    = "GENDER_NOT_ALLOWED"
+	ViolationMinTenure           = "MIN_TENURE"
+	ViolationAttachmentRequired  = "ATTACHMENT_REQUIRED"
+	ViolationBlackoutPeriod      = "BLACKOUT_PERIOD"
+	ViolationInsufficientBalance = "INSUFFICIENT_BALANCE"
+	ViolationTeamCoverage        = "TEAM_COVERAGE"
+)
+
+// PolicyViolation is a single leave policy rule a request breaks.
+// Blocking violations reject the request; others are returned as warnings.
+type PolicyViolation struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Blocking bool   `json:"blocking"`
+}
+
+// EmployeePolicyProfile holds the employee attributes leave policy rules depend on
+type EmployeePolicyProfile struct {
+	Gender      *string    `db:"gender"`
+	JoiningDate *time.Time `db:"joining_date"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaveStatementEntry is a ledger entry with the leave or adjustment it came from
+type LeaveStatementEntry struct {
+	LeaveTypeID      int        `db:"leave_type_id"`
+	LeaveTypeName    string     `db:"leave_type"`
+	Unit             string     `db:"unit"`
+	EntryType        string     `db:"entry_type"`
+	Quantity         float64    `db:"quantity"`
+	Note             *string    `db:"note"`
+	CreatedAt        time.Time  `db:"created_at"`
+	LeaveID          *uuid.UUID `db:"leave_id"`
+	LeaveStart       *time.Time `db:"leave_start"`
+	LeaveEnd         *time.Time `db:"leave_end"`
+	AdjustmentReason *string    `db:"adjustment_reason"`
+}
+
+// LeaveStatementBalance is the stored balance of a leave type for the statement year
+type LeaveStatementBalance struct {
+	LeaveTypeID   int     `db:"leave_type_id"`
+	LeaveTypeName string  `db:"leave_type"`
+	Unit          string  `db:"unit"`
+	Opening       float64 `db:"opening"`
+	Accrued       float64 `db:"accrued"`
+	Used          float64 `db:"used"`
+	Adjusted      float64 `db:"adjusted"`
+	Closing       float64 `db:"closing"`
+}
+
+// LeaveStatementLine is one movement on a statement with the running balance after it
+type LeaveStatementLine struct {
+	Date        time.Time  `json:"date"`
+	EntryType   string     `json:"entry_type"`
+	Description string     `json:"description"`
+	Quantity    float64    `json:"quantity"`
+	Balance     float64    `json:"balance"`
+	LeaveID     *uuid.UUID `json:"leave_id,omitempty"`
+	StartDate   *time.Time `json:"start_date,omitempty"`
+	EndDate     *time.Time `json:"end_date,omitempty"`
+}
+
+// LeaveStatementSection is the statement of one leave type
+type LeaveStatementSection struct {
+	LeaveTypeID int                  `json:"leave_type_id"`
+	LeaveType   string               `json:"leave_type"`
+	Unit        string               `json:"unit"`
+	Opening     float64              `json:"opening"`
+	Accrued     float64              `json:"accrued"`
+	Used        float64              `json:"used"`
+	Adjusted    float64              `json:"adjusted"`
+	Closing     float64              `json:"closing"`
+	Lines       []LeaveStatementLine `json:"lines"`
+}
+
+// LeaveStatement is an employee's leave balance statement for a year
+type LeaveStatement struct {
+	EmployeeID   uuid.UUID               `json:"employee_id"`
+	EmployeeName string                  `json:"employee_name"`
+	Email        string                  `json:"email"`
+	Year         int                     `json:"year"`
+	GeneratedAt  time.Time               `json:"generated_at"`
+	Sections     []LeaveStatementSection `json:"sections"`
+}
@@ -20,15 +20,18 @@ type EmployeeInput struct {
 	FullName        string     `json:"full_name" validate:"required"`
 	Email           string     `json:"email" validate:"required,email"`
 	Role            string     `json:"role" validate:"required"`
-	Password        string     `json:"password,omitempty"`       // optional - auto-generated if not provided
-	ManagerID       *uuid.UUID `json:"manager_id,omitempty"`     // optional UUID
-	DesignationID   *uuid.UUID `json:"designation_id,omitempty"` // optional UUID
-	Salary          *float64   `json:"salary,omitempty"`         // optional
-	JoiningDate     *time.Time `json:"joining_date,omitempty"`   // optional
-	EndingDate      *time.Time `json:"ending_date,omitempty"`    // optional
-	Status          *string    `json:"status,omitempty"`         // optional, new field
-	CreatedAt       *time.Time `json:"created_at,omitempty"`     // optional
-	UpdatedAt       *time.Time `json:"updated_at,omitempty"`     // optional
+	Password        string     `json:"password,omitempty"`        // optional - auto-generated if not provided
+	ManagerID       *uuid.UUID `json:"manager_id,omitempty"`      // optional UUID
+	DesignationID   *uuid.UUID `json:"designation_id,omitempty"`  // optional UUID
+	Salary          *float64   `json:"salary,omitempty"`          // optional
+	JoiningDate     *time.Time `json:"joining_date,omitempty"`    // optional
+	EndingDate      *time.Time `json:"ending_date,omitempty"`     // optional
+	Gender          *string    `json:"gender,omitempty"`          // optional: MALE, FEMALE, OTHER
+	Department      *string    `json:"department,omitempty"`      // optional
+	EmploymentType  *string    `json:"employment_type,omitempty"` // optional: FULL_TIME (default), PART_TIME, CONTRACT, INTERN
+	Status          *string    `json:"status,omitempty"`          // optional, new field
+	CreatedAt       *time.Time `json:"created_at,omitempty"`      // optional
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`      // optional
 	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
 	ManagerName     *string    `json:"manager_name,omitempty"`     // optional
 	DesignationName *string    `json:"designation_name,omitempty"` // optional
@@ -49,6 +52,9 @@ type EmployeeResponse struct {
 	Salary          *float64   `json:"salary,omitempty"` // omitted for HR in list; present for admin/detail
 	JoiningDate     *time.Time `json:"joining_date,omitempty"`
 	EndingDate      *time.Time `json:"ending_date,omitempty"`
+	Gender          *string    `json:"gender,omitempty"`
+	Department      *string    `json:"department,omitempty"`
+	EmploymentType  *string    `json:"employment_type,omitempty"`
 	CreatedAt       *time.Time `json:"created_at,omitempty"`
 	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
 }
@@ -59,30 +65,76 @@ type LeaveType struct {
 	Name               string `json:"name" db:"name"`
 	IsPaid             bool   `json:"is_paid" db:"is_paid"`
 	DefaultEntitlement int    `json:"default_entitlement" db:"default_entitlement"`
+	Unit               string `json:"unit" db:"unit"` // DAY or HOUR (entitlement and balance unit)
+
+	// Policy rules
+	MinNoticeDays               int      `json:"min_notice_days" db:"min_notice_days"`
+	MaxConsecutiveDays          *float64 `json:"max_consecutive_days" db:"max_consecutive_days"`
+	AllowedGenders              *string  `json:"allowed_genders" db:"allowed_genders"` // comma separated, nil = everyone
+	MinTenureMonths             int      `json:"min_tenure_months" db:"min_tenure_months"`
+	AttachmentRequiredAfterDays *float64 `json:"attachment_required_after_days" db:"attachment_required_after_days"`
+	AllowNegativeBalance        bool     `json:"allow_negative_balance" db:"allow_negative_balance"`
+	MaxNegativeBalance          float64  `json:"max_negative_balance" db:"max_negative_balance"`
+	AccrualFrequency            string   `json:"accrual_frequency" db:"accrual_frequency"` // NONE, MONTHLY, QUARTERLY or PAY_PERIOD
+	CarryForwardMax             float64  `json:"carry_forward_max" db:"carry_forward_max"`
+	Encashable                  bool     `json:"encashable" db:"encashable"`
+	EncashMax                   *float64 `json:"encash_max" db:"encash_max"` // nil = no limit
 	// LeaveCount         int       `json:"leave_count" db:"leave_count"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
 type LeaveTypeInput struct {
-	Name               string `json:"name" validate:"required"`
-	IsPaid             *bool  `json:"is_paid,omitempty"`
-	DefaultEntitlement *int   `json:"default_entitlement,omitempty"`
-	LeaveCount         *int   `json:"leave_count,omitempty" validate:"omitempty,gt=0"`
+	Name               string  `json:"name" validate:"required"`
+	IsPaid             *bool   `json:"is_paid,omitempty"`
+	DefaultEntitlement *int    `json:"default_entitlement,omitempty"`
+	LeaveCount         *int    `json:"leave_count,omitempty" validate:"omitempty,gt=0"`
+	Unit               *string `json:"unit,omitempty"` // DAY (default) or HOUR
+
+	// Policy rules (omitted fields keep their current value on update)
+	MinNoticeDays               *int     `json:"min_notice_days,omitempty" validate:"omitempty,gte=0"`
+	MaxConsecutiveDays          *float64 `json:"max_consecutive_days,omitempty" validate:"omitempty,gte=0"` // 0 = no limit
+	AllowedGenders              []string `json:"allowed_genders,omitempty"`                                 // empty = everyone
+	MinTenureMonths             *int     `json:"min_tenure_months,omitempty" validate:"omitempty,gte=0"`
+	AttachmentRequiredAfterDays *float64 `json:"attachment_required_after_days,omitempty" validate:"omitempty,gte=0"` // 0 = never
+	AllowNegativeBalance        *bool    `json:"allow_negative_balance,omitempty"`
+	MaxNegativeBalance          *float64 `json:"max_negative_balance,omitempty" validate:"omitempty,gte=0"`
+	AccrualFrequency            *string  `json:"accrual_frequency,omitempty"`                            // NONE (default), MONTHLY, QUARTERLY or PAY_PERIOD
+	CarryForwardMax             *float64 `json:"carry_forward_max,omitempty" validate:"omitempty,gte=0"` // 0 = nothing carried forward
+	Encashable                  *bool    `json:"encashable,omitempty"`
+	EncashMax                   *float64 `json:"encash_max,omitempty" validate:"omitempty,gte=0"` // 0 = no limit
+
+	// Entitlement tiers by tenure, designation and employment type (replaces the current
+	// rules when given, an empty list removes them)
+	EntitlementRules *[]LeaveEntitlementRuleInput `json:"entitlement_rules,omitempty"`
 }
 
+// Leave type units
+const (
+	LeaveUnitDay  = "DAY"
+	LeaveUnitHour = "HOUR"
+)
+
 // ----------------- LEAVE -----------------
 type LeaveInput struct {
-	EmployeeID    uuid.UUID  `json:"employee_id" validate:"required"`
-	LeaveTypeID   int        `json:"leave_type_id" validate:"required"`
-	LeaveTimingID *int       `json:"leave_timing_id,omitempty"` // Optional timing ID (defaults to 3 - Full Day)
-	StartDate     time.Time  `json:"start_date" validate:"required"`
-	EndDate       time.Time  `json:"end_date" validate:"required"`
-	Reason        string     `json:"reason" validate:"required,min=10,max=500"` // Enhanced validation
-	Days          *float64   `json:"days,omitempty"`
-	Status        string     `json:"status,omitempty"`
-	AppliedByID   *uuid.UUID `json:"applied_by,omitempty"`
-	ApprovedByID  *uuid.UUID `json:"approved_by,omitempty"`
+	EmployeeID    uuid.UUID `json:"employee_id" validate:"required"`
+	LeaveTypeID   int       `json:"leave_type_id" validate:"required"`
+	LeaveTimingID *int      `json:"leave_timing_id,omitempty"` // Optional timing ID (defaults to 3 - Full Day)
+	// Multi-day leaves only: portion taken on the first day (2 Second Half or 3 Full)
+	// and on the last day (1 First Half or 3 Full); days in between are full days
+	StartDayTimingID *int       `json:"start_day_timing_id,omitempty"`
+	EndDayTimingID   *int       `json:"end_day_timing_id,omitempty"`
+	StartDate        time.Time  `json:"start_date" validate:"required"`
+	EndDate          time.Time  `json:"end_date" validate:"required"`
+	StartTime        *string    `json:"start_time,omitempty"`                      // HH:MM, required for HOUR leave types
+	EndTime          *string    `json:"end_time,omitempty"`                        // HH:MM, required for HOUR leave types
+	Reason           string     `json:"reason" validate:"required,min=10,max=500"` // Enhanced validation
+	Days             *float64   `json:"days,omitempty"`
+	Status           string     `json:"status,omitempty"`
+	AppliedByID      *uuid.UUID `json:"applied_by,omitempty"`
+	ApprovedByID     *uuid.UUID `json:"approved_by,omitempty"`
+	AutoApprove      *bool      `json:"auto_approve,omitempty"` // Used by admin-add only (defaults to true)
+	Override         bool       `json:"override,omitempty"`     // Used by admin-add only: add despite blocking policy violations
 }
 
 // ----------------- LEAVE BALANCE -----------------
@@ -179,6 +231,11 @@ type LeaveResponse struct {
 	StartDate       time.Time `db:"start_date" json:"start_date"`
 	EndDate         time.Time `db:"end_date" json:"end_date"`
 	Days            float64   `db:"days" json:"days"`
+	Hours           *float64  `db:"hours" json:"hours,omitempty"`
+	StartHalfID     *int      `db:"start_half_id" json:"start_day_timing_id,omitempty"`
+	EndHalfID       *int      `db:"end_half_id" json:"end_day_timing_id,omitempty"`
+	StartTime       *string   `db:"start_time" json:"start_time,omitempty"`
+	EndTime         *string   `db:"end_time" json:"end_time,omitempty"`
 	Reason          string    `db:"reason" json:"reason"`
 	Status          string    `db:"status" json:"status"`
 	AppliedAt       time.Time `db:"applied_at" json:"applied_at"`
@@ -204,6 +261,19 @@ type CompanySettings struct {
 	LogoPath       string `db:"logo_path" json:"logo_path"`
 	PrimaryColor   string `db:"primary_color" json:"primary_color"`
 	SecondaryColor string `db:"secondary_color" json:"secondary_color"`
+
+	WorkStartTime      string  `db:"work_start_time" json:"work_start_time"`
+	WorkEndTime        string  `db:"work_end_time" json:"work_end_time"`
+	WorkingHoursPerDay float64 `db:"working_hours_per_day" json:"working_hours_per_day"`
+
+	CoverageThresholdPercent float64 `db:"coverage_threshold_percent" json:"coverage_threshold_percent"` // 0 = disabled
+	CoverageBlock            bool    `db:"coverage_block" json:"coverage_block"`
+
+	CompOffLeaveTypeID  *int `db:"comp_off_leave_type_id" json:"comp_off_leave_type_id"` // nil = comp-off disabled
+	CompOffValidityDays int  `db:"comp_off_validity_days" json:"comp_off_validity_days"`
+
+	PayrollProrationBasis string `db:"payroll_proration_basis" json:"payroll_proration_basis"` // CALENDAR_DAYS or WORKING_DAYS
+	PayrollDayRateBasis   string `db:"payroll_day_rate_basis" json:"payroll_day_rate_basis"`   // FIXED, WORKING_DAYS or CALENDAR_DAYS
 }
 
 type CompanyField struct {
@@ -213,22 +283,69 @@ type CompanyField struct {
 	PrimaryColor         string `form:"PrimaryColor" json:"primary_color"`     // e.g., "#2c3e50"
 	SecondaryColor       string `form:"SecondaryColor" json:"secondary_color"` // e.g., "#ecf0f1"
 	LogoPath             string `json:"logo_path"`
+
+	WorkStartTime      string  `form:"WorkStartTime" json:"work_start_time"` // e.g., "10:00"
+	WorkEndTime        string  `form:"WorkEndTime" json:"work_end_time"`     // e.g., "19:00"
+	WorkingHoursPerDay float64 `form:"WorkingHoursPerDay" json:"working_hours_per_day"`
+
+	CoverageThresholdPercent *float64 `form:"CoverageThresholdPercent" json:"coverage_threshold_percent"` // nil keeps current value
+	CoverageBlock            *bool    `form:"CoverageBlock" json:"coverage_block"`                        // nil keeps current value
+
+	CompOffLeaveTypeID  *int `form:"CompOffLeaveTypeID" json:"comp_off_leave_type_id"`  // nil keeps current value, 0 disables comp-off
+	CompOffValidityDays *int `form:"CompOffValidityDays" json:"comp_off_validity_days"` // nil keeps current value
+
+	PayrollProrationBasis *string `form:"PayrollProrationBasis" json:"payroll_proration_basis"` // nil keeps current value
+	PayrollDayRateBasis   *string `form:"PayrollDayRateBasis" json:"payroll_day_rate_basis"`    // nil keeps current value
 }
 
 type Leave struct {
 	ID            uuid.UUID  `db:"id"`
 	EmployeeID    uuid.UUID  `db:"employee_id"`
 	LeaveTypeID   int        `db:"leave_type_id"`
-	LeaveTimingID *int       `db:"half_id"` // Timing ID (references Tbl_Half)
+	LeaveTimingID *int       `db:"half_id"`       // Timing ID (references Tbl_Half)
+	StartHalfID   *int       `db:"start_half_id"` // First-day timing of a multi-day leave
+	EndHalfID     *int       `db:"end_half_id"`   // Last-day timing of a multi-day leave
 	StartDate     time.Time  `db:"start_date"`
 	EndDate       time.Time  `db:"end_date"`
 	Days          float64    `db:"days"`
+	Hours         *float64   `db:"hours"`      // Set for HOUR leave types
+	StartTime     *string    `db:"start_time"` // HH:MM, HOUR leave types only
+	EndTime       *string    `db:"end_time"`   // HH:MM, HOUR leave types only
 	Status        string     `db:"status"`
 	AppliedByID   *uuid.UUID `db:"applied_by"`
 	ApprovedByID  *uuid.UUID `db:"approved_by"`
 	Reason        string     `db:"reason"`
+	ParentLeaveID *uuid.UUID `db:"parent_leave_id"` // Set on leaves created by splitting an approved leave
 	CreatedAt     time.Time  `db:"created_at"`
 	UpdatedAt     time.Time  `db:"updated_at"`
+
+	// Withdrawal/cancellation request of an approved leave (manager or employee)
+	WithdrawalRequestedBy *uuid.UUID `db:"withdrawal_requested_by"`
+	WithdrawalReason      *string    `db:"withdrawal_reason"`
+	WithdrawalRequestedAt *time.Time `db:"withdrawal_requested_at"`
+}
+
+// LeaveQuantity is how much a leave request consumes.
+// Days is always set (used for payroll and reporting); Hours, StartTime and
+// EndTime are only set for HOUR leave types. StartHalfID and EndHalfID hold the
+// first/last day timing of a multi-day leave. Balance is in the leave type's unit.
+type LeaveQuantity struct {
+	Days        float64
+	Hours       *float64
+	StartTime   *string
+	EndTime     *string
+	StartHalfID *int
+	EndHalfID   *int
+	Balance     float64
+}
+
+// BalanceQuantity returns the amount this leave consumes from the balance,
+// in the unit of its leave type (hours for HOUR leave types, otherwise days)
+func (l Leave) BalanceQuantity() float64 {
+	if l.Hours != nil {
+		return *l.Hours
+	}
+	return l.Days
 }
 
 // Leave Timing
@@ -319,9 +436,14 @@ type UpdateAssignmentRequest struct {
 
 // LeaveUpdateInput is used when an employee edits their own pending leave
 type LeaveUpdateInput struct {
-	LeaveTypeID   int       `json:"leave_type_id" validate:"required"`
-	LeaveTimingID int       `json:"leave_timing_id,omitempty"`
-	StartDate     time.Time `json:"start_date" validate:"required"`
-	EndDate       time.Time `json:"end_date" validate:"required"`
-	Reason        string    `json:"reason" validate:"required,min=10,max=500"`
+	LeaveTypeID   int `json:"leave_type_id" validate:"required"`
+	LeaveTimingID int `json:"leave_timing_id,omitempty"`
+	// Multi-day leaves only, see LeaveInput
+	StartDayTimingID *int      `json:"start_day_timing_id,omitempty"`
+	EndDayTimingID   *int      `json:"end_day_timing_id,omitempty"`
+	StartDate        time.Time `json:"start_date" validate:"required"`
+	EndDate          time.Time `json:"end_date" validate:"required"`
+	StartTime        *string   `json:"start_time,omitempty"` // HH:MM, required for HOUR leave types
+	EndTime          *string   `json:"end_time,omitempty"`   // HH:MM, required for HOUR leave types
+	Reason           string    `json:"reason" validate:"required,min=10,max=500"`
 }
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Payroll run statuses. A run is prepared as a DRAFT, submitted for approval and approved by a
+// different user before it is finalized and paid; a rejected run goes back to DRAFT.
+const (
+	PayrollStatusDraft     = "DRAFT" // open run holding draft payslips
+	PayrollStatusSubmitted = "SUBMITTED"
+	PayrollStatusApproved  = "APPROVED"
+	PayrollStatusFinalized = "FINALIZED"
+	PayrollStatusPaid      = "PAID"
+)
+
+// Payroll run actions recorded in the run's history
+const (
+	PayrollActionRun      = "RUN" // draft payslips computed or refreshed
+	PayrollActionSubmit   = "SUBMIT"
+	PayrollActionApprove  = "APPROVE"
+	PayrollActionReject   = "REJECT"
+	PayrollActionFinalize = "FINALIZE"
+	PayrollActionPay      = "PAY"
+	PayrollActionWithdraw = "WITHDRAW" // payslips of a finalized run withdrawn, the run keeps its status
+)
+
+// Changes to a draft payslip's inputs since the payroll preview
+const (
+	PayrollChangeEmployeeAdded     = "EMPLOYEE_ADDED"   // now eligible, no draft payslip
+	PayrollChangeEmployeeRemoved   = "EMPLOYEE_REMOVED" // has a draft payslip, no longer eligible
+	PayrollChangeWorkingDays       = "WORKING_DAYS"
+	PayrollChangeDayRateBasis      = "DAY_RATE_BASIS"
+	PayrollChangePaidLeaves        = "PAID_LEAVES"
+	PayrollChangeUnpaidLeaves      = "UNPAID_LEAVES"
+	PayrollChangeLeaveRecords      = "LEAVE_RECORDS" // approved leaves edited without changing the day counts
+	PayrollChangeEncashment        = "LEAVE_ENCASHMENT"
+	PayrollChangeProration         = "PRORATION"          // joining or exit date changed the prorated days
+	PayrollChangeCorrectionAdded   = "CORRECTION_ADDED"   // correction queued after the preview
+	PayrollChangeCorrectionRemoved = "CORRECTION_REMOVED" // correction in the draft no longer pending
+)
+
+// PayrollRun is one payroll run of a month
+type PayrollRun struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	Month       int        `json:"month" db:"month"`
+	Year        int        `json:"year" db:"year"`
+	Status      string     `json:"status" db:"status"`
+	PreparedBy  *uuid.UUID `json:"prepared_by" db:"prepared_by"` // who last ran the draft
+	SubmittedBy *uuid.UUID `json:"submitted_by" db:"submitted_by"`
+	SubmittedAt *time.Time `json:"submitted_at" db:"submitted_at"`
+	ApprovedBy  *uuid.UUID `json:"approved_by" db:"approved_by"`
+	ApprovedAt  *time.Time `json:"approved_at" db:"approved_at"`
+	FinalizedBy *uuid.UUID `json:"finalized_by" db:"finalized_by"`
+	FinalizedAt *time.Time `json:"finalized_at" db:"finalized_at"`
+	PaidBy      *uuid.UUID `json:"paid_by" db:"paid_by"`
+	PaidAt      *time.Time `json:"paid_at" db:"paid_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// IsOpen reports whether the run can still be changed or approved
+func (r PayrollRun) IsOpen() bool {
+	return r.Status == PayrollStatusDraft || r.Status == PayrollStatusSubmitted || r.Status == PayrollStatusApproved
+}
+
+// PayrollRunAction is one transition in a payroll run's history
+type PayrollRunAction struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Action     string     `json:"action" db:"action"`
+	FromStatus *string    `json:"from_status" db:"from_status"`
+	ToStatus   string     `json:"to_status" db:"to_status"`
+	ActorID    *uuid.UUID `json:"actor_id" db:"actor_id"`
+	ActorName  *string    `json:"actor_name" db:"actor_name"`
+	Comments   *string    `json:"comments" db:"comments"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PayrollRunActionInput carries the comments of a payroll run transition
+type PayrollRunActionInput struct {
+	Comments string `json:"comments"`
+}
+
+// PayslipRecord is a stored payslip of a payroll run, a draft while the run is open
+type PayslipRecord struct {
+	ID           uuid.UUID `json:"payslip_id" db:"id"`
+	EmployeeID   uuid.UUID `json:"employee_id" db:"employee_id"`
+	Employee     string    `json:"employee" db:"full_name"`
+	BasicSalary  float64   `json:"basic_salary" db:"basic_salary"` // gross monthly earnings
+	WorkingDays  int       `json:"working_days" db:"working_days"`
+	PaidLeaves   float64   `json:"paid_leaves" db:"paid_leaves"`
+	UnpaidLeaves float64   `json:"unpaid_leaves" db:"unpaid_leaves"`
+	Deductions   float64   `json:"deductions" db:"deduction_amount"`
+	Encashment   float64   `json:"leave_encashment" db:"encashment_amount"`
+	NetSalary    float64   `json:"net_salary" db:"net_salary"`
+	// DayRateBasis is what WorkingDays counts, models.DayRate*
+	DayRateBasis string `json:"day_rate_basis" db:"day_rate_basis"`
+	// LeaveFingerprint identifies the approved leave records the payslip was computed from
+	LeaveFingerprint string     `json:"-" db:"leave_fingerprint"`
+	Status           string     `json:"status" db:"status"`
+	PayslipType      string     `json:"payslip_type" db:"payslip_type"`
+	CorrectionID     *uuid.UUID `json:"correction_id,omitempty" db:"correction_id"` // for CORRECTION payslips
+	// Proration of a joining or exit month, nil when employed the whole month
+	ProrationBasis *string       `json:"proration_basis,omitempty" db:"proration_basis"`
+	PayableDays    *float64      `json:"payable_days,omitempty" db:"payable_days"`
+	PeriodDays     *float64      `json:"period_days,omitempty" db:"period_days"`
+	Lines          []PayslipLine `json:"lines" db:"-"`
+	// PaymentReference is the bank transfer that paid the payslip, set with the run's bank file
+	PaymentReference *string `json:"payment_reference,omitempty" db:"payment_reference"`
+}
+
+// PayrollDraftChange is one difference between a draft payslip and what it would be computed
+// from now
+type PayrollDraftChange struct {
+	EmployeeID uuid.UUID   `json:"employee_id"`
+	Employee   string      `json:"employee"`
+	Change     string      `json:"change"`
+	Previewed  interface{} `json:"previewed,omitempty"`
+	Current    interface{} `json:"current,omitempty"`
+}
@@ -0,0 +1,58 @@
+package models
+
+// Bases the salary of a month an employee joined or left in is prorated on
+const (
+	ProrationCalendarDays = "CALENDAR_DAYS"
+	ProrationWorkingDays  = "WORKING_DAYS" // weekdays that are not holidays
+)
+
+// Bases of the days a month's salary is divided by for the per-day rate of unpaid leave days
+const (
+	DayRateFixed        = "FIXED" // the company's working_days_per_month
+	DayRateWorkingDays  = "WORKING_DAYS"
+	DayRateCalendarDays = "CALENDAR_DAYS"
+)
+
+// SalaryProration is the part of a payroll month an employee joining or leaving in it is
+// paid for
+type SalaryProration struct {
+	Basis       string  `json:"basis"`
+	PayableDays float64 `json:"payable_days"` // days employed in the month on the basis
+	PeriodDays  float64 `json:"period_days"`  // days of the month on the basis
+}
+
+// Factor is the share of the month's prorated earnings paid
+func (p *SalaryProration) Factor() float64 {
+	if p == nil || p.PeriodDays <= 0 {
+		return 1
+	}
+	return p.PayableDays / p.PeriodDays
+}
+
+// IsValidProrationBasis reports whether basis is a proration basis
+func IsValidProrationBasis(basis string) bool {
+	return basis == ProrationCalendarDays || basis == ProrationWorkingDays
+}
+
+// IsValidDayRateBasis reports whether basis is a per-day rate basis
+func IsValidDayRateBasis(basis string) bool {
+	return basis == DayRateFixed || basis == DayRateWorkingDays || basis == DayRateCalendarDays
+}
+
+// SetProration records the proration of a payslip, nil when employed the whole month
+func (p *PayslipRecord) SetProration(proration *SalaryProration) {
+	p.ProrationBasis, p.PayableDays, p.PeriodDays = nil, nil, nil
+	if proration != nil {
+		p.ProrationBasis = &proration.Basis
+		p.PayableDays = &proration.PayableDays
+		p.PeriodDays = &proration.PeriodDays
+	}
+}
+
+// Proration returns the proration of a payslip, nil when employed the whole month
+func (p PayslipRecord) Proration() *SalaryProration {
+	if p.ProrationBasis == nil || p.PayableDays == nil || p.PeriodDays == nil {
+		return nil
+	}
+	return &SalaryProration{Basis: *p.ProrationBasis, PayableDays: *p.PayableDays, PeriodDays: *p.PeriodDays}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Payslip statuses
+const (
+	PayslipStatusIssued    = "ISSUED"
+	PayslipStatusWithdrawn = "WITHDRAWN" // kept for audit, left out of year-to-date totals
+)
+
+// Payslip types
+const (
+	PayslipTypeRegular    = "REGULAR"
+	PayslipTypeCorrection = "CORRECTION" // recomputes a withdrawn payslip of an earlier month
+)
+
+// PayslipCorrection is a correction queued for a withdrawn payslip. The next payroll run pays
+// it as a CORRECTION payslip: the pay period recomputed, less what was already paid for it.
+type PayslipCorrection struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	PayslipID  uuid.UUID  `json:"payslip_id" db:"payslip_id"` // the original payslip
+	EmployeeID uuid.UUID  `json:"employee_id" db:"employee_id"`
+	Employee   string     `json:"employee" db:"full_name"`
+	Month      int        `json:"month" db:"month"` // pay period being corrected
+	Year       int        `json:"year" db:"year"`
+	Reason     string     `json:"reason" db:"reason"`
+	CreatedBy  *uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PayslipWithdrawInput withdraws a payslip or every payslip of a run
+type PayslipWithdrawInput struct {
+	Reason string `json:"reason" validate:"required"`
+	// Correct queues a correction of each withdrawn payslip for the next payroll run
+	Correct bool `json:"correct"`
+}
+
+// PayslipCorrectionInput queues a correction for an already withdrawn payslip
+type PayslipCorrectionInput struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// WithdrawnPayslip is a payslip withdrawn by a request, for notifying its employee
+type WithdrawnPayslip struct {
+	ID           uuid.UUID  `json:"payslip_id" db:"id"`
+	EmployeeID   uuid.UUID  `json:"employee_id" db:"employee_id"`
+	Employee     string     `json:"employee" db:"full_name"`
+	Email        string     `json:"-" db:"email"`
+	Month        int        `json:"month" db:"month"`
+	Year         int        `json:"year" db:"year"`
+	NetSalary    float64    `json:"net_salary" db:"net_salary"`
+	CorrectionID *uuid.UUID `json:"correction_id,omitempty" db:"-"`
+}
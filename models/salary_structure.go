@@ -0,0 +1,112 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Salary component and payslip line kinds
+const (
+	SalaryEarning   = "EARNING"
+	SalaryDeduction = "DEDUCTION"
+	SalaryEmployer  = "EMPLOYER" // employer contribution, listed on the payslip but not deducted
+)
+
+// Salary component calculation types
+const (
+	SalaryCalcFixed      = "FIXED"
+	SalaryCalcPercentage = "PERCENTAGE"
+	SalaryCalcFormula    = "FORMULA"
+)
+
+// Variables available to percentage bases and formulas besides component codes
+const (
+	SalaryVarCTC          = "CTC" // the employee's monthly salary (Tbl_Employee.salary)
+	SalaryVarWorkingDays  = "WORKING_DAYS"
+	SalaryVarPaidLeaves   = "PAID_LEAVES"
+	SalaryVarUnpaidLeaves = "UNPAID_LEAVES"
+)
+
+// Payslip line codes added by payroll itself
+const (
+	SalaryCodeBasic = "BASIC" // used when no structure is assigned
+	SalaryCodeLOP   = "LOP"   // loss of pay for unpaid leave days
+	// SalaryCodePreviouslyPaid deducts what was already paid for the period of a correction payslip
+	SalaryCodePreviouslyPaid = "PREV_PAID"
+)
+
+// SalaryComponent is one earning or deduction of a salary structure
+type SalaryComponent struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	StructureID uuid.UUID      `json:"structure_id" db:"structure_id"`
+	Code        string         `json:"code" db:"code"`
+	Name        string         `json:"name" db:"name"`
+	Kind        string         `json:"kind" db:"kind"`
+	CalcType    string         `json:"calc_type" db:"calc_type"`
+	Amount      *float64       `json:"amount,omitempty" db:"amount"`
+	Percentage  *float64       `json:"percentage,omitempty" db:"percentage"`
+	BaseCodes   pq.StringArray `json:"base_codes,omitempty" db:"base_codes"`
+	Formula     *string        `json:"formula,omitempty" db:"formula"`
+	Prorate     bool           `json:"prorate" db:"prorate"`
+	SortOrder   int            `json:"sort_order" db:"sort_order"`
+}
+
+// SalaryStructure is a named set of salary components
+type SalaryStructure struct {
+	ID          uuid.UUID         `json:"id" db:"id"`
+	Name        string            `json:"name" db:"name"`
+	Description *string           `json:"description" db:"description"`
+	Components  []SalaryComponent `json:"components" db:"-"`
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// SalaryComponentInput is one component in a salary structure request
+type SalaryComponentInput struct {
+	Code       string   `json:"code" validate:"required"`
+	Name       string   `json:"name" validate:"required"`
+	Kind       string   `json:"kind" validate:"required"`
+	CalcType   string   `json:"calc_type" validate:"required"`
+	Amount     *float64 `json:"amount,omitempty"`
+	Percentage *float64 `json:"percentage,omitempty"`
+	BaseCodes  []string `json:"base_codes,omitempty"`
+	Formula    *string  `json:"formula,omitempty"`
+	Prorate    *bool    `json:"prorate,omitempty"` // defaults to true for earnings
+}
+
+// SalaryStructureInput creates or replaces a salary structure
+type SalaryStructureInput struct {
+	Name        string                 `json:"name" validate:"required"`
+	Description *string                `json:"description,omitempty"`
+	Components  []SalaryComponentInput `json:"components" validate:"required,min=1,dive"`
+}
+
+// SalaryStructureAssignInput assigns a structure to an employee or designation, or clears it
+type SalaryStructureAssignInput struct {
+	SalaryStructureID *uuid.UUID `json:"salary_structure_id"`
+}
+
+// PayslipLine is one earning or deduction line of a payslip
+type PayslipLine struct {
+	Code   string  `json:"code" db:"code"`
+	Name   string  `json:"name" db:"name"`
+	Kind   string  `json:"kind" db:"kind"`
+	Amount float64 `json:"amount" db:"amount"`
+}
+
+// SalaryBreakdown is an employee's computed salary for a month. Earnings are listed at their
+// monthly amount, prorated in a joining or exit month; unpaid leave days are a loss of pay
+// deduction.
+type SalaryBreakdown struct {
+	Lines                 []PayslipLine `json:"lines"`
+	Gross                 float64       `json:"gross"`
+	Deductions            float64       `json:"deductions"`
+	EmployerContributions float64       `json:"employer_contributions"`
+	// Earned is each earning after loss of pay, the wages statutory deductions are based on
+	Earned map[string]float64 `json:"-"`
+	// MonthlyGross is the gross of a whole month, before proration, which ESI coverage and the
+	// income tax projection follow
+	MonthlyGross float64 `json:"-"`
+}
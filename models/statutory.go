@@ -0,0 +1,142 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statutory rule kinds
+const (
+	StatutoryTDS = "TDS" // income tax deducted at source
+	StatutoryEPF = "EPF" // employees' provident fund
+	StatutoryESI = "ESI" // employees' state insurance
+	StatutoryPT  = "PT"  // state professional tax
+)
+
+// StatutoryDefaultCode is the rule code of kinds with a single rule set
+const StatutoryDefaultCode = "DEFAULT"
+
+// Income tax regimes, the rule codes of TDS rules
+const (
+	TaxRegimeNew = "NEW"
+	TaxRegimeOld = "OLD"
+)
+
+// Payslip line codes of statutory deductions and employer contributions
+const (
+	StatutoryCodeTDS         = "TDS"
+	StatutoryCodeEPF         = "EPF"
+	StatutoryCodeEPFEmployer = "EPF_ER"
+	StatutoryCodeEPSEmployer = "EPS_ER"
+	StatutoryCodeESI         = "ESI"
+	StatutoryCodeESIEmployer = "ESI_ER"
+	StatutoryCodePT          = "PT"
+)
+
+// StatutoryRule is one version of a statutory rule set; Config is kind specific
+type StatutoryRule struct {
+	ID            uuid.UUID       `json:"id" db:"id"`
+	Kind          string          `json:"kind" db:"kind"`
+	Code          string          `json:"code" db:"code"`
+	Name          string          `json:"name" db:"name"`
+	EffectiveFrom time.Time       `json:"effective_from" db:"effective_from"`
+	Config        json.RawMessage `json:"config" db:"config"`
+	CreatedBy     *uuid.UUID      `json:"created_by" db:"created_by"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+}
+
+// StatutoryRuleInput adds a new version of a statutory rule set
+type StatutoryRuleInput struct {
+	Kind          string          `json:"kind" validate:"required"`
+	Code          string          `json:"code"` // tax regime for TDS, state for PT
+	Name          string          `json:"name" validate:"required"`
+	EffectiveFrom string          `json:"effective_from" validate:"required"` // YYYY-MM-DD
+	Config        json.RawMessage `json:"config" validate:"required"`
+}
+
+// TaxSlab taxes the part of the income between From and To (nil = no upper bound) at Rate %
+type TaxSlab struct {
+	From float64  `json:"from"`
+	To   *float64 `json:"to,omitempty"`
+	Rate float64  `json:"rate"`
+}
+
+// TDSConfig is the config of an income tax regime
+type TDSConfig struct {
+	StandardDeduction float64   `json:"standard_deduction"`
+	Slabs             []TaxSlab `json:"slabs"`
+	RebateLimit       float64   `json:"rebate_limit"` // taxable income up to which the rebate applies
+	RebateMax         float64   `json:"rebate_max"`
+	CessPercent       float64   `json:"cess_percent"`
+	Section80CLimit   float64   `json:"section_80c_limit,omitempty"` // employee EPF deductible up to this, 0 = not deductible
+}
+
+// EPFConfig is the config of the provident fund
+type EPFConfig struct {
+	WageCodes         []string `json:"wage_codes"` // salary components that are PF wages
+	EmployeeRate      float64  `json:"employee_rate"`
+	EmployerRate      float64  `json:"employer_rate"` // EPS is paid out of the employer share
+	EPSRate           float64  `json:"eps_rate"`
+	WageCeiling       float64  `json:"wage_ceiling"`
+	RestrictToCeiling bool     `json:"restrict_to_ceiling"` // contributions on wages up to the ceiling only
+}
+
+// ESIConfig is the config of the state insurance
+type ESIConfig struct {
+	EmployeeRate  float64 `json:"employee_rate"`
+	EmployerRate  float64 `json:"employer_rate"`
+	WageThreshold float64 `json:"wage_threshold"` // monthly gross above which ESI does not apply
+}
+
+// ProfessionalTaxSlab is the monthly professional tax for an earned gross between Min and Max
+// (nil = no upper bound). MonthAmounts overrides the amount for months such as February.
+type ProfessionalTaxSlab struct {
+	Min          float64            `json:"min"`
+	Max          *float64           `json:"max,omitempty"`
+	Amount       float64            `json:"amount"`
+	MonthAmounts map[string]float64 `json:"month_amounts,omitempty"` // month number => amount
+}
+
+// ProfessionalTaxConfig is the config of a state's professional tax
+type ProfessionalTaxConfig struct {
+	Slabs []ProfessionalTaxSlab `json:"slabs"`
+}
+
+// StatutoryProfile is what statutory deductions need to know about an employee
+type StatutoryProfile struct {
+	TaxRegime string  `json:"tax_regime" db:"tax_regime"`
+	PTState   *string `json:"pt_state" db:"pt_state"` // nil = no professional tax
+	PFEnabled bool    `json:"pf_enabled" db:"pf_enabled"`
+}
+
+// StatutoryProfileInput updates an employee's statutory profile; omitted fields are kept
+type StatutoryProfileInput struct {
+	TaxRegime *string `json:"tax_regime,omitempty"`
+	PTState   *string `json:"pt_state,omitempty"` // "" removes professional tax
+	PFEnabled *bool   `json:"pf_enabled,omitempty"`
+}
+
+// StatutoryYTD is what an employee earned and paid in the financial year before a payroll month
+type StatutoryYTD struct {
+	TaxableIncome float64 `db:"taxable_income"`
+	TDS           float64 `db:"tds"`
+	EPF           float64 `db:"epf"`
+}
+
+// PayrollYTDSummary totals an employee's finalized payslips of a financial year
+type PayrollYTDSummary struct {
+	EmployeeID            uuid.UUID     `json:"employee_id"`
+	EmployeeName          string        `json:"employee_name"`
+	FinancialYear         string        `json:"financial_year"` // e.g. 2025-26
+	Payslips              int           `json:"payslips" db:"payslips"`
+	Gross                 float64       `json:"gross" db:"gross"`
+	Encashment            float64       `json:"leave_encashment" db:"encashment"`
+	Deductions            float64       `json:"deductions" db:"deductions"`
+	NetPay                float64       `json:"net_pay" db:"net_pay"`
+	TaxableIncome         float64       `json:"taxable_income" db:"taxable_income"`
+	TDS                   float64       `json:"tds" db:"tds"`
+	EmployerContributions float64       `json:"employer_contributions" db:"employer_contributions"`
+	Lines                 []PayslipLine `json:"lines"` // totals per component and statutory code
+}
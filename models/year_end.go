@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// YearEndBalance is an employee's closing balance of a leave type used by the year-end close
+type YearEndBalance struct {
+	EmployeeID   uuid.UUID `db:"employee_id"`
+	EmployeeName string    `db:"employee_name"`
+	Salary       float64   `db:"salary"`
+	LeaveTypeID  int       `db:"leave_type_id"`
+	Closing      float64   `db:"closing"`
+}
+
+// YearEndLine is the year-end outcome of one employee's leave type balance
+type YearEndLine struct {
+	ID             *uuid.UUID `json:"id,omitempty" db:"id"`
+	EmployeeID     uuid.UUID  `json:"employee_id" db:"employee_id"`
+	EmployeeName   string     `json:"employee_name" db:"employee_name"`
+	LeaveTypeID    int        `json:"leave_type_id" db:"leave_type_id"`
+	LeaveTypeName  string     `json:"leave_type" db:"leave_type"`
+	Unit           string     `json:"unit" db:"unit"`
+	Closing        float64    `json:"closing" db:"closing"`
+	CarriedForward float64    `json:"carried_forward" db:"carried_forward"`
+	Encashed       float64    `json:"encashed" db:"encashed"`
+	Lapsed         float64    `json:"lapsed" db:"lapsed"`
+	EncashRate     float64    `json:"encash_rate" db:"encash_rate"` // per unit of the leave type
+	EncashAmount   float64    `json:"encash_amount" db:"encash_amount"`
+	PayrollRunID   *uuid.UUID `json:"payroll_run_id,omitempty" db:"payroll_run_id"`
+}
+
+// YearEndClose is a finalized year-end close
+type YearEndClose struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	Year          int        `json:"year" db:"year"`
+	FinalizedBy   *uuid.UUID `json:"finalized_by" db:"finalized_by"`
+	FinalizedName *string    `json:"finalized_by_name" db:"finalized_by_name"`
+	FinalizedAt   time.Time  `json:"finalized_at" db:"finalized_at"`
+}
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Leave types can be configured in DAY or HOUR units
+ALTER TABLE Tbl_Leave_type
+ADD COLUMN IF NOT EXISTS unit VARCHAR(10) NOT NULL DEFAULT 'DAY';
+
+ALTER TABLE Tbl_Leave_type
+ADD CONSTRAINT chk_leave_type_unit CHECK (unit IN ('DAY', 'HOUR'));
+
+-- 2️ Hourly leave requests store their time window and hours
+ALTER TABLE Tbl_Leave ADD COLUMN IF NOT EXISTS start_time VARCHAR(5) DEFAULT NULL; -- HH:MM
+ALTER TABLE Tbl_Leave ADD COLUMN IF NOT EXISTS end_time VARCHAR(5) DEFAULT NULL;   -- HH:MM
+ALTER TABLE Tbl_Leave ADD COLUMN IF NOT EXISTS hours NUMERIC DEFAULT NULL;
+
+-- 3️ Company working hours used to validate hourly requests and convert hours to days
+ALTER TABLE Tbl_Company_Settings ADD COLUMN IF NOT EXISTS work_start_time VARCHAR(5) NOT NULL DEFAULT '10:00';
+ALTER TABLE Tbl_Company_Settings ADD COLUMN IF NOT EXISTS work_end_time VARCHAR(5) NOT NULL DEFAULT '19:00';
+ALTER TABLE Tbl_Company_Settings ADD COLUMN IF NOT EXISTS working_hours_per_day NUMERIC NOT NULL DEFAULT 9;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE Tbl_Company_Settings DROP COLUMN IF EXISTS working_hours_per_day;
+ALTER TABLE Tbl_Company_Settings DROP COLUMN IF EXISTS work_end_time;
+ALTER TABLE Tbl_Company_Settings DROP COLUMN IF EXISTS work_start_time;
+
+ALTER TABLE Tbl_Leave DROP COLUMN IF EXISTS hours;
+ALTER TABLE Tbl_Leave DROP COLUMN IF EXISTS end_time;
+ALTER TABLE Tbl_Leave DROP COLUMN IF EXISTS start_time;
+
+ALTER TABLE Tbl_Leave_type DROP CONSTRAINT IF EXISTS chk_leave_type_unit;
+ALTER TABLE Tbl_Leave_type DROP COLUMN IF EXISTS unit;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Per-day timing for multi-day leaves: portion taken on the first and last day.
+-- NULL means the day follows half_id (the whole-range timing).
+ALTER TABLE Tbl_Leave ADD COLUMN IF NOT EXISTS start_half_id INT DEFAULT NULL;
+ALTER TABLE Tbl_Leave ADD COLUMN IF NOT EXISTS end_half_id INT DEFAULT NULL;
+
+-- 2️ Add foreign key constraints
+ALTER TABLE Tbl_Leave
+ADD CONSTRAINT fk_leave_start_half
+FOREIGN KEY (start_half_id) REFERENCES Tbl_Half(id);
+
+ALTER TABLE Tbl_Leave
+ADD CONSTRAINT fk_leave_end_half
+FOREIGN KEY (end_half_id) REFERENCES Tbl_Half(id);
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE Tbl_Leave DROP CONSTRAINT IF EXISTS fk_leave_end_half;
+ALTER TABLE Tbl_Leave DROP CONSTRAINT IF EXISTS fk_leave_start_half;
+
+ALTER TABLE Tbl_Leave DROP COLUMN IF EXISTS end_half_id;
+ALTER TABLE Tbl_Leave DROP COLUMN IF EXISTS start_half_id;
+-- +goose StatementEnd
@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Employee gender (used by leave types restricted to a gender)
+ALTER TABLE Tbl_Employee ADD COLUMN IF NOT EXISTS gender VARCHAR(10) DEFAULT NULL;
+
+ALTER TABLE Tbl_Employee
+ADD CONSTRAINT chk_employee_gender CHECK (gender IS NULL OR gender IN ('MALE', 'FEMALE', 'OTHER'));
+
+-- 2️ Per-leave-type policy rules
+ALTER TABLE Tbl_Leave_type ADD COLUMN IF NOT EXISTS min_notice_days INT NOT NULL DEFAULT 0;
+ALTER TABLE Tbl_Leave_type ADD COLUMN IF NOT EXISTS max_consecutive_days NUMERIC DEFAULT NULL;            -- NULL = no limit
+ALTER TABLE Tbl_Leave_type ADD COLUMN IF NOT EXISTS allowed_genders VARCHAR(50) DEFAULT NULL;             -- comma separated, NULL = everyone
+ALTER TABLE Tbl_Leave_type ADD COLUMN IF NOT EXISTS min_tenure_months INT NOT NULL DEFAULT 0;
+ALTER TABLE Tbl_Leave_type ADD COLUMN IF NOT EXISTS attachment_required_after_days NUMERIC DEFAULT NULL;  -- NULL = never required
+ALTER TABLE Tbl_Leave_type ADD COLUMN IF NOT EXISTS allow_negative_balance BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE Tbl_Leave_type ADD COLUMN IF NOT EXISTS max_negative_balance NUMERIC NOT NULL DEFAULT 0;
+
+-- 3️ Company blackout periods (no leave can be applied within them)
+CREATE TABLE IF NOT EXISTS Tbl_Leave_blackout (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    leave_type_id INT DEFAULT NULL REFERENCES Tbl_Leave_type(id) ON DELETE CASCADE, -- NULL = all leave types
+    created_by UUID REFERENCES Tbl_Employee(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_blackout_dates CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_blackout_dates ON Tbl_Leave_blackout(start_date, end_date);
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS Tbl_Leave_blackout;
+
+ALTER TABLE Tbl_Leave_type DROP COLUMN IF EXISTS max_negative_balance;
+ALTER TABLE Tbl_Leave_type DROP COLUMN IF EXISTS allow_negative_balance;
+ALTER TABLE Tbl_Leave_type DROP COLUMN IF EXISTS attachment_required_after_days;
+ALTER TABLE Tbl_Leave_type DROP COLUMN IF EXISTS min_tenure_months;
+ALTER TABLE Tbl_Leave_type DROP COLUMN IF EXISTS allowed_genders;
+ALTER TABLE Tbl_Leave_type DROP COLUMN IF EXISTS max_consecutive_days;
+ALTER TABLE Tbl_Leave_type DROP COLUMN IF EXISTS min_notice_days;
+
+ALTER TABLE Tbl_Employee DROP CONSTRAINT IF EXISTS chk_employee_gender;
+ALTER TABLE Tbl_Employee DROP COLUMN IF EXISTS gender;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Employee department (used by the leave calendar and team coverage)
+ALTER TABLE Tbl_Employee ADD COLUMN IF NOT EXISTS department VARCHAR(100) DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_employee_department ON Tbl_Employee(department);
+
+-- 2️ Team coverage rule: warn (or block) when more than X% of a team would be absent on a day
+ALTER TABLE Tbl_Company_Settings ADD COLUMN IF NOT EXISTS coverage_threshold_percent NUMERIC NOT NULL DEFAULT 0; -- 0 = disabled
+ALTER TABLE Tbl_Company_Settings ADD COLUMN IF NOT EXISTS coverage_block BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE Tbl_Company_Settings DROP COLUMN IF EXISTS coverage_block;
+ALTER TABLE Tbl_Company_Settings DROP COLUMN IF EXISTS coverage_threshold_percent;
+
+DROP INDEX IF EXISTS idx_employee_department;
+ALTER TABLE Tbl_Employee DROP COLUMN IF EXISTS department;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Per-user secret token for subscribable ICS calendar feeds
+CREATE TABLE IF NOT EXISTS Tbl_Calendar_feed_token (
+    employee_id UUID PRIMARY KEY REFERENCES Tbl_Employee(id) ON DELETE CASCADE,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS Tbl_Calendar_feed_token;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Supporting documents (medical certificates etc.) uploaded for leave requests
+CREATE TABLE IF NOT EXISTS Tbl_Leave_attachment (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    leave_id UUID NOT NULL REFERENCES Tbl_Leave(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    file_path TEXT NOT NULL,
+    content_type VARCHAR(100) NOT NULL DEFAULT 'application/octet-stream',
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    uploaded_by UUID REFERENCES Tbl_Employee(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_attachment_leave ON Tbl_Leave_attachment(leave_id);
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_leave_attachment_leave;
+DROP TABLE IF EXISTS Tbl_Leave_attachment;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Leave type credited for approved comp-off and how long a credit stays valid
+ALTER TABLE Tbl_Company_Settings
+ADD COLUMN IF NOT EXISTS comp_off_leave_type_id INT DEFAULT NULL REFERENCES Tbl_Leave_type(id) ON DELETE SET NULL;
+ALTER TABLE Tbl_Company_Settings ADD COLUMN IF NOT EXISTS comp_off_validity_days INT NOT NULL DEFAULT 60;
+
+-- 2️ Comp-off requests for work done on weekends and holidays
+CREATE TABLE IF NOT EXISTS Tbl_Comp_off_request (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES Tbl_Employee(id),
+    work_date DATE NOT NULL,
+    days NUMERIC NOT NULL DEFAULT 1,
+    reason TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'Pending',
+    action_by UUID REFERENCES Tbl_Employee(id),
+    action_comment TEXT,
+    action_at TIMESTAMP,
+    leave_type_id INT REFERENCES Tbl_Leave_type(id),
+    expires_on DATE,
+    lapsed_days NUMERIC NOT NULL DEFAULT 0,
+    lapsed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_comp_off_status CHECK (status IN ('Pending', 'APPROVED', 'REJECTED', 'CANCELLED'))
+);
+
+-- One open or approved request per employee and worked date
+CREATE UNIQUE INDEX IF NOT EXISTS uq_comp_off_employee_date
+ON Tbl_Comp_off_request(employee_id, work_date)
+WHERE status IN ('Pending', 'APPROVED');
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS uq_comp_off_employee_date;
+DROP TABLE IF EXISTS Tbl_Comp_off_request;
+ALTER TABLE Tbl_Company_Settings DROP COLUMN IF EXISTS comp_off_validity_days;
+ALTER TABLE Tbl_Company_Settings DROP COLUMN IF EXISTS comp_off_leave_type_id;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Leaves created by splitting an approved leave point back to the original
+ALTER TABLE Tbl_Leave
+ADD COLUMN IF NOT EXISTS parent_leave_id UUID DEFAULT NULL REFERENCES Tbl_Leave(id);
+
+-- 2️ History of shortened/split approved leaves
+CREATE TABLE IF NOT EXISTS Tbl_Leave_modification (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    leave_id UUID NOT NULL REFERENCES Tbl_Leave(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL, -- SHORTEN / SPLIT
+    old_start_date DATE NOT NULL,
+    old_end_date DATE NOT NULL,
+    old_days NUMERIC NOT NULL,
+    old_hours NUMERIC,
+    new_periods TEXT NOT NULL,   -- e.g. "2026-10-05 to 2026-10-06 (2.0), 2026-10-09 to 2026-10-09 (1.0)"
+    new_days NUMERIC NOT NULL,
+    restored NUMERIC NOT NULL,   -- amount returned to the balance (leave type unit)
+    result_leave_ids UUID[] NOT NULL,
+    reason TEXT,
+    modified_by UUID REFERENCES Tbl_Employee(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_leave_modification_action CHECK (action IN ('SHORTEN', 'SPLIT'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_modification_leave ON Tbl_Leave_modification(leave_id);
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_leave_modification_leave;
+DROP TABLE IF EXISTS Tbl_Leave_modification;
+ALTER TABLE Tbl_Leave DROP COLUMN IF EXISTS parent_leave_id;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Who asked to withdraw/cancel an approved leave, and why (manager or employee request)
+ALTER TABLE Tbl_Leave ADD COLUMN IF NOT EXISTS withdrawal_requested_by UUID DEFAULT NULL REFERENCES Tbl_Employee(id);
+ALTER TABLE Tbl_Leave ADD COLUMN IF NOT EXISTS withdrawal_reason TEXT DEFAULT NULL;
+ALTER TABLE Tbl_Leave ADD COLUMN IF NOT EXISTS withdrawal_requested_at TIMESTAMP DEFAULT NULL;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE Tbl_Leave DROP COLUMN IF EXISTS withdrawal_requested_at;
+ALTER TABLE Tbl_Leave DROP COLUMN IF EXISTS withdrawal_reason;
+ALTER TABLE Tbl_Leave DROP COLUMN IF EXISTS withdrawal_requested_by;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ How a leave type is credited: NONE = full entitlement up front, otherwise in instalments
+ALTER TABLE Tbl_Leave_type ADD COLUMN IF NOT EXISTS accrual_frequency VARCHAR(20) NOT NULL DEFAULT 'NONE';
+
+ALTER TABLE Tbl_Leave_type
+ADD CONSTRAINT chk_leave_type_accrual_frequency CHECK (accrual_frequency IN ('NONE', 'MONTHLY', 'QUARTERLY', 'PAY_PERIOD'));
+
+-- 2️ Accrual ledger: one credit per employee, leave type and period
+CREATE TABLE IF NOT EXISTS Tbl_Leave_accrual (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES Tbl_Employee(id),
+    leave_type_id INT NOT NULL REFERENCES Tbl_Leave_type(id) ON DELETE CASCADE,
+    year INT NOT NULL,
+    frequency VARCHAR(20) NOT NULL,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    entitlement NUMERIC NOT NULL,          -- yearly entitlement the credit was computed from
+    proration NUMERIC NOT NULL DEFAULT 1,  -- share of the period the employee was employed (0-1)
+    amount NUMERIC NOT NULL,
+    created_by UUID REFERENCES Tbl_Employee(id), -- NULL = scheduler
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_leave_accrual_period UNIQUE (employee_id, leave_type_id, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_accrual_employee_year ON Tbl_Leave_accrual(employee_id, year);
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS Tbl_Leave_accrual;
+ALTER TABLE Tbl_Leave_type DROP CONSTRAINT IF EXISTS chk_leave_type_accrual_frequency;
+ALTER TABLE Tbl_Leave_type DROP COLUMN IF EXISTS accrual_frequency;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Year-end rules per leave type
+ALTER TABLE Tbl_Leave_type ADD COLUMN IF NOT EXISTS carry_forward_max NUMERIC NOT NULL DEFAULT 0; -- 0 = nothing carried forward
+ALTER TABLE Tbl_Leave_type ADD COLUMN IF NOT EXISTS encashable BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE Tbl_Leave_type ADD COLUMN IF NOT EXISTS encash_max NUMERIC DEFAULT NULL;           -- NULL = no limit
+
+-- 2️ Finalized year-end close, one per year
+CREATE TABLE IF NOT EXISTS Tbl_Year_end_close (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    year INT NOT NULL UNIQUE,
+    finalized_by UUID REFERENCES Tbl_Employee(id),
+    finalized_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 3️ Outcome per employee and leave type
+CREATE TABLE IF NOT EXISTS Tbl_Year_end_close_line (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    close_id UUID NOT NULL REFERENCES Tbl_Year_end_close(id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL REFERENCES Tbl_Employee(id),
+    leave_type_id INT NOT NULL REFERENCES Tbl_Leave_type(id),
+    closing NUMERIC NOT NULL,
+    carried_forward NUMERIC NOT NULL DEFAULT 0,
+    encashed NUMERIC NOT NULL DEFAULT 0,
+    lapsed NUMERIC NOT NULL DEFAULT 0,
+    encash_rate NUMERIC NOT NULL DEFAULT 0,
+    encash_amount NUMERIC NOT NULL DEFAULT 0,
+    payroll_run_id UUID REFERENCES Tbl_Payroll_run(id), -- set once paid out by payroll
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_year_end_line_pending ON Tbl_Year_end_close_line(employee_id) WHERE payroll_run_id IS NULL AND encash_amount > 0;
+
+-- 4️ Leave encashment paid with a payslip
+ALTER TABLE Tbl_Payslip ADD COLUMN IF NOT EXISTS encashment_amount NUMERIC NOT NULL DEFAULT 0;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE Tbl_Payslip DROP COLUMN IF EXISTS encashment_amount;
+DROP TABLE IF EXISTS Tbl_Year_end_close_line;
+DROP TABLE IF EXISTS Tbl_Year_end_close;
+ALTER TABLE Tbl_Leave_type DROP COLUMN IF EXISTS encash_max;
+ALTER TABLE Tbl_Leave_type DROP COLUMN IF EXISTS encashable;
+ALTER TABLE Tbl_Leave_type DROP COLUMN IF EXISTS carry_forward_max;
+-- +goose StatementEnd
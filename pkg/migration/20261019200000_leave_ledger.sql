@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Every balance movement; a balance's closing equals the sum of its entries
+CREATE TABLE IF NOT EXISTS Tbl_Leave_ledger (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES Tbl_Employee(id),
+    leave_type_id INT NOT NULL REFERENCES Tbl_Leave_type(id),
+    year INT NOT NULL,
+    entry_type VARCHAR(20) NOT NULL,
+    quantity NUMERIC NOT NULL,                                    -- signed effect on closing
+    leave_id UUID REFERENCES Tbl_Leave(id),                       -- consumption/restoration source
+    adjustment_id UUID REFERENCES Tbl_Leave_adjustment(id),       -- adjustment source
+    reference TEXT,                                               -- other sources (accrual period, year-end close)
+    note TEXT,
+    created_by UUID REFERENCES Tbl_Employee(id),                  -- NULL = system
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_leave_ledger_entry_type CHECK (entry_type IN
+        ('GRANT', 'ACCRUAL', 'CONSUMPTION', 'RESTORATION', 'ADJUSTMENT', 'CARRY_FORWARD', 'LAPSE', 'ENCASHMENT'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_ledger_balance ON Tbl_Leave_ledger(employee_id, leave_type_id, year);
+
+-- 2️ Ledger entries are immutable
+CREATE OR REPLACE FUNCTION fn_leave_ledger_immutable() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'Tbl_Leave_ledger entries are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_leave_ledger_immutable
+BEFORE UPDATE OR DELETE ON Tbl_Leave_ledger
+FOR EACH ROW EXECUTE FUNCTION fn_leave_ledger_immutable();
+
+-- 3️ Opening entries for existing balances, taken from their current totals
+INSERT INTO Tbl_Leave_ledger (employee_id, leave_type_id, year, entry_type, quantity, note)
+SELECT employee_id, leave_type_id, year, t.entry_type, t.quantity, 'Migrated from balance totals'
+FROM Tbl_Leave_balance b
+CROSS JOIN LATERAL (VALUES
+    ('GRANT', COALESCE(b.opening, 0)),
+    ('ACCRUAL', COALESCE(b.accrued, 0)),
+    ('CONSUMPTION', -COALESCE(b.used, 0)),
+    ('ADJUSTMENT', COALESCE(b.adjusted, 0))
+) AS t(entry_type, quantity)
+WHERE b.year IS NOT NULL AND t.quantity <> 0;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_leave_ledger_immutable ON Tbl_Leave_ledger;
+DROP FUNCTION IF EXISTS fn_leave_ledger_immutable();
+DROP TABLE IF EXISTS Tbl_Leave_ledger;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Bulk balance adjustments applied to a filtered set of employees
+CREATE TABLE IF NOT EXISTS Tbl_Leave_adjustment_batch (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    leave_type_id INT NOT NULL REFERENCES Tbl_Leave_type(id),
+    year INT NOT NULL,
+    quantity NUMERIC NOT NULL,                      -- +ve or -ve, per employee
+    reason TEXT NOT NULL,
+    filter_type VARCHAR(20) NOT NULL,               -- ALL, DEPARTMENT, DESIGNATION, ROLE or EMPLOYEES
+    filter_value TEXT,
+    employee_count INT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'APPLIED',  -- APPLIED or REVERSED
+    created_by UUID REFERENCES Tbl_Employee(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    reversed_by UUID REFERENCES Tbl_Employee(id),
+    reversed_at TIMESTAMP,
+    reversal_reason TEXT
+);
+
+-- 2️ Adjustments made by a batch, and the adjustment a reversal undoes
+ALTER TABLE Tbl_Leave_adjustment ADD COLUMN IF NOT EXISTS batch_id UUID REFERENCES Tbl_Leave_adjustment_batch(id);
+ALTER TABLE Tbl_Leave_adjustment ADD COLUMN IF NOT EXISTS reversal_of UUID REFERENCES Tbl_Leave_adjustment(id);
+
+CREATE INDEX IF NOT EXISTS idx_leave_adjustment_batch ON Tbl_Leave_adjustment(batch_id) WHERE batch_id IS NOT NULL;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_leave_adjustment_batch;
+ALTER TABLE Tbl_Leave_adjustment DROP COLUMN IF EXISTS reversal_of;
+ALTER TABLE Tbl_Leave_adjustment DROP COLUMN IF EXISTS batch_id;
+DROP TABLE IF EXISTS Tbl_Leave_adjustment_batch;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Employment type, used by entitlement rules
+ALTER TABLE Tbl_Employee ADD COLUMN IF NOT EXISTS employment_type VARCHAR(20) NOT NULL DEFAULT 'FULL_TIME';
+ALTER TABLE Tbl_Employee ADD CONSTRAINT chk_employee_employment_type
+    CHECK (employment_type IN ('FULL_TIME', 'PART_TIME', 'CONTRACT', 'INTERN'));
+
+-- 2️ Entitlement tiers of a leave type. The most specific matching rule wins (designation and
+--    employment type matches first, then the highest tenure band); default_entitlement applies
+--    when no rule matches
+CREATE TABLE IF NOT EXISTS Tbl_Leave_entitlement_rule (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    leave_type_id INT NOT NULL REFERENCES Tbl_Leave_type(id) ON DELETE CASCADE,
+    min_tenure_months INT NOT NULL DEFAULT 0,          -- completed months since joining_date
+    max_tenure_months INT,                             -- exclusive, NULL = no upper bound
+    designation_id UUID REFERENCES Tbl_Designation(id) ON DELETE CASCADE, -- NULL = any designation
+    employment_type VARCHAR(20),                       -- NULL = any employment type
+    entitlement NUMERIC NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_entitlement_rule_tenure CHECK (min_tenure_months >= 0 AND (max_tenure_months IS NULL OR max_tenure_months > min_tenure_months)),
+    CONSTRAINT chk_entitlement_rule_value CHECK (entitlement >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_entitlement_rule_type ON Tbl_Leave_entitlement_rule(leave_type_id);
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS Tbl_Leave_entitlement_rule;
+ALTER TABLE Tbl_Employee DROP CONSTRAINT IF EXISTS chk_employee_employment_type;
+ALTER TABLE Tbl_Employee DROP COLUMN IF EXISTS employment_type;
+-- +goose StatementEnd
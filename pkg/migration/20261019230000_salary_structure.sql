@@ -0,0 +1,65 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Salary structures: a named set of earning and deduction components
+CREATE TABLE IF NOT EXISTS Tbl_Salary_structure (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 2️ Components of a structure. Monthly amounts are a fixed amount, a percentage of the sum of
+--    other components (or CTC, the employee's monthly salary), or a formula over them
+CREATE TABLE IF NOT EXISTS Tbl_Salary_component (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    structure_id UUID NOT NULL REFERENCES Tbl_Salary_structure(id) ON DELETE CASCADE,
+    code VARCHAR(30) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    calc_type VARCHAR(20) NOT NULL,
+    amount NUMERIC,                         -- FIXED
+    percentage NUMERIC,                     -- PERCENTAGE
+    base_codes TEXT[],                      -- PERCENTAGE
+    formula TEXT,                           -- FORMULA
+    prorate BOOLEAN NOT NULL DEFAULT TRUE,  -- earnings reduced for unpaid leave days
+    sort_order INT NOT NULL DEFAULT 0,
+    CONSTRAINT uq_salary_component_code UNIQUE (structure_id, code),
+    CONSTRAINT chk_salary_component_kind CHECK (kind IN ('EARNING', 'DEDUCTION')),
+    CONSTRAINT chk_salary_component_calc_type CHECK (calc_type IN ('FIXED', 'PERCENTAGE', 'FORMULA'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_salary_component_structure ON Tbl_Salary_component(structure_id);
+
+-- 3️ Assignment: the employee's own structure, else the designation's
+ALTER TABLE Tbl_Employee ADD COLUMN IF NOT EXISTS salary_structure_id UUID
+    REFERENCES Tbl_Salary_structure(id) ON DELETE SET NULL;
+ALTER TABLE Tbl_Designation ADD COLUMN IF NOT EXISTS salary_structure_id UUID
+    REFERENCES Tbl_Salary_structure(id) ON DELETE SET NULL;
+
+-- 4️ Per-component line items of a payslip
+CREATE TABLE IF NOT EXISTS Tbl_Payslip_line (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payslip_id UUID NOT NULL REFERENCES Tbl_Payslip(id) ON DELETE CASCADE,
+    code VARCHAR(30) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    amount NUMERIC NOT NULL DEFAULT 0,
+    sort_order INT NOT NULL DEFAULT 0,
+    CONSTRAINT chk_payslip_line_kind CHECK (kind IN ('EARNING', 'DEDUCTION'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_payslip_line_payslip ON Tbl_Payslip_line(payslip_id);
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS Tbl_Payslip_line;
+ALTER TABLE Tbl_Designation DROP COLUMN IF EXISTS salary_structure_id;
+ALTER TABLE Tbl_Employee DROP COLUMN IF EXISTS salary_structure_id;
+DROP TABLE IF EXISTS Tbl_Salary_component;
+DROP TABLE IF EXISTS Tbl_Salary_structure;
+-- +goose StatementEnd
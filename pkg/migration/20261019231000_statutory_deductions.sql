@@ -0,0 +1,100 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Statutory rules as versioned data. A rule applies to payslips of months starting on or
+--    after effective_from until a newer version of the same kind and code takes over.
+--    code is the tax regime for TDS (NEW/OLD), the state for PT and DEFAULT for EPF and ESI
+CREATE TABLE IF NOT EXISTS Tbl_Statutory_rule (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(10) NOT NULL,
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    effective_from DATE NOT NULL,
+    config JSONB NOT NULL,
+    created_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_statutory_rule_version UNIQUE (kind, code, effective_from),
+    CONSTRAINT chk_statutory_rule_kind CHECK (kind IN ('TDS', 'EPF', 'ESI', 'PT'))
+);
+
+-- 2️ Statutory profile of an employee
+ALTER TABLE Tbl_Employee ADD COLUMN IF NOT EXISTS tax_regime VARCHAR(10) NOT NULL DEFAULT 'NEW';
+ALTER TABLE Tbl_Employee ADD COLUMN IF NOT EXISTS pt_state VARCHAR(20);          -- NULL = no professional tax
+ALTER TABLE Tbl_Employee ADD COLUMN IF NOT EXISTS pf_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- 3️ Employer contributions are listed on the payslip without reducing net pay
+ALTER TABLE Tbl_Payslip_line DROP CONSTRAINT IF EXISTS chk_payslip_line_kind;
+ALTER TABLE Tbl_Payslip_line ADD CONSTRAINT chk_payslip_line_kind
+    CHECK (kind IN ('EARNING', 'DEDUCTION', 'EMPLOYER'));
+
+-- 4️ Rules in force for FY 2025-26
+INSERT INTO Tbl_Statutory_rule (kind, code, name, effective_from, config) VALUES
+('TDS', 'NEW', 'Income tax - new regime', '2025-04-01', '{
+    "standard_deduction": 75000,
+    "slabs": [
+        {"from": 0, "to": 400000, "rate": 0},
+        {"from": 400000, "to": 800000, "rate": 5},
+        {"from": 800000, "to": 1200000, "rate": 10},
+        {"from": 1200000, "to": 1600000, "rate": 15},
+        {"from": 1600000, "to": 2000000, "rate": 20},
+        {"from": 2000000, "to": 2400000, "rate": 25},
+        {"from": 2400000, "rate": 30}
+    ],
+    "rebate_limit": 1200000,
+    "rebate_max": 60000,
+    "cess_percent": 4
+}'),
+('TDS', 'OLD', 'Income tax - old regime', '2025-04-01', '{
+    "standard_deduction": 50000,
+    "slabs": [
+        {"from": 0, "to": 250000, "rate": 0},
+        {"from": 250000, "to": 500000, "rate": 5},
+        {"from": 500000, "to": 1000000, "rate": 20},
+        {"from": 1000000, "rate": 30}
+    ],
+    "rebate_limit": 500000,
+    "rebate_max": 12500,
+    "cess_percent": 4,
+    "section_80c_limit": 150000
+}'),
+('EPF', 'DEFAULT', 'Employees'' Provident Fund', '2025-04-01', '{
+    "wage_codes": ["BASIC", "DA"],
+    "employee_rate": 12,
+    "employer_rate": 12,
+    "eps_rate": 8.33,
+    "wage_ceiling": 15000,
+    "restrict_to_ceiling": true
+}'),
+('ESI', 'DEFAULT', 'Employees'' State Insurance', '2025-04-01', '{
+    "employee_rate": 0.75,
+    "employer_rate": 3.25,
+    "wage_threshold": 21000
+}'),
+('PT', 'MH', 'Professional tax - Maharashtra', '2025-04-01', '{
+    "slabs": [
+        {"min": 0, "max": 7500, "amount": 0},
+        {"min": 7500.01, "max": 10000, "amount": 175},
+        {"min": 10000.01, "amount": 200, "month_amounts": {"2": 300}}
+    ]
+}'),
+('PT', 'KA', 'Professional tax - Karnataka', '2025-04-01', '{
+    "slabs": [
+        {"min": 0, "max": 24999.99, "amount": 0},
+        {"min": 25000, "amount": 200, "month_amounts": {"2": 300}}
+    ]
+}')
+ON CONFLICT (kind, code, effective_from) DO NOTHING;
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM Tbl_Payslip_line WHERE kind = 'EMPLOYER';
+ALTER TABLE Tbl_Payslip_line DROP CONSTRAINT IF EXISTS chk_payslip_line_kind;
+ALTER TABLE Tbl_Payslip_line ADD CONSTRAINT chk_payslip_line_kind CHECK (kind IN ('EARNING', 'DEDUCTION'));
+ALTER TABLE Tbl_Employee DROP COLUMN IF EXISTS pf_enabled;
+ALTER TABLE Tbl_Employee DROP COLUMN IF EXISTS pt_state;
+ALTER TABLE Tbl_Employee DROP COLUMN IF EXISTS tax_regime;
+DROP TABLE IF EXISTS Tbl_Statutory_rule;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Earlier previews inserted a new run on every call: keep only the latest open run of a
+--    month, and none where the month is already finalized
+DELETE FROM Tbl_Payslip_line
+WHERE payslip_id IN (
+    SELECT p.id FROM Tbl_Payslip p
+    JOIN Tbl_Payroll_run r ON r.id = p.payroll_run_id
+    WHERE r.status = 'PREVIEW'
+);
+DELETE FROM Tbl_Payslip
+WHERE payroll_run_id IN (SELECT id FROM Tbl_Payroll_run WHERE status = 'PREVIEW');
+
+DELETE FROM Tbl_Payroll_run r
+WHERE r.status = 'PREVIEW'
+AND EXISTS (
+    SELECT 1 FROM Tbl_Payroll_run o
+    WHERE o.month = r.month AND o.year = r.year AND o.id <> r.id
+    AND (o.status <> 'PREVIEW' OR o.created_at > r.created_at
+         OR (o.created_at = r.created_at AND o.id > r.id))
+);
+
+-- 2️ One open (draft) run per month; re-running payroll refreshes its draft payslips
+CREATE UNIQUE INDEX IF NOT EXISTS uq_payroll_run_open ON Tbl_Payroll_run(month, year) WHERE status = 'PREVIEW';
+
+-- 3️ Draft payslips remember the leave records they were computed from, so finalizing can
+--    flag leaves approved, cancelled or edited since the preview. Half days need decimals.
+ALTER TABLE Tbl_Payslip ADD COLUMN IF NOT EXISTS leave_fingerprint TEXT;
+ALTER TABLE Tbl_Payslip ALTER COLUMN paid_leaves TYPE NUMERIC;
+ALTER TABLE Tbl_Payslip ALTER COLUMN unpaid_leaves TYPE NUMERIC;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE Tbl_Payslip ALTER COLUMN unpaid_leaves TYPE INT USING ROUND(unpaid_leaves);
+ALTER TABLE Tbl_Payslip ALTER COLUMN paid_leaves TYPE INT USING ROUND(paid_leaves);
+ALTER TABLE Tbl_Payslip DROP COLUMN IF EXISTS leave_fingerprint;
+DROP INDEX IF EXISTS uq_payroll_run_open;
+
+-- +goose StatementEnd
@@ -0,0 +1,61 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Maker-checker run states: DRAFT (was PREVIEW) -> SUBMITTED -> APPROVED -> FINALIZED -> PAID.
+--    A rejected run goes back to DRAFT.
+UPDATE Tbl_Payroll_run SET status = 'DRAFT' WHERE status = 'PREVIEW';
+ALTER TABLE Tbl_Payroll_run ADD CONSTRAINT chk_payroll_run_status
+    CHECK (status IN ('DRAFT', 'SUBMITTED', 'APPROVED', 'FINALIZED', 'PAID'));
+
+-- 2️ Still one open run per month while it is being reviewed
+DROP INDEX IF EXISTS uq_payroll_run_open;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_payroll_run_open ON Tbl_Payroll_run(month, year)
+    WHERE status IN ('DRAFT', 'SUBMITTED', 'APPROVED');
+
+-- 3️ Who prepared, submitted, approved, finalized and paid the run
+ALTER TABLE Tbl_Payroll_run ADD COLUMN IF NOT EXISTS prepared_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL;
+ALTER TABLE Tbl_Payroll_run ADD COLUMN IF NOT EXISTS submitted_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL;
+ALTER TABLE Tbl_Payroll_run ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMP;
+ALTER TABLE Tbl_Payroll_run ADD COLUMN IF NOT EXISTS approved_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL;
+ALTER TABLE Tbl_Payroll_run ADD COLUMN IF NOT EXISTS approved_at TIMESTAMP;
+ALTER TABLE Tbl_Payroll_run ADD COLUMN IF NOT EXISTS finalized_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL;
+ALTER TABLE Tbl_Payroll_run ADD COLUMN IF NOT EXISTS finalized_at TIMESTAMP;
+ALTER TABLE Tbl_Payroll_run ADD COLUMN IF NOT EXISTS paid_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL;
+ALTER TABLE Tbl_Payroll_run ADD COLUMN IF NOT EXISTS paid_at TIMESTAMP;
+
+-- 4️ Every transition of a run with the reviewer's comments
+CREATE TABLE IF NOT EXISTS Tbl_Payroll_run_action (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payroll_run_id UUID NOT NULL REFERENCES Tbl_Payroll_run(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    actor_id UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    comments TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_payroll_run_action_run ON Tbl_Payroll_run_action(payroll_run_id, created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS Tbl_Payroll_run_action;
+ALTER TABLE Tbl_Payroll_run
+    DROP COLUMN IF EXISTS prepared_by,
+    DROP COLUMN IF EXISTS submitted_by,
+    DROP COLUMN IF EXISTS submitted_at,
+    DROP COLUMN IF EXISTS approved_by,
+    DROP COLUMN IF EXISTS approved_at,
+    DROP COLUMN IF EXISTS finalized_by,
+    DROP COLUMN IF EXISTS finalized_at,
+    DROP COLUMN IF EXISTS paid_by,
+    DROP COLUMN IF EXISTS paid_at;
+ALTER TABLE Tbl_Payroll_run DROP CONSTRAINT IF EXISTS chk_payroll_run_status;
+UPDATE Tbl_Payroll_run SET status = 'FINALIZED' WHERE status = 'PAID';
+UPDATE Tbl_Payroll_run SET status = 'PREVIEW' WHERE status IN ('DRAFT', 'SUBMITTED', 'APPROVED');
+DROP INDEX IF EXISTS uq_payroll_run_open;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_payroll_run_open ON Tbl_Payroll_run(month, year) WHERE status = 'PREVIEW';
+
+-- +goose StatementEnd
@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Corrections queued for withdrawn payslips, settled by a CORRECTION payslip in a later run.
+--    payslip_id is the original (REGULAR) payslip, month/year its pay period.
+CREATE TABLE IF NOT EXISTS Tbl_Payslip_correction (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payslip_id UUID NOT NULL REFERENCES Tbl_Payslip(id),
+    employee_id UUID NOT NULL REFERENCES Tbl_Employee(id),
+    month INT NOT NULL,
+    year INT NOT NULL,
+    reason TEXT NOT NULL,
+    created_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_payslip_correction_payslip ON Tbl_Payslip_correction(payslip_id);
+
+-- 2️ Withdrawn payslips are kept for audit and left out of year-to-date totals
+ALTER TABLE Tbl_Payslip ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'ISSUED';
+ALTER TABLE Tbl_Payslip ADD COLUMN IF NOT EXISTS withdrawn_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL;
+ALTER TABLE Tbl_Payslip ADD COLUMN IF NOT EXISTS withdrawn_at TIMESTAMP;
+ALTER TABLE Tbl_Payslip ADD COLUMN IF NOT EXISTS withdrawal_reason TEXT;
+ALTER TABLE Tbl_Payslip ADD CONSTRAINT chk_payslip_status CHECK (status IN ('ISSUED', 'WITHDRAWN'));
+
+-- 3️ A run pays one regular payslip per employee plus any correction (arrears) payslips
+ALTER TABLE Tbl_Payslip ADD COLUMN IF NOT EXISTS payslip_type VARCHAR(20) NOT NULL DEFAULT 'REGULAR';
+ALTER TABLE Tbl_Payslip ADD COLUMN IF NOT EXISTS correction_id UUID REFERENCES Tbl_Payslip_correction(id);
+ALTER TABLE Tbl_Payslip ADD CONSTRAINT chk_payslip_type CHECK (
+    (payslip_type = 'REGULAR' AND correction_id IS NULL) OR (payslip_type = 'CORRECTION' AND correction_id IS NOT NULL)
+);
+ALTER TABLE Tbl_Payslip DROP CONSTRAINT IF EXISTS uq_payroll_employee;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_payslip_regular ON Tbl_Payslip(payroll_run_id, employee_id) WHERE payslip_type = 'REGULAR';
+CREATE UNIQUE INDEX IF NOT EXISTS uq_payslip_correction ON Tbl_Payslip(payroll_run_id, correction_id) WHERE correction_id IS NOT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DELETE FROM Tbl_Payslip WHERE payslip_type = 'CORRECTION';
+DROP INDEX IF EXISTS uq_payslip_correction;
+DROP INDEX IF EXISTS uq_payslip_regular;
+ALTER TABLE Tbl_Payslip ADD CONSTRAINT uq_payroll_employee UNIQUE (payroll_run_id, employee_id);
+ALTER TABLE Tbl_Payslip DROP CONSTRAINT IF EXISTS chk_payslip_type;
+ALTER TABLE Tbl_Payslip DROP CONSTRAINT IF EXISTS chk_payslip_status;
+ALTER TABLE Tbl_Payslip
+    DROP COLUMN IF EXISTS correction_id,
+    DROP COLUMN IF EXISTS payslip_type,
+    DROP COLUMN IF EXISTS withdrawal_reason,
+    DROP COLUMN IF EXISTS withdrawn_at,
+    DROP COLUMN IF EXISTS withdrawn_by,
+    DROP COLUMN IF EXISTS status;
+DROP TABLE IF EXISTS Tbl_Payslip_correction;
+
+-- +goose StatementEnd
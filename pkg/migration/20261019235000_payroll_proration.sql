@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Basis the salary of a joining or exit month is prorated on
+ALTER TABLE Tbl_Company_Settings ADD COLUMN IF NOT EXISTS payroll_proration_basis VARCHAR(20) NOT NULL DEFAULT 'CALENDAR_DAYS';
+ALTER TABLE Tbl_Company_Settings ADD CONSTRAINT chk_payroll_proration_basis CHECK (payroll_proration_basis IN ('CALENDAR_DAYS', 'WORKING_DAYS'));
+
+-- 2️ Proration of a payslip; NULL when the employee was employed the whole month
+ALTER TABLE Tbl_Payslip ADD COLUMN IF NOT EXISTS proration_basis VARCHAR(20);
+ALTER TABLE Tbl_Payslip ADD COLUMN IF NOT EXISTS payable_days NUMERIC;
+ALTER TABLE Tbl_Payslip ADD COLUMN IF NOT EXISTS period_days NUMERIC;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE Tbl_Payslip DROP COLUMN IF EXISTS period_days;
+ALTER TABLE Tbl_Payslip DROP COLUMN IF EXISTS payable_days;
+ALTER TABLE Tbl_Payslip DROP COLUMN IF EXISTS proration_basis;
+ALTER TABLE Tbl_Company_Settings DROP CONSTRAINT IF EXISTS chk_payroll_proration_basis;
+ALTER TABLE Tbl_Company_Settings DROP COLUMN IF EXISTS payroll_proration_basis;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Days a month's salary is divided by for the per-day rate: working_days_per_month (FIXED),
+--    the month's weekdays less holidays (WORKING_DAYS) or its calendar days (CALENDAR_DAYS)
+ALTER TABLE Tbl_Company_Settings ADD COLUMN IF NOT EXISTS payroll_day_rate_basis VARCHAR(20) NOT NULL DEFAULT 'FIXED';
+ALTER TABLE Tbl_Company_Settings ADD CONSTRAINT chk_payroll_day_rate_basis CHECK (payroll_day_rate_basis IN ('FIXED', 'WORKING_DAYS', 'CALENDAR_DAYS'));
+
+-- 2️ Basis of a payslip's working_days; NULL for payslips computed before it was configurable (FIXED)
+ALTER TABLE Tbl_Payslip ADD COLUMN IF NOT EXISTS day_rate_basis VARCHAR(20);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE Tbl_Payslip DROP COLUMN IF EXISTS day_rate_basis;
+ALTER TABLE Tbl_Company_Settings DROP CONSTRAINT IF EXISTS chk_payroll_day_rate_basis;
+ALTER TABLE Tbl_Company_Settings DROP COLUMN IF EXISTS payroll_day_rate_basis;
+-- +goose StatementEnd
@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- 1️ Accounts employees' salaries are paid to
+CREATE TABLE IF NOT EXISTS Tbl_Employee_bank_account (
+    employee_id UUID PRIMARY KEY REFERENCES Tbl_Employee(id) ON DELETE CASCADE,
+    account_holder_name VARCHAR(100) NOT NULL,
+    account_number VARCHAR(34) NOT NULL,
+    account_type VARCHAR(10) NOT NULL DEFAULT 'SAVINGS' CHECK (account_type IN ('SAVINGS', 'CHECKING')),
+    bank_name VARCHAR(100),
+    ifsc_code VARCHAR(11),      -- NEFT
+    routing_number VARCHAR(9),  -- NACHA (ABA)
+    iban VARCHAR(34),           -- pain.001
+    bic VARCHAR(11),
+    updated_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 2️ The single company account salaries are paid from
+CREATE TABLE IF NOT EXISTS Tbl_Company_bank_account (
+    id INT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    account_holder_name VARCHAR(100) NOT NULL,
+    account_number VARCHAR(34) NOT NULL,
+    account_type VARCHAR(10) NOT NULL DEFAULT 'CHECKING' CHECK (account_type IN ('SAVINGS', 'CHECKING')),
+    bank_name VARCHAR(100),
+    ifsc_code VARCHAR(11),
+    routing_number VARCHAR(9),
+    iban VARCHAR(34),
+    bic VARCHAR(11),
+    ach_company_id VARCHAR(10),
+    currency VARCHAR(3) NOT NULL DEFAULT 'INR',
+    updated_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 3️ Bank payment files generated for payroll runs, kept for download and audit
+CREATE TABLE IF NOT EXISTS Tbl_Payroll_bank_file (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payroll_run_id UUID NOT NULL REFERENCES Tbl_Payroll_run(id) ON DELETE CASCADE,
+    format VARCHAR(20) NOT NULL CHECK (format IN ('CSV', 'NACHA', 'PAIN001', 'NEFT')),
+    file_name VARCHAR(100) NOT NULL,
+    content TEXT NOT NULL,
+    entry_count INT NOT NULL,
+    control_total NUMERIC NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    generated_by UUID REFERENCES Tbl_Employee(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_payroll_bank_file_run ON Tbl_Payroll_bank_file(payroll_run_id);
+
+-- 4️ Reference of the transfer that paid a payslip
+ALTER TABLE Tbl_Payslip ADD COLUMN IF NOT EXISTS payment_reference VARCHAR(35);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE Tbl_Payslip DROP COLUMN IF EXISTS payment_reference;
+DROP TABLE IF EXISTS Tbl_Payroll_bank_file;
+DROP TABLE IF EXISTS Tbl_Company_bank_account;
+DROP TABLE IF EXISTS Tbl_Employee_bank_account;
+-- +goose StatementEnd
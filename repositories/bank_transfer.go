@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+const bankAccountColumns = `account_holder_name, account_number, account_type, bank_name, ifsc_code,
+	routing_number, iban, bic`
+
+// GetEmployeeBankAccount returns the account an employee's salary is paid to
+func (r *Repository) GetEmployeeBankAccount(employeeID uuid.UUID) (models.EmployeeBankAccount, error) {
+	var account models.EmployeeBankAccount
+	err := r.DB.Get(&account, `
+		SELECT employee_id, `+bankAccountColumns+`, updated_by, updated_at
+		FROM Tbl_Employee_bank_account
+		WHERE employee_id = $1
+	`, employeeID)
+	return account, err
+}
+
+// UpsertEmployeeBankAccountTx creates or replaces the account an employee's salary is paid to
+func (r *Repository) UpsertEmployeeBankAccountTx(tx *sqlx.Tx, employeeID uuid.UUID, a models.BankAccount, updatedBy uuid.UUID) error {
+	_, err := tx.Exec(`
+		INSERT INTO Tbl_Employee_bank_account
+		(employee_id, `+bankAccountColumns+`, updated_by, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,NOW())
+		ON CONFLICT (employee_id) DO UPDATE SET
+			account_holder_name = EXCLUDED.account_holder_name, account_number = EXCLUDED.account_number,
+			account_type = EXCLUDED.account_type, bank_name = EXCLUDED.bank_name, ifsc_code = EXCLUDED.ifsc_code,
+			routing_number = EXCLUDED.routing_number, iban = EXCLUDED.iban, bic = EXCLUDED.bic,
+			updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`, employeeID, a.AccountHolderName, a.AccountNumber, a.AccountType, a.BankName, a.IFSCCode,
+		a.RoutingNumber, a.IBAN, a.BIC, updatedBy)
+	return err
+}
+
+// GetCompanyBankAccount returns the account salaries are paid from
+func (r *Repository) GetCompanyBankAccount() (models.CompanyBankAccount, error) {
+	var account models.CompanyBankAccount
+	err := r.DB.Get(&account, `
+		SELECT `+bankAccountColumns+`, ach_company_id, currency, updated_by, updated_at
+		FROM Tbl_Company_bank_account
+		WHERE id = 1
+	`)
+	return account, err
+}
+
+// UpsertCompanyBankAccountTx creates or replaces the account salaries are paid from
+func (r *Repository) UpsertCompanyBankAccountTx(tx *sqlx.Tx, a models.CompanyBankAccount, updatedBy uuid.UUID) error {
+	_, err := tx.Exec(`
+		INSERT INTO Tbl_Company_bank_account
+		(id, `+bankAccountColumns+`, ach_company_id, currency, updated_by, updated_at)
+		VALUES (1,$1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			account_holder_name = EXCLUDED.account_holder_name, account_number = EXCLUDED.account_number,
+			account_type = EXCLUDED.account_type, bank_name = EXCLUDED.bank_name, ifsc_code = EXCLUDED.ifsc_code,
+			routing_number = EXCLUDED.routing_number, iban = EXCLUDED.iban, bic = EXCLUDED.bic,
+			ach_company_id = EXCLUDED.ach_company_id, currency = EXCLUDED.currency,
+			updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`, a.AccountHolderName, a.AccountNumber, a.AccountType, a.BankName, a.IFSCCode,
+		a.RoutingNumber, a.IBAN, a.BIC, a.ACHCompanyID, a.Currency, updatedBy)
+	return err
+}
+
+// runPayslipPaymentsSQL selects the issued payslips of a run with their employee's bank account
+const runPayslipPaymentsSQL = `
+	SELECT p.id AS payslip_id, p.employee_id, e.full_name, COALESCE(p.net_salary, 0) AS net_salary,
+		ba.employee_id IS NOT NULL AS has_account,
+		COALESCE(ba.account_holder_name, '') AS account_holder_name,
+		COALESCE(ba.account_number, '') AS account_number,
+		COALESCE(ba.account_type, '') AS account_type,
+		ba.bank_name, ba.ifsc_code, ba.routing_number, ba.iban, ba.bic
+	FROM Tbl_Payslip p
+	JOIN Tbl_Employee e ON e.id = p.employee_id
+	LEFT JOIN Tbl_Employee_bank_account ba ON ba.employee_id = p.employee_id
+	WHERE p.payroll_run_id = $1 AND p.status = 'ISSUED'`
+
+// GetRunPayslipPayments returns the issued payslips of a payroll run with bank accounts
+func (r *Repository) GetRunPayslipPayments(runID uuid.UUID) ([]models.RunPayslipPayment, error) {
+	payslips := []models.RunPayslipPayment{}
+	err := r.DB.Select(&payslips, runPayslipPaymentsSQL+`
+		ORDER BY e.full_name, p.id
+	`, runID)
+	return payslips, err
+}
+
+// GetRunPayslipPaymentsTx locks and returns the issued payslips of a payroll run with bank
+// accounts
+func (r *Repository) GetRunPayslipPaymentsTx(tx *sqlx.Tx, runID uuid.UUID) ([]models.RunPayslipPayment, error) {
+	payslips := []models.RunPayslipPayment{}
+	err := tx.Select(&payslips, runPayslipPaymentsSQL+`
+		ORDER BY e.full_name, p.id
+		FOR UPDATE OF p
+	`, runID)
+	return payslips, err
+}
+
+// SetPayslipPaymentReferenceTx records the transfer that paid payslips
+func (r *Repository) SetPayslipPaymentReferenceTx(tx *sqlx.Tx, payslipIDs []uuid.UUID, reference string) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Payslip
+		SET payment_reference = $2, updated_at = NOW()
+		WHERE id = ANY($1)
+	`, pq.Array(payslipIDs), reference)
+	return err
+}
+
+// InsertBankFileTx stores a generated bank payment file
+func (r *Repository) InsertBankFileTx(tx *sqlx.Tx, f models.BankFile) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.Get(&id, `
+		INSERT INTO Tbl_Payroll_bank_file
+		(payroll_run_id, format, file_name, content, entry_count, control_total, checksum, generated_by)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		RETURNING id
+	`, f.PayrollRunID, f.Format, f.FileName, f.Content, f.EntryCount, f.ControlTotal, f.Checksum, f.GeneratedBy)
+	return id, err
+}
+
+// GetRunBankFiles returns the bank files generated for a payroll run, without their content
+func (r *Repository) GetRunBankFiles(runID uuid.UUID) ([]models.BankFile, error) {
+	files := []models.BankFile{}
+	err := r.DB.Select(&files, `
+		SELECT id, payroll_run_id, format, file_name, '' AS content, entry_count, control_total,
+			checksum, generated_by, created_at
+		FROM Tbl_Payroll_bank_file
+		WHERE payroll_run_id = $1
+		ORDER BY created_at
+	`, runID)
+	return files, err
+}
+
+// GetBankFile returns a generated bank payment file
+func (r *Repository) GetBankFile(id uuid.UUID) (models.BankFile, error) {
+	var f models.BankFile
+	err := r.DB.Get(&f, `
+		SELECT id, payroll_run_id, format, file_name, content, entry_count, control_total,
+			checksum, generated_by, created_at
+		FROM Tbl_Payroll_bank_file
+		WHERE id = $1
+	`, id)
+	return f, err
+}
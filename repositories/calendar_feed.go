@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// GetCalendarFeedToken returns the calendar feed token of an employee
+func (r *Repository) GetCalendarFeedToken(employeeID uuid.UUID) (string, error) {
+	var token string
+	err := r.DB.Get(&token, `SELECT token FROM Tbl_Calendar_feed_token WHERE employee_id = $1`, employeeID)
+	return token, err
+}
+
+// SaveCalendarFeedToken creates or replaces the calendar feed token of an employee
+func (r *Repository) SaveCalendarFeedToken(employeeID uuid.UUID, token string) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO Tbl_Calendar_feed_token (employee_id, token)
+		VALUES ($1, $2)
+		ON CONFLICT (employee_id) DO UPDATE SET token = EXCLUDED.token, updated_at = NOW()
+	`, employeeID, token)
+	return err
+}
+
+// GetEmployeeByFeedToken resolves a feed token to an active employee
+func (r *Repository) GetEmployeeByFeedToken(token string) (models.CalendarEmployee, error) {
+	var emp models.CalendarEmployee
+	err := r.DB.Get(&emp, `
+		SELECT e.id, e.full_name
+		FROM Tbl_Calendar_feed_token t
+		JOIN Tbl_Employee e ON e.id = t.employee_id
+		WHERE t.token = $1 AND e.status = 'active'
+	`, token)
+	return emp, err
+}
+
+// GetFeedTeamMemberIDs returns the employee's team for calendar feeds:
+// direct reports and colleagues sharing the same manager (excluding the employee)
+func (r *Repository) GetFeedTeamMemberIDs(employeeID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.DB.Select(&ids, `
+		SELECT t.id FROM Tbl_Employee t
+		WHERE t.status = 'active' AND t.id <> $1
+		AND (
+			t.manager_id = $1
+			OR t.manager_id = (SELECT manager_id FROM Tbl_Employee WHERE id = $1)
+		)
+	`, employeeID)
+	return ids, err
+}
+
+// GetFeedLeaves returns approved, withdrawn and cancelled leaves of the given employees
+// overlapping the range. Withdrawn/cancelled leaves are published as cancelled events.
+func (r *Repository) GetFeedLeaves(employeeIDs []uuid.UUID, start, end time.Time) ([]models.FeedLeave, error) {
+	var leaves []models.FeedLeave
+	if len(employeeIDs) == 0 {
+		return leaves, nil
+	}
+	ids := make([]string, len(employeeIDs))
+	for i, id := range employeeIDs {
+		ids[i] = id.String()
+	}
+	err := r.DB.Select(&leaves, `
+		SELECT l.id, e.full_name AS employee_name, lt.name AS leave_type, l.status,
+			l.start_date, l.end_date, COALESCE(l.half_id, 3) AS half_id,
+			l.start_half_id, l.end_half_id, l.start_time, l.end_time, l.updated_at
+		FROM Tbl_Leave l
+		JOIN Tbl_Employee e ON e.id = l.employee_id
+		JOIN Tbl_Leave_type lt ON lt.id = l.leave_type_id
+		WHERE l.employee_id = ANY($1::uuid[])
+		AND l.status IN ('APPROVED', 'WITHDRAWAL_PENDING', 'WITHDRAWN', 'CANCELLED')
+		AND l.start_date <= $3 AND l.end_date >= $2
+		ORDER BY l.start_date
+	`, pq.Array(ids), start, end)
+	return leaves, err
+}
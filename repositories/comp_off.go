@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+const compOffColumns = `
+	c.id, c.employee_id, e.full_name AS employee_name, c.work_date, c.days,
+	COALESCE(c.reason, '') AS reason, c.status, c.action_by, a.full_name AS action_by_name,
+	c.action_comment, c.action_at, c.leave_type_id, c.expires_on, c.lapsed_days, c.lapsed_at, c.created_at
+	FROM Tbl_Comp_off_request c
+	JOIN Tbl_Employee e ON e.id = c.employee_id
+	LEFT JOIN Tbl_Employee a ON a.id = c.action_by`
+
+// GetCompOffSettingsTx fetches the comp-off configuration (inside TX)
+func (r *Repository) GetCompOffSettingsTx(tx *sqlx.Tx) (models.CompOffSettings, error) {
+	var settings models.CompOffSettings
+	err := tx.Get(&settings, `SELECT comp_off_leave_type_id, comp_off_validity_days FROM Tbl_Company_Settings LIMIT 1`)
+	return settings, err
+}
+
+// IsHolidayTx reports whether a date is a company holiday
+func (r *Repository) IsHolidayTx(tx *sqlx.Tx, date time.Time) (bool, error) {
+	var exists bool
+	err := tx.Get(&exists, `SELECT EXISTS(SELECT 1 FROM Tbl_Holiday WHERE date = $1)`, date)
+	return exists, err
+}
+
+// InsertCompOffRequest creates a pending comp-off request
+func (r *Repository) InsertCompOffRequest(tx *sqlx.Tx, employeeID uuid.UUID, input models.CompOffInput, days float64) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(`
+		INSERT INTO Tbl_Comp_off_request (employee_id, work_date, days, reason)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, employeeID, input.WorkDate, days, input.Reason).Scan(&id)
+	return id, err
+}
+
+// HasCompOffForDateTx reports whether the employee already has an open or approved request for the date
+func (r *Repository) HasCompOffForDateTx(tx *sqlx.Tx, employeeID uuid.UUID, workDate time.Time) (bool, error) {
+	var exists bool
+	err := tx.Get(&exists, `
+		SELECT EXISTS(
+			SELECT 1 FROM Tbl_Comp_off_request
+			WHERE employee_id = $1 AND work_date = $2 AND status IN ('Pending', 'APPROVED')
+		)
+	`, employeeID, workDate)
+	return exists, err
+}
+
+// GetCompOffRequestTx fetches a comp-off request with a row lock
+func (r *Repository) GetCompOffRequestTx(tx *sqlx.Tx, id uuid.UUID) (models.CompOffRequest, error) {
+	var req models.CompOffRequest
+	err := tx.Get(&req, `SELECT `+compOffColumns+` WHERE c.id = $1 FOR UPDATE OF c`, id)
+	return req, err
+}
+
+// GetCompOffRequests lists comp-off requests. A nil employee or manager ID is not filtered on;
+// an empty status returns every status.
+func (r *Repository) GetCompOffRequests(employeeID, managerID *uuid.UUID, status string) ([]models.CompOffRequest, error) {
+	requests := []models.CompOffRequest{}
+	err := r.DB.Select(&requests, `SELECT `+compOffColumns+`
+		WHERE ($1::uuid IS NULL OR c.employee_id = $1)
+		AND ($2::uuid IS NULL OR e.manager_id = $2)
+		AND ($3 = '' OR c.status = $3)
+		ORDER BY c.created_at DESC
+	`, employeeID, managerID, status)
+	return requests, err
+}
+
+// ApproveCompOffRequest marks a request approved and records the credited leave type and expiry
+func (r *Repository) ApproveCompOffRequest(tx *sqlx.Tx, id, approverID uuid.UUID, comment string, leaveTypeID int, expiresOn time.Time) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Comp_off_request
+		SET status = 'APPROVED', action_by = $2, action_comment = NULLIF($3, ''), action_at = NOW(),
+		    leave_type_id = $4, expires_on = $5, updated_at = NOW()
+		WHERE id = $1
+	`, id, approverID, comment, leaveTypeID, expiresOn)
+	return err
+}
+
+// UpdateCompOffStatus rejects or cancels a pending request
+func (r *Repository) UpdateCompOffStatus(tx *sqlx.Tx, id, actorID uuid.UUID, status, comment string) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Comp_off_request
+		SET status = $2, action_by = $3, action_comment = NULLIF($4, ''), action_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, id, status, actorID, comment)
+	return err
+}
+
+// GetExpiredCompOffCreditsTx returns approved credits past their expiry that have not been lapsed yet,
+// oldest first, locked for update
+func (r *Repository) GetExpiredCompOffCreditsTx(tx *sqlx.Tx, today time.Time) ([]models.CompOffRequest, error) {
+	var credits []models.CompOffRequest
+	err := tx.Select(&credits, `SELECT `+compOffColumns+`
+		WHERE c.status = 'APPROVED' AND c.lapsed_at IS NULL AND c.expires_on < $1
+		ORDER BY c.employee_id, c.expires_on, c.created_at
+		FOR UPDATE OF c
+	`, today)
+	return credits, err
+}
+
+// GetActiveCompOffCreditsTx returns the total of an employee's approved credits of a year (the
+// year they were approved and credited in) that have not expired yet
+func (r *Repository) GetActiveCompOffCreditsTx(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID, year int, today time.Time) (float64, error) {
+	var total float64
+	err := tx.Get(&total, `
+		SELECT COALESCE(SUM(days), 0) FROM Tbl_Comp_off_request
+		WHERE employee_id = $1 AND leave_type_id = $2 AND status = 'APPROVED'
+		AND lapsed_at IS NULL AND expires_on >= $3
+		AND EXTRACT(YEAR FROM action_at) = $4
+	`, employeeID, leaveTypeID, today, year)
+	return total, err
+}
+
+// MarkCompOffLapsed records how much of a credit lapsed
+func (r *Repository) MarkCompOffLapsed(tx *sqlx.Tx, id uuid.UUID, lapsed float64) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Comp_off_request
+		SET lapsed_days = $2, lapsed_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, id, lapsed)
+	return err
+}
+
+// InsertSystemLeaveAdjustment records a balance adjustment made by a scheduled job (no creator)
+func (r *Repository) InsertSystemLeaveAdjustment(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID int, quantity float64, reason string, year int) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(`
+		INSERT INTO Tbl_Leave_adjustment
+		(employee_id, leave_type_id, quantity, reason, created_by, created_at, year)
+		VALUES ($1,$2,$3,$4,NULL,NOW(),$5)
+		RETURNING id
+	`, employeeID, leaveTypeID, quantity, reason, year).Scan(&id)
+	return id, err
+}
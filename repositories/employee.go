@@ -40,7 +40,7 @@ func (r *Repository) GetAllEmployees(roleFilter, designationFilter, role string)
         SELECT 
             e.id, e.full_name, e.email, e.status,
             r.type AS role, e.manager_id, e.designation_id,
-            %s, e.joining_date, e.ending_date,
+            %s, e.joining_date, e.ending_date, e.gender, e.department,
             e.created_at, e.updated_at,
             m.full_name AS manager_name,
             d.designation_name
@@ -85,6 +85,8 @@ func (r *Repository) GetAllEmployees(roleFilter, designationFilter, role string)
 			&emp.Salary,
 			&emp.JoiningDate,
 			&emp.EndingDate,
+			&emp.Gender,
+			&emp.Department,
 			&emp.CreatedAt,
 			&emp.UpdatedAt,
 			&emp.ManagerName,
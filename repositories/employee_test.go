@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/utils/constant"
+)
+
+// employeeRowDriver answers every query with one employee row holding exactly the columns the
+// query selects, so a Scan that does not match the SELECT list fails like it does on Postgres
+type employeeRowDriver struct{}
+
+var employeeRowValues = map[string]driver.Value{
+	"id":               uuid.MustParse("5b0e6b0c-8f0a-4a43-9a5c-2f6d0f1b7c11").String(),
+	"full_name":        "Asha Rao",
+	"email":            "asha@example.com",
+	"status":           "active",
+	"role":             constant.ROLE_EMPLOYEE,
+	"manager_id":       uuid.MustParse("9c3f7a52-6d1e-4f4b-8a0c-1e2d3f4a5b6c").String(),
+	"designation_id":   nil,
+	"salary":           50000.0,
+	"joining_date":     time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+	"ending_date":      nil,
+	"gender":           "FEMALE",
+	"department":       "Engineering",
+	"created_at":       time.Date(2024, 3, 20, 9, 0, 0, 0, time.UTC),
+	"updated_at":       time.Date(2024, 3, 20, 9, 0, 0, 0, time.UTC),
+	"manager_name":     "Ravi Kumar",
+	"designation_name": nil,
+}
+
+var selectListPattern = regexp.MustCompile(`(?is)SELECT\s+(.*?)\s+FROM\s`)
+
+func (employeeRowDriver) Open(string) (driver.Conn, error) { return employeeRowConn{}, nil }
+
+type employeeRowConn struct{}
+
+func (employeeRowConn) Prepare(query string) (driver.Stmt, error) {
+	m := selectListPattern.FindStringSubmatch(query)
+	if m == nil {
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+	var columns []string
+	for _, expr := range strings.Split(m[1], ",") {
+		fields := strings.Fields(expr)
+		name := fields[len(fields)-1]
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		if _, ok := employeeRowValues[name]; !ok {
+			return nil, fmt.Errorf("unexpected column %q", name)
+		}
+		columns = append(columns, name)
+	}
+	return employeeRowStmt{columns: columns}, nil
+}
+
+func (employeeRowConn) Close() error              { return nil }
+func (employeeRowConn) Begin() (driver.Tx, error) { return nil, fmt.Errorf("not supported") }
+
+type employeeRowStmt struct{ columns []string }
+
+func (s employeeRowStmt) Close() error  { return nil }
+func (s employeeRowStmt) NumInput() int { return -1 }
+func (s employeeRowStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("not supported")
+}
+func (s employeeRowStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &employeeRows{columns: s.columns}, nil
+}
+
+type employeeRows struct {
+	columns []string
+	done    bool
+}
+
+func (r *employeeRows) Columns() []string { return r.columns }
+func (r *employeeRows) Close() error      { return nil }
+func (r *employeeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	for i, c := range r.columns {
+		dest[i] = employeeRowValues[c]
+	}
+	return nil
+}
+
+func init() {
+	sql.Register("employee-row", employeeRowDriver{})
+}
+
+func newEmployeeRowRepo(t *testing.T) *Repository {
+	db, err := sql.Open("employee-row", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &Repository{DB: sqlx.NewDb(db, "postgres")}
+}
+
+func TestGetAllEmployeesScansRow(t *testing.T) {
+	r := newEmployeeRowRepo(t)
+	for _, role := range []string{constant.ROLE_SUPER_ADMIN, constant.ROLE_HR} {
+		employees, err := r.GetAllEmployees("", "", role)
+		if err != nil {
+			t.Fatalf("GetAllEmployees as %s: %v", role, err)
+		}
+		if len(employees) != 1 {
+			t.Fatalf("GetAllEmployees as %s returned %d employees, want 1", role, len(employees))
+		}
+		emp := employees[0]
+		if emp.FullName != "Asha Rao" || emp.Gender == nil || *emp.Gender != "FEMALE" ||
+			emp.Department == nil || *emp.Department != "Engineering" ||
+			emp.ManagerName == nil || *emp.ManagerName != "Ravi Kumar" {
+			t.Errorf("GetAllEmployees as %s scanned %+v", role, emp)
+		}
+	}
+}
+
+func TestGetEmployeesByManagerIDScansRow(t *testing.T) {
+	r := newEmployeeRowRepo(t)
+	employees, err := r.GetEmployeesByManagerID(uuid.New())
+	if err != nil {
+		t.Fatalf("GetEmployeesByManagerID: %v", err)
+	}
+	if len(employees) != 1 {
+		t.Fatalf("GetEmployeesByManagerID returned %d employees, want 1", len(employees))
+	}
+	emp := employees[0]
+	if emp.Gender == nil || *emp.Gender != "FEMALE" || emp.Department == nil || *emp.Department != "Engineering" ||
+		emp.CreatedAt == nil || emp.DesignationName != nil {
+		t.Errorf("GetEmployeesByManagerID scanned %+v", emp)
+	}
+}
@@ -10,10 +10,16 @@ import (
 	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
 )
 
+// leaveTypeColumns are the Tbl_Leave_type columns scanned into models.LeaveType
+const leaveTypeColumns = `id, name, is_paid, default_entitlement, unit,
+	min_notice_days, max_consecutive_days, allowed_genders, min_tenure_months,
+	attachment_required_after_days, allow_negative_balance, max_negative_balance,
+	accrual_frequency, carry_forward_max, encashable, encash_max, created_at, updated_at`
+
 // 1. Get leave type entitlement
 func (r *Repository) GetLeaveTypeByIdTx(tx *sqlx.Tx, leaveTypeID int) (models.LeaveType, error) {
 	var leaves models.LeaveType
-	query := `SELECT id, name, is_paid, default_entitlement,  created_at, updated_at FROM Tbl_Leave_type WHERE id=$1`
+	query := `SELECT ` + leaveTypeColumns + ` FROM Tbl_Leave_type WHERE id=$1`
 	err := tx.Get(&leaves,
 		query,
 		leaveTypeID,
@@ -24,7 +30,7 @@ func (r *Repository) GetLeaveTypeByIdTx(tx *sqlx.Tx, leaveTypeID int) (models.Le
 // 1. Get leave type entitlement
 func (r *Repository) GetLeaveTypeById(leaveTypeID int) (models.LeaveType, error) {
 	var leaves models.LeaveType
-	query := `SELECT id, name, is_paid, default_entitlement,  created_at, updated_at FROM Tbl_Leave_type WHERE id=$1`
+	query := `SELECT ` + leaveTypeColumns + ` FROM Tbl_Leave_type WHERE id=$1`
 	err := r.DB.Get(&leaves,
 		query,
 		leaveTypeID,
@@ -49,7 +55,7 @@ func (q *Repository) GetLeaveTypeByLeaveID(leaveID uuid.UUID) (int, error) {
 
 func (r *Repository) GetAllLeaveType() ([]models.LeaveType, error) {
 	var leaveType []models.LeaveType
-	query := `SELECT id, name, is_paid, default_entitlement,  created_at, updated_at FROM Tbl_Leave_type ORDER BY id`
+	query := `SELECT ` + leaveTypeColumns + ` FROM Tbl_Leave_type ORDER BY id`
 	err := r.DB.Select(&leaveType, query)
 	return leaveType, err
 }
@@ -59,35 +65,44 @@ func (r *Repository) GetAllLeaveType() ([]models.LeaveType, error) {
 func (r *Repository) AddLeaveType(tx *sqlx.Tx, input models.LeaveTypeInput) (models.LeaveType, error) {
 	var leave models.LeaveType
 	query := `
-		INSERT INTO Tbl_Leave_type (name, is_paid, default_entitlement)
-		VALUES ($1, $2, $3)
+		INSERT INTO Tbl_Leave_type (name, is_paid, default_entitlement, unit)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id, created_at, updated_at
 	`
-	err := tx.QueryRow(query, input.Name, *input.IsPaid, *input.DefaultEntitlement).
+	err := tx.QueryRow(query, input.Name, *input.IsPaid, *input.DefaultEntitlement, *input.Unit).
 		Scan(&leave.ID, &leave.CreatedAt, &leave.UpdatedAt)
 	return leave, err
 }
 
-// 3. Get leave balance (inside TX)
-func (r *Repository) GetLeaveBalance(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID int) (float64, error) {
+// 3. Get leave balance of a year (inside TX)
+func (r *Repository) GetLeaveBalance(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID, year int) (float64, error) {
 	var balance float64
 	err := tx.Get(&balance, `
 		SELECT closing 
 		FROM Tbl_Leave_balance 
 		WHERE employee_id=$1 AND leave_type_id=$2 
-		AND year = EXTRACT(YEAR FROM CURRENT_DATE)
-	`, employeeID, leaveTypeID)
+		AND year = $3
+	`, employeeID, leaveTypeID, year)
 	return balance, err
 }
 
-// create leave balance
-func (r *Repository) CreateLeaveBalance(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID int, entitlement int) error {
-	_, err := tx.Exec(`
+// CreateLeaveBalance creates the employee's balance of a year with their opening entitlement
+// (see GetOpeningEntitlementTx) and returns it
+func (r *Repository) CreateLeaveBalance(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID, year int) (float64, error) {
+	entitlement, err := r.GetOpeningEntitlementTx(tx, employeeID, leaveTypeID, year)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(`
 		INSERT INTO Tbl_Leave_balance 
 			(employee_id, leave_type_id, year, opening, accrued, used, adjusted, closing)
-		VALUES ($1, $2, EXTRACT(YEAR FROM CURRENT_DATE), $3, 0, 0, 0, $3)
-	`, employeeID, leaveTypeID, entitlement)
-	return err
+		VALUES ($1, $2, $4, $3, 0, 0, 0, $3)
+	`, employeeID, leaveTypeID, entitlement, year)
+	if err != nil || entitlement == 0 {
+		return entitlement, err
+	}
+	return entitlement, r.InsertLeaveLedgerEntry(tx, employeeID, leaveTypeID, year, entitlement,
+		models.LedgerRef{EntryType: models.LedgerGrant, Note: "Opening entitlement"})
 }
 
 // 5. Check overlapping leaves
@@ -130,7 +145,7 @@ func (r *Repository) InsertLeave(
 	leaveTypeID int,
 	leaveTimingID int,
 	startDate, endDate time.Time,
-	days float64,
+	qty models.LeaveQuantity,
 	reason string,
 ) (uuid.UUID, error) {
 
@@ -138,8 +153,8 @@ func (r *Repository) InsertLeave(
 
 	err := tx.QueryRow(`
 		INSERT INTO Tbl_Leave 
-		(employee_id, leave_type_id, half_id, start_date, end_date, days, status, reason)
-		VALUES ($1,$2,$3,$4,$5,$6,'Pending',$7)
+		(employee_id, leave_type_id, half_id, start_date, end_date, days, hours, start_time, end_time, start_half_id, end_half_id, status, reason)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,'Pending',$12)
 		RETURNING id
 	`,
 		employeeID,
@@ -147,7 +162,12 @@ func (r *Repository) InsertLeave(
 		leaveTimingID,
 		startDate,
 		endDate,
-		days,
+		qty.Days,
+		qty.Hours,
+		qty.StartTime,
+		qty.EndTime,
+		qty.StartHalfID,
+		qty.EndHalfID,
 		reason,
 	).Scan(&leaveID)
 
@@ -237,6 +257,11 @@ func (r *Repository) GetAllEmployeeLeaveByMonthYear(userID uuid.UUID, month, yea
 			l.start_date,
 			l.end_date,
 			l.days,
+			l.hours,
+			l.start_half_id,
+			l.end_half_id,
+			l.start_time,
+			l.end_time,
 			COALESCE(l.reason, '') AS reason,
 			l.status,
 			l.created_at AS applied_at,
@@ -270,6 +295,11 @@ func (r *Repository) GetAllleavebaseonassignManagerByMonthYear(userID uuid.UUID,
 			l.start_date,
 			l.end_date,
 			l.days,
+			l.hours,
+			l.start_half_id,
+			l.end_half_id,
+			l.start_time,
+			l.end_time,
 			COALESCE(l.reason, '') AS reason,
 			l.status,
 			l.created_at AS applied_at,
@@ -303,6 +333,11 @@ func (r *Repository) GetAllLeaveByMonthYear(month, year int) ([]models.LeaveResp
 			l.start_date,
 			l.end_date,
 			l.days,
+			l.hours,
+			l.start_half_id,
+			l.end_half_id,
+			l.start_time,
+			l.end_time,
 			COALESCE(l.reason, '') AS reason,
 			l.status,
 			l.created_at AS applied_at,
@@ -323,10 +358,10 @@ func (r *Repository) GetAllLeaveByMonthYear(month, year int) ([]models.LeaveResp
 func (r *Repository) UpdateLeaveType(tx *sqlx.Tx, leaveTypeID int, input models.LeaveTypeInput) error {
 	query := `
 		UPDATE Tbl_Leave_type 
-		SET name = $1, is_paid = $2, default_entitlement = $3, updated_at = NOW()
-		WHERE id = $4
+		SET name = $1, is_paid = $2, default_entitlement = $3, unit = $4, updated_at = NOW()
+		WHERE id = $5
 	`
-	result, err := tx.Exec(query, input.Name, *input.IsPaid, *input.DefaultEntitlement, leaveTypeID)
+	result, err := tx.Exec(query, input.Name, *input.IsPaid, *input.DefaultEntitlement, *input.Unit, leaveTypeID)
 	if err != nil {
 		return err
 	}
@@ -359,6 +394,11 @@ func (r *Repository) GetMyLeavesByMonthYear(userID uuid.UUID, month, year int) (
 			l.start_date,
 			l.end_date,
 			l.days,
+			l.hours,
+			l.start_half_id,
+			l.end_half_id,
+			l.start_time,
+			l.end_time,
 			COALESCE(l.reason, '') AS reason,
 			l.status,
 			l.created_at AS applied_at,
@@ -376,51 +416,6 @@ func (r *Repository) GetMyLeavesByMonthYear(userID uuid.UUID, month, year int) (
 	return result, err
 }
 
-// UpdateLeaveBalancesForEntitlementChange updates leave balances when default entitlement changes
-// This updates opening and closing balances for all employees with this leave type in the current year
-// Example: If default entitlement changes from 18 to 20:
-//   - Opening 18 → 20 (adds +2)
-//   - Closing 18 → 20 (adds +2, maintains available balance)
-//   - Closing 15 → 17 (if 3 days used, maintains same available balance)
-func (r *Repository) UpdateLeaveBalancesForEntitlementChange(tx *sqlx.Tx, leaveTypeID int, oldDefaultEntitlement, newDefaultEntitlement int, currentYear int) error {
-	// Calculate the difference
-	difference := float64(newDefaultEntitlement - oldDefaultEntitlement)
-
-	// Only update if there's a change
-	if difference == 0 {
-		return nil
-	}
-
-	// Update all leave balances for this leave type in current year
-	// Update opening: add difference to current opening
-	// Update closing: add difference to current closing (maintains available balance)
-	// This ensures:
-	// - If opening was equal to old default, it becomes equal to new default
-	// - Available balance (closing) is adjusted proportionally
-	query := `
-		UPDATE Tbl_Leave_balance
-		SET opening = opening + $1,
-		    closing = closing + $1,
-		    updated_at = NOW()
-		WHERE leave_type_id = $2 
-		AND year = $3
-	`
-
-	result, err := tx.Exec(query, difference, leaveTypeID, currentYear)
-	if err != nil {
-		return err
-	}
-
-	// Log how many balances were updated (optional, for debugging)
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected > 0 {
-		fmt.Printf("Updated %d leave balances for leave_type_id=%d (entitlement: %d → %d, year: %d)\n",
-			rowsAffected, leaveTypeID, oldDefaultEntitlement, newDefaultEntitlement, currentYear)
-	}
-
-	return nil
-}
-
 // DeleteLeaveType - Delete leave policy
 func (r *Repository) DeleteLeaveType(tx *sqlx.Tx, leaveTypeID int) error {
 	// Check if leave type is being used in any leave applications
@@ -452,7 +447,7 @@ func (r *Repository) DeleteLeaveType(tx *sqlx.Tx, leaveTypeID int) error {
 	return nil
 }
 
-func (r *Repository) UpdatePendingLeave(tx *sqlx.Tx, leaveID uuid.UUID, empID uuid.UUID, input models.LeaveUpdateInput, NewDays float64) error {
+func (r *Repository) UpdatePendingLeave(tx *sqlx.Tx, leaveID uuid.UUID, empID uuid.UUID, input models.LeaveUpdateInput, qty models.LeaveQuantity) error {
 
 	// 2. RE-CALCULATE DAYS using your existing service
 	// Ensure you pass the correct timingID (1, 2, or 3)
@@ -466,6 +461,11 @@ func (r *Repository) UpdatePendingLeave(tx *sqlx.Tx, leaveID uuid.UUID, empID uu
             reason = $4,
 			days = $5,           
             half_id = $6,
+            hours = $9,
+            start_time = $10,
+            end_time = $11,
+            start_half_id = $12,
+            end_half_id = $13,
             updated_at = NOW()
         WHERE id = $7 
           AND employee_id = $8 
@@ -476,10 +476,15 @@ func (r *Repository) UpdatePendingLeave(tx *sqlx.Tx, leaveID uuid.UUID, empID uu
 		input.EndDate,
 		input.LeaveTypeID,
 		input.Reason,
-		NewDays,
+		qty.Days,
 		input.LeaveTimingID,
 		leaveID,
 		empID,
+		qty.Hours,
+		qty.StartTime,
+		qty.EndTime,
+		qty.StartHalfID,
+		qty.EndHalfID,
 	)
 	if err != nil {
 		return err
@@ -493,3 +498,52 @@ func (r *Repository) UpdatePendingLeave(tx *sqlx.Tx, leaveID uuid.UUID, empID uu
 
 	return nil
 }
+
+// InsertLeaveOnBehalf inserts a leave recorded by an admin/manager for another employee.
+// applied_by is always set; approved_by is set only when the leave is pre-approved.
+func (r *Repository) InsertLeaveOnBehalf(
+	tx *sqlx.Tx,
+	input models.LeaveInput,
+	leaveTimingID int,
+	qty models.LeaveQuantity,
+	status string,
+) (uuid.UUID, error) {
+
+	var leaveID uuid.UUID
+
+	err := tx.QueryRow(`
+		INSERT INTO Tbl_Leave 
+		(employee_id, leave_type_id, half_id, start_date, end_date, days, hours, start_time, end_time, start_half_id, end_half_id, status, reason, applied_by, approved_by)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
+		RETURNING id
+	`,
+		input.EmployeeID,
+		input.LeaveTypeID,
+		leaveTimingID,
+		input.StartDate,
+		input.EndDate,
+		qty.Days,
+		qty.Hours,
+		qty.StartTime,
+		qty.EndTime,
+		qty.StartHalfID,
+		qty.EndHalfID,
+		status,
+		input.Reason,
+		input.AppliedByID,
+		input.ApprovedByID,
+	).Scan(&leaveID)
+
+	return leaveID, err
+}
+
+// DeductLeaveBalance moves the consumed quantity (days, or hours for HOUR leave types)
+// from closing to used for the balance of the year the leave is taken in, recorded as a
+// CONSUMPTION of the leave
+func (r *Repository) DeductLeaveBalance(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID, year int, days float64, leaveID uuid.UUID, by *uuid.UUID) error {
+	if _, err := r.EnsureLeaveBalance(tx, employeeID, leaveTypeID, year); err != nil {
+		return err
+	}
+	return r.PostLeaveLedgerEntry(tx, employeeID, leaveTypeID, year, -days,
+		models.LedgerRef{EntryType: models.LedgerConsumption, LeaveID: &leaveID, CreatedBy: by})
+}
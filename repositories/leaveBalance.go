@@ -3,6 +3,7 @@ package repositories
 import (
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
 )
 
 // LeaveTypeData represents leave type information for balance calculation
@@ -10,6 +11,7 @@ type LeaveTypeData struct {
 	LeaveTypeID       int     `db:"leave_type_id"`
 	LeaveTypeName     string  `db:"leave_type_name"`
 	DefaultEntitlement float64 `db:"default_entitlement"`
+	Unit              string  `db:"unit"`
 }
 
 // BalanceData represents raw balance data from database
@@ -35,18 +37,21 @@ type LeaveBalanceForAdjustment struct {
 	Year        int       `db:"year"`
 }
 
-// GetAllLeaveTypesWithEntitlements fetches all leave types with their default entitlements
-func (r *Repository) GetAllLeaveTypesWithEntitlements() ([]LeaveTypeData, error) {
+// GetAllLeaveTypesWithEntitlements fetches all leave types with the employee's opening
+// entitlement for the year
+func (r *Repository) GetAllLeaveTypesWithEntitlements(employeeID uuid.UUID, year int) ([]LeaveTypeData, error) {
 	var leaveTypes []LeaveTypeData
 	query := `
 		SELECT 
 			lt.id AS leave_type_id,
 			lt.name AS leave_type_name,
-			COALESCE(lt.default_entitlement, 0) AS default_entitlement
+			CASE WHEN lt.accrual_frequency = 'NONE' THEN ` + entitlementSQL("$2") + ` ELSE 0 END AS default_entitlement,
+			lt.unit
 		FROM Tbl_Leave_Type lt
+		LEFT JOIN Tbl_Employee e ON e.id = $1
 		ORDER BY lt.id
 	`
-	err := r.DB.Select(&leaveTypes, query)
+	err := r.DB.Select(&leaveTypes, query, employeeID, EntitlementDate(year))
 	return leaveTypes, err
 }
 
@@ -90,44 +95,38 @@ func (r *Repository) GetLeaveBalanceForAdjustment(tx *sqlx.Tx, employeeID uuid.U
 	return balance, err
 }
 
-// GetDefaultEntitlementByLeaveTypeID fetches default entitlement for a leave type
-func (r *Repository) GetDefaultEntitlementByLeaveTypeID(tx *sqlx.Tx, leaveTypeID int) (float64, error) {
-	var defaultEntitlement float64
-	err := tx.Get(&defaultEntitlement, `SELECT default_entitlement FROM Tbl_Leave_Type WHERE id=$1`, leaveTypeID)
-	return defaultEntitlement, err
-}
-
-// CreateLeaveBalanceForAdjustment creates a new leave balance record
-func (r *Repository) CreateLeaveBalanceForAdjustment(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID int, year int, defaultEntitlement float64) (LeaveBalanceForAdjustment, error) {
+// CreateLeaveBalanceForAdjustment creates a new leave balance record for the year with the
+// employee's opening entitlement (see GetOpeningEntitlementTx)
+func (r *Repository) CreateLeaveBalanceForAdjustment(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID int, year int) (LeaveBalanceForAdjustment, error) {
 	var balance LeaveBalanceForAdjustment
-	err := tx.QueryRow(`
+	defaultEntitlement, err := r.GetOpeningEntitlementTx(tx, employeeID, leaveTypeID, year)
+	if err != nil {
+		return balance, err
+	}
+	err = tx.QueryRow(`
 		INSERT INTO Tbl_Leave_balance
 		(employee_id, leave_type_id, year, opening, accrued, used, adjusted, closing, created_at, updated_at)
 		VALUES ($1,$2,$3,$4,0,0,0,$4,NOW(),NOW())
 		RETURNING id, opening, accrued, used, adjusted, closing, employee_id, leave_type_id, year
 	`, employeeID, leaveTypeID, year, defaultEntitlement).
 		Scan(&balance.ID, &balance.Opening, &balance.Accrued, &balance.Used, &balance.Adjusted, &balance.Closing, &balance.EmployeeID, &balance.LeaveTypeID, &balance.Year)
+	if err != nil || defaultEntitlement == 0 {
+		return balance, err
+	}
+	err = r.InsertLeaveLedgerEntry(tx, employeeID, leaveTypeID, year, defaultEntitlement,
+		models.LedgerRef{EntryType: models.LedgerGrant, Note: "Opening entitlement"})
 	return balance, err
 }
 
-// UpdateLeaveBalanceAdjustment updates adjusted and closing values for leave balance
-func (r *Repository) UpdateLeaveBalanceAdjustment(tx *sqlx.Tx, balanceID uuid.UUID, newAdjusted, newClosing float64) error {
-	query := `
-		UPDATE Tbl_Leave_balance
-		SET adjusted=$1, closing=$2, updated_at=NOW()
-		WHERE id=$3
-	`
-	_, err := tx.Exec(query, newAdjusted, newClosing, balanceID)
-	return err
-}
-
 // InsertLeaveAdjustment inserts a record into leave adjustment log
-func (r *Repository) InsertLeaveAdjustment(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID int, quantity float64, reason string, createdBy string, year int) error {
+func (r *Repository) InsertLeaveAdjustment(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID int, quantity float64, reason string, createdBy string, year int) (uuid.UUID, error) {
+	var id uuid.UUID
 	query := `
 		INSERT INTO Tbl_Leave_adjustment
 		(employee_id, leave_type_id, quantity, reason, created_by, created_at, year)
 		VALUES ($1,$2,$3,$4,$5,NOW(),$6)
+		RETURNING id
 	`
-	_, err := tx.Exec(query, employeeID, leaveTypeID, quantity, reason, createdBy, year)
-	return err
+	err := tx.QueryRow(query, employeeID, leaveTypeID, quantity, reason, createdBy, year).Scan(&id)
+	return id, err
 }
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// GetAccrualLeaveTypesTx fetches the leave types credited by the accrual engine
+func (r *Repository) GetAccrualLeaveTypesTx(tx *sqlx.Tx) ([]models.LeaveType, error) {
+	var leaveTypes []models.LeaveType
+	err := tx.Select(&leaveTypes, `SELECT `+leaveTypeColumns+` FROM Tbl_Leave_type WHERE accrual_frequency <> 'NONE' ORDER BY id`)
+	return leaveTypes, err
+}
+
+// GetAccrualEmployeesTx fetches employees employed for at least part of the period.
+// Deactivated employees are only included while their ending_date falls within or after the period.
+func (r *Repository) GetAccrualEmployeesTx(tx *sqlx.Tx, start, end time.Time) ([]models.AccrualEmployee, error) {
+	var employees []models.AccrualEmployee
+	err := tx.Select(&employees, `
+		SELECT id, full_name, joining_date, ending_date
+		FROM Tbl_Employee
+		WHERE deleted_at IS NULL
+		AND (joining_date IS NULL OR joining_date <= $2)
+		AND (ending_date IS NULL OR ending_date >= $1)
+		AND (status = 'active' OR ending_date IS NOT NULL)
+		ORDER BY full_name
+	`, start, end)
+	return employees, err
+}
+
+// GetAccruedEmployeeIDsTx returns the employees already credited for a leave type and period
+func (r *Repository) GetAccruedEmployeeIDsTx(tx *sqlx.Tx, leaveTypeID int, periodStart time.Time) (map[uuid.UUID]bool, error) {
+	var ids []uuid.UUID
+	err := tx.Select(&ids, `
+		SELECT employee_id FROM Tbl_Leave_accrual
+		WHERE leave_type_id = $1 AND period_start = $2
+	`, leaveTypeID, periodStart)
+	if err != nil {
+		return nil, err
+	}
+	accrued := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		accrued[id] = true
+	}
+	return accrued, nil
+}
+
+// IsPayrollFinalizedTx reports whether the payroll run of the month is finalized (or paid)
+func (r *Repository) IsPayrollFinalizedTx(tx *sqlx.Tx, month, year int) (bool, error) {
+	var finalized bool
+	err := tx.Get(&finalized, `
+		SELECT EXISTS(SELECT 1 FROM Tbl_Payroll_run WHERE month = $1 AND year = $2 AND status IN ('FINALIZED', 'PAID'))
+	`, month, year)
+	return finalized, err
+}
+
+// HasLeaveAccrualsTx reports whether any ACCRUAL ledger entry was posted for the year
+func (r *Repository) HasLeaveAccrualsTx(tx *sqlx.Tx, year int) (bool, error) {
+	var exists bool
+	err := tx.Get(&exists, `
+		SELECT EXISTS(SELECT 1 FROM Tbl_Leave_ledger WHERE year = $1 AND entry_type = $2)
+	`, year, models.LedgerAccrual)
+	return exists, err
+}
+
+// InsertLeaveAccrual records an accrual ledger entry. It reports false when the
+// employee was already credited for the period.
+func (r *Repository) InsertLeaveAccrual(tx *sqlx.Tx, entry models.LeaveAccrual) (bool, error) {
+	result, err := tx.Exec(`
+		INSERT INTO Tbl_Leave_accrual
+		(employee_id, leave_type_id, year, frequency, period_start, period_end, entitlement, proration, amount, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (employee_id, leave_type_id, period_start) DO NOTHING
+	`, entry.EmployeeID, entry.LeaveTypeID, entry.Year, entry.Frequency, entry.PeriodStart, entry.PeriodEnd,
+		entry.Entitlement, entry.Proration, entry.Amount, entry.CreatedBy)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// GetLeaveAccruals lists accrual ledger entries, optionally filtered by employee, leave type and year
+func (r *Repository) GetLeaveAccruals(employeeID *uuid.UUID, leaveTypeID *int, year *int) ([]models.LeaveAccrual, error) {
+	accruals := []models.LeaveAccrual{}
+	err := r.DB.Select(&accruals, `
+		SELECT a.id, a.employee_id, e.full_name AS employee_name, a.leave_type_id, lt.name AS leave_type,
+			a.year, a.frequency, a.period_start, a.period_end, a.entitlement, a.proration, a.amount,
+			a.created_by, a.created_at
+		FROM Tbl_Leave_accrual a
+		JOIN Tbl_Employee e ON e.id = a.employee_id
+		JOIN Tbl_Leave_type lt ON lt.id = a.leave_type_id
+		WHERE ($1::uuid IS NULL OR a.employee_id = $1)
+		AND ($2::int IS NULL OR a.leave_type_id = $2)
+		AND ($3::int IS NULL OR a.year = $3)
+		ORDER BY a.period_start DESC, e.full_name, lt.name
+	`, employeeID, leaveTypeID, year)
+	return accruals, err
+}
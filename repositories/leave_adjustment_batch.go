@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// GetBulkAdjustmentTargetsTx returns the active employees matched by a bulk adjustment filter
+func (r *Repository) GetBulkAdjustmentTargetsTx(tx *sqlx.Tx, filter models.BulkAdjustmentFilter) ([]models.BulkAdjustmentTarget, error) {
+	query := `
+		SELECT e.id, e.full_name, e.email
+		FROM Tbl_Employee e
+		JOIN Tbl_Role r ON r.id = e.role_id
+		LEFT JOIN Tbl_Designation d ON d.id = e.designation_id
+		WHERE e.deleted_at IS NULL AND e.status = 'active'`
+	var args []interface{}
+	switch filter.Type {
+	case models.BulkFilterAll:
+	case models.BulkFilterDepartment:
+		query += ` AND LOWER(e.department) = LOWER($1)`
+		args = append(args, filter.Value)
+	case models.BulkFilterDesignation:
+		query += ` AND (d.id::text = $1 OR LOWER(d.designation_name) = LOWER($1))`
+		args = append(args, filter.Value)
+	case models.BulkFilterRole:
+		query += ` AND r.type = UPPER($1)`
+		args = append(args, filter.Value)
+	case models.BulkFilterEmployees:
+		emails := make([]string, len(filter.Emails))
+		for i, email := range filter.Emails {
+			emails[i] = strings.ToLower(strings.TrimSpace(email))
+		}
+		ids := make([]string, len(filter.EmployeeIDs))
+		for i, id := range filter.EmployeeIDs {
+			ids[i] = id.String()
+		}
+		query += ` AND (e.id::text = ANY($1) OR LOWER(e.email) = ANY($2))`
+		args = append(args, pq.Array(ids), pq.Array(emails))
+	default:
+		return nil, fmt.Errorf("unknown filter type %s", filter.Type)
+	}
+	query += ` ORDER BY e.full_name`
+
+	var targets []models.BulkAdjustmentTarget
+	err := tx.Select(&targets, query, args...)
+	return targets, err
+}
+
+// InsertLeaveAdjustmentBatch records a bulk adjustment
+func (r *Repository) InsertLeaveAdjustmentBatch(tx *sqlx.Tx, input models.BulkAdjustmentInput, year, employeeCount int, createdBy uuid.UUID) (uuid.UUID, error) {
+	var filterValue *string
+	if input.Filter.Value != "" {
+		filterValue = &input.Filter.Value
+	}
+	var id uuid.UUID
+	err := tx.QueryRow(`
+		INSERT INTO Tbl_Leave_adjustment_batch
+		(leave_type_id, year, quantity, reason, filter_type, filter_value, employee_count, created_by)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		RETURNING id
+	`, input.LeaveTypeID, year, input.Quantity, input.Reason, input.Filter.Type, filterValue, employeeCount, createdBy).Scan(&id)
+	return id, err
+}
+
+// InsertBatchLeaveAdjustment records one employee's adjustment of a batch. reversalOf is the
+// adjustment undone by a batch reversal.
+func (r *Repository) InsertBatchLeaveAdjustment(tx *sqlx.Tx, batchID, employeeID uuid.UUID, leaveTypeID int, quantity float64, reason string, createdBy uuid.UUID, year int, reversalOf *uuid.UUID) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(`
+		INSERT INTO Tbl_Leave_adjustment
+		(employee_id, leave_type_id, quantity, reason, created_by, created_at, year, batch_id, reversal_of)
+		VALUES ($1,$2,$3,$4,$5,NOW(),$6,$7,$8)
+		RETURNING id
+	`, employeeID, leaveTypeID, quantity, reason, createdBy, year, batchID, reversalOf).Scan(&id)
+	return id, err
+}
+
+const leaveAdjustmentBatchColumns = `
+	b.id, b.leave_type_id, lt.name AS leave_type, b.year, b.quantity, b.reason,
+	b.filter_type, b.filter_value, b.employee_count, b.status,
+	b.created_by, e.full_name AS created_by_name, b.created_at,
+	b.reversed_by, b.reversed_at, b.reversal_reason
+	FROM Tbl_Leave_adjustment_batch b
+	JOIN Tbl_Leave_type lt ON lt.id = b.leave_type_id
+	LEFT JOIN Tbl_Employee e ON e.id = b.created_by`
+
+// GetLeaveAdjustmentBatches lists bulk adjustments, newest first
+func (r *Repository) GetLeaveAdjustmentBatches() ([]models.LeaveAdjustmentBatch, error) {
+	var batches []models.LeaveAdjustmentBatch
+	err := r.DB.Select(&batches, `SELECT `+leaveAdjustmentBatchColumns+` ORDER BY b.created_at DESC`)
+	return batches, err
+}
+
+// GetLeaveAdjustmentBatch fetches a bulk adjustment
+func (r *Repository) GetLeaveAdjustmentBatch(batchID uuid.UUID) (models.LeaveAdjustmentBatch, error) {
+	var batch models.LeaveAdjustmentBatch
+	err := r.DB.Get(&batch, `SELECT `+leaveAdjustmentBatchColumns+` WHERE b.id = $1`, batchID)
+	return batch, err
+}
+
+// GetLeaveAdjustmentBatchForUpdateTx fetches and locks a bulk adjustment
+func (r *Repository) GetLeaveAdjustmentBatchForUpdateTx(tx *sqlx.Tx, batchID uuid.UUID) (models.LeaveAdjustmentBatch, error) {
+	var batch models.LeaveAdjustmentBatch
+	err := tx.Get(&batch, `SELECT `+leaveAdjustmentBatchColumns+` WHERE b.id = $1 FOR UPDATE OF b`, batchID)
+	return batch, err
+}
+
+// GetBatchLeaveAdjustmentsTx returns the adjustments made by a batch, excluding its reversal
+func (r *Repository) GetBatchLeaveAdjustmentsTx(tx *sqlx.Tx, batchID uuid.UUID) ([]models.BulkAdjustmentLine, error) {
+	var lines []models.BulkAdjustmentLine
+	err := tx.Select(&lines, `
+		SELECT a.id, a.employee_id, e.full_name AS employee_name, a.quantity
+		FROM Tbl_Leave_adjustment a
+		JOIN Tbl_Employee e ON e.id = a.employee_id
+		WHERE a.batch_id = $1 AND a.reversal_of IS NULL
+		ORDER BY e.full_name
+	`, batchID)
+	return lines, err
+}
+
+// GetBatchLeaveAdjustments returns every adjustment of a batch, reversals included
+func (r *Repository) GetBatchLeaveAdjustments(batchID uuid.UUID) ([]models.LeaveAdjustmentRecord, error) {
+	var rows []models.LeaveAdjustmentRecord
+	err := r.DB.Select(&rows, `
+		SELECT a.id, a.employee_id, e.full_name AS employee_name, a.quantity, a.reason,
+			a.reversal_of, a.created_at
+		FROM Tbl_Leave_adjustment a
+		JOIN Tbl_Employee e ON e.id = a.employee_id
+		WHERE a.batch_id = $1
+		ORDER BY a.created_at, e.full_name
+	`, batchID)
+	return rows, err
+}
+
+// MarkLeaveAdjustmentBatchReversed records the reversal of a batch
+func (r *Repository) MarkLeaveAdjustmentBatchReversed(tx *sqlx.Tx, batchID, reversedBy uuid.UUID, reason string) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Leave_adjustment_batch
+		SET status = $2, reversed_by = $3, reversed_at = NOW(), reversal_reason = $4
+		WHERE id = $1
+	`, batchID, models.AdjustmentBatchReversed, reversedBy, reason)
+	return err
+}
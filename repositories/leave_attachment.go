@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// InsertLeaveAttachment stores attachment metadata for a leave
+func (r *Repository) InsertLeaveAttachment(tx *sqlx.Tx, att models.LeaveAttachment) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(`
+		INSERT INTO Tbl_Leave_attachment (leave_id, file_name, file_path, content_type, size_bytes, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, att.LeaveID, att.FileName, att.FilePath, att.ContentType, att.SizeBytes, att.UploadedBy).Scan(&id)
+	return id, err
+}
+
+// GetLeaveAttachments returns all attachments of a leave
+func (r *Repository) GetLeaveAttachments(leaveID uuid.UUID) ([]models.LeaveAttachment, error) {
+	attachments := []models.LeaveAttachment{}
+	err := r.DB.Select(&attachments, `
+		SELECT id, leave_id, file_name, file_path, content_type, size_bytes, uploaded_by, created_at
+		FROM Tbl_Leave_attachment
+		WHERE leave_id = $1
+		ORDER BY created_at
+	`, leaveID)
+	return attachments, err
+}
+
+// GetLeaveAttachment returns a single attachment of a leave
+func (r *Repository) GetLeaveAttachment(leaveID, attachmentID uuid.UUID) (models.LeaveAttachment, error) {
+	var att models.LeaveAttachment
+	err := r.DB.Get(&att, `
+		SELECT id, leave_id, file_name, file_path, content_type, size_bytes, uploaded_by, created_at
+		FROM Tbl_Leave_attachment
+		WHERE leave_id = $1 AND id = $2
+	`, leaveID, attachmentID)
+	return att, err
+}
+
+// CountLeaveAttachmentsTx returns how many attachments a leave has
+func (r *Repository) CountLeaveAttachmentsTx(tx *sqlx.Tx, leaveID uuid.UUID) (int, error) {
+	var count int
+	err := tx.Get(&count, `SELECT COUNT(*) FROM Tbl_Leave_attachment WHERE leave_id = $1`, leaveID)
+	return count, err
+}
+
+// DeleteLeaveAttachment removes attachment metadata
+func (r *Repository) DeleteLeaveAttachment(tx *sqlx.Tx, attachmentID uuid.UUID) error {
+	_, err := tx.Exec(`DELETE FROM Tbl_Leave_attachment WHERE id = $1`, attachmentID)
+	return err
+}
+
+// GetLeaveByIdNoLock reads a leave outside of a transaction
+func (r *Repository) GetLeaveByIdNoLock(leaveID uuid.UUID) (models.Leave, error) {
+	var leave models.Leave
+	err := r.DB.Get(&leave, `SELECT * FROM Tbl_Leave WHERE id=$1`, leaveID)
+	return leave, err
+}
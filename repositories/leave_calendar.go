@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// Leave statuses shown on the calendar and counted for team coverage
+var calendarLeaveStatuses = []string{"Pending", "MANAGER_APPROVED", "APPROVED", "WITHDRAWAL_PENDING"}
+
+// EmployeeTeamInfo is the reporting manager and department of an employee
+type EmployeeTeamInfo struct {
+	ManagerID  *uuid.UUID `db:"manager_id"`
+	Department *string    `db:"department"`
+}
+
+// GetEmployeeTeamInfo fetches the manager and department of an employee
+func (r *Repository) GetEmployeeTeamInfo(employeeID uuid.UUID) (EmployeeTeamInfo, error) {
+	var info EmployeeTeamInfo
+	err := r.DB.Get(&info, `SELECT manager_id, department FROM Tbl_Employee WHERE id = $1`, employeeID)
+	return info, err
+}
+
+// GetEmployeesByManager returns active employees reporting to a manager
+func (r *Repository) GetEmployeesByManager(managerID uuid.UUID) ([]models.CalendarEmployee, error) {
+	var employees []models.CalendarEmployee
+	err := r.DB.Select(&employees, `
+		SELECT id, full_name FROM Tbl_Employee
+		WHERE manager_id = $1 AND status = 'active'
+		ORDER BY full_name
+	`, managerID)
+	return employees, err
+}
+
+// GetEmployeesByDepartment returns active employees of a department
+func (r *Repository) GetEmployeesByDepartment(department string) ([]models.CalendarEmployee, error) {
+	var employees []models.CalendarEmployee
+	err := r.DB.Select(&employees, `
+		SELECT id, full_name FROM Tbl_Employee
+		WHERE department = $1 AND status = 'active'
+		ORDER BY full_name
+	`, department)
+	return employees, err
+}
+
+// GetActiveEmployees returns all active employees
+func (r *Repository) GetActiveEmployees() ([]models.CalendarEmployee, error) {
+	var employees []models.CalendarEmployee
+	err := r.DB.Select(&employees, `
+		SELECT id, full_name FROM Tbl_Employee
+		WHERE status = 'active'
+		ORDER BY full_name
+	`)
+	return employees, err
+}
+
+// GetTeamMemberIDsTx returns the active employees sharing the employee's manager, including the employee.
+// Employees without a manager have no team and get an empty result.
+func (r *Repository) GetTeamMemberIDsTx(tx *sqlx.Tx, employeeID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := tx.Select(&ids, `
+		SELECT t.id FROM Tbl_Employee t
+		JOIN Tbl_Employee e ON e.manager_id = t.manager_id
+		WHERE e.id = $1 AND (t.status = 'active' OR t.id = e.id)
+	`, employeeID)
+	return ids, err
+}
+
+// GetCalendarLeaves returns open and approved leaves of the given employees overlapping the range
+func (r *Repository) GetCalendarLeaves(q sqlx.Queryer, employeeIDs []uuid.UUID, start, end time.Time) ([]models.CalendarLeave, error) {
+	var leaves []models.CalendarLeave
+	if len(employeeIDs) == 0 {
+		return leaves, nil
+	}
+	ids := make([]string, len(employeeIDs))
+	for i, id := range employeeIDs {
+		ids[i] = id.String()
+	}
+	err := sqlx.Select(q, &leaves, `
+		SELECT l.id, l.employee_id, e.full_name AS employee_name, lt.name AS leave_type, l.status,
+			l.start_date, l.end_date, l.days, l.hours, COALESCE(l.half_id, 3) AS half_id,
+			l.start_half_id, l.end_half_id, l.start_time, l.end_time
+		FROM Tbl_Leave l
+		JOIN Tbl_Employee e ON e.id = l.employee_id
+		JOIN Tbl_Leave_type lt ON lt.id = l.leave_type_id
+		WHERE l.employee_id = ANY($1::uuid[])
+		AND l.status = ANY($2)
+		AND l.start_date <= $4 AND l.end_date >= $3
+		ORDER BY l.start_date, e.full_name
+	`, pq.Array(ids), pq.Array(calendarLeaveStatuses), start, end)
+	return leaves, err
+}
+
+// GetHolidaysBetween fetches holidays within a date range
+func (r *Repository) GetHolidaysBetween(start, end time.Time) ([]models.Holiday, error) {
+	var holidays []models.Holiday
+	err := r.DB.Select(&holidays, `
+		SELECT id, name, date, day, type, created_at, updated_at
+		FROM Tbl_Holiday
+		WHERE date BETWEEN $1 AND $2
+		ORDER BY date
+	`, start, end)
+	return holidays, err
+}
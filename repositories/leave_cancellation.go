@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// RequestLeaveWithdrawal moves an approved leave to WITHDRAWAL_PENDING and records the request
+func (r *Repository) RequestLeaveWithdrawal(tx *sqlx.Tx, leaveID, requestedBy uuid.UUID, reason string) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Leave
+		SET status = 'WITHDRAWAL_PENDING', withdrawal_requested_by = $2, withdrawal_reason = $3,
+		    withdrawal_requested_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'APPROVED'
+	`, leaveID, requestedBy, reason)
+	return err
+}
+
+// RejectLeaveWithdrawal returns a WITHDRAWAL_PENDING leave to APPROVED and clears the request
+func (r *Repository) RejectLeaveWithdrawal(tx *sqlx.Tx, leaveID uuid.UUID) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Leave
+		SET status = 'APPROVED', withdrawal_requested_by = NULL, withdrawal_reason = NULL,
+		    withdrawal_requested_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'WITHDRAWAL_PENDING'
+	`, leaveID)
+	return err
+}
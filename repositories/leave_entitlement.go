@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// entitlementSQL is the annual entitlement of employee e for leave type lt as of the date
+// bound to asOf: the most specific matching entitlement rule (designation and employment type
+// matches first, then the highest tenure band), or the leave type's default entitlement
+func entitlementSQL(asOf string) string {
+	return fmt.Sprintf(`COALESCE((
+		SELECT er.entitlement
+		FROM Tbl_Leave_entitlement_rule er
+		CROSS JOIN LATERAL (
+			SELECT CASE WHEN e.joining_date IS NULL OR e.joining_date::date > %[1]s::date THEN 0
+				ELSE (EXTRACT(YEAR FROM age(%[1]s::date, e.joining_date::date)) * 12
+					+ EXTRACT(MONTH FROM age(%[1]s::date, e.joining_date::date)))::int
+			END AS months
+		) tenure
+		WHERE er.leave_type_id = lt.id
+		AND tenure.months >= er.min_tenure_months
+		AND (er.max_tenure_months IS NULL OR tenure.months < er.max_tenure_months)
+		AND (er.designation_id IS NULL OR er.designation_id = e.designation_id)
+		AND (er.employment_type IS NULL OR er.employment_type = e.employment_type)
+		ORDER BY (er.designation_id IS NOT NULL)::int + (er.employment_type IS NOT NULL)::int DESC,
+			er.min_tenure_months DESC, er.entitlement DESC
+		LIMIT 1
+	), lt.default_entitlement, 0)`, asOf)
+}
+
+// EntitlementDate is the date tenure is measured at for balances of the year: today, kept
+// within the year
+func EntitlementDate(year int) time.Time {
+	today := time.Now()
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	date := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	if date.Before(start) {
+		return start
+	}
+	if date.After(end) {
+		return end
+	}
+	return date
+}
+
+// GetEntitlementTx returns an employee's annual entitlement of a leave type as of a date
+func (r *Repository) GetEntitlementTx(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID int, asOf time.Time) (float64, error) {
+	var entitlement float64
+	err := tx.Get(&entitlement, `
+		SELECT `+entitlementSQL("$2")+`
+		FROM Tbl_Leave_type lt
+		LEFT JOIN Tbl_Employee e ON e.id = $1
+		WHERE lt.id = $3
+	`, employeeID, asOf, leaveTypeID)
+	return entitlement, err
+}
+
+// GetOpeningEntitlementTx returns what a new balance row of the year starts with: the
+// employee's entitlement, or nothing for accruing leave types which are credited period by period
+func (r *Repository) GetOpeningEntitlementTx(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID, year int) (float64, error) {
+	var entitlement float64
+	err := tx.Get(&entitlement, `
+		SELECT CASE WHEN lt.accrual_frequency = 'NONE' THEN `+entitlementSQL("$2")+` ELSE 0 END
+		FROM Tbl_Leave_type lt
+		LEFT JOIN Tbl_Employee e ON e.id = $1
+		WHERE lt.id = $3
+	`, employeeID, EntitlementDate(year), leaveTypeID)
+	return entitlement, err
+}
+
+// GetLeaveEntitlementRules lists the entitlement tiers of a leave type
+func (r *Repository) GetLeaveEntitlementRules(leaveTypeID int) ([]models.LeaveEntitlementRule, error) {
+	rules := []models.LeaveEntitlementRule{}
+	err := r.DB.Select(&rules, `
+		SELECT er.id, er.leave_type_id, er.min_tenure_months, er.max_tenure_months,
+			er.designation_id, d.designation_name, er.employment_type, er.entitlement, er.created_at
+		FROM Tbl_Leave_entitlement_rule er
+		LEFT JOIN Tbl_Designation d ON d.id = er.designation_id
+		WHERE er.leave_type_id = $1
+		ORDER BY er.min_tenure_months, d.designation_name NULLS FIRST, er.employment_type NULLS FIRST
+	`, leaveTypeID)
+	return rules, err
+}
+
+// ReplaceLeaveEntitlementRules replaces the entitlement tiers of a leave type
+func (r *Repository) ReplaceLeaveEntitlementRules(tx *sqlx.Tx, leaveTypeID int, rules []models.LeaveEntitlementRuleInput) error {
+	if _, err := tx.Exec(`DELETE FROM Tbl_Leave_entitlement_rule WHERE leave_type_id = $1`, leaveTypeID); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		_, err := tx.Exec(`
+			INSERT INTO Tbl_Leave_entitlement_rule
+			(leave_type_id, min_tenure_months, max_tenure_months, designation_id, employment_type, entitlement)
+			VALUES ($1,$2,$3,$4,$5,$6)
+		`, leaveTypeID, rule.MinTenureMonths, rule.MaxTenureMonths, rule.DesignationID, rule.EmploymentType, rule.Entitlement)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecomputeLeaveEntitlements brings the balances of a leave type for the year in line with each
+// employee's current entitlement. The difference between the entitlement and what was granted
+// so far is posted to the ledger as a GRANT, so used days and adjustments are kept.
+// Returns the number of balances changed.
+func (r *Repository) RecomputeLeaveEntitlements(tx *sqlx.Tx, leaveTypeID, year int, changedBy uuid.UUID) (int, error) {
+	var rows []struct {
+		EmployeeID  uuid.UUID `db:"employee_id"`
+		Entitlement float64   `db:"entitlement"`
+		Granted     float64   `db:"granted"`
+	}
+	err := tx.Select(&rows, `
+		SELECT DISTINCT ON (b.employee_id) b.employee_id,
+			`+entitlementSQL("$3")+` AS entitlement,
+			COALESCE((
+				SELECT SUM(l.quantity) FROM Tbl_Leave_ledger l
+				WHERE l.employee_id = b.employee_id AND l.leave_type_id = b.leave_type_id
+				AND l.year = b.year AND l.entry_type = 'GRANT'
+			), 0) AS granted
+		FROM Tbl_Leave_balance b
+		JOIN Tbl_Leave_type lt ON lt.id = b.leave_type_id
+		JOIN Tbl_Employee e ON e.id = b.employee_id
+		WHERE b.leave_type_id = $1 AND b.year = $2
+		ORDER BY b.employee_id
+	`, leaveTypeID, year, EntitlementDate(year))
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, row := range rows {
+		difference := math.Round((row.Entitlement-row.Granted)*10000) / 10000
+		if difference == 0 {
+			continue
+		}
+		note := fmt.Sprintf("Entitlement changed from %v to %v", row.Granted, row.Entitlement)
+		err := r.PostLeaveLedgerEntry(tx, row.EmployeeID, leaveTypeID, year, difference,
+			models.LedgerRef{EntryType: models.LedgerGrant, Note: note, CreatedBy: &changedBy})
+		if err != nil {
+			return changed, err
+		}
+		changed++
+	}
+	return changed, nil
+}
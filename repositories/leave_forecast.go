@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// GetAccrualEmployeeTx fetches the employment dates of an employee used for accrual proration
+func (r *Repository) GetAccrualEmployeeTx(tx *sqlx.Tx, employeeID uuid.UUID) (models.AccrualEmployee, error) {
+	var emp models.AccrualEmployee
+	err := tx.Get(&emp, `
+		SELECT id, full_name, joining_date, ending_date
+		FROM Tbl_Employee WHERE id = $1
+	`, employeeID)
+	return emp, err
+}
+
+// GetAccruedPeriodsTx returns the accrual periods of the year already credited to an employee
+// for a leave type, keyed by period start
+func (r *Repository) GetAccruedPeriodsTx(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID, year int) (map[time.Time]bool, error) {
+	var starts []time.Time
+	err := tx.Select(&starts, `
+		SELECT period_start FROM Tbl_Leave_accrual
+		WHERE employee_id = $1 AND leave_type_id = $2 AND year = $3
+	`, employeeID, leaveTypeID, year)
+	if err != nil {
+		return nil, err
+	}
+	accrued := make(map[time.Time]bool, len(starts))
+	for _, start := range starts {
+		accrued[time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)] = true
+	}
+	return accrued, nil
+}
+
+// GetOpenLeavesTx returns an employee's leaves of a leave type that are awaiting approval
+// (whatever their dates) or approved and starting on or after from, in date order
+func (r *Repository) GetOpenLeavesTx(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID int, from time.Time) ([]models.ProjectedLeave, error) {
+	var leaves []models.ProjectedLeave
+	err := tx.Select(&leaves, `
+		SELECT id, status, start_date, end_date, COALESCE(hours, days, 0) AS quantity
+		FROM Tbl_Leave
+		WHERE employee_id = $1 AND leave_type_id = $2
+		AND (status IN ('Pending', 'MANAGER_APPROVED') OR (status = 'APPROVED' AND start_date >= $3))
+		ORDER BY start_date
+	`, employeeID, leaveTypeID, from)
+	return leaves, err
+}
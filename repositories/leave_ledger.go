@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// ledgerBalanceColumn returns the Tbl_Leave_balance total an entry type moves and the
+// change to apply to it for a quantity (the signed effect on closing)
+func ledgerBalanceColumn(entryType string, quantity float64) (string, float64) {
+	switch entryType {
+	case models.LedgerGrant:
+		return "opening", quantity
+	case models.LedgerAccrual:
+		return "accrued", quantity
+	case models.LedgerConsumption, models.LedgerRestoration:
+		return "used", -quantity
+	case models.LedgerCarryForward:
+		if quantity > 0 {
+			return "opening", quantity
+		}
+	}
+	return "adjusted", quantity
+}
+
+// InsertLeaveLedgerEntry records a balance movement without touching the balance totals
+// (used when the balance row is created with the amount already in it)
+func (r *Repository) InsertLeaveLedgerEntry(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID, year int, quantity float64, ref models.LedgerRef) error {
+	if ref.EntryType == "" {
+		ref.EntryType = models.LedgerAdjustment
+	}
+	_, err := tx.Exec(`
+		INSERT INTO Tbl_Leave_ledger
+		(employee_id, leave_type_id, year, entry_type, quantity, leave_id, adjustment_id, reference, note, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''), $10)
+	`, employeeID, leaveTypeID, year, ref.EntryType, quantity, ref.LeaveID, ref.AdjustmentID, ref.Reference, ref.Note, ref.CreatedBy)
+	return err
+}
+
+// PostLeaveLedgerEntry records a balance movement and applies it to the balance totals.
+// Returns sql.ErrNoRows when the employee has no balance row for the year.
+func (r *Repository) PostLeaveLedgerEntry(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID, year int, quantity float64, ref models.LedgerRef) error {
+	if quantity == 0 {
+		return nil
+	}
+	if ref.EntryType == "" {
+		ref.EntryType = models.LedgerAdjustment
+	}
+	column, delta := ledgerBalanceColumn(ref.EntryType, quantity)
+	result, err := tx.Exec(fmt.Sprintf(`
+		UPDATE Tbl_Leave_balance
+		SET %[1]s = COALESCE(%[1]s, 0) + $1, closing = COALESCE(closing, 0) + $2, updated_at = NOW()
+		WHERE employee_id=$3 AND leave_type_id=$4 AND year=$5
+	`, column), delta, quantity, employeeID, leaveTypeID, year)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return r.InsertLeaveLedgerEntry(tx, employeeID, leaveTypeID, year, quantity, ref)
+}
+
+// GetLeaveLedger lists an employee's ledger entries, optionally filtered by leave type and year
+func (r *Repository) GetLeaveLedger(employeeID uuid.UUID, leaveTypeID *int, year *int) ([]models.LeaveLedgerEntry, error) {
+	entries := []models.LeaveLedgerEntry{}
+	err := r.DB.Select(&entries, `
+		SELECT l.id, l.employee_id, l.leave_type_id, lt.name AS leave_type, l.year, l.entry_type, l.quantity,
+			l.leave_id, l.adjustment_id, l.reference, l.note, l.created_by, c.full_name AS created_by_name, l.created_at
+		FROM Tbl_Leave_ledger l
+		JOIN Tbl_Leave_type lt ON lt.id = l.leave_type_id
+		LEFT JOIN Tbl_Employee c ON c.id = l.created_by
+		WHERE l.employee_id = $1
+		AND ($2::int IS NULL OR l.leave_type_id = $2)
+		AND ($3::int IS NULL OR l.year = $3)
+		ORDER BY l.year, l.leave_type_id, l.created_at
+	`, employeeID, leaveTypeID, year)
+	return entries, err
+}
+
+// GetLedgerMismatches compares Tbl_Leave_balance with the ledger and returns the balances whose
+// closing or used disagree with their entries, whose totals don't add up, or that are duplicated
+func (r *Repository) GetLedgerMismatches(year *int, employeeID *uuid.UUID) ([]models.LedgerMismatch, error) {
+	mismatches := []models.LedgerMismatch{}
+	err := r.DB.Select(&mismatches, `
+		WITH b AS (
+			SELECT employee_id, leave_type_id, year,
+				SUM(COALESCE(closing, 0)) AS closing,
+				SUM(COALESCE(used, 0)) AS used,
+				BOOL_OR(ROUND(COALESCE(closing, 0) - (COALESCE(opening, 0) + COALESCE(accrued, 0) - COALESCE(used, 0) + COALESCE(adjusted, 0)), 4) <> 0) AS totals_mismatch,
+				COUNT(*) AS balance_rows
+			FROM Tbl_Leave_balance
+			WHERE ($1::int IS NULL OR year = $1) AND ($2::uuid IS NULL OR employee_id = $2)
+			GROUP BY employee_id, leave_type_id, year
+		), l AS (
+			SELECT employee_id, leave_type_id, year,
+				SUM(quantity) AS ledger_closing,
+				COALESCE(-SUM(quantity) FILTER (WHERE entry_type IN ('CONSUMPTION', 'RESTORATION')), 0) AS ledger_used,
+				COUNT(*) AS ledger_entries
+			FROM Tbl_Leave_ledger
+			WHERE ($1::int IS NULL OR year = $1) AND ($2::uuid IS NULL OR employee_id = $2)
+			GROUP BY employee_id, leave_type_id, year
+		)
+		SELECT * FROM (
+			SELECT COALESCE(b.employee_id, l.employee_id) AS employee_id, e.full_name AS employee_name,
+				COALESCE(b.leave_type_id, l.leave_type_id) AS leave_type_id, lt.name AS leave_type,
+				COALESCE(b.year, l.year) AS year,
+				COALESCE(b.closing, 0) AS closing, COALESCE(l.ledger_closing, 0) AS ledger_closing,
+				COALESCE(b.used, 0) AS used, COALESCE(l.ledger_used, 0) AS ledger_used,
+				ROUND(COALESCE(b.closing, 0) - COALESCE(l.ledger_closing, 0), 4) AS difference,
+				COALESCE(l.ledger_entries, 0) AS ledger_entries,
+				COALESCE(b.balance_rows, 0) AS balance_rows,
+				COALESCE(b.totals_mismatch, FALSE) AS totals_mismatch
+			FROM b
+			FULL OUTER JOIN l ON l.employee_id = b.employee_id AND l.leave_type_id = b.leave_type_id AND l.year = b.year
+			JOIN Tbl_Employee e ON e.id = COALESCE(b.employee_id, l.employee_id)
+			JOIN Tbl_Leave_type lt ON lt.id = COALESCE(b.leave_type_id, l.leave_type_id)
+		) r
+		WHERE r.difference <> 0 OR ROUND(r.used - r.ledger_used, 4) <> 0 OR r.totals_mismatch OR r.balance_rows > 1
+		ORDER BY r.year DESC, r.employee_name, r.leave_type_id
+	`, year, employeeID)
+	return mismatches, err
+}
+
+// EnsureLeaveBalance fetches (and locks) an employee's balance for the year, creating it with
+// the employee's opening entitlement when it doesn't exist yet
+func (r *Repository) EnsureLeaveBalance(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID, year int) (LeaveBalanceForAdjustment, error) {
+	balance, err := r.GetLeaveBalanceForAdjustment(tx, employeeID, leaveTypeID, year)
+	if err != sql.ErrNoRows {
+		return balance, err
+	}
+	return r.CreateLeaveBalanceForAdjustment(tx, employeeID, leaveTypeID, year)
+}
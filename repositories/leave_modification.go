@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// UpdateLeavePeriod changes the period and quantity of an approved leave
+func (r *Repository) UpdateLeavePeriod(tx *sqlx.Tx, leaveID uuid.UUID, start, end time.Time, timingID int, qty models.LeaveQuantity) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Leave
+		SET start_date = $2, end_date = $3, half_id = $4, days = $5, hours = $6,
+		    start_half_id = $7, end_half_id = $8, updated_at = NOW()
+		WHERE id = $1
+	`, leaveID, start, end, timingID, qty.Days, qty.Hours, qty.StartHalfID, qty.EndHalfID)
+	return err
+}
+
+// SetParentLeave links a leave created by a split to the original leave
+func (r *Repository) SetParentLeave(tx *sqlx.Tx, leaveID, parentID uuid.UUID) error {
+	_, err := tx.Exec(`UPDATE Tbl_Leave SET parent_leave_id = $2 WHERE id = $1`, leaveID, parentID)
+	return err
+}
+
+// RestoreLeaveBalance returns quantity to the balance of a leave type for the year the leave's
+// days were consumed in (its start year), recorded as a RESTORATION of the leave
+func (r *Repository) RestoreLeaveBalance(tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID, year int, quantity float64, leaveID uuid.UUID, by *uuid.UUID) error {
+	if _, err := r.EnsureLeaveBalance(tx, employeeID, leaveTypeID, year); err != nil {
+		return err
+	}
+	return r.PostLeaveLedgerEntry(tx, employeeID, leaveTypeID, year, quantity,
+		models.LedgerRef{EntryType: models.LedgerRestoration, LeaveID: &leaveID, CreatedBy: by})
+}
+
+// InsertLeaveModification records a shortened or split leave
+func (r *Repository) InsertLeaveModification(tx *sqlx.Tx, m models.LeaveModification) error {
+	_, err := tx.Exec(`
+		INSERT INTO Tbl_Leave_modification
+		(leave_id, action, old_start_date, old_end_date, old_days, old_hours, new_periods, new_days, restored, result_leave_ids, reason, modified_by)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10::uuid[],$11,$12)
+	`, m.LeaveID, m.Action, m.OldStartDate, m.OldEndDate, m.OldDays, m.OldHours, m.NewPeriods, m.NewDays, m.Restored,
+		m.ResultLeaveIDs, m.Reason, m.ModifiedBy)
+	return err
+}
+
+// GetLeaveModifications returns the modification history of a leave, including
+// modifications of the leave it was split from
+func (r *Repository) GetLeaveModifications(leaveID uuid.UUID) ([]models.LeaveModification, error) {
+	modifications := []models.LeaveModification{}
+	err := r.DB.Select(&modifications, `
+		SELECT m.id, m.leave_id, m.action, m.old_start_date, m.old_end_date, m.old_days, m.old_hours,
+			m.new_periods, m.new_days, m.restored, m.result_leave_ids::text[] AS result_leave_ids,
+			m.reason, m.modified_by, e.full_name AS modified_by_name, m.created_at
+		FROM Tbl_Leave_modification m
+		LEFT JOIN Tbl_Employee e ON e.id = m.modified_by
+		WHERE m.leave_id = $1
+		   OR m.leave_id = (SELECT parent_leave_id FROM Tbl_Leave WHERE id = $1)
+		ORDER BY m.created_at
+	`, leaveID)
+	return modifications, err
+}
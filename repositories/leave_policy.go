@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// UpdateLeaveTypePolicy saves the policy rule columns of a leave type
+func (r *Repository) UpdateLeaveTypePolicy(tx *sqlx.Tx, leaveTypeID int, lt models.LeaveType) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Leave_type
+		SET min_notice_days = $1,
+			max_consecutive_days = $2,
+			allowed_genders = $3,
+			min_tenure_months = $4,
+			attachment_required_after_days = $5,
+			allow_negative_balance = $6,
+			max_negative_balance = $7,
+			accrual_frequency = $8,
+			carry_forward_max = $9,
+			encashable = $10,
+			encash_max = $11,
+			updated_at = NOW()
+		WHERE id = $12
+	`,
+		lt.MinNoticeDays,
+		lt.MaxConsecutiveDays,
+		lt.AllowedGenders,
+		lt.MinTenureMonths,
+		lt.AttachmentRequiredAfterDays,
+		lt.AllowNegativeBalance,
+		lt.MaxNegativeBalance,
+		lt.AccrualFrequency,
+		lt.CarryForwardMax,
+		lt.Encashable,
+		lt.EncashMax,
+		leaveTypeID,
+	)
+	return err
+}
+
+// GetEmployeePolicyProfile fetches the employee attributes used by leave policy rules
+func (r *Repository) GetEmployeePolicyProfile(tx *sqlx.Tx, employeeID uuid.UUID) (models.EmployeePolicyProfile, error) {
+	var profile models.EmployeePolicyProfile
+	err := tx.Get(&profile, `SELECT gender, joining_date FROM Tbl_Employee WHERE id = $1`, employeeID)
+	return profile, err
+}
+
+// GetBlackoutsForRange returns blackout periods overlapping the date range that apply to the leave type
+func (r *Repository) GetBlackoutsForRange(tx *sqlx.Tx, start, end time.Time, leaveTypeID int) ([]models.LeaveBlackout, error) {
+	var blackouts []models.LeaveBlackout
+	err := tx.Select(&blackouts, `
+		SELECT id, name, start_date, end_date, leave_type_id, created_by, created_at
+		FROM Tbl_Leave_blackout
+		WHERE start_date <= $2 AND end_date >= $1
+		AND (leave_type_id IS NULL OR leave_type_id = $3)
+		ORDER BY start_date
+	`, start, end, leaveTypeID)
+	return blackouts, err
+}
+
+// AddLeaveBlackout inserts a blackout period
+func (r *Repository) AddLeaveBlackout(tx *sqlx.Tx, input models.LeaveBlackoutInput, createdBy uuid.UUID) (int, error) {
+	var id int
+	err := tx.QueryRow(`
+		INSERT INTO Tbl_Leave_blackout (name, start_date, end_date, leave_type_id, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, input.Name, input.StartDate, input.EndDate, input.LeaveTypeID, createdBy).Scan(&id)
+	return id, err
+}
+
+// GetAllLeaveBlackouts fetches all blackout periods
+func (r *Repository) GetAllLeaveBlackouts() ([]models.LeaveBlackout, error) {
+	var blackouts []models.LeaveBlackout
+	err := r.DB.Select(&blackouts, `
+		SELECT id, name, start_date, end_date, leave_type_id, created_by, created_at
+		FROM Tbl_Leave_blackout
+		ORDER BY start_date
+	`)
+	return blackouts, err
+}
+
+// DeleteLeaveBlackout removes a blackout period
+func (r *Repository) DeleteLeaveBlackout(tx *sqlx.Tx, id int) error {
+	result, err := tx.Exec(`DELETE FROM Tbl_Leave_blackout WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
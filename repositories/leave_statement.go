@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// GetStatementBalances fetches an employee's stored balances for the year
+func (r *Repository) GetStatementBalances(employeeID uuid.UUID, year int) ([]models.LeaveStatementBalance, error) {
+	var balances []models.LeaveStatementBalance
+	err := r.DB.Select(&balances, `
+		SELECT b.leave_type_id, lt.name AS leave_type, lt.unit,
+			SUM(COALESCE(b.opening, 0)) AS opening,
+			SUM(COALESCE(b.accrued, 0)) AS accrued,
+			SUM(COALESCE(b.used, 0)) AS used,
+			SUM(COALESCE(b.adjusted, 0)) AS adjusted,
+			SUM(COALESCE(b.closing, 0)) AS closing
+		FROM Tbl_Leave_balance b
+		JOIN Tbl_Leave_type lt ON lt.id = b.leave_type_id
+		WHERE b.employee_id = $1 AND b.year = $2
+		GROUP BY b.leave_type_id, lt.name, lt.unit
+		ORDER BY b.leave_type_id
+	`, employeeID, year)
+	return balances, err
+}
+
+// GetStatementEntries fetches an employee's ledger entries for the year in the order they were
+// posted, with the dates of the leave and the reason of the adjustment behind each entry
+func (r *Repository) GetStatementEntries(employeeID uuid.UUID, year int) ([]models.LeaveStatementEntry, error) {
+	var entries []models.LeaveStatementEntry
+	err := r.DB.Select(&entries, `
+		SELECT l.leave_type_id, lt.name AS leave_type, lt.unit, l.entry_type, l.quantity, l.note, l.created_at,
+			l.leave_id, lv.start_date AS leave_start, lv.end_date AS leave_end,
+			a.reason AS adjustment_reason
+		FROM Tbl_Leave_ledger l
+		JOIN Tbl_Leave_type lt ON lt.id = l.leave_type_id
+		LEFT JOIN Tbl_Leave lv ON lv.id = l.leave_id
+		LEFT JOIN Tbl_Leave_adjustment a ON a.id = l.adjustment_id
+		WHERE l.employee_id = $1 AND l.year = $2
+		ORDER BY l.leave_type_id, l.created_at, l.id
+	`, employeeID, year)
+	return entries, err
+}
@@ -4,14 +4,19 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
 )
 
 type EmpMonthlyData struct {
-	ID          uuid.UUID `db:"id" json:"id"`
-	FullName    string    `db:"full_name" json:"full_name"`
-	Salary      *float64  `db:"salary" json:"salary"`
-	Status      string    `db:"status" json:"status"`
-	JoiningDate time.Time `db:"joining_date" json:"joining_date"`
+	ID          uuid.UUID  `db:"id" json:"id"`
+	FullName    string     `db:"full_name" json:"full_name"`
+	Salary      *float64   `db:"salary" json:"salary"`
+	Status      string     `db:"status" json:"status"`
+	JoiningDate time.Time  `db:"joining_date" json:"joining_date"`
+	EndingDate  *time.Time `db:"ending_date" json:"ending_date"`
+	// SalaryStructureID is the employee's own salary structure, else the designation's
+	SalaryStructureID *uuid.UUID `db:"salary_structure_id" json:"salary_structure_id"`
+	models.StatutoryProfile
 }
 
 type ExistingRun struct {
@@ -48,6 +53,7 @@ func (r *Repository) GetCompanyCurrWorkingDays() int {
 }
 
 // Get All Employee Base on MONTH and YEARS also JOININIG DATES
+// Employees who left are included for the month their ending_date falls in, and before it
 
 func (r *Repository) GetEmployeeByMonthAndYear(input struct {
 	Month int `json:"month" validate:"required"`
@@ -56,20 +62,44 @@ func (r *Repository) GetEmployeeByMonthAndYear(input struct {
 
 	var employees []EmpMonthlyData
 
+	firstDay := time.Date(input.Year, time.Month(input.Month), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstDay.AddDate(0, 1, -1)
+
 	query := `
-		SELECT id, full_name, salary, status, joining_date
-		FROM tbl_employee
-		WHERE status = 'active'
-		AND (
-			EXTRACT(YEAR FROM joining_date) < $1
-			OR (EXTRACT(YEAR FROM joining_date) = $1 
-			    AND EXTRACT(MONTH FROM joining_date) <= $2)
-		)
+		SELECT e.id, e.full_name, e.salary, e.status, e.joining_date, e.ending_date,
+			COALESCE(e.salary_structure_id, d.salary_structure_id) AS salary_structure_id,
+			e.tax_regime, e.pt_state, e.pf_enabled
+		FROM tbl_employee e
+		LEFT JOIN Tbl_Designation d ON d.id = e.designation_id
+		WHERE (e.status = 'active' OR e.ending_date IS NOT NULL)
+		AND e.joining_date <= $2
+		AND (e.ending_date IS NULL OR e.ending_date >= $1)
 	`
 
-	err := r.DB.Select(&employees, query, input.Year, input.Month)
+	err := r.DB.Select(&employees, query, firstDay, lastDay)
 	if err != nil {
 		return nil, err
 	}
 	return employees, nil
 }
+
+// GetPayrollDayRateBasis returns the basis of the days a month's salary is divided by for the
+// per-day rate
+func (r *Repository) GetPayrollDayRateBasis() string {
+	var basis string
+	err := r.DB.Get(&basis, `SELECT payroll_day_rate_basis FROM Tbl_Company_Settings LIMIT 1`)
+	if err != nil || !models.IsValidDayRateBasis(basis) {
+		basis = models.DayRateFixed // fallback default
+	}
+	return basis
+}
+
+// GetPayrollProrationBasis returns the basis joining and exit months are prorated on
+func (r *Repository) GetPayrollProrationBasis() string {
+	var basis string
+	err := r.DB.Get(&basis, `SELECT payroll_proration_basis FROM Tbl_Company_Settings LIMIT 1`)
+	if err != nil || !models.IsValidProrationBasis(basis) {
+		basis = models.ProrationCalendarDays // fallback default
+	}
+	return basis
+}
@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+const payrollRunColumns = `id, month, year, status, prepared_by, submitted_by, submitted_at,
+	approved_by, approved_at, finalized_by, finalized_at, paid_by, paid_at, created_at, updated_at`
+
+// GetPayrollRun returns a payroll run
+func (r *Repository) GetPayrollRun(id uuid.UUID) (models.PayrollRun, error) {
+	var run models.PayrollRun
+	err := r.DB.Get(&run, `
+		SELECT `+payrollRunColumns+`
+		FROM Tbl_Payroll_run
+		WHERE id = $1
+	`, id)
+	return run, err
+}
+
+// GetPayrollRunTx locks and returns a payroll run
+func (r *Repository) GetPayrollRunTx(tx *sqlx.Tx, id uuid.UUID) (models.PayrollRun, error) {
+	var run models.PayrollRun
+	err := tx.Get(&run, `
+		SELECT `+payrollRunColumns+`
+		FROM Tbl_Payroll_run
+		WHERE id = $1
+		FOR UPDATE
+	`, id)
+	return run, err
+}
+
+// GetPayrollRunsForMonthTx locks and returns the payroll runs of a month
+func (r *Repository) GetPayrollRunsForMonthTx(tx *sqlx.Tx, month, year int) ([]models.PayrollRun, error) {
+	runs := []models.PayrollRun{}
+	err := tx.Select(&runs, `
+		SELECT `+payrollRunColumns+`
+		FROM Tbl_Payroll_run
+		WHERE month = $1 AND year = $2
+		ORDER BY created_at
+		FOR UPDATE
+	`, month, year)
+	return runs, err
+}
+
+// InsertPayrollRunTx creates the draft payroll run of a month
+func (r *Repository) InsertPayrollRunTx(tx *sqlx.Tx, month, year int, preparedBy uuid.UUID) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.Get(&id, `
+		INSERT INTO Tbl_Payroll_run (month, year, status, prepared_by)
+		VALUES ($1,$2,$3,$4)
+		RETURNING id
+	`, month, year, models.PayrollStatusDraft, preparedBy)
+	return id, err
+}
+
+// MarkPayrollRunPreparedTx records who refreshed the draft payslips of a run
+func (r *Repository) MarkPayrollRunPreparedTx(tx *sqlx.Tx, id, preparedBy uuid.UUID) error {
+	_, err := tx.Exec(`UPDATE Tbl_Payroll_run SET prepared_by = $2, updated_at = NOW() WHERE id = $1`, id, preparedBy)
+	return err
+}
+
+// payrollTransitionColumns are the actor and time columns set when a run enters a status
+var payrollTransitionColumns = map[string]string{
+	models.PayrollStatusSubmitted: "submitted_by = $3, submitted_at = NOW()",
+	models.PayrollStatusApproved:  "approved_by = $3, approved_at = NOW()",
+	models.PayrollStatusFinalized: "finalized_by = $3, finalized_at = NOW()",
+	models.PayrollStatusPaid:      "paid_by = $3, paid_at = NOW()",
+	// back to draft: the run has to be submitted and approved again
+	models.PayrollStatusDraft: "submitted_by = NULL, submitted_at = NULL, approved_by = NULL, approved_at = NULL, prepared_by = COALESCE(prepared_by, $3)",
+}
+
+// TransitionPayrollRunTx moves a payroll run to a status and records the transition with the
+// actor's comments in the run's history
+func (r *Repository) TransitionPayrollRunTx(tx *sqlx.Tx, run models.PayrollRun, toStatus, action string, actorID uuid.UUID, comments string) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Payroll_run
+		SET status = $2, `+payrollTransitionColumns[toStatus]+`, updated_at = NOW()
+		WHERE id = $1
+	`, run.ID, toStatus, actorID)
+	if err != nil {
+		return err
+	}
+	return r.InsertPayrollRunActionTx(tx, run.ID, action, &run.Status, toStatus, actorID, comments)
+}
+
+// InsertPayrollRunActionTx adds an entry to a payroll run's history
+func (r *Repository) InsertPayrollRunActionTx(tx *sqlx.Tx, runID uuid.UUID, action string, fromStatus *string, toStatus string, actorID uuid.UUID, comments string) error {
+	var note *string
+	if comments != "" {
+		note = &comments
+	}
+	_, err := tx.Exec(`
+		INSERT INTO Tbl_Payroll_run_action (payroll_run_id, action, from_status, to_status, actor_id, comments)
+		VALUES ($1,$2,$3,$4,$5,$6)
+	`, runID, action, fromStatus, toStatus, actorID, note)
+	return err
+}
+
+// GetPayrollRunActions returns the history of a payroll run, oldest first
+func (r *Repository) GetPayrollRunActions(runID uuid.UUID) ([]models.PayrollRunAction, error) {
+	actions := []models.PayrollRunAction{}
+	err := r.DB.Select(&actions, `
+		SELECT a.id, a.action, a.from_status, a.to_status, a.actor_id, e.full_name AS actor_name,
+			a.comments, a.created_at
+		FROM Tbl_Payroll_run_action a
+		LEFT JOIN Tbl_Employee e ON e.id = a.actor_id
+		WHERE a.payroll_run_id = $1
+		ORDER BY a.created_at
+	`, runID)
+	return actions, err
+}
+
+// GetPayrollApproverEmails returns the emails of the active ADMIN and SUPERADMIN users who may
+// approve a payroll run, leaving out its preparer and submitter
+func (r *Repository) GetPayrollApproverEmails(exclude ...uuid.UUID) ([]string, error) {
+	ids := make([]string, 0, len(exclude))
+	for _, id := range exclude {
+		ids = append(ids, id.String())
+	}
+	var emails []string
+	err := r.DB.Select(&emails, `
+		SELECT e.email
+		FROM Tbl_Employee e
+		JOIN Tbl_Role r ON e.role_id = r.id
+		WHERE r.type IN ('ADMIN', 'SUPERADMIN') AND e.status = 'active'
+		AND NOT (e.id::text = ANY($1))
+	`, pq.Array(ids))
+	return emails, err
+}
+
+// DeleteRunPayslipsTx deletes the payslips of a payroll run and their lines
+func (r *Repository) DeleteRunPayslipsTx(tx *sqlx.Tx, runID uuid.UUID) error {
+	_, err := tx.Exec(`DELETE FROM Tbl_Payslip WHERE payroll_run_id = $1`, runID)
+	return err
+}
+
+// InsertPayslipTx stores a payslip of a payroll run with its lines and returns its ID
+func (r *Repository) InsertPayslipTx(tx *sqlx.Tx, runID uuid.UUID, p models.PayslipRecord) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.Get(&id, `
+		INSERT INTO Tbl_Payslip
+		(payroll_run_id, employee_id, basic_salary, working_days, paid_leaves, unpaid_leaves,
+		 deduction_amount, net_salary, encashment_amount, leave_fingerprint, payslip_type, correction_id,
+		 proration_basis, payable_days, period_days, day_rate_basis)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
+		RETURNING id
+	`, runID, p.EmployeeID, p.BasicSalary, p.WorkingDays, p.PaidLeaves, p.UnpaidLeaves,
+		p.Deductions, p.NetSalary, p.Encashment, p.LeaveFingerprint, p.PayslipType, p.CorrectionID,
+		p.ProrationBasis, p.PayableDays, p.PeriodDays, p.DayRateBasis)
+	if err != nil {
+		return id, err
+	}
+	return id, r.InsertPayslipLinesTx(tx, id, p.Lines)
+}
+
+// GetRunPayslips returns the payslips of a payroll run with their lines
+func (r *Repository) GetRunPayslips(runID uuid.UUID) ([]models.PayslipRecord, error) {
+	payslips := []models.PayslipRecord{}
+	err := r.DB.Select(&payslips, `
+		SELECT p.id, p.employee_id, e.full_name,
+			COALESCE(p.basic_salary, 0) AS basic_salary,
+			COALESCE(p.working_days, 0) AS working_days,
+			COALESCE(p.day_rate_basis, 'FIXED') AS day_rate_basis,
+			COALESCE(p.paid_leaves, 0) AS paid_leaves,
+			COALESCE(p.unpaid_leaves, 0) AS unpaid_leaves,
+			COALESCE(p.deduction_amount, 0) AS deduction_amount,
+			COALESCE(p.net_salary, 0) AS net_salary,
+			COALESCE(p.encashment_amount, 0) AS encashment_amount,
+			COALESCE(p.leave_fingerprint, '') AS leave_fingerprint,
+			p.status, p.payslip_type, p.correction_id,
+			p.proration_basis, p.payable_days, p.period_days, p.payment_reference
+		FROM Tbl_Payslip p
+		JOIN Tbl_Employee e ON e.id = p.employee_id
+		WHERE p.payroll_run_id = $1
+		ORDER BY e.full_name, p.payslip_type DESC
+	`, runID)
+	if err != nil || len(payslips) == 0 {
+		return payslips, err
+	}
+
+	var lines []struct {
+		PayslipID uuid.UUID `db:"payslip_id"`
+		models.PayslipLine
+	}
+	err = r.DB.Select(&lines, `
+		SELECT pl.payslip_id, pl.code, pl.name, pl.kind, pl.amount
+		FROM Tbl_Payslip_line pl
+		JOIN Tbl_Payslip p ON p.id = pl.payslip_id
+		WHERE p.payroll_run_id = $1
+		ORDER BY pl.sort_order
+	`, runID)
+	if err != nil {
+		return nil, err
+	}
+	byPayslip := make(map[uuid.UUID][]models.PayslipLine, len(payslips))
+	for _, line := range lines {
+		byPayslip[line.PayslipID] = append(byPayslip[line.PayslipID], line.PayslipLine)
+	}
+	for i := range payslips {
+		payslips[i].Lines = byPayslip[payslips[i].ID]
+		if payslips[i].Lines == nil {
+			payslips[i].Lines = []models.PayslipLine{}
+		}
+	}
+	return payslips, nil
+}
+
+// GetLeaveFingerprints returns, per employee, a hash of the approved leave records overlapping
+// a month: any leave approved, cancelled or edited changes it
+func (r *Repository) GetLeaveFingerprints(month, year int) (map[uuid.UUID]string, error) {
+	firstDay := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstDay.AddDate(0, 1, -1)
+	var rows []struct {
+		EmployeeID  uuid.UUID `db:"employee_id"`
+		Fingerprint string    `db:"fingerprint"`
+	}
+	err := r.DB.Select(&rows, `
+		SELECT l.employee_id,
+			MD5(STRING_AGG(CONCAT_WS('|', l.id, l.leave_type_id, lt.is_paid, l.start_date, l.end_date,
+				l.days, l.hours, l.half_id, l.start_half_id, l.end_half_id), ',' ORDER BY l.id)) AS fingerprint
+		FROM Tbl_Leave l
+		JOIN Tbl_Leave_type lt ON lt.id = l.leave_type_id
+		WHERE l.status = 'APPROVED'
+		AND l.start_date <= $1
+		AND l.end_date >= $2
+		GROUP BY l.employee_id
+	`, lastDay, firstDay)
+	if err != nil {
+		return nil, err
+	}
+	fingerprints := make(map[uuid.UUID]string, len(rows))
+	for _, row := range rows {
+		fingerprints[row.EmployeeID] = row.Fingerprint
+	}
+	return fingerprints, nil
+}
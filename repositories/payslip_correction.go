@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// payslipWithdrawalSQL selects payslips p of runs pr with their employee e and pay period: the
+// run's month, or the corrected month pc for correction payslips
+const payslipWithdrawalSQL = `
+	SELECT p.id, p.employee_id, e.full_name, e.email,
+		COALESCE(pc.month, pr.month) AS month, COALESCE(pc.year, pr.year) AS year,
+		COALESCE(p.net_salary, 0) AS net_salary,
+		pr.status AS run_status, p.status, COALESCE(pc.payslip_id, p.id) AS original_id
+	FROM Tbl_Payslip p
+	JOIN Tbl_Payroll_run pr ON pr.id = p.payroll_run_id
+	JOIN Tbl_Employee e ON e.id = p.employee_id
+	LEFT JOIN Tbl_Payslip_correction pc ON pc.id = p.correction_id`
+
+// PayslipForWithdrawal is a payslip with what withdrawing it depends on
+type PayslipForWithdrawal struct {
+	models.WithdrawnPayslip
+	RunStatus string `db:"run_status"`
+	Status    string `db:"status"`
+	// OriginalID is the payslip a correction is queued for: the payslip itself, or the payslip
+	// a correction payslip corrected
+	OriginalID uuid.UUID `db:"original_id"`
+}
+
+// GetPayslipForWithdrawalTx locks and returns a payslip
+func (r *Repository) GetPayslipForWithdrawalTx(tx *sqlx.Tx, id uuid.UUID) (PayslipForWithdrawal, error) {
+	var p PayslipForWithdrawal
+	err := tx.Get(&p, payslipWithdrawalSQL+`
+		WHERE p.id = $1
+		FOR UPDATE OF p
+	`, id)
+	return p, err
+}
+
+// GetIssuedRunPayslipsTx locks and returns the issued payslips of a payroll run
+func (r *Repository) GetIssuedRunPayslipsTx(tx *sqlx.Tx, runID uuid.UUID) ([]PayslipForWithdrawal, error) {
+	payslips := []PayslipForWithdrawal{}
+	err := tx.Select(&payslips, payslipWithdrawalSQL+`
+		WHERE p.payroll_run_id = $1 AND p.status = 'ISSUED'
+		ORDER BY e.full_name
+		FOR UPDATE OF p
+	`, runID)
+	return payslips, err
+}
+
+// WithdrawPayslipTx marks a payslip as withdrawn, keeping it for audit
+func (r *Repository) WithdrawPayslipTx(tx *sqlx.Tx, id, withdrawnBy uuid.UUID, reason string) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Payslip
+		SET status = 'WITHDRAWN', withdrawn_by = $2, withdrawn_at = NOW(), withdrawal_reason = $3, updated_at = NOW()
+		WHERE id = $1
+	`, id, withdrawnBy, reason)
+	return err
+}
+
+// HasOpenCorrectionTx reports whether a payslip has a correction that is pending, or settled by
+// a correction payslip that was not withdrawn
+func (r *Repository) HasOpenCorrectionTx(tx *sqlx.Tx, payslipID uuid.UUID) (bool, error) {
+	var open bool
+	err := tx.Get(&open, `
+		SELECT EXISTS (
+			SELECT 1 FROM Tbl_Payslip_correction pc
+			WHERE pc.payslip_id = $1
+			AND NOT EXISTS (
+				SELECT 1 FROM Tbl_Payslip p
+				JOIN Tbl_Payroll_run pr ON pr.id = p.payroll_run_id
+				WHERE p.correction_id = pc.id AND p.status = 'WITHDRAWN'
+				AND pr.status IN ('FINALIZED', 'PAID')
+			)
+		)
+	`, payslipID)
+	return open, err
+}
+
+// InsertPayslipCorrectionTx queues a correction of a payslip for the next payroll run
+func (r *Repository) InsertPayslipCorrectionTx(tx *sqlx.Tx, payslipID uuid.UUID, reason string, createdBy uuid.UUID) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.Get(&id, `
+		INSERT INTO Tbl_Payslip_correction (payslip_id, employee_id, month, year, reason, created_by)
+		SELECT p.id, p.employee_id, pr.month, pr.year, $2, $3
+		FROM Tbl_Payslip p
+		JOIN Tbl_Payroll_run pr ON pr.id = p.payroll_run_id
+		WHERE p.id = $1
+		RETURNING id
+	`, payslipID, reason, createdBy)
+	return id, err
+}
+
+// GetPendingPayslipCorrections returns the corrections not yet settled by a finalized run
+func (r *Repository) GetPendingPayslipCorrections() ([]models.PayslipCorrection, error) {
+	corrections := []models.PayslipCorrection{}
+	err := r.DB.Select(&corrections, `
+		SELECT pc.id, pc.payslip_id, pc.employee_id, e.full_name, pc.month, pc.year, pc.reason,
+			pc.created_by, pc.created_at
+		FROM Tbl_Payslip_correction pc
+		JOIN Tbl_Employee e ON e.id = pc.employee_id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM Tbl_Payslip p
+			JOIN Tbl_Payroll_run pr ON pr.id = p.payroll_run_id
+			WHERE p.correction_id = pc.id AND pr.status IN ('FINALIZED', 'PAID')
+		)
+		ORDER BY pc.year, pc.month, e.full_name
+	`)
+	return corrections, err
+}
+
+// GetCorrectionsForRun returns the corrections a payroll run of a month pays: those of earlier
+// months not in a payslip of another run
+func (r *Repository) GetCorrectionsForRun(month, year int) ([]models.PayslipCorrection, error) {
+	corrections := []models.PayslipCorrection{}
+	err := r.DB.Select(&corrections, `
+		SELECT pc.id, pc.payslip_id, pc.employee_id, e.full_name, pc.month, pc.year, pc.reason,
+			pc.created_by, pc.created_at
+		FROM Tbl_Payslip_correction pc
+		JOIN Tbl_Employee e ON e.id = pc.employee_id
+		WHERE pc.year * 12 + pc.month < $2 * 12 + $1
+		AND NOT EXISTS (
+			SELECT 1 FROM Tbl_Payslip p
+			JOIN Tbl_Payroll_run pr ON pr.id = p.payroll_run_id
+			WHERE p.correction_id = pc.id AND NOT (pr.month = $1 AND pr.year = $2)
+		)
+		ORDER BY pc.year, pc.month, e.full_name
+	`, month, year)
+	return corrections, err
+}
+
+// GetPaidForPeriod returns what was paid to an employee for a pay period: the net pay of the
+// payslips of the period in paid runs, unless withdrawn before the run was paid. Corrections
+// are only queued for payslips of PAID runs, so FINALIZED runs are not counted.
+func (r *Repository) GetPaidForPeriod(employeeID uuid.UUID, month, year int) (float64, error) {
+	var paid float64
+	err := r.DB.Get(&paid, `
+		SELECT COALESCE(SUM(p.net_salary), 0)
+		FROM Tbl_Payslip p
+		JOIN Tbl_Payroll_run pr ON pr.id = p.payroll_run_id
+		LEFT JOIN Tbl_Payslip_correction pc ON pc.id = p.correction_id
+		WHERE p.employee_id = $1
+		AND COALESCE(pc.month, pr.month) = $2 AND COALESCE(pc.year, pr.year) = $3
+		AND pr.status = 'PAID'
+		AND (p.withdrawn_at IS NULL OR p.withdrawn_at > pr.paid_at)
+	`, employeeID, month, year)
+	return paid, err
+}
+
+// GetPayslipEncashment returns the leave encashment paid with a payslip
+func (r *Repository) GetPayslipEncashment(payslipID uuid.UUID) (float64, error) {
+	var amount float64
+	err := r.DB.Get(&amount, `SELECT COALESCE(encashment_amount, 0) FROM Tbl_Payslip WHERE id = $1`, payslipID)
+	return amount, err
+}
+
+// GetPayrollEmployee returns the payroll data of an employee, whether or not still active
+func (r *Repository) GetPayrollEmployee(employeeID uuid.UUID) (EmpMonthlyData, error) {
+	var emp EmpMonthlyData
+	err := r.DB.Get(&emp, `
+		SELECT e.id, e.full_name, e.salary, e.status, e.joining_date, e.ending_date,
+			COALESCE(e.salary_structure_id, d.salary_structure_id) AS salary_structure_id,
+			e.tax_regime, e.pt_state, e.pf_enabled
+		FROM Tbl_Employee e
+		LEFT JOIN Tbl_Designation d ON d.id = e.designation_id
+		WHERE e.id = $1
+	`, employeeID)
+	return emp, err
+}
@@ -132,11 +132,11 @@ func (r *Repository) GetRoleID(role string) (string, error) {
 }
 
 // ------------------ CREATE EMPLOYEE ------------------
-func (r *Repository) InsertEmployee(fullName, email, roleID, password string, salary *float64, joining *time.Time) error {
+func (r *Repository) InsertEmployee(fullName, email, roleID, password string, salary *float64, joining *time.Time, gender, department *string, employmentType string) error {
 	_, err := r.DB.Exec(`
-		INSERT INTO Tbl_Employee (full_name, email, role_id, password, salary, joining_date)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`, fullName, email, roleID, password, salary, joining)
+		INSERT INTO Tbl_Employee (full_name, email, role_id, password, salary, joining_date, gender, department, employment_type)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, fullName, email, roleID, password, salary, joining, gender, department, employmentType)
 	return err
 }
 
@@ -219,12 +219,17 @@ func (r *Repository) GetAllFinalizedPayslips() (*sql.Rows, error) {
 	    p.deduction_amount,
 	    p.net_salary,
 	    COALESCE(p.pdf_path, '') AS pdf_path,
-	    CONCAT('₹', p.basic_salary, ' - ₹', p.deduction_amount, ' = ₹', p.net_salary) AS calculation,
-	    p.created_at
+	    CASE WHEN p.encashment_amount > 0
+	        THEN CONCAT('₹', p.basic_salary, ' + ₹', p.encashment_amount, ' (leave encashment) - ₹', p.deduction_amount, ' = ₹', p.net_salary)
+	        ELSE CONCAT('₹', p.basic_salary, ' - ₹', p.deduction_amount, ' = ₹', p.net_salary)
+	    END AS calculation,
+	    p.created_at,
+	    p.status,
+	    p.payslip_type
 	FROM Tbl_Payslip p
 	JOIN Tbl_Employee e ON p.employee_id = e.id
 	JOIN Tbl_Payroll_Run pr ON pr.id = p.payroll_run_id
-	WHERE pr.status = 'FINALIZED'
+	WHERE pr.status IN ('FINALIZED', 'PAID')
 	ORDER BY pr.year DESC, pr.month DESC, e.full_name ASC;
 	`
 	return r.DB.Query(query)
@@ -246,12 +251,17 @@ func (r *Repository) GetFinalizedPayslipsByEmployee(id uuid.UUID) (*sql.Rows, er
 	    p.deduction_amount,
 	    p.net_salary,
 	    COALESCE(p.pdf_path, '') AS pdf_path,
-	    CONCAT('₹', p.basic_salary, ' - ₹', p.deduction_amount, ' = ₹', p.net_salary) AS calculation,
-	    p.created_at
+	    CASE WHEN p.encashment_amount > 0
+	        THEN CONCAT('₹', p.basic_salary, ' + ₹', p.encashment_amount, ' (leave encashment) - ₹', p.deduction_amount, ' = ₹', p.net_salary)
+	        ELSE CONCAT('₹', p.basic_salary, ' - ₹', p.deduction_amount, ' = ₹', p.net_salary)
+	    END AS calculation,
+	    p.created_at,
+	    p.status,
+	    p.payslip_type
 	FROM Tbl_Payslip p
 	JOIN Tbl_Employee e ON p.employee_id = e.id
 	JOIN Tbl_Payroll_Run pr ON pr.id = p.payroll_run_id
-	WHERE pr.status = 'FINALIZED' AND e.id = $1
+	WHERE pr.status IN ('FINALIZED', 'PAID') AND e.id = $1
 	ORDER BY pr.year DESC, pr.month DESC;
 	`
 	return r.DB.Query(query, id)
@@ -265,7 +275,7 @@ func (r *Repository) GetEmployeeByID(empID uuid.UUID) (*models.EmployeeResponse,
         SELECT 
             e.id, e.full_name, e.email, e.status,
             r.type AS role, e.manager_id, e.designation_id,
-            e.salary, e.joining_date, e.ending_date,
+            e.salary, e.joining_date, e.ending_date, e.gender, e.department, e.employment_type,
             e.created_at, e.updated_at,
             m.full_name AS manager_name,
             d.designation_name
@@ -286,6 +296,9 @@ func (r *Repository) GetEmployeeByID(empID uuid.UUID) (*models.EmployeeResponse,
 		&emp.Salary,
 		&emp.JoiningDate,
 		&emp.EndingDate,
+		&emp.Gender,
+		&emp.Department,
+		&emp.EmploymentType,
 		&emp.CreatedAt,
 		&emp.UpdatedAt,
 		&emp.ManagerName,
@@ -298,12 +311,12 @@ func (r *Repository) GetEmployeeByID(empID uuid.UUID) (*models.EmployeeResponse,
 }
 
 // ------------------ UPDATE EMPLOYEE INFO ------------------
-func (r *Repository) UpdateEmployeeInfo(empID uuid.UUID, fullName, email string, salary *float64, joiningDate, endingDate *time.Time) error {
+func (r *Repository) UpdateEmployeeInfo(empID uuid.UUID, fullName, email string, salary *float64, joiningDate, endingDate *time.Time, gender, department *string, employmentType string) error {
 	_, err := r.DB.Exec(`
         UPDATE Tbl_Employee
-        SET full_name = $1, email = $2, salary = $3, joining_date = $4, ending_date = $5, updated_at = NOW()
-        WHERE id = $6
-    `, fullName, email, salary, joiningDate, endingDate, empID)
+        SET full_name = $1, email = $2, salary = $3, joining_date = $4, ending_date = $5, gender = $6, department = $7, employment_type = $8, updated_at = NOW()
+        WHERE id = $9
+    `, fullName, email, salary, joiningDate, endingDate, gender, department, employmentType, empID)
 	return err
 }
 
@@ -335,7 +348,7 @@ func (r *Repository) GetEmployeesByManagerID(managerID uuid.UUID) ([]models.Empl
         SELECT 
             e.id, e.full_name, e.email, e.status,
             r.type AS role, e.manager_id, e.designation_id,
-            e.salary, e.joining_date, e.ending_date,
+            e.salary, e.joining_date, e.ending_date, e.gender, e.department,
             e.created_at, e.updated_at,
             m.full_name AS manager_name,
             d.designation_name
@@ -366,6 +379,8 @@ func (r *Repository) GetEmployeesByManagerID(managerID uuid.UUID) ([]models.Empl
 			&emp.Salary,
 			&emp.JoiningDate,
 			&emp.EndingDate,
+			&emp.Gender,
+			&emp.Department,
 			&emp.CreatedAt,
 			&emp.UpdatedAt,
 			&emp.ManagerName,
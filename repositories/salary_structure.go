@@ -0,0 +1,179 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// GetSalaryStructures lists every salary structure with its components
+func (r *Repository) GetSalaryStructures() ([]models.SalaryStructure, error) {
+	structures := []models.SalaryStructure{}
+	err := r.DB.Select(&structures, `
+		SELECT id, name, description, created_at, updated_at
+		FROM Tbl_Salary_structure
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	var components []models.SalaryComponent
+	err = r.DB.Select(&components, `
+		SELECT id, structure_id, code, name, kind, calc_type, amount, percentage, base_codes,
+			formula, prorate, sort_order
+		FROM Tbl_Salary_component
+		ORDER BY structure_id, sort_order, code
+	`)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]int, len(structures))
+	for i := range structures {
+		structures[i].Components = []models.SalaryComponent{}
+		byID[structures[i].ID] = i
+	}
+	for _, comp := range components {
+		if i, ok := byID[comp.StructureID]; ok {
+			structures[i].Components = append(structures[i].Components, comp)
+		}
+	}
+	return structures, nil
+}
+
+// GetSalaryStructureMap returns every salary structure with its components by ID
+func (r *Repository) GetSalaryStructureMap() (map[uuid.UUID]models.SalaryStructure, error) {
+	structures, err := r.GetSalaryStructures()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]models.SalaryStructure, len(structures))
+	for _, s := range structures {
+		byID[s.ID] = s
+	}
+	return byID, nil
+}
+
+// GetSalaryStructure returns a salary structure with its components
+func (r *Repository) GetSalaryStructure(id uuid.UUID) (models.SalaryStructure, error) {
+	var structure models.SalaryStructure
+	err := r.DB.Get(&structure, `
+		SELECT id, name, description, created_at, updated_at
+		FROM Tbl_Salary_structure
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return structure, err
+	}
+	structure.Components = []models.SalaryComponent{}
+	err = r.DB.Select(&structure.Components, `
+		SELECT id, structure_id, code, name, kind, calc_type, amount, percentage, base_codes,
+			formula, prorate, sort_order
+		FROM Tbl_Salary_component
+		WHERE structure_id = $1
+		ORDER BY sort_order, code
+	`, id)
+	return structure, err
+}
+
+// SalaryStructureNameExistsTx reports whether another structure already uses the name
+func (r *Repository) SalaryStructureNameExistsTx(tx *sqlx.Tx, name string, excludeID uuid.UUID) (bool, error) {
+	var exists bool
+	err := tx.Get(&exists, `
+		SELECT EXISTS (SELECT 1 FROM Tbl_Salary_structure WHERE LOWER(name) = LOWER($1) AND id <> $2)
+	`, name, excludeID)
+	return exists, err
+}
+
+// InsertSalaryStructure creates a salary structure and returns its ID
+func (r *Repository) InsertSalaryStructure(tx *sqlx.Tx, name string, description *string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.Get(&id, `
+		INSERT INTO Tbl_Salary_structure (name, description)
+		VALUES ($1, $2)
+		RETURNING id
+	`, name, description)
+	return id, err
+}
+
+// UpdateSalaryStructure renames a salary structure and returns the number of rows updated
+func (r *Repository) UpdateSalaryStructure(tx *sqlx.Tx, id uuid.UUID, name string, description *string) (int64, error) {
+	res, err := tx.Exec(`
+		UPDATE Tbl_Salary_structure
+		SET name = $2, description = $3, updated_at = NOW()
+		WHERE id = $1
+	`, id, name, description)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ReplaceSalaryComponents replaces the components of a salary structure
+func (r *Repository) ReplaceSalaryComponents(tx *sqlx.Tx, structureID uuid.UUID, components []models.SalaryComponent) error {
+	if _, err := tx.Exec(`DELETE FROM Tbl_Salary_component WHERE structure_id = $1`, structureID); err != nil {
+		return err
+	}
+	for i, comp := range components {
+		_, err := tx.Exec(`
+			INSERT INTO Tbl_Salary_component
+			(structure_id, code, name, kind, calc_type, amount, percentage, base_codes, formula, prorate, sort_order)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		`, structureID, comp.Code, comp.Name, comp.Kind, comp.CalcType, comp.Amount, comp.Percentage,
+			pq.Array([]string(comp.BaseCodes)), comp.Formula, comp.Prorate, i+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteSalaryStructure deletes a salary structure; employees and designations using it are
+// left without one
+func (r *Repository) DeleteSalaryStructure(tx *sqlx.Tx, id uuid.UUID) (int64, error) {
+	res, err := tx.Exec(`DELETE FROM Tbl_Salary_structure WHERE id = $1`, id)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// AssignEmployeeSalaryStructure sets an employee's own salary structure; nil falls back to
+// the designation's
+func (r *Repository) AssignEmployeeSalaryStructure(tx *sqlx.Tx, employeeID uuid.UUID, structureID *uuid.UUID) error {
+	_, err := tx.Exec(`UPDATE Tbl_Employee SET salary_structure_id = $2, updated_at = NOW() WHERE id = $1`, employeeID, structureID)
+	return err
+}
+
+// AssignDesignationSalaryStructure sets the salary structure of a designation
+func (r *Repository) AssignDesignationSalaryStructure(tx *sqlx.Tx, designationID uuid.UUID, structureID *uuid.UUID) error {
+	_, err := tx.Exec(`UPDATE Tbl_Designation SET salary_structure_id = $2 WHERE id = $1`, designationID, structureID)
+	return err
+}
+
+// InsertPayslipLinesTx stores the line items of a payslip
+func (r *Repository) InsertPayslipLinesTx(tx *sqlx.Tx, payslipID uuid.UUID, lines []models.PayslipLine) error {
+	for i, line := range lines {
+		_, err := tx.Exec(`
+			INSERT INTO Tbl_Payslip_line (payslip_id, code, name, kind, amount, sort_order)
+			VALUES ($1,$2,$3,$4,$5,$6)
+		`, payslipID, line.Code, line.Name, line.Kind, line.Amount, i+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPayslipLines returns the line items of a payslip; payslips finalized before salary
+// structures have none
+func (r *Repository) GetPayslipLines(payslipID uuid.UUID) ([]models.PayslipLine, error) {
+	lines := []models.PayslipLine{}
+	err := r.DB.Select(&lines, `
+		SELECT code, name, kind, amount
+		FROM Tbl_Payslip_line
+		WHERE payslip_id = $1
+		ORDER BY sort_order
+	`, payslipID)
+	return lines, err
+}
@@ -19,11 +19,30 @@ func (r *Repository) UpdateCompanySettings(tx *sqlx.Tx, input models.CompanyFiel
         UPDATE Tbl_Company_Settings
         SET working_days_per_month=$1, allow_manager_add_leave=$2, company_name = $3, 
 		    primary_color = $4, 
-		    secondary_color = $5, logo_path = COALESCE(NULLIF($6, ''), logo_path), updated_at=NOW()
+		    secondary_color = $5, logo_path = COALESCE(NULLIF($6, ''), logo_path),
+		    work_start_time = COALESCE(NULLIF($7, ''), work_start_time),
+		    work_end_time = COALESCE(NULLIF($8, ''), work_end_time),
+		    working_hours_per_day = COALESCE(NULLIF($9::numeric, 0), working_hours_per_day),
+		    coverage_threshold_percent = COALESCE($10::numeric, coverage_threshold_percent),
+		    coverage_block = COALESCE($11::boolean, coverage_block),
+		    comp_off_leave_type_id = CASE WHEN $12::int IS NULL THEN comp_off_leave_type_id ELSE NULLIF($12::int, 0) END,
+		    comp_off_validity_days = COALESCE($13::int, comp_off_validity_days),
+		    payroll_proration_basis = COALESCE($14::varchar, payroll_proration_basis),
+		    payroll_day_rate_basis = COALESCE($15::varchar, payroll_day_rate_basis),
+		    updated_at=NOW()
     `, input.WorkingDaysPerMonth, input.AllowManagerAddLeave, input.CompanyName, // New field
 		input.PrimaryColor, // New field
 		input.SecondaryColor,
 		logoPath, // New field
+		input.WorkStartTime,
+		input.WorkEndTime,
+		input.WorkingHoursPerDay,
+		input.CoverageThresholdPercent,
+		input.CoverageBlock,
+		input.CompOffLeaveTypeID,
+		input.CompOffValidityDays,
+		input.PayrollProrationBasis,
+		input.PayrollDayRateBasis,
 	)
 
 	if err != nil {
@@ -31,3 +50,37 @@ func (r *Repository) UpdateCompanySettings(tx *sqlx.Tx, input models.CompanyFiel
 	}
 	return nil
 }
+
+// WorkingHours is the company working window used for hourly leave
+type WorkingHours struct {
+	Start       string  `db:"work_start_time"`
+	End         string  `db:"work_end_time"`
+	HoursPerDay float64 `db:"working_hours_per_day"`
+}
+
+// GetWorkingHours fetches the company working hours
+func (r *Repository) GetWorkingHours() (WorkingHours, error) {
+	var wh WorkingHours
+	err := r.DB.Get(&wh, `SELECT work_start_time, work_end_time, working_hours_per_day FROM Tbl_Company_Settings LIMIT 1`)
+	return wh, err
+}
+
+// CoverageRule is the company team coverage rule used when applying leave
+type CoverageRule struct {
+	ThresholdPercent float64 `db:"coverage_threshold_percent"`
+	Block            bool    `db:"coverage_block"`
+}
+
+// GetCoverageRuleTx fetches the team coverage rule (inside TX)
+func (r *Repository) GetCoverageRuleTx(tx *sqlx.Tx) (CoverageRule, error) {
+	var rule CoverageRule
+	err := tx.Get(&rule, `SELECT coverage_threshold_percent, coverage_block FROM Tbl_Company_Settings LIMIT 1`)
+	return rule, err
+}
+
+// GetWorkingHoursTx fetches the company working hours (inside TX)
+func (r *Repository) GetWorkingHoursTx(tx *sqlx.Tx) (WorkingHours, error) {
+	var wh WorkingHours
+	err := tx.Get(&wh, `SELECT work_start_time, work_end_time, working_hours_per_day FROM Tbl_Company_Settings LIMIT 1`)
+	return wh, err
+}
@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// GetStatutoryRules lists every version of the statutory rules, optionally of one kind
+func (r *Repository) GetStatutoryRules(kind string) ([]models.StatutoryRule, error) {
+	rules := []models.StatutoryRule{}
+	err := r.DB.Select(&rules, `
+		SELECT id, kind, code, name, effective_from, config, created_by, created_at
+		FROM Tbl_Statutory_rule
+		WHERE ($1 = '' OR kind = $1)
+		ORDER BY kind, code, effective_from DESC
+	`, kind)
+	return rules, err
+}
+
+// GetStatutoryRulesInForce returns the latest version of each rule set effective on a date,
+// keyed by service.StatutoryRuleKey
+func (r *Repository) GetStatutoryRulesInForce(asOf time.Time) (map[string]models.StatutoryRule, error) {
+	var rules []models.StatutoryRule
+	err := r.DB.Select(&rules, `
+		SELECT DISTINCT ON (kind, code) id, kind, code, name, effective_from, config, created_by, created_at
+		FROM Tbl_Statutory_rule
+		WHERE effective_from <= $1
+		ORDER BY kind, code, effective_from DESC
+	`, asOf)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]models.StatutoryRule, len(rules))
+	for _, rule := range rules {
+		byKey[rule.Kind+":"+rule.Code] = rule
+	}
+	return byKey, nil
+}
+
+// InsertStatutoryRule adds a version of a statutory rule set
+func (r *Repository) InsertStatutoryRule(tx *sqlx.Tx, input models.StatutoryRuleInput, effectiveFrom time.Time, createdBy uuid.UUID) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.Get(&id, `
+		INSERT INTO Tbl_Statutory_rule (kind, code, name, effective_from, config, created_by)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		RETURNING id
+	`, input.Kind, input.Code, input.Name, effectiveFrom, string(input.Config), createdBy)
+	return id, err
+}
+
+// StatutoryRuleVersionExistsTx reports whether a version of the rule set already starts on the date
+func (r *Repository) StatutoryRuleVersionExistsTx(tx *sqlx.Tx, kind, code string, effectiveFrom time.Time) (bool, error) {
+	var exists bool
+	err := tx.Get(&exists, `
+		SELECT EXISTS (SELECT 1 FROM Tbl_Statutory_rule WHERE kind = $1 AND code = $2 AND effective_from = $3)
+	`, kind, code, effectiveFrom)
+	return exists, err
+}
+
+// GetStatutoryRuleTx returns a statutory rule version
+func (r *Repository) GetStatutoryRuleTx(tx *sqlx.Tx, id uuid.UUID) (models.StatutoryRule, error) {
+	var rule models.StatutoryRule
+	err := tx.Get(&rule, `
+		SELECT id, kind, code, name, effective_from, config, created_by, created_at
+		FROM Tbl_Statutory_rule
+		WHERE id = $1
+	`, id)
+	return rule, err
+}
+
+// DeleteStatutoryRule deletes a statutory rule version
+func (r *Repository) DeleteStatutoryRule(tx *sqlx.Tx, id uuid.UUID) error {
+	_, err := tx.Exec(`DELETE FROM Tbl_Statutory_rule WHERE id = $1`, id)
+	return err
+}
+
+// GetStatutoryProfile returns the statutory profile of an employee
+func (r *Repository) GetStatutoryProfile(employeeID uuid.UUID) (models.StatutoryProfile, error) {
+	var profile models.StatutoryProfile
+	err := r.DB.Get(&profile, `SELECT tax_regime, pt_state, pf_enabled FROM Tbl_Employee WHERE id = $1`, employeeID)
+	return profile, err
+}
+
+// UpdateStatutoryProfile saves the statutory profile of an employee
+func (r *Repository) UpdateStatutoryProfile(tx *sqlx.Tx, employeeID uuid.UUID, profile models.StatutoryProfile) error {
+	_, err := tx.Exec(`
+		UPDATE Tbl_Employee
+		SET tax_regime = $2, pt_state = $3, pf_enabled = $4, updated_at = NOW()
+		WHERE id = $1
+	`, employeeID, profile.TaxRegime, profile.PTState, profile.PFEnabled)
+	return err
+}
+
+// payslipTaxableSQL is the taxable income of payslip p with line totals l: gross and leave
+// encashment less loss of pay. Payslips finalized before payslip lines have deductions made
+// of loss of pay only.
+const payslipTaxableSQL = `COALESCE(p.basic_salary, 0) + COALESCE(p.encashment_amount, 0)
+	- CASE WHEN l.has_lines THEN l.lop ELSE COALESCE(p.deduction_amount, 0) END`
+
+// payslipLineTotalsSQL totals the lines of payslip p
+const payslipLineTotalsSQL = `CROSS JOIN LATERAL (
+		SELECT COUNT(*) > 0 AS has_lines,
+			COALESCE(SUM(pl.amount) FILTER (WHERE pl.code = 'LOP'), 0) AS lop,
+			COALESCE(SUM(pl.amount) FILTER (WHERE pl.code = 'TDS'), 0) AS tds,
+			COALESCE(SUM(pl.amount) FILTER (WHERE pl.code = 'EPF'), 0) AS epf,
+			COALESCE(SUM(pl.amount) FILTER (WHERE pl.kind = 'EMPLOYER'), 0) AS employer,
+			COALESCE(SUM(pl.amount) FILTER (WHERE pl.code = 'PREV_PAID'), 0) AS prev_paid
+		FROM Tbl_Payslip_line pl
+		WHERE pl.payslip_id = p.id
+	) l`
+
+// GetStatutoryYTDs returns, per employee, the taxable income, TDS and EPF of the issued
+// payslips of the financial year before a payroll month
+func (r *Repository) GetStatutoryYTDs(month, year, fyStartYear int) (map[uuid.UUID]models.StatutoryYTD, error) {
+	var rows []struct {
+		EmployeeID uuid.UUID `db:"employee_id"`
+		models.StatutoryYTD
+	}
+	err := r.DB.Select(&rows, `
+		SELECT p.employee_id,
+			COALESCE(SUM(`+payslipTaxableSQL+`), 0) AS taxable_income,
+			COALESCE(SUM(l.tds), 0) AS tds,
+			COALESCE(SUM(l.epf), 0) AS epf
+		FROM Tbl_Payslip p
+		JOIN Tbl_Payroll_run pr ON pr.id = p.payroll_run_id
+		`+payslipLineTotalsSQL+`
+		WHERE pr.status IN ('FINALIZED', 'PAID') AND p.status = 'ISSUED'
+		AND pr.year * 12 + pr.month >= $1
+		AND pr.year * 12 + pr.month < $2
+		GROUP BY p.employee_id
+	`, fyStartYear*12+4, year*12+month)
+	if err != nil {
+		return nil, err
+	}
+	ytds := make(map[uuid.UUID]models.StatutoryYTD, len(rows))
+	for _, row := range rows {
+		ytds[row.EmployeeID] = row.StatutoryYTD
+	}
+	return ytds, nil
+}
+
+// GetPayrollYTDSummary totals an employee's issued payslips of the financial year starting in
+// April of fyStartYear. What correction payslips deduct as previously paid counts as paid, not
+// as a deduction.
+func (r *Repository) GetPayrollYTDSummary(employeeID uuid.UUID, fyStartYear int) (models.PayrollYTDSummary, error) {
+	var summary models.PayrollYTDSummary
+	from, to := fyStartYear*12+4, (fyStartYear+1)*12+3
+	err := r.DB.Get(&summary, `
+		SELECT COUNT(*) AS payslips,
+			COALESCE(SUM(p.basic_salary), 0) AS gross,
+			COALESCE(SUM(p.encashment_amount), 0) AS encashment,
+			COALESCE(SUM(p.deduction_amount - l.prev_paid), 0) AS deductions,
+			COALESCE(SUM(p.net_salary + l.prev_paid), 0) AS net_pay,
+			COALESCE(SUM(`+payslipTaxableSQL+`), 0) AS taxable_income,
+			COALESCE(SUM(l.tds), 0) AS tds,
+			COALESCE(SUM(l.employer), 0) AS employer_contributions
+		FROM Tbl_Payslip p
+		JOIN Tbl_Payroll_run pr ON pr.id = p.payroll_run_id
+		`+payslipLineTotalsSQL+`
+		WHERE p.employee_id = $1
+		AND pr.status IN ('FINALIZED', 'PAID') AND p.status = 'ISSUED'
+		AND pr.year * 12 + pr.month BETWEEN $2 AND $3
+	`, employeeID, from, to)
+	if err != nil {
+		return summary, err
+	}
+	summary.Lines = []models.PayslipLine{}
+	err = r.DB.Select(&summary.Lines, `
+		SELECT pl.code, CASE WHEN pl.code = 'LOP' THEN 'Loss of Pay' ELSE MAX(pl.name) END AS name,
+			pl.kind, SUM(pl.amount) AS amount
+		FROM Tbl_Payslip_line pl
+		JOIN Tbl_Payslip p ON p.id = pl.payslip_id
+		JOIN Tbl_Payroll_run pr ON pr.id = p.payroll_run_id
+		WHERE p.employee_id = $1
+		AND pr.status IN ('FINALIZED', 'PAID') AND p.status = 'ISSUED'
+		AND pr.year * 12 + pr.month BETWEEN $2 AND $3
+		AND pl.code <> 'PREV_PAID'
+		GROUP BY pl.code, pl.kind
+		ORDER BY CASE pl.kind WHEN 'EARNING' THEN 1 WHEN 'DEDUCTION' THEN 2 ELSE 3 END, MIN(pl.sort_order), pl.code
+	`, employeeID, from, to)
+	return summary, err
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// GetYearEndBalancesTx fetches the closing balance of every leave type for employees still
+// employed at the end of the year. Missing balance rows fall back to the opening entitlement,
+// the same way GetLeaveBalances shows them.
+func (r *Repository) GetYearEndBalancesTx(tx *sqlx.Tx, year int) ([]models.YearEndBalance, error) {
+	var balances []models.YearEndBalance
+	yearEnd := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	err := tx.Select(&balances, `
+		SELECT e.id AS employee_id, e.full_name AS employee_name, COALESCE(e.salary, 0) AS salary,
+			lt.id AS leave_type_id,
+			COALESCE(b.closing, CASE WHEN lt.accrual_frequency = 'NONE' THEN COALESCE(lt.default_entitlement, 0) ELSE 0 END) AS closing
+		FROM Tbl_Employee e
+		CROSS JOIN Tbl_Leave_type lt
+		LEFT JOIN Tbl_Leave_balance b ON b.employee_id = e.id AND b.leave_type_id = lt.id AND b.year = $1
+		WHERE e.status = 'active' AND e.deleted_at IS NULL
+		AND (e.joining_date IS NULL OR e.joining_date <= $2)
+		AND (e.ending_date IS NULL OR e.ending_date > $2)
+		ORDER BY e.full_name, lt.id
+	`, year, yearEnd)
+	return balances, err
+}
+
+// YearEndClosedTx reports whether the year has already been closed
+func (r *Repository) YearEndClosedTx(tx *sqlx.Tx, year int) (bool, error) {
+	var exists bool
+	err := tx.Get(&exists, `SELECT EXISTS(SELECT 1 FROM Tbl_Year_end_close WHERE year = $1)`, year)
+	return exists, err
+}
+
+// InsertYearEndClose records a finalized year-end close
+func (r *Repository) InsertYearEndClose(tx *sqlx.Tx, year int, finalizedBy uuid.UUID) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := tx.QueryRow(`
+		INSERT INTO Tbl_Year_end_close (year, finalized_by) VALUES ($1, $2) RETURNING id
+	`, year, finalizedBy).Scan(&id)
+	return id, err
+}
+
+// InsertYearEndCloseLine records the outcome of one employee's leave type balance
+func (r *Repository) InsertYearEndCloseLine(tx *sqlx.Tx, closeID uuid.UUID, line models.YearEndLine) error {
+	_, err := tx.Exec(`
+		INSERT INTO Tbl_Year_end_close_line
+		(close_id, employee_id, leave_type_id, closing, carried_forward, encashed, lapsed, encash_rate, encash_amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, closeID, line.EmployeeID, line.LeaveTypeID, line.Closing, line.CarriedForward, line.Encashed,
+		line.Lapsed, line.EncashRate, line.EncashAmount)
+	return err
+}
+
+// GetYearEndClose fetches a finalized year-end close
+func (r *Repository) GetYearEndClose(year int) (models.YearEndClose, error) {
+	var close models.YearEndClose
+	err := r.DB.Get(&close, `
+		SELECT c.id, c.year, c.finalized_by, e.full_name AS finalized_by_name, c.finalized_at
+		FROM Tbl_Year_end_close c
+		LEFT JOIN Tbl_Employee e ON e.id = c.finalized_by
+		WHERE c.year = $1
+	`, year)
+	return close, err
+}
+
+// GetYearEndCloseLines fetches the lines of a year-end close
+func (r *Repository) GetYearEndCloseLines(closeID uuid.UUID) ([]models.YearEndLine, error) {
+	lines := []models.YearEndLine{}
+	err := r.DB.Select(&lines, `
+		SELECT l.id, l.employee_id, e.full_name AS employee_name, l.leave_type_id, lt.name AS leave_type, lt.unit,
+			l.closing, l.carried_forward, l.encashed, l.lapsed, l.encash_rate, l.encash_amount, l.payroll_run_id
+		FROM Tbl_Year_end_close_line l
+		JOIN Tbl_Employee e ON e.id = l.employee_id
+		JOIN Tbl_Leave_type lt ON lt.id = l.leave_type_id
+		WHERE l.close_id = $1
+		ORDER BY e.full_name, lt.id
+	`, closeID)
+	return lines, err
+}
+
+// GetPendingEncashments returns the leave encashment not yet paid out, per employee
+func (r *Repository) GetPendingEncashments() (map[uuid.UUID]float64, error) {
+	var rows []struct {
+		EmployeeID uuid.UUID `db:"employee_id"`
+		Amount     float64   `db:"amount"`
+	}
+	err := r.DB.Select(&rows, `
+		SELECT employee_id, SUM(encash_amount) AS amount
+		FROM Tbl_Year_end_close_line
+		WHERE payroll_run_id IS NULL AND encash_amount > 0
+		GROUP BY employee_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	pending := make(map[uuid.UUID]float64, len(rows))
+	for _, row := range rows {
+		pending[row.EmployeeID] = row.Amount
+	}
+	return pending, nil
+}
+
+// ClaimPendingEncashmentTx marks an employee's unpaid leave encashment as paid by the payroll run
+// and returns the amount
+func (r *Repository) ClaimPendingEncashmentTx(tx *sqlx.Tx, employeeID, payrollRunID uuid.UUID) (float64, error) {
+	var amount float64
+	err := tx.Get(&amount, `
+		WITH claimed AS (
+			UPDATE Tbl_Year_end_close_line
+			SET payroll_run_id = $2
+			WHERE employee_id = $1 AND payroll_run_id IS NULL AND encash_amount > 0
+			RETURNING encash_amount
+		)
+		SELECT COALESCE(SUM(encash_amount), 0) FROM claimed
+	`, employeeID, payrollRunID)
+	return amount, err
+}
@@ -33,39 +33,86 @@ func SetupRoutes(r *gin.Engine, h *controllers.HandlerFunc) {
 	employees := r.Group("/api/employee")
 	employees.Use(middleware.AuthMiddleware(h)) // Protect employee routes
 	{
-		employees.GET("/", h.GetEmployee)                                // List all employees (SUPER_ADMIN, ADMIN/HR)
-		employees.GET("/my-team", h.GetMyTeam)                           // Get manager's team members (MANAGER only)
-		employees.GET("/:id", h.GetEmployeeById)                         // Get employee details (Self/Manager/Admin)
-		employees.POST("/", h.CreateEmployee)                            // Create employee (SUPER_ADMIN, ADMIN/HR)
-		employees.PATCH("/:id", h.UpdateEmployeeInfo)                    // Update employee info (SUPER_ADMIN, ADMIN/HR)
-		employees.PATCH("/:id/password", h.UpdateEmployeePassword)       // Update employee password (SUPER_ADMIN, ADMIN, HR)
-		employees.PATCH("/:id/role", h.UpdateEmployeeRole)               // Change employee role (SUPER_ADMIN, ADMIN/HR)
-		employees.PATCH("/:id/manager", h.UpdateEmployeeManager)         // Set/change manager (SUPER_ADMIN, ADMIN/HR)
-		employees.PATCH("/:id/designation", h.UpdateEmployeeDesignation) // Assign/update designation (SUPER_ADMIN, ADMIN, HR)
-		employees.PUT("/deactivate/:id", h.DeleteEmployeeStatus)         // Deactivate/Activate employee (SUPER_ADMIN, ADMIN/HR)
-		employees.GET("/:id/reports", h.GetEmployeeReports)              // Get direct reports (Self/Manager/Admin)
+		employees.GET("/", h.GetEmployee)                                         // List all employees (SUPER_ADMIN, ADMIN/HR)
+		employees.GET("/my-team", h.GetMyTeam)                                    // Get manager's team members (MANAGER only)
+		employees.GET("/:id", h.GetEmployeeById)                                  // Get employee details (Self/Manager/Admin)
+		employees.POST("/", h.CreateEmployee)                                     // Create employee (SUPER_ADMIN, ADMIN/HR)
+		employees.PATCH("/:id", h.UpdateEmployeeInfo)                             // Update employee info (SUPER_ADMIN, ADMIN/HR)
+		employees.PATCH("/:id/password", h.UpdateEmployeePassword)                // Update employee password (SUPER_ADMIN, ADMIN, HR)
+		employees.PATCH("/:id/role", h.UpdateEmployeeRole)                        // Change employee role (SUPER_ADMIN, ADMIN/HR)
+		employees.PATCH("/:id/manager", h.UpdateEmployeeManager)                  // Set/change manager (SUPER_ADMIN, ADMIN/HR)
+		employees.PATCH("/:id/designation", h.UpdateEmployeeDesignation)          // Assign/update designation (SUPER_ADMIN, ADMIN, HR)
+		employees.PATCH("/:id/salary-structure", h.UpdateEmployeeSalaryStructure) // Assign/remove own salary structure (SUPER_ADMIN, ADMIN)
+		employees.GET("/:id/statutory", h.GetEmployeeStatutoryProfile)            // Tax regime, PT state, PF membership (Self/Admin/HR)
+		employees.PATCH("/:id/statutory", h.UpdateEmployeeStatutoryProfile)       // Update statutory details (SUPER_ADMIN, ADMIN, HR)
+		employees.GET("/:id/bank-account", h.GetEmployeeBankAccount)              // Salary bank account (Self/Admin/HR)
+		employees.PUT("/:id/bank-account", h.UpdateEmployeeBankAccount)           // Set salary bank account (SUPER_ADMIN, ADMIN, HR)
+		employees.PUT("/deactivate/:id", h.DeleteEmployeeStatus)                  // Deactivate/Activate employee (SUPER_ADMIN, ADMIN/HR)
+		employees.GET("/:id/reports", h.GetEmployeeReports)                       // Get direct reports (Self/Manager/Admin)
 	}
 
 	// ----------------- Leaves -----------------
 	leaves := r.Group("/api/leaves")
 	leaves.Use(middleware.AuthMiddleware(h))
 	{
-		leaves.POST("/apply", h.ApplyLeave) // Employee applies for leave
+		leaves.POST("/apply", h.ApplyLeave)        // Employee applies for leave
+		leaves.POST("/admin-add", h.AdminAddLeave) // Admin/HR/Manager adds leave on behalf of an employee
 
-		leaves.PUT("/edit/:id", h.EditMyLeave)                         // New Route
-		leaves.POST("/admin-add/policy", h.AdminAddLeavePolicy)        // Admin creates leave policy
-		leaves.PUT("/admin-update/policy/:id", h.UpdateLeavePolicy)    // Admin, SuperAdmin, HR update leave policy
-		leaves.DELETE("/admin-delete/policy/:id", h.DeleteLeavePolicy) // Admin, SuperAdmin, HR delete leave policy
-		leaves.GET("/Get-All-Leave-Policy", h.GetAllLeavePolicies)     // Get all leave policies
-		leaves.GET("/manager/history", h.GetManagerLeaveHistory)       // Manager gets team leave history
-		leaves.POST("/:id/action", h.ActionLeave)                      // Approve/Reject leave
-		leaves.DELETE("/:id/cancel", h.CancelLeave)                    // Cancel pending leave (Employee/Admin)
-		leaves.POST("/:id/withdraw", h.WithdrawLeave)                  // Withdraw approved leave (Admin/Manager)
-		leaves.GET("/all", h.GetAllLeaves)                             // Get all leaves (filtered by role)
-		leaves.GET("/my-leaves", h.GetAllMyLeave)                      // Get current user's own leaves with month/year filtering
-		leaves.GET("/:id", h.GetLeaveByID)                             // Get leave by ID (role-based access)
-		leaves.GET("/timming", h.GetLeaveTiming)                       // Get all Leave Timing
-		leaves.PUT("/timming", h.UpdateLeaveTiming)                    // Update leave timing by super admin and admin
+		leaves.PUT("/edit/:id", h.EditMyLeave)                                  // New Route
+		leaves.POST("/admin-add/policy", h.AdminAddLeavePolicy)                 // Admin creates leave policy
+		leaves.PUT("/admin-update/policy/:id", h.UpdateLeavePolicy)             // Admin, SuperAdmin, HR update leave policy
+		leaves.DELETE("/admin-delete/policy/:id", h.DeleteLeavePolicy)          // Admin, SuperAdmin, HR delete leave policy
+		leaves.GET("/policy/:id/entitlement-rules", h.GetLeaveEntitlementRules) // Entitlement tiers of a leave policy
+		leaves.GET("/Get-All-Leave-Policy", h.GetAllLeavePolicies)              // Get all leave policies
+		leaves.GET("/manager/history", h.GetManagerLeaveHistory)                // Manager gets team leave history
+		leaves.GET("/calendar", h.GetLeaveCalendar)                             // Per-day absence calendar (team/department/company)
+		leaves.POST("/:id/action", h.ActionLeave)                               // Approve/Reject leave
+		leaves.DELETE("/:id/cancel", h.CancelLeave)                             // Cancel pending leave (Employee/Admin)
+		leaves.POST("/:id/withdraw", h.WithdrawLeave)                           // Withdraw approved leave (Admin/Manager)
+		leaves.GET("/all", h.GetAllLeaves)                                      // Get all leaves (filtered by role)
+		leaves.GET("/my-leaves", h.GetAllMyLeave)                               // Get current user's own leaves with month/year filtering
+		leaves.GET("/:id", h.GetLeaveByID)                                      // Get leave by ID (role-based access)
+		leaves.GET("/timming", h.GetLeaveTiming)                                // Get all Leave Timing
+		leaves.PUT("/timming", h.UpdateLeaveTiming)                             // Update leave timing by super admin and admin
+
+		leaves.POST("/:id/attachments", h.UploadLeaveAttachments)                // Upload supporting documents
+		leaves.GET("/:id/attachments/:attachmentId", h.DownloadLeaveAttachment)  // Download an attachment (same access as GetLeaveByID)
+		leaves.DELETE("/:id/attachments/:attachmentId", h.DeleteLeaveAttachment) // Remove an attachment while awaiting approval
+
+		leaves.POST("/:id/modify", h.ModifyLeave)     // Shorten/split an approved leave (Admin/HR/Manager)
+		leaves.GET("/:id/history", h.GetLeaveHistory) // Shorten/split history of a leave
+
+		leaves.POST("/:id/cancel-request", h.RequestLeaveCancellation) // Employee requests cancellation of own approved leave (HR override for started leaves)
+		leaves.POST("/:id/withdraw/reject", h.RejectLeaveWithdrawal)   // Reject/retract a pending withdrawal request
+	}
+
+	// ----------------- Comp-Off -----------------
+	compOff := r.Group("/api/comp-off")
+	compOff.Use(middleware.AuthMiddleware(h))
+	{
+		compOff.POST("/", h.RequestCompOff)          // Employee requests comp-off for a worked weekend/holiday
+		compOff.GET("/my", h.GetMyCompOffRequests)   // Current user's comp-off requests
+		compOff.GET("/", h.GetCompOffRequests)       // Requests to approve (Manager: team, Admin/HR: all)
+		compOff.POST("/:id/action", h.ActionCompOff) // Approve/Reject comp-off request
+		compOff.DELETE("/:id", h.CancelCompOff)      // Cancel own pending request
+		compOff.POST("/lapse", h.RunCompOffLapse)    // Run comp-off lapse now (SUPERADMIN, ADMIN, HR)
+	}
+
+	// ----------------- Leave Accruals -----------------
+	leaveAccruals := r.Group("/api/leave-accruals")
+	leaveAccruals.Use(middleware.AuthMiddleware(h))
+	{
+		leaveAccruals.GET("/", h.GetLeaveAccruals)    // Accrual ledger (own entries unless SUPERADMIN, ADMIN, HR)
+		leaveAccruals.POST("/run", h.RunLeaveAccrual) // Run accruals now, supports dry_run (SUPERADMIN, ADMIN, HR)
+	}
+
+	// ----------------- Year-End Close -----------------
+	yearEnd := r.Group("/api/year-end")
+	yearEnd.Use(middleware.AuthMiddleware(h))
+	{
+		yearEnd.GET("/:year", h.GetYearEndClose)           // Finalized close with lines (SUPERADMIN, ADMIN, HR)
+		yearEnd.GET("/:year/preview", h.PreviewYearEnd)    // Preview carry-forward, encashment and lapse (SUPERADMIN, ADMIN, HR)
+		yearEnd.POST("/:year/finalize", h.FinalizeYearEnd) // Close the year, set next year's opening balances (SUPERADMIN, ADMIN)
 	}
 
 	// ----------------- Leave Balances -----------------
@@ -75,6 +122,16 @@ func SetupRoutes(r *gin.Engine, h *controllers.HandlerFunc) {
 
 		leaveBalances.GET("/employee/:id", h.GetLeaveBalances)  // GET /api/employees/:id/leave-balances
 		leaveBalances.POST("/:id/adjust", h.AdjustLeaveBalance) // POST /api/leave-balances/:id/adjust
+
+		leaveBalances.GET("/ledger/:id", h.GetLeaveLedger)           // Balance movements of an employee
+		leaveBalances.GET("/reconcile", h.ReconcileLeaveBalances)    // Balances that disagree with the ledger (SUPERADMIN, ADMIN, HR)
+		leaveBalances.GET("/statement/:id", h.GetLeaveStatement)     // Yearly statement as JSON, PDF or CSV
+		leaveBalances.GET("/projection/:id", h.GetBalanceProjection) // Expected balance on a future date
+
+		leaveBalances.POST("/bulk-adjust", h.BulkAdjustLeaveBalances)                      // Adjust many employees at once (?dry_run=true to preview)
+		leaveBalances.GET("/bulk-adjustments", h.GetLeaveAdjustmentBatches)                // Bulk adjustment batches
+		leaveBalances.GET("/bulk-adjustments/:id", h.GetLeaveAdjustmentBatch)              // Batch with its adjustments
+		leaveBalances.POST("/bulk-adjustments/:id/reverse", h.ReverseLeaveAdjustmentBatch) // Undo a batch
 	}
 
 	// ----------------- Payroll -----------------
@@ -89,12 +146,65 @@ func SetupRoutes(r *gin.Engine, h *controllers.HandlerFunc) {
 		payroll.POST("/:id/finalize", h.FinalizePayroll)
 		// POST /api/payroll/{id}/finalize
 
+		// Maker-checker review: DRAFT -> SUBMITTED -> APPROVED -> FINALIZED -> PAID
+		payroll.POST("/:id/submit", h.SubmitPayroll)
+		payroll.POST("/:id/approve", h.ApprovePayroll)
+		payroll.POST("/:id/reject", h.RejectPayroll)
+		payroll.POST("/:id/paid", h.MarkPayrollPaid)
+
+		// Withdraw finalized payslips (kept for audit) and pay corrections in the next run
+		payroll.POST("/:id/withdraw", h.WithdrawPayrollRun)
+		payroll.POST("/payslips/:id/withdraw", h.WithdrawPayslip)
+		payroll.POST("/payslips/:id/correct", h.CorrectPayslip)
+		payroll.GET("/corrections", h.GetPayslipCorrections)
+
+		// Bank salary transfer files (CSV, NACHA, PAIN001, NEFT); generating one marks the run PAID
+		payroll.GET("/bank-account", h.GetCompanyBankAccount)
+		payroll.PUT("/bank-account", h.UpdateCompanyBankAccount)
+		payroll.POST("/:id/bank-file", h.GenerateBankFile)
+		payroll.GET("/:id/bank-files", h.GetPayrollBankFiles)
+		payroll.GET("/bank-files/:id", h.DownloadBankFile)
+
+		// Payroll run with its (draft) payslips and history, and what changed since the preview
+		payroll.GET("/:id", h.GetPayrollRun)
+		payroll.GET("/:id/changes", h.GetPayrollDraftChanges)
+
 		payroll.GET("/payslip", h.GetFinalizedPayslips)
 
 		// Download payslip PDF for a specific employee payslip ID
 		payroll.GET("/payslips/:id/pdf", h.GetPayslipPDF)
 		// GET /api/payroll/payslips/{id}/pdf
 
+		// Salary structures: earning and deduction components assigned per employee or designation
+		payroll.GET("/salary-structures", h.GetSalaryStructures)
+		payroll.POST("/salary-structures", h.CreateSalaryStructure)
+		payroll.GET("/salary-structures/:id", h.GetSalaryStructure)
+		payroll.PUT("/salary-structures/:id", h.UpdateSalaryStructure)
+		payroll.DELETE("/salary-structures/:id", h.DeleteSalaryStructure)
+
+		// Statutory deductions: versioned TDS, EPF, ESI and professional tax rules
+		payroll.GET("/statutory-rules", h.GetStatutoryRules)
+		payroll.POST("/statutory-rules", h.CreateStatutoryRule)
+		payroll.DELETE("/statutory-rules/:id", h.DeleteStatutoryRule)
+
+		// Year-to-date payslip totals of an employee for a financial year
+		payroll.GET("/ytd/:id", h.GetPayrollYTD)
+
+	}
+
+	// ----------------- Calendar Feeds -----------------
+	calendarFeeds := r.Group("/api/calendar-feeds")
+	calendarFeeds.Use(middleware.AuthMiddleware(h))
+	{
+		calendarFeeds.GET("/", h.GetCalendarFeeds)               // Get my ICS subscription URLs
+		calendarFeeds.POST("/rotate", h.RotateCalendarFeedToken) // Issue a new feed token (old URLs stop working)
+	}
+	// Public ICS feeds, authenticated by the secret token in the URL
+	ics := r.Group("/api/ics/:token")
+	{
+		ics.GET("/my-leaves.ics", h.GetMyLeavesFeed)     // My approved leaves
+		ics.GET("/team-leaves.ics", h.GetTeamLeavesFeed) // Team leaves
+		ics.GET("/holidays.ics", h.GetHolidaysFeed)      // Company holidays
 	}
 
 	// ----------------- Settings -----------------
@@ -111,16 +221,24 @@ func SetupRoutes(r *gin.Engine, h *controllers.HandlerFunc) {
 		holidays.GET("/", h.GetHolidays)         // List all holidays
 		holidays.DELETE("/:id", h.DeleteHoliday) // Remove holiday
 	}
+	blackouts := r.Group("/api/settings/blackouts")
+	blackouts.Use(middleware.AuthMiddleware(h))
+	{
+		blackouts.POST("/", h.AddLeaveBlackout)         // Add leave blackout period (SUPERADMIN, ADMIN, HR)
+		blackouts.GET("/", h.GetLeaveBlackouts)         // List blackout periods
+		blackouts.DELETE("/:id", h.DeleteLeaveBlackout) // Remove blackout period (SUPERADMIN, ADMIN, HR)
+	}
 
 	// ----------------- Designations -----------------
 	designations := r.Group("/api/designations")
 	designations.Use(middleware.AuthMiddleware(h))
 	{
-		designations.POST("/", h.CreateDesignation)      // Create designation (ADMIN, SUPERADMIN, HR)
-		designations.GET("/", h.GetAllDesignations)      // Get all designations (All authenticated users)
-		designations.GET("/:id", h.GetDesignationByID)   // Get designation by ID (All authenticated users)
-		designations.PATCH("/:id", h.UpdateDesignation)  // Update designation (ADMIN, SUPERADMIN, HR)
-		designations.DELETE("/:id", h.DeleteDesignation) // Delete designation (ADMIN, SUPERADMIN, HR)
+		designations.POST("/", h.CreateDesignation)                                     // Create designation (ADMIN, SUPERADMIN, HR)
+		designations.GET("/", h.GetAllDesignations)                                     // Get all designations (All authenticated users)
+		designations.GET("/:id", h.GetDesignationByID)                                  // Get designation by ID (All authenticated users)
+		designations.PATCH("/:id", h.UpdateDesignation)                                 // Update designation (ADMIN, SUPERADMIN, HR)
+		designations.DELETE("/:id", h.DeleteDesignation)                                // Delete designation (ADMIN, SUPERADMIN, HR)
+		designations.PATCH("/:id/salary-structure", h.UpdateDesignationSalaryStructure) // Assign/remove salary structure (ADMIN, SUPERADMIN)
 	}
 	logs := r.Group("/api/logs")
 	logs.Use((middleware.AuthMiddleware(h)))
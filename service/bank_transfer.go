@@ -0,0 +1,534 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+var (
+	accountNumberPattern = regexp.MustCompile(`^[A-Z0-9]{4,34}$`)
+	ifscPattern          = regexp.MustCompile(`^[A-Z]{4}0[A-Z0-9]{6}$`)
+	routingPattern       = regexp.MustCompile(`^[0-9]{9}$`)
+	ibanPattern          = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+	bicPattern           = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+	achCompanyIDPattern  = regexp.MustCompile(`^[A-Z0-9]{1,10}$`)
+	currencyPattern      = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// IsValidBankFileFormat reports whether format is a bank payment file format
+func IsValidBankFileFormat(format string) bool {
+	switch format {
+	case models.BankFileCSV, models.BankFileNACHA, models.BankFilePain001, models.BankFileNEFT:
+		return true
+	}
+	return false
+}
+
+// bankIdentifier upper-cases an optional identifier and drops its spaces, nil when empty
+func bankIdentifier(v *string) *string {
+	if v == nil {
+		return nil
+	}
+	id := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(*v), " ", ""))
+	if id == "" {
+		return nil
+	}
+	return &id
+}
+
+// validRoutingNumber checks the check digit of an ABA routing number
+func validRoutingNumber(routing string) bool {
+	if !routingPattern.MatchString(routing) {
+		return false
+	}
+	weights := []int{3, 7, 1}
+	sum := 0
+	for i, r := range routing {
+		sum += int(r-'0') * weights[i%3]
+	}
+	return sum%10 == 0
+}
+
+// validIBAN checks the mod-97 check digits of an IBAN
+func validIBAN(iban string) bool {
+	if !ibanPattern.MatchString(iban) {
+		return false
+	}
+	var digits strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// NormalizeBankAccount validates a bank account request
+func NormalizeBankAccount(in models.BankAccountInput) (models.BankAccount, error) {
+	a := models.BankAccount{
+		AccountHolderName: strings.TrimSpace(in.AccountHolderName),
+		AccountNumber:     strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(in.AccountNumber), " ", "")),
+		AccountType:       strings.ToUpper(strings.TrimSpace(in.AccountType)),
+		IFSCCode:          bankIdentifier(in.IFSCCode),
+		RoutingNumber:     bankIdentifier(in.RoutingNumber),
+		IBAN:              bankIdentifier(in.IBAN),
+		BIC:               bankIdentifier(in.BIC),
+	}
+	if in.BankName != nil && strings.TrimSpace(*in.BankName) != "" {
+		name := strings.TrimSpace(*in.BankName)
+		a.BankName = &name
+	}
+	if a.AccountType == "" {
+		a.AccountType = models.BankAccountSavings
+	}
+
+	if a.AccountHolderName == "" || len(a.AccountHolderName) > 100 {
+		return a, fmt.Errorf("account_holder_name is required and must be at most 100 characters")
+	}
+	if !accountNumberPattern.MatchString(a.AccountNumber) {
+		return a, fmt.Errorf("account_number must be 4 to 34 letters or digits")
+	}
+	if a.AccountType != models.BankAccountSavings && a.AccountType != models.BankAccountChecking {
+		return a, fmt.Errorf("account_type must be SAVINGS or CHECKING")
+	}
+	if a.IFSCCode != nil && !ifscPattern.MatchString(*a.IFSCCode) {
+		return a, fmt.Errorf("ifsc_code must be 11 characters: 4 letters, 0, then 6 letters or digits")
+	}
+	if a.RoutingNumber != nil && !validRoutingNumber(*a.RoutingNumber) {
+		return a, fmt.Errorf("routing_number must be a valid 9-digit ABA routing number")
+	}
+	if a.IBAN != nil && !validIBAN(*a.IBAN) {
+		return a, fmt.Errorf("iban is not a valid IBAN")
+	}
+	if a.BIC != nil && !bicPattern.MatchString(*a.BIC) {
+		return a, fmt.Errorf("bic must be 8 or 11 letters or digits")
+	}
+	return a, nil
+}
+
+// NormalizeCompanyBankAccount validates the company's salary account request
+func NormalizeCompanyBankAccount(in models.CompanyBankAccountInput) (models.CompanyBankAccount, error) {
+	if strings.TrimSpace(in.AccountType) == "" {
+		in.AccountType = models.BankAccountChecking
+	}
+	account, err := NormalizeBankAccount(in.BankAccountInput)
+	a := models.CompanyBankAccount{
+		BankAccount:  account,
+		ACHCompanyID: bankIdentifier(in.ACHCompanyID),
+		Currency:     strings.ToUpper(strings.TrimSpace(in.Currency)),
+	}
+	if err != nil {
+		return a, err
+	}
+	if a.Currency == "" {
+		a.Currency = "INR"
+	}
+	if !currencyPattern.MatchString(a.Currency) {
+		return a, fmt.Errorf("currency must be a 3-letter ISO 4217 code")
+	}
+	if a.ACHCompanyID != nil && !achCompanyIDPattern.MatchString(*a.ACHCompanyID) {
+		return a, fmt.Errorf("ach_company_id must be at most 10 letters or digits")
+	}
+	return a, nil
+}
+
+// PaymentReference is the reference of the n-th salary transfer of a payroll month, short
+// enough for a NACHA individual ID (15 characters)
+func PaymentReference(month, year, n int) string {
+	return fmt.Sprintf("SAL%04d%02d%05d", year, month, n)
+}
+
+// BankPaymentsFromPayslips groups the issued payslips of a run into one transfer per employee
+// of the net pay of their payslips. Employees whose net pay is not positive, such as a
+// correction recovering an overpayment, are left out.
+func BankPaymentsFromPayslips(payslips []models.RunPayslipPayment, month, year int) []models.BankPayment {
+	byEmployee := map[uuid.UUID]int{}
+	var grouped []models.BankPayment
+	for _, p := range payslips {
+		i, ok := byEmployee[p.EmployeeID]
+		if !ok {
+			i = len(grouped)
+			byEmployee[p.EmployeeID] = i
+			payment := models.BankPayment{EmployeeID: p.EmployeeID, Employee: p.Employee}
+			if p.HasAccount {
+				account := p.BankAccount
+				payment.Account = &account
+			}
+			grouped = append(grouped, payment)
+		}
+		grouped[i].Amount += p.NetSalary
+		grouped[i].PayslipIDs = append(grouped[i].PayslipIDs, p.PayslipID)
+	}
+
+	payments := []models.BankPayment{}
+	for _, payment := range grouped {
+		payment.Amount = float64(minorUnits(payment.Amount)) / 100
+		if payment.Amount <= 0 {
+			continue
+		}
+		payment.Reference = PaymentReference(month, year, len(payments)+1)
+		payments = append(payments, payment)
+	}
+	return payments
+}
+
+// BankFileViolations lists the bank details a format needs that the company or employees are
+// missing. payer is nil when the company has no salary account on file.
+func BankFileViolations(format string, payer *models.CompanyBankAccount, payments []models.BankPayment) []models.BankFileViolation {
+	violations := []models.BankFileViolation{}
+	company := func(message string) {
+		violations = append(violations, models.BankFileViolation{Message: message})
+	}
+	if payer == nil && format != models.BankFileCSV {
+		company("The company salary account is not set up")
+	}
+	if payer != nil {
+		switch format {
+		case models.BankFileNACHA:
+			if payer.RoutingNumber == nil {
+				company("The company salary account has no routing number")
+			}
+			if payer.ACHCompanyID == nil {
+				company("The company salary account has no ACH company ID")
+			}
+		case models.BankFileNEFT:
+			if payer.IFSCCode == nil {
+				company("The company salary account has no IFSC code")
+			}
+		}
+	}
+
+	for i := range payments {
+		p := &payments[i]
+		employee := func(message string) {
+			violations = append(violations, models.BankFileViolation{EmployeeID: &p.EmployeeID, Employee: p.Employee, Message: message})
+		}
+		switch {
+		case p.Account == nil:
+			employee("No bank account on file")
+		case format == models.BankFileNACHA && p.Account.RoutingNumber == nil:
+			employee("Bank account has no routing number")
+		case format == models.BankFileNEFT && p.Account.IFSCCode == nil:
+			employee("Bank account has no IFSC code")
+		}
+	}
+	return violations
+}
+
+// minorUnits converts an amount to paise or cents
+func minorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// formatMinor formats paise or cents as an amount with two decimals
+func formatMinor(units int64) string {
+	return fmt.Sprintf("%d.%02d", units/100, units%100)
+}
+
+// BuildBankFile generates the bank payment file of a payroll month's transfers, with its
+// entry count, control total and SHA-256 checksum. payer is nil only for CSV files.
+func BuildBankFile(format string, payer *models.CompanyBankAccount, payments []models.BankPayment, month, year int, execution, now time.Time) (models.BankFile, error) {
+	file := models.BankFile{Format: format, EntryCount: len(payments)}
+	var total int64
+	for _, p := range payments {
+		total += minorUnits(p.Amount)
+	}
+	file.ControlTotal = float64(total) / 100
+
+	var content string
+	var err error
+	ext := "txt"
+	switch format {
+	case models.BankFileCSV:
+		content, err = buildBankCSV(payer, payments, total)
+		ext = "csv"
+	case models.BankFileNACHA:
+		content, err = buildNACHA(payer, payments, month, year, execution, now)
+		ext = "ach"
+	case models.BankFilePain001:
+		content, err = buildPain001(payer, payments, total, month, year, execution, now)
+		ext = "xml"
+	case models.BankFileNEFT:
+		content, err = buildBulkNEFT(payer, payments, total, month, year, execution)
+	default:
+		err = fmt.Errorf("unsupported bank file format %s", format)
+	}
+	if err != nil {
+		return file, err
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	file.Content = content
+	file.Checksum = hex.EncodeToString(sum[:])
+	file.FileName = fmt.Sprintf("salary_%04d_%02d_%s.%s", year, month, strings.ToLower(format), ext)
+	return file, nil
+}
+
+// optional returns an optional bank detail, "" when not set
+func optional(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+// buildBankCSV writes one row per transfer and a CONTROL row with the entry count and total
+func buildBankCSV(payer *models.CompanyBankAccount, payments []models.BankPayment, total int64) (string, error) {
+	currency := "INR"
+	if payer != nil {
+		currency = payer.Currency
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"Payment Reference", "Employee ID", "Beneficiary Name", "Account Number", "Account Type",
+		"Bank Name", "IFSC Code", "Routing Number", "IBAN", "BIC", "Amount", "Currency"})
+	for _, p := range payments {
+		a := p.Account
+		w.Write([]string{p.Reference, p.EmployeeID.String(), a.AccountHolderName, a.AccountNumber, a.AccountType,
+			optional(a.BankName), optional(a.IFSCCode), optional(a.RoutingNumber), optional(a.IBAN), optional(a.BIC),
+			formatMinor(minorUnits(p.Amount)), currency})
+	}
+	w.Write([]string{"CONTROL", strconv.Itoa(len(payments)), "", "", "", "", "", "", "", "", formatMinor(total), currency})
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+// nachaText upper-cases, strips and pads or truncates text to a NACHA field
+func nachaText(s string, width int) string {
+	s = strings.ToUpper(strings.Map(func(r rune) rune {
+		if r < 32 || r > 126 {
+			return -1
+		}
+		return r
+	}, s))
+	if len(s) > width {
+		return s[:width]
+	}
+	return s + strings.Repeat(" ", width-len(s))
+}
+
+// nachaNumber zero-pads a number to a NACHA field, keeping its last digits when too long
+func nachaNumber(n int64, width int) string {
+	s := fmt.Sprintf("%0*d", width, n)
+	return s[len(s)-width:]
+}
+
+// buildNACHA writes a NACHA file of one PPD batch of credits. The batch and file control
+// records carry the entry count, entry hash (sum of receiving bank routing numbers) and total
+// credit; the file is padded to blocks of ten records.
+func buildNACHA(payer *models.CompanyBankAccount, payments []models.BankPayment, month, year int, execution, now time.Time) (string, error) {
+	routing := *payer.RoutingNumber
+	odfi := routing[:8]
+	companyID := *payer.ACHCompanyID
+
+	records := []string{
+		"1" + "01" + " " + routing + nachaText(companyID, 10) + now.Format("060102") + now.Format("1504") +
+			"A" + "094" + "10" + "1" + nachaText(optional(payer.BankName), 23) + nachaText(payer.AccountHolderName, 23) +
+			nachaText("", 8),
+		"5" + "220" + nachaText(payer.AccountHolderName, 16) + nachaText("", 20) + nachaText(companyID, 10) + "PPD" +
+			nachaText("PAYROLL", 10) + nachaText(strings.ToUpper(time.Month(month).String()[:3])+fmt.Sprintf("%02d", year%100), 6) +
+			execution.Format("060102") + "   " + "1" + odfi + nachaNumber(1, 7),
+	}
+
+	var hash, credit int64
+	for i, p := range payments {
+		a := p.Account
+		rdfi := *a.RoutingNumber
+		code := "32" // savings credit
+		if a.AccountType == models.BankAccountChecking {
+			code = "22"
+		}
+		bankHash, err := strconv.ParseInt(rdfi[:8], 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid routing number of %s", p.Employee)
+		}
+		hash += bankHash
+		amount := minorUnits(p.Amount)
+		if amount > 9999999999 {
+			return "", fmt.Errorf("salary transfer to %s exceeds the NACHA amount limit", p.Employee)
+		}
+		credit += amount
+		records = append(records, "6"+code+rdfi[:8]+rdfi[8:]+nachaText(a.AccountNumber, 17)+nachaNumber(amount, 10)+
+			nachaText(p.Reference, 15)+nachaText(a.AccountHolderName, 22)+"  "+"0"+odfi+nachaNumber(int64(i+1), 7))
+	}
+
+	entries := int64(len(payments))
+	records = append(records, "8"+"220"+nachaNumber(entries, 6)+nachaNumber(hash, 10)+nachaNumber(0, 12)+
+		nachaNumber(credit, 12)+nachaText(companyID, 10)+nachaText("", 19)+nachaText("", 6)+odfi+nachaNumber(1, 7))
+	blocks := int64((len(records) + 1 + 9) / 10)
+	records = append(records, "9"+nachaNumber(1, 6)+nachaNumber(blocks, 6)+nachaNumber(entries, 8)+
+		nachaNumber(hash, 10)+nachaNumber(0, 12)+nachaNumber(credit, 12)+nachaText("", 39))
+	for len(records)%10 != 0 {
+		records = append(records, strings.Repeat("9", 94))
+	}
+	return strings.Join(records, "\n") + "\n", nil
+}
+
+// ISO 20022 pain.001.001.03 customer credit transfer initiation
+type pain001Document struct {
+	XMLName    xml.Name          `xml:"urn:iso:std:iso:20022:tech:xsd:pain.001.001.03 Document"`
+	Initiation pain001Initiation `xml:"CstmrCdtTrfInitn"`
+}
+
+type pain001Initiation struct {
+	GroupHeader pain001GroupHeader `xml:"GrpHdr"`
+	PaymentInfo pain001PaymentInfo `xml:"PmtInf"`
+}
+
+type pain001GroupHeader struct {
+	MessageID       string      `xml:"MsgId"`
+	CreatedAt       string      `xml:"CreDtTm"`
+	NumberOfTxs     int         `xml:"NbOfTxs"`
+	ControlSum      string      `xml:"CtrlSum"`
+	InitiatingParty pain001Name `xml:"InitgPty"`
+}
+
+type pain001PaymentInfo struct {
+	PaymentInfoID string            `xml:"PmtInfId"`
+	PaymentMethod string            `xml:"PmtMtd"`
+	NumberOfTxs   int               `xml:"NbOfTxs"`
+	ControlSum    string            `xml:"CtrlSum"`
+	CategoryCode  string            `xml:"PmtTpInf>CtgyPurp>Cd"`
+	ExecutionDate string            `xml:"ReqdExctnDt"`
+	Debtor        pain001Name       `xml:"Dbtr"`
+	DebtorAccount pain001Account    `xml:"DbtrAcct"`
+	DebtorAgent   pain001Agent      `xml:"DbtrAgt"`
+	Transfers     []pain001Transfer `xml:"CdtTrfTxInf"`
+}
+
+type pain001Name struct {
+	Name string `xml:"Nm"`
+}
+
+type pain001Account struct {
+	ID       pain001AccountID `xml:"Id"`
+	Currency string           `xml:"Ccy,omitempty"`
+}
+
+// pain001AccountID is an IBAN or another account identifier
+type pain001AccountID struct {
+	IBAN  string        `xml:"IBAN,omitempty"`
+	Other *pain001Other `xml:"Othr,omitempty"`
+}
+
+type pain001Other struct {
+	ID string `xml:"Id"`
+}
+
+// pain001Agent is a bank identified by BIC, or by another identifier when the BIC is not known
+type pain001Agent struct {
+	BIC   string        `xml:"FinInstnId>BIC,omitempty"`
+	Other *pain001Other `xml:"FinInstnId>Othr,omitempty"`
+}
+
+type pain001Amount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type pain001Transfer struct {
+	EndToEndID      string         `xml:"PmtId>EndToEndId"`
+	Amount          pain001Amount  `xml:"Amt>InstdAmt"`
+	CreditorAgent   *pain001Agent  `xml:"CdtrAgt,omitempty"`
+	Creditor        pain001Name    `xml:"Cdtr"`
+	CreditorAccount pain001Account `xml:"CdtrAcct"`
+	Remittance      string         `xml:"RmtInf>Ustrd"`
+}
+
+// pain001AccountOf identifies an account by IBAN, else by account number
+func pain001AccountOf(a models.BankAccount) pain001Account {
+	if a.IBAN != nil {
+		return pain001Account{ID: pain001AccountID{IBAN: *a.IBAN}}
+	}
+	return pain001Account{ID: pain001AccountID{Other: &pain001Other{ID: a.AccountNumber}}}
+}
+
+// buildPain001 writes an ISO 20022 pain.001.001.03 message of one salary payment batch whose
+// group header and payment information carry the transaction count and control sum
+func buildPain001(payer *models.CompanyBankAccount, payments []models.BankPayment, total int64, month, year int, execution, now time.Time) (string, error) {
+	messageID := fmt.Sprintf("SAL%04d%02d-%s", year, month, now.Format("20060102150405"))
+	remittance := fmt.Sprintf("Salary %s %d", time.Month(month), year)
+
+	debtorAccount := pain001AccountOf(payer.BankAccount)
+	debtorAccount.Currency = payer.Currency
+	debtorAgent := pain001Agent{BIC: optional(payer.BIC)}
+	if debtorAgent.BIC == "" {
+		debtorAgent.Other = &pain001Other{ID: "NOTPROVIDED"}
+	}
+
+	doc := pain001Document{Initiation: pain001Initiation{
+		GroupHeader: pain001GroupHeader{
+			MessageID:       messageID,
+			CreatedAt:       now.Format("2006-01-02T15:04:05"),
+			NumberOfTxs:     len(payments),
+			ControlSum:      formatMinor(total),
+			InitiatingParty: pain001Name{Name: payer.AccountHolderName},
+		},
+		PaymentInfo: pain001PaymentInfo{
+			PaymentInfoID: messageID,
+			PaymentMethod: "TRF",
+			NumberOfTxs:   len(payments),
+			ControlSum:    formatMinor(total),
+			CategoryCode:  "SALA",
+			ExecutionDate: execution.Format("2006-01-02"),
+			Debtor:        pain001Name{Name: payer.AccountHolderName},
+			DebtorAccount: debtorAccount,
+			DebtorAgent:   debtorAgent,
+		},
+	}}
+	for _, p := range payments {
+		transfer := pain001Transfer{
+			EndToEndID:      p.Reference,
+			Amount:          pain001Amount{Currency: payer.Currency, Value: formatMinor(minorUnits(p.Amount))},
+			Creditor:        pain001Name{Name: p.Account.AccountHolderName},
+			CreditorAccount: pain001AccountOf(*p.Account),
+			Remittance:      remittance,
+		}
+		if p.Account.BIC != nil {
+			transfer.CreditorAgent = &pain001Agent{BIC: *p.Account.BIC}
+		}
+		doc.Initiation.PaymentInfo.Transfers = append(doc.Initiation.PaymentInfo.Transfers, transfer)
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(out) + "\n", nil
+}
+
+// neftText strips the field separator from text in a bulk NEFT file
+func neftText(s string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(s, ",", " ")), " ")
+}
+
+// buildBulkNEFT writes an Indian bank bulk NEFT upload: a header with the debit account, one
+// N (NEFT) record per transfer and a trailer with the record count and control total
+func buildBulkNEFT(payer *models.CompanyBankAccount, payments []models.BankPayment, total int64, month, year int, execution time.Time) (string, error) {
+	remarks := strings.ToUpper(fmt.Sprintf("SALARY %s %d", time.Month(month).String()[:3], year))
+	lines := []string{strings.Join([]string{"H", execution.Format("02012006"), payer.AccountNumber, *payer.IFSCCode,
+		strconv.Itoa(len(payments)), formatMinor(total)}, ",")}
+	for _, p := range payments {
+		a := p.Account
+		lines = append(lines, strings.Join([]string{"N", a.AccountNumber, formatMinor(minorUnits(p.Amount)),
+			neftText(a.AccountHolderName), *a.IFSCCode, p.Reference, remarks}, ","))
+	}
+	lines = append(lines, strings.Join([]string{"T", strconv.Itoa(len(payments)), formatMinor(total)}, ","))
+	return strings.Join(lines, "\n") + "\n", nil
+}
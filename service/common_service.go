@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -100,16 +101,21 @@ func CalculateAbsentDaysForMonth(db *sqlx.DB, employeeID uuid.UUID, month, year
 
 	// 3. Updated SQL: Removed "is_paid = false" to get ALL approved leaves
 	type LeaveRecord struct {
-		StartDate  time.Time `db:"start_date"`
-		EndDate    time.Time `db:"end_date"`
-		Days       float64   `db:"days"`
-		IsPaid     bool      `db:"is_paid"` // Now fetching this field
-		TimingType *string   `db:"timing_type"`
+		StartDate   time.Time `db:"start_date"`
+		EndDate     time.Time `db:"end_date"`
+		Days        float64   `db:"days"`
+		Hours       *float64  `db:"hours"`
+		IsPaid      bool      `db:"is_paid"` // Now fetching this field
+		TimingType  *string   `db:"timing_type"`
+		HalfID      int       `db:"half_id"`
+		StartHalfID *int      `db:"start_half_id"`
+		EndHalfID   *int      `db:"end_half_id"`
 	}
 
 	var leaves []LeaveRecord
 	err := db.Select(&leaves, `
-        SELECT l.start_date, l.end_date, l.days, lt.is_paid, h.type as timing_type
+        SELECT l.start_date, l.end_date, l.days, l.hours, lt.is_paid, h.type as timing_type,
+               COALESCE(l.half_id, 3) AS half_id, l.start_half_id, l.end_half_id
         FROM Tbl_Leave l
         JOIN Tbl_Leave_type lt ON l.leave_type_id = lt.id
         LEFT JOIN Tbl_Half h ON l.half_id = h.id
@@ -124,6 +130,10 @@ func CalculateAbsentDaysForMonth(db *sqlx.DB, employeeID uuid.UUID, month, year
 		return LeaveSummary{}
 	}
 
+	// Hourly leaves count as hours / working_hours_per_day of a day
+	var hoursPerDay float64
+	_ = db.Get(&hoursPerDay, `SELECT working_hours_per_day FROM Tbl_Company_Settings LIMIT 1`)
+
 	summary := LeaveSummary{}
 
 	// 4. Calculate days
@@ -145,10 +155,13 @@ func CalculateAbsentDaysForMonth(db *sqlx.DB, employeeID uuid.UUID, month, year
 				continue
 			}
 
-			if leave.StartDate.Equal(leave.EndDate) && leave.Days < 1.0 {
+			if leave.Hours != nil && hoursPerDay > 0 {
+				actualDaysInMonth += math.Min(*leave.Hours/hoursPerDay, 1.0)
+			} else if leave.StartDate.Equal(leave.EndDate) && leave.Days < 1.0 {
 				actualDaysInMonth += leave.Days
 			} else {
-				actualDaysInMonth += 1.0
+				// Per-day breakdown: first/last day timing, otherwise the leave's timing
+				actualDaysInMonth += LeaveDayPortion(d, leave.StartDate, leave.EndDate, leave.HalfID, leave.StartHalfID, leave.EndHalfID)
 			}
 		}
 
@@ -178,12 +191,14 @@ type LeaveTypeData struct {
 	LeaveTypeID        int
 	LeaveTypeName      string
 	DefaultEntitlement float64
+	Unit               string
 }
 
 // CalculatedBalance represents the calculated leave balance result
 type CalculatedBalance struct {
 	LeaveTypeID int     `json:"leave_type_id"`
 	LeaveType   string  `json:"leave_type"`
+	Unit        string  `json:"unit"` // DAY or HOUR; all amounts are in this unit
 	Opening     float64 `json:"opening"`
 	Accrued     float64 `json:"accrued"`
 	Used        float64 `json:"used"`
@@ -234,6 +249,7 @@ func CalculateLeaveBalances(leaveTypes []LeaveTypeData, balanceRecords []LeaveBa
 		calculatedBalances = append(calculatedBalances, CalculatedBalance{
 			LeaveTypeID: lt.LeaveTypeID,
 			LeaveType:   lt.LeaveTypeName,
+			Unit:        lt.Unit,
 			Opening:     opening,
 			Accrued:     accrued,
 			Used:        used,
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
+)
+
+// ApplyBalanceAdjustment adds quantity (positive or negative) to the adjusted and closing
+// values of an employee's balance for the year, creating the balance row if needed.
+// The movement is posted to the ledger with ref (an ADJUSTMENT unless ref says otherwise).
+func ApplyBalanceAdjustment(Query *repositories.Repository, tx *sqlx.Tx, employeeID uuid.UUID, leaveTypeID, year int, quantity float64, ref models.LedgerRef) (repositories.LeaveBalanceForAdjustment, error) {
+	balance, err := Query.EnsureLeaveBalance(tx, employeeID, leaveTypeID, year)
+	if err != nil {
+		return balance, fmt.Errorf("failed to fetch leave balance: %v", err)
+	}
+	if err := Query.PostLeaveLedgerEntry(tx, employeeID, leaveTypeID, year, quantity, ref); err != nil {
+		return balance, fmt.Errorf("failed to update leave balance: %v", err)
+	}
+	return Query.GetLeaveBalanceForAdjustment(tx, employeeID, leaveTypeID, year)
+}
+
+// CompOffDays converts a day timing ID into the comp-off days earned (half or full day)
+func CompOffDays(timingID *int) (float64, error) {
+	if timingID == nil {
+		return 1, nil
+	}
+	switch *timingID {
+	case 1, 2:
+		return 0.5, nil
+	case 3:
+		return 1, nil
+	}
+	return 0, fmt.Errorf("invalid day timing ID. Must be 1 (First Half), 2 (Second Half), or 3 (Full Day)")
+}
+
+// ValidateCompOffLeaveType checks that a leave type can be the comp-off leave type: its balance
+// may only come from comp-off credits, so it cannot have a default entitlement, accrual or
+// entitlement tiers (tierEntitlements)
+func ValidateCompOffLeaveType(lt models.LeaveType, tierEntitlements []float64) error {
+	tiered := false
+	for _, entitlement := range tierEntitlements {
+		tiered = tiered || entitlement != 0
+	}
+	if lt.DefaultEntitlement != 0 || lt.AccrualFrequency != models.AccrualNone || tiered {
+		return fmt.Errorf("the comp-off leave type cannot have a default entitlement, accrual or entitlement tiers; its balance only comes from comp-off credits")
+	}
+	return nil
+}
+
+// compOffCreditYear is the year of the balance a credit was booked to, the year it was approved in
+func compOffCreditYear(credit models.CompOffRequest, today time.Time) int {
+	if credit.ActionAt == nil {
+		return today.Year()
+	}
+	return credit.ActionAt.Year()
+}
+
+// LapseExpiredCompOff lapses the unused part of comp-off credits that have passed their expiry,
+// from the balance of the year each credit was booked to.
+// Leave taken is assumed to consume the oldest credits first, so the unused amount of the
+// expired credits is whatever the year's balance holds beyond the credits of that year that are
+// still valid. This relies on the comp-off leave type holding nothing but comp-off credits (see
+// ValidateCompOffLeaveType).
+func LapseExpiredCompOff(Query *repositories.Repository, tx *sqlx.Tx, today time.Time) ([]models.CompOffLapse, error) {
+	today = truncateDate(today)
+	credits, err := Query.GetExpiredCompOffCreditsTx(tx, today)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch expired comp-off credits: %v", err)
+	}
+
+	// Group expired credits per employee, leave type and credit year, keeping expiry order
+	type groupKey struct {
+		employeeID  uuid.UUID
+		leaveTypeID int
+		year        int
+	}
+	var order []groupKey
+	groups := make(map[groupKey][]models.CompOffRequest)
+	for _, credit := range credits {
+		if credit.LeaveTypeID == nil {
+			continue
+		}
+		key := groupKey{credit.EmployeeID, *credit.LeaveTypeID, compOffCreditYear(credit, today)}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], credit)
+	}
+
+	var lapses []models.CompOffLapse
+	for _, key := range order {
+		group := groups[key]
+		year := key.year
+		expired := 0.0
+		for _, credit := range group {
+			expired += credit.Days
+		}
+
+		unused := 0.0
+		balance, err := Query.GetLeaveBalanceForAdjustment(tx, key.employeeID, key.leaveTypeID, year)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to fetch leave balance: %v", err)
+		}
+		if err == nil {
+			active, err := Query.GetActiveCompOffCreditsTx(tx, key.employeeID, key.leaveTypeID, year, today)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch active comp-off credits: %v", err)
+			}
+			unused = math.Max(0, math.Min(expired, balance.Closing-active))
+		}
+
+		// The unused part belongs to the most recent expired credits
+		remaining := unused
+		for i := len(group) - 1; i >= 0; i-- {
+			lapsed := math.Min(group[i].Days, remaining)
+			remaining -= lapsed
+			if err := Query.MarkCompOffLapsed(tx, group[i].ID, lapsed); err != nil {
+				return nil, fmt.Errorf("failed to mark comp-off credit lapsed: %v", err)
+			}
+		}
+
+		if unused > 0 {
+			reason := fmt.Sprintf("Comp-off lapsed: %d expired credit(s)", len(group))
+			adjustmentID, err := Query.InsertSystemLeaveAdjustment(tx, key.employeeID, key.leaveTypeID, -unused, reason, year)
+			if err != nil {
+				return nil, fmt.Errorf("failed to record comp-off lapse: %v", err)
+			}
+			ref := models.LedgerRef{EntryType: models.LedgerLapse, AdjustmentID: &adjustmentID, Note: reason}
+			if _, err := ApplyBalanceAdjustment(Query, tx, key.employeeID, key.leaveTypeID, year, -unused, ref); err != nil {
+				return nil, err
+			}
+		}
+
+		lapses = append(lapses, models.CompOffLapse{
+			EmployeeID:  key.employeeID,
+			LeaveTypeID: key.leaveTypeID,
+			Year:        year,
+			Lapsed:      unused,
+			Credits:     len(group),
+		})
+	}
+	return lapses, nil
+}
+
+// RunCompOffLapse runs LapseExpiredCompOff in its own transaction (used by the scheduler)
+func RunCompOffLapse(Query *repositories.Repository) ([]models.CompOffLapse, error) {
+	tx, err := Query.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	lapses, err := LapseExpiredCompOff(Query, tx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return lapses, tx.Commit()
+}
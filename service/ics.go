@@ -0,0 +1,160 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// icsTimestamp is the UTC date-time format used by iCalendar (RFC 5545)
+const icsTimestamp = "20060102T150405Z"
+
+// ICSCalendar builds an iCalendar (RFC 5545) document line by line
+type ICSCalendar struct {
+	domain string
+	lines  []string
+}
+
+// NewICSCalendar starts a calendar with the given display name.
+// domain is used to build globally unique event UIDs.
+func NewICSCalendar(name, domain string) *ICSCalendar {
+	cal := &ICSCalendar{domain: domain}
+	cal.add("BEGIN:VCALENDAR")
+	cal.add("VERSION:2.0")
+	cal.add("PRODID:-//Leave Management System//Leave Feeds//EN")
+	cal.add("CALSCALE:GREGORIAN")
+	cal.add("METHOD:PUBLISH")
+	cal.add("X-WR-CALNAME:" + icsEscape(name))
+	cal.add("X-PUBLISHED-TTL:PT1H")
+	return cal
+}
+
+// AddLeave adds a leave as an event. Withdrawn and cancelled leaves are
+// published with STATUS:CANCELLED so subscribed clients remove them.
+func (cal *ICSCalendar) AddLeave(l models.FeedLeave, withName bool) {
+	summary := l.LeaveType
+	if withName {
+		summary = l.EmployeeName + " - " + l.LeaveType
+	}
+
+	status := "CONFIRMED"
+	switch l.Status {
+	case "WITHDRAWN", "CANCELLED":
+		status = "CANCELLED"
+	case "WITHDRAWAL_PENDING":
+		summary += " (withdrawal pending)"
+	}
+
+	cal.add("BEGIN:VEVENT")
+	cal.add(fmt.Sprintf("UID:leave-%s@%s", l.ID, cal.domain))
+	cal.add("DTSTAMP:" + l.UpdatedAt.UTC().Format(icsTimestamp))
+	cal.add("LAST-MODIFIED:" + l.UpdatedAt.UTC().Format(icsTimestamp))
+	cal.add(fmt.Sprintf("SEQUENCE:%d", l.UpdatedAt.Unix()))
+
+	if l.StartTime != nil && l.EndTime != nil {
+		// Hourly leave: floating local time within the working day
+		day := l.StartDate.Format("20060102")
+		cal.add("DTSTART:" + day + "T" + strings.ReplaceAll(*l.StartTime, ":", "") + "00")
+		cal.add("DTEND:" + day + "T" + strings.ReplaceAll(*l.EndTime, ":", "") + "00")
+		summary += fmt.Sprintf(" (%s-%s)", *l.StartTime, *l.EndTime)
+	} else {
+		// All-day event, DTEND is exclusive
+		cal.add("DTSTART;VALUE=DATE:" + l.StartDate.Format("20060102"))
+		cal.add("DTEND;VALUE=DATE:" + l.EndDate.AddDate(0, 0, 1).Format("20060102"))
+		summary += feedTimingLabel(l)
+	}
+
+	cal.add("SUMMARY:" + icsEscape(summary))
+	cal.add("STATUS:" + status)
+	cal.add("TRANSP:TRANSPARENT")
+	cal.add("END:VEVENT")
+}
+
+// AddHoliday adds a company holiday as an all-day event
+func (cal *ICSCalendar) AddHoliday(h models.Holiday) {
+	stamp := h.UpdatedAt
+	if stamp.IsZero() {
+		stamp = h.CreatedAt
+	}
+	cal.add("BEGIN:VEVENT")
+	cal.add(fmt.Sprintf("UID:holiday-%d@%s", h.ID, cal.domain))
+	cal.add("DTSTAMP:" + stamp.UTC().Format(icsTimestamp))
+	cal.add("DTSTART;VALUE=DATE:" + h.Date.Format("20060102"))
+	cal.add("DTEND;VALUE=DATE:" + h.Date.AddDate(0, 0, 1).Format("20060102"))
+	cal.add("SUMMARY:" + icsEscape(h.Name))
+	cal.add("CATEGORIES:HOLIDAY")
+	cal.add("TRANSP:TRANSPARENT")
+	cal.add("END:VEVENT")
+}
+
+// String closes the calendar and returns it with CRLF line endings
+func (cal *ICSCalendar) String() string {
+	var b strings.Builder
+	for _, line := range append(cal.lines, "END:VCALENDAR") {
+		b.WriteString(icsFold(line))
+		b.WriteString("\r\n")
+	}
+	return b.String()
+}
+
+func (cal *ICSCalendar) add(line string) {
+	cal.lines = append(cal.lines, line)
+}
+
+// feedTimingLabel describes half-day timings for the event summary
+func feedTimingLabel(l models.FeedLeave) string {
+	half := func(id int) string {
+		switch id {
+		case 1:
+			return "first half"
+		case 2:
+			return "second half"
+		}
+		return ""
+	}
+	if sameDate(l.StartDate, l.EndDate) {
+		if s := half(l.HalfID); s != "" {
+			return " (" + s + ")"
+		}
+		return ""
+	}
+	var parts []string
+	if l.StartHalfID != nil && half(*l.StartHalfID) != "" {
+		parts = append(parts, "starts "+half(*l.StartHalfID))
+	}
+	if l.EndHalfID != nil && half(*l.EndHalfID) != "" {
+		parts = append(parts, "ends "+half(*l.EndHalfID))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// icsEscape escapes text values per RFC 5545
+func icsEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// icsFold folds lines longer than 75 octets without splitting UTF-8 sequences
+func icsFold(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+	var b strings.Builder
+	limit := 75
+	count := 0
+	for _, r := range line {
+		size := len(string(r))
+		if count+size > limit {
+			b.WriteString("\r\n ")
+			count = 1
+			limit = 75
+		}
+		b.WriteRune(r)
+		count += size
+	}
+	return b.String()
+}
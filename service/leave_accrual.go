@@ -0,0 +1,186 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
+)
+
+// AccrualPeriod is one accrual period of a year
+type AccrualPeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+// AccrualPeriods splits a year into the periods of the given frequency
+func AccrualPeriods(frequency string, year int) []AccrualPeriod {
+	count, ok := models.AccrualPeriodsPerYear[frequency]
+	if !ok {
+		return nil
+	}
+	months := 12 / count
+	periods := make([]AccrualPeriod, 0, count)
+	for i := 0; i < count; i++ {
+		start := time.Date(year, time.Month(1+i*months), 1, 0, 0, 0, 0, time.UTC)
+		periods = append(periods, AccrualPeriod{Start: start, End: start.AddDate(0, months, -1)})
+	}
+	return periods
+}
+
+// AccrualProration is the share of the period the employee was employed, based on
+// joining_date and ending_date (calendar days)
+func AccrualProration(emp models.AccrualEmployee, period AccrualPeriod) float64 {
+	start, end := period.Start, period.End
+	if emp.JoiningDate != nil && truncateDate(*emp.JoiningDate).After(start) {
+		start = truncateDate(*emp.JoiningDate)
+	}
+	if emp.EndingDate != nil && truncateDate(*emp.EndingDate).Before(end) {
+		end = truncateDate(*emp.EndingDate)
+	}
+	if end.Before(start) {
+		return 0
+	}
+	employed := end.Sub(start).Hours()/24 + 1
+	total := period.End.Sub(period.Start).Hours()/24 + 1
+	return math.Min(1, employed/total)
+}
+
+// AccrualAmount is what an employee is credited for one of the periodsPerYear periods of a
+// year given their annual entitlement, along with the proration applied
+func AccrualAmount(entitlement float64, periodsPerYear int, emp models.AccrualEmployee, period AccrualPeriod) (proration, amount float64) {
+	proration = math.Round(AccrualProration(emp, period)*10000) / 10000
+	amount = math.Round(entitlement/float64(periodsPerYear)*proration*100) / 100
+	return proration, amount
+}
+
+// RunLeaveAccruals credits every accruing leave type for the periods of the year that ended
+// before asOf. Each employee is credited once per leave type and period (tracked in the accrual
+// ledger), so running it again only picks up what is missing. PAY_PERIOD types are credited once
+// the payroll of the month is finalized.
+func RunLeaveAccruals(Query *repositories.Repository, tx *sqlx.Tx, year int, asOf time.Time, createdBy *uuid.UUID) ([]models.LeaveAccrual, error) {
+	asOf = truncateDate(asOf)
+	leaveTypes, err := Query.GetAccrualLeaveTypesTx(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch accruing leave types: %v", err)
+	}
+
+	accruals := []models.LeaveAccrual{}
+	for _, lt := range leaveTypes {
+		periods := AccrualPeriods(lt.AccrualFrequency, year)
+
+		for _, period := range periods {
+			if !period.End.Before(asOf) {
+				break
+			}
+			if lt.AccrualFrequency == models.AccrualPayPeriod {
+				finalized, err := Query.IsPayrollFinalizedTx(tx, int(period.Start.Month()), year)
+				if err != nil {
+					return nil, fmt.Errorf("failed to check payroll run: %v", err)
+				}
+				if !finalized {
+					continue
+				}
+			}
+
+			accrued, err := Query.GetAccruedEmployeeIDsTx(tx, lt.ID, period.Start)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch accrual ledger: %v", err)
+			}
+			employees, err := Query.GetAccrualEmployeesTx(tx, period.Start, period.End)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch employees: %v", err)
+			}
+
+			for _, emp := range employees {
+				if accrued[emp.ID] {
+					continue
+				}
+				// Entitlement tiers are evaluated at the end of the period
+				entitlement, err := Query.GetEntitlementTx(tx, emp.ID, lt.ID, period.End)
+				if err != nil {
+					return nil, fmt.Errorf("failed to fetch entitlement: %v", err)
+				}
+				proration, amount := AccrualAmount(entitlement, len(periods), emp, period)
+				if amount <= 0 {
+					continue
+				}
+				entry := models.LeaveAccrual{
+					EmployeeID:    emp.ID,
+					EmployeeName:  emp.FullName,
+					LeaveTypeID:   lt.ID,
+					LeaveTypeName: lt.Name,
+					Year:          year,
+					Frequency:     lt.AccrualFrequency,
+					PeriodStart:   period.Start,
+					PeriodEnd:     period.End,
+					Entitlement:   entitlement,
+					Proration:     proration,
+					Amount:        amount,
+					CreatedBy:     createdBy,
+				}
+				inserted, err := Query.InsertLeaveAccrual(tx, entry)
+				if err != nil {
+					return nil, fmt.Errorf("failed to record accrual: %v", err)
+				}
+				if !inserted {
+					continue
+				}
+				ref := models.LedgerRef{
+					EntryType: models.LedgerAccrual,
+					Reference: fmt.Sprintf("accrual:%s", period.Start.Format("2006-01-02")),
+					Note:      fmt.Sprintf("%s accrual %s to %s", lt.AccrualFrequency, period.Start.Format("2006-01-02"), period.End.Format("2006-01-02")),
+					CreatedBy: createdBy,
+				}
+				if _, err := ApplyBalanceAdjustment(Query, tx, emp.ID, lt.ID, year, amount, ref); err != nil {
+					return nil, err
+				}
+				accruals = append(accruals, entry)
+			}
+		}
+	}
+	return accruals, nil
+}
+
+// RunScheduledLeaveAccruals runs RunLeaveAccruals for the year of the last completed day
+// in its own transaction (used by the scheduler). The previous year is run too until it is
+// closed at year-end: December's payroll is finalized after 1 January, so its PAY_PERIOD
+// accruals, and the last periods of a missed 1 January run, are only credited later. Only a
+// year the accruals already ran for is picked up, so going live never backfills a whole
+// past year; that is left to an explicit accrual run.
+func RunScheduledLeaveAccruals(Query *repositories.Repository) ([]models.LeaveAccrual, error) {
+	tx, err := Query.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	year := now.AddDate(0, 0, -1).Year()
+	closed, err := Query.YearEndClosedTx(tx, year-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check year-end close: %v", err)
+	}
+	accrued, err := Query.HasLeaveAccrualsTx(tx, year-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check accruals of %d: %v", year-1, err)
+	}
+	years := []int{year}
+	if !closed && accrued {
+		years = []int{year - 1, year}
+	}
+
+	accruals := []models.LeaveAccrual{}
+	for _, y := range years {
+		credited, err := RunLeaveAccruals(Query, tx, y, now, nil)
+		if err != nil {
+			return nil, err
+		}
+		accruals = append(accruals, credited...)
+	}
+	return accruals, tx.Commit()
+}
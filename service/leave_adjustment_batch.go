@@ -0,0 +1,106 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
+)
+
+// batchReference is the ledger reference of the entries posted by a bulk adjustment
+func batchReference(batchID uuid.UUID) string {
+	return "bulk-adjustment:" + batchID.String()
+}
+
+// UnmatchedBulkTargets returns the uploaded employee IDs and emails that matched no active employee
+func UnmatchedBulkTargets(filter models.BulkAdjustmentFilter, targets []models.BulkAdjustmentTarget) []string {
+	ids := make(map[uuid.UUID]bool, len(targets))
+	emails := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		ids[t.EmployeeID] = true
+		emails[strings.ToLower(t.Email)] = true
+	}
+	var unmatched []string
+	for _, id := range filter.EmployeeIDs {
+		if !ids[id] {
+			unmatched = append(unmatched, id.String())
+		}
+	}
+	for _, email := range filter.Emails {
+		if !emails[strings.ToLower(strings.TrimSpace(email))] {
+			unmatched = append(unmatched, email)
+		}
+	}
+	return unmatched
+}
+
+// ApplyBulkAdjustment records a batch and adjusts the balance of every target by the input
+// quantity, each with its own Tbl_Leave_adjustment row tagged with the batch.
+func ApplyBulkAdjustment(Query *repositories.Repository, tx *sqlx.Tx, input models.BulkAdjustmentInput, year int, targets []models.BulkAdjustmentTarget, createdBy uuid.UUID) (uuid.UUID, []models.BulkAdjustmentLine, error) {
+	batchID, err := Query.InsertLeaveAdjustmentBatch(tx, input, year, len(targets), createdBy)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("failed to record adjustment batch: %v", err)
+	}
+
+	lines := make([]models.BulkAdjustmentLine, 0, len(targets))
+	for _, t := range targets {
+		adjustmentID, err := Query.InsertBatchLeaveAdjustment(tx, batchID, t.EmployeeID, input.LeaveTypeID, input.Quantity, input.Reason, createdBy, year, nil)
+		if err != nil {
+			return uuid.Nil, nil, fmt.Errorf("failed to record leave adjustment for %s: %v", t.EmployeeName, err)
+		}
+		ref := models.LedgerRef{AdjustmentID: &adjustmentID, Reference: batchReference(batchID), Note: input.Reason, CreatedBy: &createdBy}
+		balance, err := ApplyBalanceAdjustment(Query, tx, t.EmployeeID, input.LeaveTypeID, year, input.Quantity, ref)
+		if err != nil {
+			return uuid.Nil, nil, fmt.Errorf("%s: %v", t.EmployeeName, err)
+		}
+		lines = append(lines, models.BulkAdjustmentLine{
+			AdjustmentID:    &adjustmentID,
+			EmployeeID:      t.EmployeeID,
+			EmployeeName:    t.EmployeeName,
+			Quantity:        input.Quantity,
+			PreviousClosing: balance.Closing - input.Quantity,
+			NewClosing:      balance.Closing,
+		})
+	}
+	return batchID, lines, nil
+}
+
+// ReverseBulkAdjustment undoes every adjustment of an applied batch with an opposite
+// adjustment and marks the batch reversed
+func ReverseBulkAdjustment(Query *repositories.Repository, tx *sqlx.Tx, batch models.LeaveAdjustmentBatch, reason string, reversedBy uuid.UUID) ([]models.BulkAdjustmentLine, error) {
+	originals, err := Query.GetBatchLeaveAdjustmentsTx(tx, batch.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch batch adjustments: %v", err)
+	}
+
+	note := "Reversal of bulk adjustment: " + reason
+	lines := make([]models.BulkAdjustmentLine, 0, len(originals))
+	for _, o := range originals {
+		quantity := -o.Quantity
+		adjustmentID, err := Query.InsertBatchLeaveAdjustment(tx, batch.ID, o.EmployeeID, batch.LeaveTypeID, quantity, note, reversedBy, batch.Year, o.AdjustmentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record reversal for %s: %v", o.EmployeeName, err)
+		}
+		ref := models.LedgerRef{AdjustmentID: &adjustmentID, Reference: batchReference(batch.ID), Note: note, CreatedBy: &reversedBy}
+		balance, err := ApplyBalanceAdjustment(Query, tx, o.EmployeeID, batch.LeaveTypeID, batch.Year, quantity, ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", o.EmployeeName, err)
+		}
+		lines = append(lines, models.BulkAdjustmentLine{
+			AdjustmentID:    &adjustmentID,
+			EmployeeID:      o.EmployeeID,
+			EmployeeName:    o.EmployeeName,
+			Quantity:        quantity,
+			PreviousClosing: balance.Closing - quantity,
+			NewClosing:      balance.Closing,
+		})
+	}
+
+	if err := Query.MarkLeaveAdjustmentBatchReversed(tx, batch.ID, reversedBy, reason); err != nil {
+		return nil, fmt.Errorf("failed to mark batch reversed: %v", err)
+	}
+	return lines, nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
+)
+
+// CalendarLeavePortion returns the portion of a day a calendar leave covers.
+// Hourly leaves count as hours / hoursPerDay; others follow the per-day timing breakdown.
+func CalendarLeavePortion(l models.CalendarLeave, day time.Time, hoursPerDay float64) float64 {
+	if l.Hours != nil && hoursPerDay > 0 {
+		return math.Min(*l.Hours/hoursPerDay, 1.0)
+	}
+	return LeaveDayPortion(day, l.StartDate, l.EndDate, l.HalfID, l.StartHalfID, l.EndHalfID)
+}
+
+// BuildLeaveCalendar builds the per-day absence view for a date range.
+// employeeCount is the size of the group used for absent_percent.
+func BuildLeaveCalendar(start, end time.Time, employeeCount int, leaves []models.CalendarLeave, holidays []models.Holiday, hoursPerDay float64) []models.CalendarDay {
+	holidayMap := make(map[string]string)
+	for _, h := range holidays {
+		holidayMap[h.Date.Format("2006-01-02")] = h.Name
+	}
+
+	start = truncateDate(start)
+	end = truncateDate(end)
+
+	var days []models.CalendarDay
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		dayStr := d.Format("2006-01-02")
+		day := models.CalendarDay{
+			Date:      dayStr,
+			Weekday:   d.Weekday().String(),
+			IsWeekend: d.Weekday() == time.Saturday || d.Weekday() == time.Sunday,
+			Absences:  []models.CalendarAbsence{},
+		}
+		if name, ok := holidayMap[dayStr]; ok {
+			holidayName := name
+			day.Holiday = &holidayName
+		}
+
+		// Leaves don't consume weekends or holidays, so nobody is "absent" on them
+		if !day.IsWeekend && day.Holiday == nil {
+			absent := make(map[uuid.UUID]bool)
+			for _, l := range leaves {
+				if d.Before(truncateDate(l.StartDate)) || d.After(truncateDate(l.EndDate)) {
+					continue
+				}
+				day.Absences = append(day.Absences, models.CalendarAbsence{
+					LeaveID:      l.ID,
+					EmployeeID:   l.EmployeeID,
+					EmployeeName: l.EmployeeName,
+					LeaveType:    l.LeaveType,
+					Status:       l.Status,
+					Portion:      CalendarLeavePortion(l, d, hoursPerDay),
+					StartTime:    l.StartTime,
+					EndTime:      l.EndTime,
+				})
+				absent[l.EmployeeID] = true
+			}
+			day.AbsentCount = len(absent)
+			if employeeCount > 0 {
+				day.AbsentPercent = math.Round(float64(day.AbsentCount)/float64(employeeCount)*10000) / 100
+			}
+		}
+
+		days = append(days, day)
+	}
+	return days
+}
+
+// CheckTeamCoverage checks whether the employee's leave would take the share of absent
+// team members (employees with the same manager) above the company coverage threshold on
+// any working day. Returns a TEAM_COVERAGE violation, blocking when the company is configured to block.
+func CheckTeamCoverage(Query *repositories.Repository, tx *sqlx.Tx, employeeID uuid.UUID, start, end time.Time) ([]models.PolicyViolation, error) {
+	rule, err := Query.GetCoverageRuleTx(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch coverage settings: %v", err)
+	}
+	if rule.ThresholdPercent <= 0 {
+		return nil, nil
+	}
+
+	team, err := Query.GetTeamMemberIDsTx(tx, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch team members: %v", err)
+	}
+	if len(team) <= 1 {
+		return nil, nil
+	}
+
+	var others []uuid.UUID
+	for _, id := range team {
+		if id != employeeID {
+			others = append(others, id)
+		}
+	}
+
+	start = truncateDate(start)
+	end = truncateDate(end)
+
+	leaves, err := Query.GetCalendarLeaves(tx, others, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch team leaves: %v", err)
+	}
+	holidays, err := Query.GetByFilterHolidayBetwweenTwoDates(tx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch holidays: %v", err)
+	}
+	holidayMap := make(map[string]bool)
+	for _, h := range holidays {
+		holidayMap[h.Format("2006-01-02")] = true
+	}
+
+	var conflictDays []string
+	worst := 0.0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday || holidayMap[d.Format("2006-01-02")] {
+			continue
+		}
+		absent := map[uuid.UUID]bool{employeeID: true}
+		for _, l := range leaves {
+			if !d.Before(truncateDate(l.StartDate)) && !d.After(truncateDate(l.EndDate)) {
+				absent[l.EmployeeID] = true
+			}
+		}
+		pct := float64(len(absent)) / float64(len(team)) * 100
+		if pct > rule.ThresholdPercent {
+			conflictDays = append(conflictDays, d.Format("2006-01-02"))
+			worst = math.Max(worst, pct)
+		}
+	}
+	if len(conflictDays) == 0 {
+		return nil, nil
+	}
+
+	return []models.PolicyViolation{{
+		Code: models.ViolationTeamCoverage,
+		Message: fmt.Sprintf("More than %.0f%% of your team would be absent on %s (up to %.0f%%)",
+			rule.ThresholdPercent, strings.Join(conflictDays, ", "), worst),
+		Blocking: rule.Block,
+	}}, nil
+}
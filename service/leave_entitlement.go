@@ -0,0 +1,43 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// NormalizeEmploymentType upper-cases and validates an employment type
+func NormalizeEmploymentType(employmentType string) (string, error) {
+	t := strings.ToUpper(strings.TrimSpace(employmentType))
+	switch t {
+	case models.EmploymentFullTime, models.EmploymentPartTime, models.EmploymentContract, models.EmploymentIntern:
+		return t, nil
+	}
+	return "", fmt.Errorf("invalid employment type %q. Must be FULL_TIME, PART_TIME, CONTRACT or INTERN", employmentType)
+}
+
+// NormalizeEntitlementRules validates the entitlement tiers of a leave policy request and
+// normalizes their employment types
+func NormalizeEntitlementRules(rules []models.LeaveEntitlementRuleInput) error {
+	for i := range rules {
+		rule := &rules[i]
+		if rule.MinTenureMonths < 0 {
+			return fmt.Errorf("entitlement rule %d: min_tenure_months cannot be negative", i+1)
+		}
+		if rule.MaxTenureMonths != nil && *rule.MaxTenureMonths <= rule.MinTenureMonths {
+			return fmt.Errorf("entitlement rule %d: max_tenure_months must be greater than min_tenure_months", i+1)
+		}
+		if rule.Entitlement < 0 {
+			return fmt.Errorf("entitlement rule %d: entitlement cannot be negative", i+1)
+		}
+		if rule.EmploymentType != nil {
+			t, err := NormalizeEmploymentType(*rule.EmploymentType)
+			if err != nil {
+				return fmt.Errorf("entitlement rule %d: %v", i+1, err)
+			}
+			rule.EmploymentType = &t
+		}
+	}
+	return nil
+}
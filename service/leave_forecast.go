@@ -0,0 +1,186 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
+)
+
+// balanceProjector accumulates the expected movements of a balance projection
+type balanceProjector struct {
+	projection *models.BalanceProjection
+	balance    float64
+}
+
+func (p *balanceProjector) add(date time.Time, lineType, description string, quantity float64) {
+	p.balance += quantity
+	p.projection.Lines = append(p.projection.Lines, models.BalanceProjectionLine{
+		Date:        date,
+		Type:        lineType,
+		Description: description,
+		Quantity:    quantity,
+		Balance:     p.balance,
+	})
+}
+
+// projectAccruals adds the accruals of the year credited before until that are not in the
+// accrual ledger yet
+func projectAccruals(Query *repositories.Repository, tx *sqlx.Tx, p *balanceProjector, emp models.AccrualEmployee, lt models.LeaveType, year int, until time.Time) error {
+	if lt.AccrualFrequency == "" || lt.AccrualFrequency == models.AccrualNone {
+		return nil
+	}
+	accrued, err := Query.GetAccruedPeriodsTx(tx, emp.ID, lt.ID, year)
+	if err != nil {
+		return fmt.Errorf("failed to fetch accrual ledger: %v", err)
+	}
+	periods := AccrualPeriods(lt.AccrualFrequency, year)
+	for _, period := range periods {
+		if !period.End.Before(until) {
+			break
+		}
+		if accrued[period.Start] {
+			continue
+		}
+		entitlement, err := Query.GetEntitlementTx(tx, emp.ID, lt.ID, period.End)
+		if err != nil {
+			return fmt.Errorf("failed to fetch entitlement: %v", err)
+		}
+		_, amount := AccrualAmount(entitlement, len(periods), emp, period)
+		if amount <= 0 {
+			continue
+		}
+		p.projection.FutureAccruals += amount
+		p.add(period.End.AddDate(0, 0, 1), models.ProjectionAccrual,
+			fmt.Sprintf("%s accrual %s to %s", lt.AccrualFrequency, period.Start.Format("2006-01-02"), period.End.Format("2006-01-02")), amount)
+	}
+	return nil
+}
+
+// projectPendingLeaves deducts the leaves awaiting approval that start after after and on or
+// before until
+func projectPendingLeaves(p *balanceProjector, leaves []models.ProjectedLeave, after, until time.Time) {
+	for _, l := range leaves {
+		if l.Status == "APPROVED" {
+			continue
+		}
+		start := truncateDate(l.StartDate)
+		if !start.After(after) || start.After(until) {
+			continue
+		}
+		p.projection.PendingLeaves += l.Quantity
+		p.add(start, models.ProjectionPendingLeave,
+			fmt.Sprintf("Pending leave %s to %s", l.StartDate.Format("2006-01-02"), l.EndDate.Format("2006-01-02")), -l.Quantity)
+	}
+}
+
+// ProjectLeaveBalance computes the balance an employee is expected to have on the target date
+// (today at the earliest, and no later than the end of next year): the current balance, plus
+// the accruals still to be credited, minus leaves awaiting approval that start by then.
+// A target in next year also applies the year-end carry-forward rules to the projected
+// closing balance and adds next year's entitlement. Approved leaves are already deducted
+// from the current balance and are only listed.
+func ProjectLeaveBalance(Query *repositories.Repository, tx *sqlx.Tx, employeeID uuid.UUID, lt models.LeaveType, target time.Time) (models.BalanceProjection, error) {
+	today := truncateDate(time.Now())
+	target = truncateDate(target)
+	if target.Before(today) {
+		target = today
+	}
+	year := today.Year()
+	if target.Year() > year+1 {
+		return models.BalanceProjection{}, fmt.Errorf("target date must be no later than the end of %d", year+1)
+	}
+
+	projection := models.BalanceProjection{
+		EmployeeID:     employeeID,
+		LeaveTypeID:    lt.ID,
+		LeaveType:      lt.Name,
+		Unit:           lt.Unit,
+		TargetDate:     target,
+		Lines:          []models.BalanceProjectionLine{},
+		ApprovedLeaves: []models.ProjectedLeave{},
+	}
+	p := &balanceProjector{projection: &projection}
+
+	current, err := Query.GetLeaveBalance(tx, employeeID, lt.ID, year)
+	if err == sql.ErrNoRows {
+		current, err = Query.GetOpeningEntitlementTx(tx, employeeID, lt.ID, year)
+	}
+	if err != nil {
+		return projection, fmt.Errorf("failed to fetch leave balance: %v", err)
+	}
+	projection.CurrentBalance = current
+	p.add(today, models.ProjectionCurrent, "Current balance", current)
+
+	emp, err := Query.GetAccrualEmployeeTx(tx, employeeID)
+	if err != nil {
+		return projection, fmt.Errorf("failed to fetch employee: %v", err)
+	}
+	leaves, err := Query.GetOpenLeavesTx(tx, employeeID, lt.ID, today)
+	if err != nil {
+		return projection, fmt.Errorf("failed to fetch leaves: %v", err)
+	}
+	for _, l := range leaves {
+		if l.Status == "APPROVED" {
+			projection.ApprovedLeaves = append(projection.ApprovedLeaves, l)
+		}
+	}
+
+	yearEnd := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	if target.Year() == year {
+		if err := projectAccruals(Query, tx, p, emp, lt, year, target); err != nil {
+			return projection, err
+		}
+		projectPendingLeaves(p, leaves, time.Time{}, target)
+	} else {
+		// The rest of this year, then the year-end close
+		if err := projectAccruals(Query, tx, p, emp, lt, year, yearEnd.AddDate(0, 0, 1)); err != nil {
+			return projection, err
+		}
+		projectPendingLeaves(p, leaves, time.Time{}, yearEnd)
+
+		// Year-end close, posted the way FinalizeYearEnd posts it to the ledger
+		nextYear := yearEnd.AddDate(0, 0, 1)
+		closing := p.balance
+		carried, encashed, lapsed := SplitYearEndBalance(lt, closing)
+		projection.CarryForward = carried
+		projection.Lapsed = encashed + lapsed
+		if encashed+lapsed > 0 {
+			p.add(yearEnd, models.ProjectionLapse, fmt.Sprintf("Year-end close: %v encashed, %v lapsed", encashed, lapsed), -(encashed + lapsed))
+		}
+		if closing < 0 {
+			p.add(yearEnd, models.ProjectionLapse, "Year-end close: negative balance not carried forward", -closing)
+		}
+		if carried > 0 {
+			p.add(yearEnd, models.ProjectionCarryForward, fmt.Sprintf("Carried forward to %d", nextYear.Year()), -carried)
+			p.add(nextYear, models.ProjectionCarryForward, fmt.Sprintf("Carried forward from %d", year), carried)
+		}
+
+		entitlement, err := Query.GetOpeningEntitlementTx(tx, employeeID, lt.ID, nextYear.Year())
+		if err != nil {
+			return projection, fmt.Errorf("failed to fetch entitlement: %v", err)
+		}
+		projection.NewEntitlement = entitlement
+		if entitlement != 0 {
+			p.add(nextYear, models.ProjectionEntitlement, fmt.Sprintf("%d entitlement", nextYear.Year()), entitlement)
+		}
+
+		if err := projectAccruals(Query, tx, p, emp, lt, nextYear.Year(), target); err != nil {
+			return projection, err
+		}
+		projectPendingLeaves(p, leaves, yearEnd, target)
+	}
+
+	projection.ProjectedBalance = p.balance
+	return projection, nil
+}
+
+// StartsAfterToday reports whether a leave starting on start is validated against its
+// projected balance rather than the current one
+func StartsAfterToday(start time.Time) bool {
+	return truncateDate(start).After(truncateDate(time.Now()))
+}
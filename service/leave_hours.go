@@ -0,0 +1,121 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
+)
+
+// ParseClockTime parses an "HH:MM" (or "HH:MM:SS") clock time into a duration since midnight
+func ParseClockTime(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid time %q. Expected HH:MM", value)
+}
+
+// CalculateLeaveHours validates an hourly leave window against the company working
+// hours and returns the number of hours requested (rounded to 2 decimals)
+func CalculateLeaveHours(wh repositories.WorkingHours, startTime, endTime string) (float64, error) {
+	start, err := ParseClockTime(startTime)
+	if err != nil {
+		return 0, err
+	}
+	end, err := ParseClockTime(endTime)
+	if err != nil {
+		return 0, err
+	}
+	workStart, err := ParseClockTime(wh.Start)
+	if err != nil {
+		return 0, fmt.Errorf("invalid company work start time: %v", err)
+	}
+	workEnd, err := ParseClockTime(wh.End)
+	if err != nil {
+		return 0, fmt.Errorf("invalid company work end time: %v", err)
+	}
+
+	if end <= start {
+		return 0, fmt.Errorf("end time must be after start time")
+	}
+	if start < workStart || end > workEnd {
+		return 0, fmt.Errorf("leave time must be within working hours (%s-%s)", wh.Start, wh.End)
+	}
+
+	hours := math.Round((end-start).Hours()*100) / 100
+	if wh.HoursPerDay > 0 && hours > wh.HoursPerDay {
+		hours = wh.HoursPerDay
+	}
+	return hours, nil
+}
+
+// CalculateLeaveQuantity works out how much a leave request consumes.
+// DAY leave types use working days with half-day timing, including first/last day
+// timing of multi-day leaves (see CalculateWorkingDaysWithDayTiming).
+// HOUR leave types must be a single working day with start/end times inside
+// company working hours; the balance is consumed in hours and Days is hours / working_hours_per_day.
+func CalculateLeaveQuantity(Query *repositories.Repository, tx *sqlx.Tx, leaveType models.LeaveType, start, end time.Time, timingID int, startHalfID, endHalfID *int, startTime, endTime *string) (models.LeaveQuantity, error) {
+	if leaveType.Unit != models.LeaveUnitHour {
+		startHalfID, endHalfID, err := NormalizeDayTiming(start, end, timingID, startHalfID, endHalfID)
+		if err != nil {
+			return models.LeaveQuantity{}, err
+		}
+		days, err := CalculateWorkingDaysWithDayTiming(Query, tx, start, end, timingID, startHalfID, endHalfID)
+		if err != nil {
+			return models.LeaveQuantity{}, err
+		}
+		return models.LeaveQuantity{Days: days, StartHalfID: startHalfID, EndHalfID: endHalfID, Balance: days}, nil
+	}
+
+	if startHalfID != nil || endHalfID != nil {
+		return models.LeaveQuantity{}, fmt.Errorf("start_day_timing_id and end_day_timing_id are not supported for hourly leave")
+	}
+
+	if startTime == nil || endTime == nil || *startTime == "" || *endTime == "" {
+		return models.LeaveQuantity{}, fmt.Errorf("start_time and end_time are required for hourly leave")
+	}
+	if !sameDate(start, end) {
+		return models.LeaveQuantity{}, fmt.Errorf("hourly leave must start and end on the same date")
+	}
+
+	workingDays, err := CalculateWorkingDays(Query, tx, start, end)
+	if err != nil {
+		return models.LeaveQuantity{}, err
+	}
+	if workingDays == 0 {
+		return models.LeaveQuantity{}, fmt.Errorf("hourly leave must be on a working day")
+	}
+
+	wh, err := Query.GetWorkingHoursTx(tx)
+	if err != nil {
+		return models.LeaveQuantity{}, fmt.Errorf("failed to fetch company working hours: %v", err)
+	}
+	if wh.HoursPerDay <= 0 {
+		return models.LeaveQuantity{}, fmt.Errorf("company working hours per day is not configured")
+	}
+
+	hours, err := CalculateLeaveHours(wh, *startTime, *endTime)
+	if err != nil {
+		return models.LeaveQuantity{}, err
+	}
+
+	st, et := strings.TrimSpace(*startTime), strings.TrimSpace(*endTime)
+	return models.LeaveQuantity{
+		Days:      math.Round(hours/wh.HoursPerDay*100) / 100,
+		Hours:     &hours,
+		StartTime: &st,
+		EndTime:   &et,
+		Balance:   hours,
+	}, nil
+}
+
+func sameDate(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day()
+}
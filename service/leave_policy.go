@@ -0,0 +1,294 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
+)
+
+// LeavePolicyRequest is everything the policy engine needs to evaluate a leave request
+type LeavePolicyRequest struct {
+	LeaveType     models.LeaveType
+	Profile       models.EmployeePolicyProfile
+	StartDate     time.Time
+	EndDate       time.Time
+	Days          float64 // working days requested
+	Required      float64 // amount consumed from the balance (leave type unit)
+	Balance       float64 // current closing balance
+	Blackouts     []models.LeaveBlackout
+	HasAttachment bool
+	Today         time.Time
+}
+
+// EvaluateLeavePolicy checks a leave request against the rules of its leave type and
+// the company blackout periods, and returns every rule it violates
+func EvaluateLeavePolicy(req LeavePolicyRequest) []models.PolicyViolation {
+	lt := req.LeaveType
+	var violations []models.PolicyViolation
+
+	today := truncateDate(req.Today)
+	start := truncateDate(req.StartDate)
+
+	// Minimum notice
+	if lt.MinNoticeDays > 0 {
+		notice := int(start.Sub(today).Hours() / 24)
+		if notice < lt.MinNoticeDays {
+			violations = append(violations, models.PolicyViolation{
+				Code:     models.ViolationMinNotice,
+				Message:  fmt.Sprintf("%s requires at least %d days notice (given %d)", lt.Name, lt.MinNoticeDays, notice),
+				Blocking: true,
+			})
+		}
+	}
+
+	// Maximum consecutive days
+	if lt.MaxConsecutiveDays != nil && *lt.MaxConsecutiveDays > 0 && req.Days > *lt.MaxConsecutiveDays {
+		violations = append(violations, models.PolicyViolation{
+			Code:     models.ViolationMaxConsecutiveDays,
+			Message:  fmt.Sprintf("%s cannot exceed %.1f consecutive days (requested %.1f)", lt.Name, *lt.MaxConsecutiveDays, req.Days),
+			Blocking: true,
+		})
+	}
+
+	// Allowed genders
+	if genders := SplitGenders(lt.AllowedGenders); len(genders) > 0 {
+		allowed := false
+		if req.Profile.Gender != nil {
+			for _, g := range genders {
+				if g == *req.Profile.Gender {
+					allowed = true
+					break
+				}
+			}
+		}
+		if !allowed {
+			violations = append(violations, models.PolicyViolation{
+				Code:     models.ViolationGenderNotAllowed,
+				Message:  fmt.Sprintf("%s is only available to: %s", lt.Name, strings.Join(genders, ", ")),
+				Blocking: true,
+			})
+		}
+	}
+
+	// Minimum tenure
+	if lt.MinTenureMonths > 0 {
+		if req.Profile.JoiningDate == nil {
+			violations = append(violations, models.PolicyViolation{
+				Code:     models.ViolationMinTenure,
+				Message:  fmt.Sprintf("%s requires %d months of service but your joining date is not recorded", lt.Name, lt.MinTenureMonths),
+				Blocking: true,
+			})
+		} else if eligibleFrom := truncateDate(req.Profile.JoiningDate.AddDate(0, lt.MinTenureMonths, 0)); start.Before(eligibleFrom) {
+			violations = append(violations, models.PolicyViolation{
+				Code:     models.ViolationMinTenure,
+				Message:  fmt.Sprintf("%s is available after %d months of service (from %s)", lt.Name, lt.MinTenureMonths, eligibleFrom.Format("2006-01-02")),
+				Blocking: true,
+			})
+		}
+	}
+
+	// Blackout periods
+	for _, b := range req.Blackouts {
+		violations = append(violations, models.PolicyViolation{
+			Code:     models.ViolationBlackoutPeriod,
+			Message:  fmt.Sprintf("Leave is not allowed during %s (%s to %s)", b.Name, b.StartDate.Format("2006-01-02"), b.EndDate.Format("2006-01-02")),
+			Blocking: true,
+		})
+	}
+
+	// Balance (with negative allowance)
+	if !BalanceAllows(lt, req.Balance, req.Required) {
+		msg := fmt.Sprintf("Insufficient leave balance. Available: %.1f, Required: %.1f", req.Balance, req.Required)
+		if lt.AllowNegativeBalance {
+			msg += fmt.Sprintf(" (negative balance allowed up to %.1f)", lt.MaxNegativeBalance)
+		}
+		violations = append(violations, models.PolicyViolation{
+			Code:     models.ViolationInsufficientBalance,
+			Message:  msg,
+			Blocking: true,
+		})
+	}
+
+	// Attachment: reported at apply time, the document can be provided before approval
+	if AttachmentRequired(lt, req.Days) && !req.HasAttachment {
+		violations = append(violations, models.PolicyViolation{
+			Code:     models.ViolationAttachmentRequired,
+			Message:  fmt.Sprintf("%s over %.1f days requires a supporting document", lt.Name, *lt.AttachmentRequiredAfterDays),
+			Blocking: false,
+		})
+	}
+
+	return violations
+}
+
+// CheckLeavePolicy loads the employee profile and blackout periods for a leave request
+// and evaluates it against the leave type's policy
+func CheckLeavePolicy(Query *repositories.Repository, tx *sqlx.Tx, employeeID uuid.UUID, leaveType models.LeaveType, start, end time.Time, qty models.LeaveQuantity, balance float64, hasAttachment bool) ([]models.PolicyViolation, error) {
+	profile, err := Query.GetEmployeePolicyProfile(tx, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch employee profile: %v", err)
+	}
+	blackouts, err := Query.GetBlackoutsForRange(tx, start, end, leaveType.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch blackout periods: %v", err)
+	}
+	return EvaluateLeavePolicy(LeavePolicyRequest{
+		LeaveType:     leaveType,
+		Profile:       profile,
+		StartDate:     start,
+		EndDate:       end,
+		Days:          qty.Days,
+		Required:      qty.Balance,
+		Balance:       balance,
+		Blackouts:     blackouts,
+		HasAttachment: hasAttachment,
+		Today:         time.Now(),
+	}), nil
+}
+
+// AttachmentRequired reports whether a leave of the given length needs a supporting document
+func AttachmentRequired(lt models.LeaveType, days float64) bool {
+	return lt.AttachmentRequiredAfterDays != nil && *lt.AttachmentRequiredAfterDays > 0 &&
+		days > *lt.AttachmentRequiredAfterDays
+}
+
+// HasBlockingViolation reports whether any violation rejects the request
+func HasBlockingViolation(violations []models.PolicyViolation) bool {
+	for _, v := range violations {
+		if v.Blocking {
+			return true
+		}
+	}
+	return false
+}
+
+// BalanceAllows reports whether consuming required from balance is allowed,
+// taking the leave type's negative balance allowance into account
+func BalanceAllows(lt models.LeaveType, balance, required float64) bool {
+	floor := 0.0
+	if lt.AllowNegativeBalance {
+		floor = -lt.MaxNegativeBalance
+	}
+	return balance-required >= floor
+}
+
+// NormalizeGender upper-cases and validates a gender value
+func NormalizeGender(gender string) (string, error) {
+	g := strings.ToUpper(strings.TrimSpace(gender))
+	switch g {
+	case models.GenderMale, models.GenderFemale, models.GenderOther:
+		return g, nil
+	}
+	return "", fmt.Errorf("invalid gender %q. Must be MALE, FEMALE or OTHER", gender)
+}
+
+// SplitGenders parses the comma separated allowed_genders column
+func SplitGenders(allowed *string) []string {
+	if allowed == nil {
+		return nil
+	}
+	var genders []string
+	for _, g := range strings.Split(*allowed, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			genders = append(genders, g)
+		}
+	}
+	return genders
+}
+
+// ApplyLeaveTypePolicyInput validates the policy fields of a leave type input and
+// merges them into lt. Fields that are not provided keep their current value.
+func ApplyLeaveTypePolicyInput(lt *models.LeaveType, input models.LeaveTypeInput) error {
+	if input.MinNoticeDays != nil {
+		if *input.MinNoticeDays < 0 {
+			return fmt.Errorf("min_notice_days cannot be negative")
+		}
+		lt.MinNoticeDays = *input.MinNoticeDays
+	}
+	if input.MaxConsecutiveDays != nil {
+		if *input.MaxConsecutiveDays < 0 {
+			return fmt.Errorf("max_consecutive_days cannot be negative")
+		}
+		lt.MaxConsecutiveDays = nil
+		if *input.MaxConsecutiveDays > 0 {
+			lt.MaxConsecutiveDays = input.MaxConsecutiveDays
+		}
+	}
+	if input.AllowedGenders != nil {
+		lt.AllowedGenders = nil
+		var genders []string
+		for _, g := range input.AllowedGenders {
+			gender, err := NormalizeGender(g)
+			if err != nil {
+				return err
+			}
+			genders = append(genders, gender)
+		}
+		if len(genders) > 0 {
+			joined := strings.Join(genders, ",")
+			lt.AllowedGenders = &joined
+		}
+	}
+	if input.MinTenureMonths != nil {
+		if *input.MinTenureMonths < 0 {
+			return fmt.Errorf("min_tenure_months cannot be negative")
+		}
+		lt.MinTenureMonths = *input.MinTenureMonths
+	}
+	if input.AttachmentRequiredAfterDays != nil {
+		if *input.AttachmentRequiredAfterDays < 0 {
+			return fmt.Errorf("attachment_required_after_days cannot be negative")
+		}
+		lt.AttachmentRequiredAfterDays = nil
+		if *input.AttachmentRequiredAfterDays > 0 {
+			lt.AttachmentRequiredAfterDays = input.AttachmentRequiredAfterDays
+		}
+	}
+	if input.AllowNegativeBalance != nil {
+		lt.AllowNegativeBalance = *input.AllowNegativeBalance
+	}
+	if input.MaxNegativeBalance != nil {
+		if *input.MaxNegativeBalance < 0 {
+			return fmt.Errorf("max_negative_balance cannot be negative")
+		}
+		lt.MaxNegativeBalance = *input.MaxNegativeBalance
+	}
+	if input.AccrualFrequency != nil {
+		frequency := strings.ToUpper(strings.TrimSpace(*input.AccrualFrequency))
+		if _, ok := models.AccrualPeriodsPerYear[frequency]; !ok && frequency != models.AccrualNone {
+			return fmt.Errorf("accrual_frequency must be NONE, MONTHLY, QUARTERLY or PAY_PERIOD")
+		}
+		lt.AccrualFrequency = frequency
+	}
+	if input.CarryForwardMax != nil {
+		if *input.CarryForwardMax < 0 {
+			return fmt.Errorf("carry_forward_max cannot be negative")
+		}
+		lt.CarryForwardMax = *input.CarryForwardMax
+	}
+	if input.Encashable != nil {
+		lt.Encashable = *input.Encashable
+	}
+	if input.EncashMax != nil {
+		if *input.EncashMax < 0 {
+			return fmt.Errorf("encash_max cannot be negative")
+		}
+		lt.EncashMax = nil
+		if *input.EncashMax > 0 {
+			lt.EncashMax = input.EncashMax
+		}
+	}
+	if lt.AccrualFrequency == "" {
+		lt.AccrualFrequency = models.AccrualNone
+	}
+	return nil
+}
+
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
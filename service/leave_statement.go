@@ -0,0 +1,101 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// statementDescription describes a ledger entry on a statement
+func statementDescription(e models.LeaveStatementEntry) string {
+	note := ""
+	if e.Note != nil {
+		note = *e.Note
+	}
+	switch e.EntryType {
+	case models.LedgerConsumption, models.LedgerRestoration:
+		label := "Leave taken"
+		if e.EntryType == models.LedgerRestoration {
+			label = "Leave restored"
+		}
+		if e.LeaveStart != nil && e.LeaveEnd != nil {
+			return fmt.Sprintf("%s %s to %s", label, e.LeaveStart.Format("2006-01-02"), e.LeaveEnd.Format("2006-01-02"))
+		}
+		if note != "" {
+			return label + ": " + note
+		}
+		return label
+	case models.LedgerAdjustment:
+		if e.AdjustmentReason != nil && *e.AdjustmentReason != "" {
+			return "Adjustment: " + *e.AdjustmentReason
+		}
+		if note != "" {
+			return "Adjustment: " + note
+		}
+		return "Adjustment"
+	}
+	if note != "" {
+		return note
+	}
+	switch e.EntryType {
+	case models.LedgerGrant:
+		return "Entitlement granted"
+	case models.LedgerAccrual:
+		return "Accrual"
+	case models.LedgerCarryForward:
+		return "Carry forward"
+	case models.LedgerLapse:
+		return "Lapsed"
+	case models.LedgerEncashment:
+		return "Encashed"
+	}
+	return e.EntryType
+}
+
+// BuildLeaveStatement groups ledger entries per leave type with a running balance. Leave types
+// are those with a stored balance or ledger entries in the year.
+func BuildLeaveStatement(balances []models.LeaveStatementBalance, entries []models.LeaveStatementEntry) []models.LeaveStatementSection {
+	sections := []models.LeaveStatementSection{}
+	index := make(map[int]int)
+	for _, b := range balances {
+		index[b.LeaveTypeID] = len(sections)
+		sections = append(sections, models.LeaveStatementSection{
+			LeaveTypeID: b.LeaveTypeID,
+			LeaveType:   b.LeaveTypeName,
+			Unit:        b.Unit,
+			Opening:     b.Opening,
+			Accrued:     b.Accrued,
+			Used:        b.Used,
+			Adjusted:    b.Adjusted,
+			Closing:     b.Closing,
+			Lines:       []models.LeaveStatementLine{},
+		})
+	}
+
+	running := make(map[int]float64)
+	for _, e := range entries {
+		i, ok := index[e.LeaveTypeID]
+		if !ok {
+			i = len(sections)
+			index[e.LeaveTypeID] = i
+			sections = append(sections, models.LeaveStatementSection{
+				LeaveTypeID: e.LeaveTypeID,
+				LeaveType:   e.LeaveTypeName,
+				Unit:        e.Unit,
+				Lines:       []models.LeaveStatementLine{},
+			})
+		}
+		running[e.LeaveTypeID] += e.Quantity
+		sections[i].Lines = append(sections[i].Lines, models.LeaveStatementLine{
+			Date:        e.CreatedAt,
+			EntryType:   e.EntryType,
+			Description: statementDescription(e),
+			Quantity:    e.Quantity,
+			Balance:     running[e.LeaveTypeID],
+			LeaveID:     e.LeaveID,
+			StartDate:   e.LeaveStart,
+			EndDate:     e.LeaveEnd,
+		})
+	}
+	return sections
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
+)
+
+// TimingPortion returns the fraction of a day covered by a timing ID
+// (1 First Half / 2 Second Half = 0.5, 3 Full Day = 1.0)
+func TimingPortion(timingID int) float64 {
+	if timingID == 1 || timingID == 2 {
+		return 0.5
+	}
+	return 1.0
+}
+
+// LeaveDayPortion returns the portion of a single day taken by a leave.
+// The first and last day use startHalfID/endHalfID when set, every other day uses timingID.
+func LeaveDayPortion(day, start, end time.Time, timingID int, startHalfID, endHalfID *int) float64 {
+	if startHalfID != nil && sameDate(day, start) {
+		return TimingPortion(*startHalfID)
+	}
+	if endHalfID != nil && sameDate(day, end) {
+		return TimingPortion(*endHalfID)
+	}
+	return TimingPortion(timingID)
+}
+
+// NormalizeDayTiming validates first/last day timings of a leave request and returns
+// the values to store. They are only allowed on multi-day full-day leaves: the first
+// day may be 2 (Second Half) or 3 (Full Day), the last day 1 (First Half) or 3 (Full Day).
+// Full-day values are dropped since they match the default behaviour.
+func NormalizeDayTiming(start, end time.Time, timingID int, startHalfID, endHalfID *int) (*int, *int, error) {
+	if startHalfID == nil && endHalfID == nil {
+		return nil, nil, nil
+	}
+	if sameDate(start, end) {
+		return nil, nil, fmt.Errorf("start_day_timing_id and end_day_timing_id are only for multi-day leaves. Use leave_timing_id for a single day")
+	}
+	if timingID != 3 {
+		return nil, nil, fmt.Errorf("leave_timing_id must be 3 (Full Day) when start_day_timing_id or end_day_timing_id is set")
+	}
+	if startHalfID != nil {
+		if *startHalfID != 2 && *startHalfID != 3 {
+			return nil, nil, fmt.Errorf("invalid start_day_timing_id. Must be 2 (Second Half) or 3 (Full Day)")
+		}
+		if *startHalfID == 3 {
+			startHalfID = nil
+		}
+	}
+	if endHalfID != nil {
+		if *endHalfID != 1 && *endHalfID != 3 {
+			return nil, nil, fmt.Errorf("invalid end_day_timing_id. Must be 1 (First Half) or 3 (Full Day)")
+		}
+		if *endHalfID == 3 {
+			endHalfID = nil
+		}
+	}
+	return startHalfID, endHalfID, nil
+}
+
+// CalculateWorkingDaysWithDayTiming sums the per-day portions of a leave over working days
+// (weekends and holidays skipped), honouring first/last day timings of multi-day leaves
+func CalculateWorkingDaysWithDayTiming(Query *repositories.Repository, tx *sqlx.Tx, start, end time.Time, timingID int, startHalfID, endHalfID *int) (float64, error) {
+	if startHalfID == nil && endHalfID == nil {
+		return CalculateWorkingDaysWithTiming(Query, tx, start, end, timingID)
+	}
+	if end.Before(start) {
+		return 0, fmt.Errorf("end date cannot be before start date")
+	}
+
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+
+	holidays, err := Query.GetByFilterHolidayBetwweenTwoDates(tx, start, end)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch holidays: %v", err)
+	}
+	holidayMap := make(map[string]bool)
+	for _, h := range holidays {
+		holidayMap[h.Format("2006-01-02")] = true
+	}
+
+	days := 0.0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday || holidayMap[d.Format("2006-01-02")] {
+			continue
+		}
+		days += LeaveDayPortion(d, start, end, timingID, startHalfID, endHalfID)
+	}
+	return days, nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// DiffPayrollDraft compares the draft payslips of a payroll run with what they would be
+// computed from now. Only the payroll inputs are compared (eligible employees, working days
+// and their per-day rate basis, leave days, approved leave records, leave encashment, joining or exit proration and pending
+// corrections), amounts follow from them.
+func DiffPayrollDraft(drafts, current []models.PayslipRecord) []models.PayrollDraftChange {
+	changes := []models.PayrollDraftChange{}
+	now := make(map[uuid.UUID]models.PayslipRecord, len(current))
+	corrections := map[uuid.UUID]bool{}
+	for _, p := range current {
+		if p.CorrectionID != nil {
+			corrections[*p.CorrectionID] = true
+			continue
+		}
+		now[p.EmployeeID] = p
+	}
+	drafted := make(map[uuid.UUID]bool, len(drafts))
+
+	for _, d := range drafts {
+		change := func(kind string, previewed, current interface{}) {
+			changes = append(changes, models.PayrollDraftChange{
+				EmployeeID: d.EmployeeID, Employee: d.Employee, Change: kind, Previewed: previewed, Current: current,
+			})
+		}
+		if d.CorrectionID != nil {
+			drafted[*d.CorrectionID] = true
+			if !corrections[*d.CorrectionID] {
+				change(models.PayrollChangeCorrectionRemoved, nil, nil)
+			}
+			continue
+		}
+		drafted[d.EmployeeID] = true
+		c, ok := now[d.EmployeeID]
+		if !ok {
+			change(models.PayrollChangeEmployeeRemoved, nil, nil)
+			continue
+		}
+		if d.WorkingDays != c.WorkingDays {
+			change(models.PayrollChangeWorkingDays, d.WorkingDays, c.WorkingDays)
+		}
+		if d.DayRateBasis != c.DayRateBasis {
+			change(models.PayrollChangeDayRateBasis, d.DayRateBasis, c.DayRateBasis)
+		}
+		daysChanged := false
+		if amountChanged(d.PaidLeaves, c.PaidLeaves) {
+			change(models.PayrollChangePaidLeaves, d.PaidLeaves, c.PaidLeaves)
+			daysChanged = true
+		}
+		if amountChanged(d.UnpaidLeaves, c.UnpaidLeaves) {
+			change(models.PayrollChangeUnpaidLeaves, d.UnpaidLeaves, c.UnpaidLeaves)
+			daysChanged = true
+		}
+		if !daysChanged && d.LeaveFingerprint != c.LeaveFingerprint {
+			change(models.PayrollChangeLeaveRecords, nil, nil)
+		}
+		if amountChanged(d.Encashment, c.Encashment) {
+			change(models.PayrollChangeEncashment, d.Encashment, c.Encashment)
+		}
+		if prorationChanged(d.Proration(), c.Proration()) {
+			change(models.PayrollChangeProration, d.Proration(), c.Proration())
+		}
+	}
+
+	for _, c := range current {
+		key, kind := c.EmployeeID, models.PayrollChangeEmployeeAdded
+		if c.CorrectionID != nil {
+			key, kind = *c.CorrectionID, models.PayrollChangeCorrectionAdded
+		}
+		if !drafted[key] {
+			changes = append(changes, models.PayrollDraftChange{
+				EmployeeID: c.EmployeeID, Employee: c.Employee, Change: kind,
+			})
+		}
+	}
+	return changes
+}
+
+// prorationChanged compares the prorations of two payslips, nil for a whole month
+func prorationChanged(a, b *models.SalaryProration) bool {
+	if a == nil || b == nil {
+		return a != b
+	}
+	return a.Basis != b.Basis || amountChanged(a.PayableDays, b.PayableDays) || amountChanged(a.PeriodDays, b.PeriodDays)
+}
+
+// amountChanged compares two amounts or day counts to the paisa
+func amountChanged(a, b float64) bool {
+	return math.Abs(a-b) >= 0.005
+}
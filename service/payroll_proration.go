@@ -0,0 +1,77 @@
+package service
+
+import (
+	"time"
+
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// payrollDayCounter counts calendar days, or on the WORKING_DAYS basis the weekdays that are
+// not holidays
+type payrollDayCounter map[string]bool
+
+func newPayrollDayCounter(holidays []models.Holiday) payrollDayCounter {
+	holidaySet := make(payrollDayCounter, len(holidays))
+	for _, h := range holidays {
+		holidaySet[h.Date.Format("2006-01-02")] = true
+	}
+	return holidaySet
+}
+
+func (holidaySet payrollDayCounter) count(basis string, from, to time.Time) float64 {
+	days := 0.0
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if basis == models.ProrationWorkingDays &&
+			(d.Weekday() == time.Saturday || d.Weekday() == time.Sunday || holidaySet[d.Format("2006-01-02")]) {
+			continue
+		}
+		days++
+	}
+	return days
+}
+
+// MonthWorkingDays returns the days a month's salary is divided by for the per-day rate:
+// fixedDays on the FIXED basis, else the month's working or calendar days. holidays are the
+// month's holidays.
+func MonthWorkingDays(basis string, fixedDays, month, year int, holidays []models.Holiday) int {
+	if basis != models.DayRateWorkingDays && basis != models.DayRateCalendarDays {
+		return fixedDays
+	}
+	firstDay := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	days := int(newPayrollDayCounter(holidays).count(basis, firstDay, firstDay.AddDate(0, 1, -1)))
+	if days == 0 {
+		return fixedDays
+	}
+	return days
+}
+
+// EmploymentProration returns the part of a payroll month an employee is paid for when they
+// joined or left during it, nil when they were employed the whole month. On the WORKING_DAYS
+// basis weekends and holidays are not counted.
+func EmploymentProration(basis string, joining time.Time, ending *time.Time, month, year int, holidays []models.Holiday) *models.SalaryProration {
+	firstDay := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstDay.AddDate(0, 1, -1)
+
+	start, end := firstDay, lastDay
+	if j := truncateDate(joining); j.After(start) {
+		start = j
+	}
+	if ending != nil && truncateDate(*ending).Before(end) {
+		end = truncateDate(*ending)
+	}
+	if start.Equal(firstDay) && end.Equal(lastDay) {
+		return nil
+	}
+
+	days := newPayrollDayCounter(holidays)
+	proration := &models.SalaryProration{Basis: basis, PeriodDays: days.count(basis, firstDay, lastDay)}
+	if proration.PeriodDays == 0 {
+		// A month without working days is prorated on calendar days
+		proration.Basis = models.ProrationCalendarDays
+		proration.PeriodDays = days.count(proration.Basis, firstDay, lastDay)
+	}
+	if !end.Before(start) {
+		proration.PayableDays = days.count(proration.Basis, start, end)
+	}
+	return proration
+}
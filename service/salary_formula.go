@@ -0,0 +1,229 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// formulaToken is a number, an identifier or a single-character operator
+type formulaToken struct {
+	kind rune // 'n' number, 'i' identifier, otherwise the operator itself
+	text string
+	num  float64
+}
+
+func (t formulaToken) String() string {
+	switch t.kind {
+	case 'n':
+		return strconv.FormatFloat(t.num, 'f', -1, 64)
+	case 'i':
+		return t.text
+	}
+	return string(t.kind)
+}
+
+func tokenizeFormula(expr string) ([]formulaToken, error) {
+	var tokens []formulaToken
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		ch := runes[i]
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case unicode.IsDigit(ch) || ch == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			num, err := strconv.ParseFloat(string(runes[start:i]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", string(runes[start:i]))
+			}
+			tokens = append(tokens, formulaToken{kind: 'n', num: num})
+		case unicode.IsLetter(ch) || ch == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, formulaToken{kind: 'i', text: strings.ToUpper(string(runes[start:i]))})
+		case strings.ContainsRune("+-*/(),", ch):
+			tokens = append(tokens, formulaToken{kind: ch})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q", ch)
+		}
+	}
+	return tokens, nil
+}
+
+// formulaParser evaluates a tokenized formula by recursive descent
+type formulaParser struct {
+	tokens []formulaToken
+	pos    int
+	lookup func(name string) (float64, error)
+}
+
+func (p *formulaParser) peek() rune {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos].kind
+	}
+	return 0
+}
+
+func (p *formulaParser) expect(kind rune) error {
+	if p.peek() != kind {
+		return fmt.Errorf("expected %q", string(kind))
+	}
+	p.pos++
+	return nil
+}
+
+// expr := term (('+' | '-') term)*
+func (p *formulaParser) expr() (float64, error) {
+	v, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for p.peek() == '+' || p.peek() == '-' {
+		op := p.tokens[p.pos].kind
+		p.pos++
+		rhs, err := p.term()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			v += rhs
+		} else {
+			v -= rhs
+		}
+	}
+	return v, nil
+}
+
+// term := unary (('*' | '/') unary)*
+func (p *formulaParser) term() (float64, error) {
+	v, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	for p.peek() == '*' || p.peek() == '/' {
+		op := p.tokens[p.pos].kind
+		p.pos++
+		rhs, err := p.unary()
+		if err != nil {
+			return 0, err
+		}
+		if op == '*' {
+			v *= rhs
+		} else {
+			if rhs == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			v /= rhs
+		}
+	}
+	return v, nil
+}
+
+// unary := '-' unary | primary
+func (p *formulaParser) unary() (float64, error) {
+	if p.peek() == '-' {
+		p.pos++
+		v, err := p.unary()
+		return -v, err
+	}
+	return p.primary()
+}
+
+// primary := number | name | function '(' expr (',' expr)* ')' | '(' expr ')'
+func (p *formulaParser) primary() (float64, error) {
+	switch p.peek() {
+	case 'n':
+		p.pos++
+		return p.tokens[p.pos-1].num, nil
+	case '(':
+		p.pos++
+		v, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		return v, p.expect(')')
+	case 'i':
+		name := p.tokens[p.pos].text
+		p.pos++
+		if p.peek() != '(' {
+			return p.lookup(name)
+		}
+		p.pos++
+		var args []float64
+		for {
+			v, err := p.expr()
+			if err != nil {
+				return 0, err
+			}
+			args = append(args, v)
+			if p.peek() != ',' {
+				break
+			}
+			p.pos++
+		}
+		if err := p.expect(')'); err != nil {
+			return 0, err
+		}
+		return callFormulaFunction(name, args)
+	case 0:
+		return 0, fmt.Errorf("unexpected end of formula")
+	}
+	return 0, fmt.Errorf("unexpected %q", p.tokens[p.pos].String())
+}
+
+// callFormulaFunction applies MIN, MAX or ROUND (to the nearest whole number, or to the
+// number of decimals given as second argument)
+func callFormulaFunction(name string, args []float64) (float64, error) {
+	switch name {
+	case "MIN", "MAX":
+		v := args[0]
+		for _, a := range args[1:] {
+			if name == "MIN" {
+				v = math.Min(v, a)
+			} else {
+				v = math.Max(v, a)
+			}
+		}
+		return v, nil
+	case "ROUND":
+		if len(args) > 2 {
+			return 0, fmt.Errorf("ROUND takes one or two arguments")
+		}
+		scale := 1.0
+		if len(args) == 2 {
+			scale = math.Pow(10, math.Round(args[1]))
+		}
+		return math.Round(args[0]*scale) / scale, nil
+	}
+	return 0, fmt.Errorf("unknown function %s", name)
+}
+
+// formulaFunctions cannot be used as component codes
+var formulaFunctions = map[string]bool{"MIN": true, "MAX": true, "ROUND": true}
+
+// EvaluateFormula evaluates an arithmetic formula (+ - * / and parentheses, MIN, MAX and ROUND)
+// whose names are resolved with lookup
+func EvaluateFormula(expr string, lookup func(name string) (float64, error)) (float64, error) {
+	tokens, err := tokenizeFormula(expr)
+	if err != nil {
+		return 0, err
+	}
+	p := &formulaParser{tokens: tokens, lookup: lookup}
+	v, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	if p.pos < len(p.tokens) {
+		return 0, fmt.Errorf("unexpected %q", p.tokens[p.pos].String())
+	}
+	return v, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func formulaLookup(vars map[string]float64) func(string) (float64, error) {
+	return func(name string) (float64, error) {
+		if v, ok := vars[name]; ok {
+			return v, nil
+		}
+		return 0, fmt.Errorf("unknown component %s", name)
+	}
+}
+
+func TestEvaluateFormula(t *testing.T) {
+	vars := map[string]float64{"CTC": 100000, "BASIC": 40000, "HRA": 20000, "WORKING_DAYS": 22}
+	tests := []struct {
+		name    string
+		expr    string
+		want    float64
+		wantErr string
+	}{
+		{name: "multiplication before addition", expr: "2 + 3 * 4", want: 14},
+		{name: "division before subtraction", expr: "20 - 8 / 4", want: 18},
+		{name: "left associative subtraction", expr: "10 - 4 - 3", want: 3},
+		{name: "left associative division", expr: "100 / 10 / 5", want: 2},
+		{name: "parentheses", expr: "(2 + 3) * 4", want: 20},
+		{name: "unary minus", expr: "-5 + 3", want: -2},
+		{name: "unary minus binds tighter than multiplication", expr: "-2 * -3", want: 6},
+		{name: "double unary minus", expr: "--4", want: 4},
+		{name: "unary minus on parentheses", expr: "-(2 + 3) * 2", want: -10},
+		{name: "names are case-insensitive", expr: "ctc * 0.4", want: 40000},
+		{name: "names and numbers", expr: "CTC - BASIC - HRA", want: 40000},
+		{name: "decimals", expr: "BASIC * 12.5 / 100", want: 5000},
+		{name: "MIN", expr: "MIN(BASIC * 0.12, 1800)", want: 1800},
+		{name: "MAX", expr: "MAX(CTC - 90000, 0, 5)", want: 10000},
+		{name: "ROUND to whole number", expr: "ROUND(CTC / WORKING_DAYS)", want: 4545},
+		{name: "ROUND to decimals", expr: "ROUND(CTC / WORKING_DAYS, 2)", want: 4545.45},
+		{name: "division by zero", expr: "CTC / (WORKING_DAYS - 22)", wantErr: "division by zero"},
+		{name: "division by literal zero", expr: "1 / 0", wantErr: "division by zero"},
+		{name: "unknown name", expr: "BASIC + DA", wantErr: "unknown component DA"},
+		{name: "unknown function", expr: "AVG(1, 2)", wantErr: "unknown function AVG"},
+		{name: "ROUND with three arguments", expr: "ROUND(1, 2, 3)", wantErr: "ROUND takes one or two arguments"},
+		{name: "missing closing parenthesis", expr: "(1 + 2", wantErr: `expected ")"`},
+		{name: "trailing operator", expr: "1 +", wantErr: "unexpected end of formula"},
+		{name: "trailing token", expr: "1 2", wantErr: `unexpected "2"`},
+		{name: "invalid character", expr: "BASIC % 2", wantErr: "unexpected character '%'"},
+		{name: "invalid number", expr: "1.2.3", wantErr: `invalid number "1.2.3"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EvaluateFormula(tt.expr, formulaLookup(vars))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("EvaluateFormula(%q) error = %v, want %q", tt.expr, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EvaluateFormula(%q): %v", tt.expr, err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("EvaluateFormula(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
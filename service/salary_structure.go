@@ -0,0 +1,254 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+var salaryCodePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{0,29}$`)
+
+// reservedSalaryCodes are formula variables and payroll line codes
+var reservedSalaryCodes = map[string]bool{
+	models.SalaryVarCTC:             true,
+	models.SalaryVarWorkingDays:     true,
+	models.SalaryVarPaidLeaves:      true,
+	models.SalaryVarUnpaidLeaves:    true,
+	models.SalaryCodeLOP:            true,
+	models.SalaryCodePreviouslyPaid: true,
+	// statutory line codes
+	models.StatutoryCodeTDS:         true,
+	models.StatutoryCodeEPF:         true,
+	models.StatutoryCodeEPFEmployer: true,
+	models.StatutoryCodeEPSEmployer: true,
+	models.StatutoryCodeESI:         true,
+	models.StatutoryCodeESIEmployer: true,
+	models.StatutoryCodePT:          true,
+}
+
+// NormalizeSalaryComponents validates the components of a salary structure request and
+// checks that every percentage base and formula can be evaluated
+func NormalizeSalaryComponents(inputs []models.SalaryComponentInput) ([]models.SalaryComponent, error) {
+	components := make([]models.SalaryComponent, 0, len(inputs))
+	seen := make(map[string]bool, len(inputs))
+	for i, in := range inputs {
+		comp := models.SalaryComponent{
+			Code:      strings.ToUpper(strings.TrimSpace(in.Code)),
+			Name:      strings.TrimSpace(in.Name),
+			Kind:      strings.ToUpper(strings.TrimSpace(in.Kind)),
+			CalcType:  strings.ToUpper(strings.TrimSpace(in.CalcType)),
+			SortOrder: i + 1,
+		}
+		if !salaryCodePattern.MatchString(comp.Code) {
+			return nil, fmt.Errorf("component %d: code must be upper-case letters, digits and underscores, starting with a letter", i+1)
+		}
+		if reservedSalaryCodes[comp.Code] || formulaFunctions[comp.Code] {
+			return nil, fmt.Errorf("component %d: code %s is reserved", i+1, comp.Code)
+		}
+		if seen[comp.Code] {
+			return nil, fmt.Errorf("component %d: duplicate code %s", i+1, comp.Code)
+		}
+		seen[comp.Code] = true
+		if comp.Name == "" {
+			return nil, fmt.Errorf("component %s: name is required", comp.Code)
+		}
+		if comp.Kind != models.SalaryEarning && comp.Kind != models.SalaryDeduction {
+			return nil, fmt.Errorf("component %s: kind must be EARNING or DEDUCTION", comp.Code)
+		}
+
+		switch comp.CalcType {
+		case models.SalaryCalcFixed:
+			if in.Amount == nil || *in.Amount < 0 {
+				return nil, fmt.Errorf("component %s: amount is required and cannot be negative", comp.Code)
+			}
+			comp.Amount = in.Amount
+		case models.SalaryCalcPercentage:
+			if in.Percentage == nil || *in.Percentage <= 0 {
+				return nil, fmt.Errorf("component %s: percentage must be greater than 0", comp.Code)
+			}
+			if len(in.BaseCodes) == 0 {
+				return nil, fmt.Errorf("component %s: base_codes are required", comp.Code)
+			}
+			comp.Percentage = in.Percentage
+			for _, code := range in.BaseCodes {
+				comp.BaseCodes = append(comp.BaseCodes, strings.ToUpper(strings.TrimSpace(code)))
+			}
+		case models.SalaryCalcFormula:
+			if in.Formula == nil || strings.TrimSpace(*in.Formula) == "" {
+				return nil, fmt.Errorf("component %s: formula is required", comp.Code)
+			}
+			formula := strings.TrimSpace(*in.Formula)
+			comp.Formula = &formula
+		default:
+			return nil, fmt.Errorf("component %s: calc_type must be FIXED, PERCENTAGE or FORMULA", comp.Code)
+		}
+
+		// Only earnings are reduced for unpaid leave days
+		comp.Prorate = comp.Kind == models.SalaryEarning
+		if in.Prorate != nil && comp.Kind == models.SalaryEarning {
+			comp.Prorate = *in.Prorate
+		}
+		components = append(components, comp)
+	}
+
+	// Unknown codes, cycles and syntax errors surface when evaluating with sample values
+	sample := &models.SalaryStructure{Components: components}
+	if _, err := ComputeSalary(sample, 100000, 22, LeaveSummary{}, nil); err != nil {
+		return nil, err
+	}
+	return components, nil
+}
+
+// salaryCalculator evaluates the components of a structure on demand, each at most once, so
+// components may refer to components listed after them
+type salaryCalculator struct {
+	components map[string]models.SalaryComponent
+	vars       map[string]float64
+	values     map[string]float64
+	evaluating map[string]bool
+}
+
+func (sc *salaryCalculator) value(code string) (float64, error) {
+	if v, ok := sc.vars[code]; ok {
+		return v, nil
+	}
+	if v, ok := sc.values[code]; ok {
+		return v, nil
+	}
+	comp, ok := sc.components[code]
+	if !ok {
+		return 0, fmt.Errorf("unknown component %s", code)
+	}
+	if sc.evaluating[code] {
+		return 0, fmt.Errorf("circular reference to component %s", code)
+	}
+	sc.evaluating[code] = true
+	defer delete(sc.evaluating, code)
+
+	var v float64
+	switch comp.CalcType {
+	case models.SalaryCalcFixed:
+		if comp.Amount != nil {
+			v = *comp.Amount
+		}
+	case models.SalaryCalcPercentage:
+		base := 0.0
+		for _, baseCode := range comp.BaseCodes {
+			b, err := sc.value(baseCode)
+			if err != nil {
+				return 0, err
+			}
+			base += b
+		}
+		if comp.Percentage != nil {
+			v = base * *comp.Percentage / 100
+		}
+	case models.SalaryCalcFormula:
+		if comp.Formula == nil {
+			return 0, fmt.Errorf("component %s has no formula", code)
+		}
+		var err error
+		v, err = EvaluateFormula(*comp.Formula, sc.value)
+		if err != nil {
+			return 0, fmt.Errorf("component %s: %v", code, err)
+		}
+	}
+
+	// A balancing component such as a special allowance never goes negative
+	v = math.Max(math.Round(v*100)/100, 0)
+	sc.values[code] = v
+	return v, nil
+}
+
+// ComputeSalary computes an employee's monthly salary lines from a structure. ctc is the
+// employee's monthly salary; without a structure it is paid entirely as basic salary.
+// Prorated earnings are paid for the employed part of a joining or exit month (proration, nil
+// for a whole month) and lose their daily amount for every unpaid leave day, listed as a single
+// loss of pay deduction after the other deductions.
+func ComputeSalary(structure *models.SalaryStructure, ctc float64, workingDays int, leave LeaveSummary, proration *models.SalaryProration) (models.SalaryBreakdown, error) {
+	breakdown := models.SalaryBreakdown{Lines: []models.PayslipLine{}, Earned: map[string]float64{}}
+
+	components := []models.SalaryComponent{{
+		Code:     models.SalaryCodeBasic,
+		Name:     "Basic Salary",
+		Kind:     models.SalaryEarning,
+		CalcType: models.SalaryCalcFixed,
+		Amount:   &ctc,
+		Prorate:  true,
+	}}
+	if structure != nil {
+		components = structure.Components
+	}
+
+	sc := &salaryCalculator{
+		components: make(map[string]models.SalaryComponent, len(components)),
+		vars: map[string]float64{
+			models.SalaryVarCTC:          ctc,
+			models.SalaryVarWorkingDays:  float64(workingDays),
+			models.SalaryVarPaidLeaves:   leave.PaidDays,
+			models.SalaryVarUnpaidLeaves: leave.UnpaidDays,
+		},
+		values:     make(map[string]float64, len(components)),
+		evaluating: make(map[string]bool),
+	}
+	for _, comp := range components {
+		sc.components[comp.Code] = comp
+	}
+
+	lopShare := 0.0
+	if workingDays > 0 && leave.UnpaidDays > 0 {
+		lopShare = math.Min(leave.UnpaidDays/float64(workingDays), 1)
+	}
+
+	factor := proration.Factor()
+
+	var earnings, deductions []models.PayslipLine
+	monthly, prorated := 0.0, 0.0
+	for _, comp := range components {
+		v, err := sc.value(comp.Code)
+		if err != nil {
+			return breakdown, err
+		}
+		if v == 0 {
+			continue
+		}
+		line := models.PayslipLine{Code: comp.Code, Name: comp.Name, Kind: comp.Kind, Amount: v}
+		if comp.Kind == models.SalaryDeduction {
+			deductions = append(deductions, line)
+			breakdown.Deductions += v
+			continue
+		}
+		if comp.Prorate {
+			// Loss of pay is a share of the monthly amount, whatever part of the month is paid
+			line.Amount = math.Round(v*factor*100) / 100
+			monthly += v
+			prorated += line.Amount
+			breakdown.Earned[comp.Code] = math.Max(math.Round((line.Amount-v*lopShare)*100)/100, 0)
+		} else {
+			breakdown.Earned[comp.Code] = v
+		}
+		earnings = append(earnings, line)
+		breakdown.Gross += line.Amount
+		breakdown.MonthlyGross += v
+	}
+
+	if lopShare > 0 && prorated > 0 {
+		lop := math.Round(math.Min(monthly*lopShare, prorated)*100) / 100
+		deductions = append(deductions, models.PayslipLine{
+			Code:   models.SalaryCodeLOP,
+			Name:   fmt.Sprintf("Absent Leave (%v Days)", leave.UnpaidDays),
+			Kind:   models.SalaryDeduction,
+			Amount: lop,
+		})
+		breakdown.Deductions += lop
+	}
+
+	breakdown.Lines = append(append(breakdown.Lines, earnings...), deductions...)
+	breakdown.Gross = math.Round(breakdown.Gross*100) / 100
+	breakdown.MonthlyGross = math.Round(breakdown.MonthlyGross*100) / 100
+	breakdown.Deductions = math.Round(breakdown.Deductions*100) / 100
+	return breakdown, nil
+}
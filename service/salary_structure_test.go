@@ -0,0 +1,198 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+func fixedComponent(code, kind string, amount float64, prorate bool) models.SalaryComponent {
+	return models.SalaryComponent{Code: code, Name: code, Kind: kind, CalcType: models.SalaryCalcFixed, Amount: &amount, Prorate: prorate}
+}
+
+func percentageComponent(code string, percentage float64, base ...string) models.SalaryComponent {
+	return models.SalaryComponent{Code: code, Name: code, Kind: models.SalaryEarning, CalcType: models.SalaryCalcPercentage, Percentage: &percentage, BaseCodes: base, Prorate: true}
+}
+
+func formulaComponent(code, kind, formula string) models.SalaryComponent {
+	return models.SalaryComponent{Code: code, Name: code, Kind: kind, CalcType: models.SalaryCalcFormula, Formula: &formula, Prorate: kind == models.SalaryEarning}
+}
+
+func earning(code string, amount float64) models.PayslipLine {
+	return models.PayslipLine{Code: code, Name: code, Kind: models.SalaryEarning, Amount: amount}
+}
+
+func deduction(code string, amount float64) models.PayslipLine {
+	return models.PayslipLine{Code: code, Name: code, Kind: models.SalaryDeduction, Amount: amount}
+}
+
+func TestComputeSalary(t *testing.T) {
+	// BASIC 40% of CTC, HRA 50% of BASIC, SPECIAL balances the rest of CTC; SPECIAL is listed
+	// first and refers to components after it
+	standard := &models.SalaryStructure{Components: []models.SalaryComponent{
+		formulaComponent("SPECIAL", models.SalaryEarning, "CTC - BASIC - HRA - CONVEYANCE"),
+		percentageComponent("BASIC", 40, "CTC"),
+		percentageComponent("HRA", 50, "BASIC"),
+		fixedComponent("CONVEYANCE", models.SalaryEarning, 1600, false),
+		fixedComponent("CANTEEN", models.SalaryDeduction, 500, false),
+	}}
+	lopLine := func(days string, amount float64) models.PayslipLine {
+		return models.PayslipLine{Code: models.SalaryCodeLOP, Name: "Absent Leave (" + days + " Days)", Kind: models.SalaryDeduction, Amount: amount}
+	}
+
+	tests := []struct {
+		name         string
+		structure    *models.SalaryStructure
+		ctc          float64
+		leave        LeaveSummary
+		proration    *models.SalaryProration
+		lines        []models.PayslipLine
+		gross        float64
+		monthlyGross float64
+		deductions   float64
+		earned       map[string]float64
+		wantErr      string
+	}{
+		{
+			name:         "no structure pays CTC as basic",
+			ctc:          30000,
+			lines:        []models.PayslipLine{{Code: models.SalaryCodeBasic, Name: "Basic Salary", Kind: models.SalaryEarning, Amount: 30000}},
+			gross:        30000,
+			monthlyGross: 30000,
+			earned:       map[string]float64{models.SalaryCodeBasic: 30000},
+		},
+		{
+			name:      "structure with forward references",
+			structure: standard,
+			ctc:       100000,
+			lines: []models.PayslipLine{
+				earning("SPECIAL", 38400), earning("BASIC", 40000), earning("HRA", 20000), earning("CONVEYANCE", 1600),
+				deduction("CANTEEN", 500),
+			},
+			gross:        100000,
+			monthlyGross: 100000,
+			deductions:   500,
+			earned:       map[string]float64{"SPECIAL": 38400, "BASIC": 40000, "HRA": 20000, "CONVEYANCE": 1600},
+		},
+		{
+			name: "balancing component never goes negative",
+			structure: &models.SalaryStructure{Components: []models.SalaryComponent{
+				fixedComponent("BASIC", models.SalaryEarning, 20000, true),
+				formulaComponent("SPECIAL", models.SalaryEarning, "CTC - BASIC"),
+			}},
+			ctc:          15000,
+			lines:        []models.PayslipLine{earning("BASIC", 20000)},
+			gross:        20000,
+			monthlyGross: 20000,
+			earned:       map[string]float64{"BASIC": 20000},
+		},
+		{
+			name:      "unpaid leave is a loss of pay on prorated earnings only",
+			structure: standard,
+			ctc:       100000,
+			leave:     LeaveSummary{PaidDays: 1, UnpaidDays: 2},
+			lines: []models.PayslipLine{
+				earning("SPECIAL", 38400), earning("BASIC", 40000), earning("HRA", 20000), earning("CONVEYANCE", 1600),
+				deduction("CANTEEN", 500), lopLine("2", 8945.45),
+			},
+			gross:        100000,
+			monthlyGross: 100000,
+			deductions:   9445.45,
+			earned:       map[string]float64{"SPECIAL": 34909.09, "BASIC": 36363.64, "HRA": 18181.82, "CONVEYANCE": 1600},
+		},
+		{
+			name:         "joining month is prorated",
+			ctc:          30000,
+			proration:    &models.SalaryProration{Basis: models.ProrationCalendarDays, PayableDays: 15, PeriodDays: 30},
+			lines:        []models.PayslipLine{{Code: models.SalaryCodeBasic, Name: "Basic Salary", Kind: models.SalaryEarning, Amount: 15000}},
+			gross:        15000,
+			monthlyGross: 30000,
+			earned:       map[string]float64{models.SalaryCodeBasic: 15000},
+		},
+		{
+			name:      "prorated month keeps fixed earnings whole",
+			structure: standard,
+			ctc:       100000,
+			proration: &models.SalaryProration{Basis: models.ProrationCalendarDays, PayableDays: 10, PeriodDays: 30},
+			lines: []models.PayslipLine{
+				earning("SPECIAL", 12800), earning("BASIC", 13333.33), earning("HRA", 6666.67), earning("CONVEYANCE", 1600),
+				deduction("CANTEEN", 500),
+			},
+			gross:        34400,
+			monthlyGross: 100000,
+			deductions:   500,
+			earned:       map[string]float64{"SPECIAL": 12800, "BASIC": 13333.33, "HRA": 6666.67, "CONVEYANCE": 1600},
+		},
+		{
+			name:         "loss of pay is capped at the prorated earnings",
+			ctc:          22000,
+			leave:        LeaveSummary{UnpaidDays: 15},
+			proration:    &models.SalaryProration{Basis: models.ProrationCalendarDays, PayableDays: 10, PeriodDays: 30},
+			lines:        []models.PayslipLine{{Code: models.SalaryCodeBasic, Name: "Basic Salary", Kind: models.SalaryEarning, Amount: 7333.33}, lopLine("15", 7333.33)},
+			gross:        7333.33,
+			monthlyGross: 22000,
+			deductions:   7333.33,
+			earned:       map[string]float64{models.SalaryCodeBasic: 0},
+		},
+		{
+			name: "circular reference",
+			structure: &models.SalaryStructure{Components: []models.SalaryComponent{
+				formulaComponent("BASIC", models.SalaryEarning, "HRA * 2"),
+				percentageComponent("HRA", 50, "SPECIAL"),
+				formulaComponent("SPECIAL", models.SalaryEarning, "CTC - BASIC"),
+			}},
+			ctc:     50000,
+			wantErr: "circular reference to component BASIC",
+		},
+		{
+			name: "self reference",
+			structure: &models.SalaryStructure{Components: []models.SalaryComponent{
+				formulaComponent("BASIC", models.SalaryEarning, "BASIC + 1"),
+			}},
+			ctc:     50000,
+			wantErr: "circular reference to component BASIC",
+		},
+		{
+			name: "unknown component",
+			structure: &models.SalaryStructure{Components: []models.SalaryComponent{
+				percentageComponent("HRA", 50, "BASIC"),
+			}},
+			ctc:     50000,
+			wantErr: "unknown component BASIC",
+		},
+		{
+			name: "division by zero in a formula",
+			structure: &models.SalaryStructure{Components: []models.SalaryComponent{
+				formulaComponent("BASIC", models.SalaryEarning, "CTC / (WORKING_DAYS - 22)"),
+			}},
+			ctc:     50000,
+			wantErr: "component BASIC: division by zero",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ComputeSalary(tt.structure, tt.ctc, 22, tt.leave, tt.proration)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ComputeSalary error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ComputeSalary: %v", err)
+			}
+			if !reflect.DeepEqual(b.Lines, tt.lines) {
+				t.Errorf("lines = %+v, want %+v", b.Lines, tt.lines)
+			}
+			if b.Gross != tt.gross || b.MonthlyGross != tt.monthlyGross || b.Deductions != tt.deductions {
+				t.Errorf("gross, monthly gross, deductions = %v, %v, %v, want %v, %v, %v",
+					b.Gross, b.MonthlyGross, b.Deductions, tt.gross, tt.monthlyGross, tt.deductions)
+			}
+			if !reflect.DeepEqual(b.Earned, tt.earned) {
+				t.Errorf("earned = %v, want %v", b.Earned, tt.earned)
+			}
+		})
+	}
+}
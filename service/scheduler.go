@@ -0,0 +1,26 @@
+package service
+
+import (
+	"log"
+	"time"
+)
+
+// StartDailyJob runs job in the background every day at the given hour (server local time)
+func StartDailyJob(name string, hour int, job func() error) {
+	go func() {
+		for {
+			now := time.Now()
+			next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+			if !next.After(now) {
+				next = next.AddDate(0, 0, 1)
+			}
+			time.Sleep(time.Until(next))
+
+			if err := job(); err != nil {
+				log.Printf("Scheduled job %q failed: %v", name, err)
+				continue
+			}
+			log.Printf("Scheduled job %q completed", name)
+		}
+	}()
+}
@@ -0,0 +1,393 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+// StatutoryInput is what statutory calculators know about an employee's payroll month
+type StatutoryInput struct {
+	Month        int
+	Year         int
+	Profile      models.StatutoryProfile
+	YTD          models.StatutoryYTD // earlier payslips of the financial year
+	OtherTaxable float64             // leave encashment paid with the run
+	EndingDate   *time.Time          // last working day of a leaver
+}
+
+// statutoryCalculator computes one kind of statutory deduction from the config of its rule.
+// A new kind is added by implementing it, registering it in statutoryCalculators and
+// allowing the kind in Tbl_Statutory_rule.
+type statutoryCalculator interface {
+	kind() string
+	// ruleCode is the code of the rule that applies to the employee, "" when none does
+	ruleCode(in StatutoryInput) string
+	validate(config json.RawMessage) error
+	calculate(config json.RawMessage, in StatutoryInput, b *models.SalaryBreakdown) ([]models.PayslipLine, error)
+}
+
+// statutoryCalculators run in order; income tax comes last since the old regime deducts the
+// employee's provident fund contribution
+var statutoryCalculators = []statutoryCalculator{
+	epfCalculator{},
+	esiCalculator{},
+	professionalTaxCalculator{},
+	incomeTaxCalculator{},
+}
+
+// StatutoryRuleKey is the key of a rule in the map of rules in force
+func StatutoryRuleKey(kind, code string) string {
+	return kind + ":" + code
+}
+
+// FinancialYearStart is the year the financial year (April to March) of a month starts in
+func FinancialYearStart(month, year int) int {
+	if month >= 4 {
+		return year
+	}
+	return year - 1
+}
+
+// financialMonthsLeft counts the months of the financial year from month to March
+func financialMonthsLeft(month int) int {
+	if month >= 4 {
+		return 16 - month
+	}
+	return 4 - month
+}
+
+// payableMonthsLeft counts the months of the financial year from the input's month to March
+// the employee is still employed in
+func payableMonthsLeft(in StatutoryInput) int {
+	months := financialMonthsLeft(in.Month)
+	if in.EndingDate != nil {
+		employed := (in.EndingDate.Year()-in.Year)*12 + int(in.EndingDate.Month()) - in.Month + 1
+		months = max(min(months, employed), 1)
+	}
+	return months
+}
+
+func roundRupee(v float64) float64 {
+	return math.Max(math.Round(v), 0)
+}
+
+// earnedGross is the month's earnings after loss of pay
+func earnedGross(b *models.SalaryBreakdown) float64 {
+	total := 0.0
+	for _, v := range b.Earned {
+		total += v
+	}
+	return total
+}
+
+// NormalizeStatutoryRule validates a statutory rule version and normalizes its kind and code
+func NormalizeStatutoryRule(input *models.StatutoryRuleInput) error {
+	input.Kind = strings.ToUpper(strings.TrimSpace(input.Kind))
+	input.Code = strings.ToUpper(strings.TrimSpace(input.Code))
+	input.Name = strings.TrimSpace(input.Name)
+	switch input.Kind {
+	case models.StatutoryTDS:
+		if input.Code != models.TaxRegimeNew && input.Code != models.TaxRegimeOld {
+			return fmt.Errorf("code of a TDS rule must be the tax regime, NEW or OLD")
+		}
+	case models.StatutoryPT:
+		if input.Code == "" {
+			return fmt.Errorf("code of a PT rule must be the state")
+		}
+	default:
+		input.Code = models.StatutoryDefaultCode
+	}
+	for _, calc := range statutoryCalculators {
+		if calc.kind() == input.Kind {
+			if err := calc.validate(input.Config); err != nil {
+				return fmt.Errorf("invalid %s config: %v", input.Kind, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("kind must be TDS, EPF, ESI or PT")
+}
+
+// NormalizeTaxRegime upper-cases and validates a tax regime
+func NormalizeTaxRegime(regime string) (string, error) {
+	r := strings.ToUpper(strings.TrimSpace(regime))
+	if r != models.TaxRegimeNew && r != models.TaxRegimeOld {
+		return "", fmt.Errorf("invalid tax regime %q. Must be NEW or OLD", regime)
+	}
+	return r, nil
+}
+
+// ApplyStatutoryDeductions adds the statutory deductions and employer contributions of the
+// rules in force to a computed salary. Kinds without a rule in force are skipped.
+func ApplyStatutoryDeductions(rules map[string]models.StatutoryRule, in StatutoryInput, b *models.SalaryBreakdown) error {
+	for _, calc := range statutoryCalculators {
+		code := calc.ruleCode(in)
+		if code == "" {
+			continue
+		}
+		rule, ok := rules[StatutoryRuleKey(calc.kind(), code)]
+		if !ok {
+			continue
+		}
+		lines, err := calc.calculate(rule.Config, in, b)
+		if err != nil {
+			return fmt.Errorf("%s: %v", rule.Name, err)
+		}
+		for _, line := range lines {
+			if line.Amount <= 0 {
+				continue
+			}
+			b.Lines = append(b.Lines, line)
+			if line.Kind == models.SalaryEmployer {
+				b.EmployerContributions += line.Amount
+			} else {
+				b.Deductions += line.Amount
+			}
+		}
+	}
+	b.Deductions = math.Round(b.Deductions*100) / 100
+	b.EmployerContributions = math.Round(b.EmployerContributions*100) / 100
+	return nil
+}
+
+// statutoryLineAmount is the amount of a line already in the breakdown
+func statutoryLineAmount(b *models.SalaryBreakdown, code string) float64 {
+	for _, line := range b.Lines {
+		if line.Code == code {
+			return line.Amount
+		}
+	}
+	return 0
+}
+
+// ----------------- EPF -----------------
+
+type epfCalculator struct{}
+
+func (epfCalculator) kind() string { return models.StatutoryEPF }
+
+func (epfCalculator) ruleCode(in StatutoryInput) string {
+	if !in.Profile.PFEnabled {
+		return ""
+	}
+	return models.StatutoryDefaultCode
+}
+
+func (epfCalculator) validate(config json.RawMessage) error {
+	var c models.EPFConfig
+	if err := json.Unmarshal(config, &c); err != nil {
+		return err
+	}
+	if len(c.WageCodes) == 0 {
+		return fmt.Errorf("wage_codes are required")
+	}
+	if c.EmployeeRate < 0 || c.EmployerRate < 0 || c.EPSRate < 0 || c.EPSRate > c.EmployerRate {
+		return fmt.Errorf("rates cannot be negative and eps_rate cannot exceed employer_rate")
+	}
+	if c.WageCeiling < 0 {
+		return fmt.Errorf("wage_ceiling cannot be negative")
+	}
+	return nil
+}
+
+func (epfCalculator) calculate(config json.RawMessage, in StatutoryInput, b *models.SalaryBreakdown) ([]models.PayslipLine, error) {
+	var c models.EPFConfig
+	if err := json.Unmarshal(config, &c); err != nil {
+		return nil, err
+	}
+	wage := 0.0
+	for _, code := range c.WageCodes {
+		wage += b.Earned[strings.ToUpper(code)]
+	}
+	if wage <= 0 {
+		return nil, nil
+	}
+	pfWage, epsWage := wage, wage
+	if c.WageCeiling > 0 {
+		epsWage = math.Min(wage, c.WageCeiling)
+		if c.RestrictToCeiling {
+			pfWage = epsWage
+		}
+	}
+	eps := roundRupee(epsWage * c.EPSRate / 100)
+	return []models.PayslipLine{
+		{Code: models.StatutoryCodeEPF, Name: "Provident Fund (EPF)", Kind: models.SalaryDeduction, Amount: roundRupee(pfWage * c.EmployeeRate / 100)},
+		{Code: models.StatutoryCodeEPFEmployer, Name: "Employer Provident Fund", Kind: models.SalaryEmployer, Amount: roundRupee(pfWage*c.EmployerRate/100 - eps)},
+		{Code: models.StatutoryCodeEPSEmployer, Name: "Employer Pension (EPS)", Kind: models.SalaryEmployer, Amount: eps},
+	}, nil
+}
+
+// ----------------- ESI -----------------
+
+type esiCalculator struct{}
+
+func (esiCalculator) kind() string { return models.StatutoryESI }
+
+func (esiCalculator) ruleCode(StatutoryInput) string { return models.StatutoryDefaultCode }
+
+func (esiCalculator) validate(config json.RawMessage) error {
+	var c models.ESIConfig
+	if err := json.Unmarshal(config, &c); err != nil {
+		return err
+	}
+	if c.EmployeeRate < 0 || c.EmployerRate < 0 || c.WageThreshold <= 0 {
+		return fmt.Errorf("rates cannot be negative and wage_threshold must be greater than 0")
+	}
+	return nil
+}
+
+func (esiCalculator) calculate(config json.RawMessage, in StatutoryInput, b *models.SalaryBreakdown) ([]models.PayslipLine, error) {
+	var c models.ESIConfig
+	if err := json.Unmarshal(config, &c); err != nil {
+		return nil, err
+	}
+	// Coverage follows the monthly wages, contributions the wages actually earned
+	if b.MonthlyGross <= 0 || b.MonthlyGross > c.WageThreshold {
+		return nil, nil
+	}
+	wages := earnedGross(b)
+	return []models.PayslipLine{
+		{Code: models.StatutoryCodeESI, Name: "Employee State Insurance (ESI)", Kind: models.SalaryDeduction, Amount: math.Ceil(wages * c.EmployeeRate / 100)},
+		{Code: models.StatutoryCodeESIEmployer, Name: "Employer State Insurance", Kind: models.SalaryEmployer, Amount: math.Ceil(wages * c.EmployerRate / 100)},
+	}, nil
+}
+
+// ----------------- Professional tax -----------------
+
+type professionalTaxCalculator struct{}
+
+func (professionalTaxCalculator) kind() string { return models.StatutoryPT }
+
+func (professionalTaxCalculator) ruleCode(in StatutoryInput) string {
+	if in.Profile.PTState == nil {
+		return ""
+	}
+	return strings.ToUpper(strings.TrimSpace(*in.Profile.PTState))
+}
+
+func (professionalTaxCalculator) validate(config json.RawMessage) error {
+	var c models.ProfessionalTaxConfig
+	if err := json.Unmarshal(config, &c); err != nil {
+		return err
+	}
+	if len(c.Slabs) == 0 {
+		return fmt.Errorf("slabs are required")
+	}
+	for i, slab := range c.Slabs {
+		if slab.Amount < 0 || (slab.Max != nil && *slab.Max < slab.Min) {
+			return fmt.Errorf("slab %d: amount cannot be negative and max cannot be below min", i+1)
+		}
+		for month, amount := range slab.MonthAmounts {
+			if m, err := strconv.Atoi(month); err != nil || m < 1 || m > 12 || amount < 0 {
+				return fmt.Errorf("slab %d: month_amounts must map months 1-12 to amounts", i+1)
+			}
+		}
+	}
+	return nil
+}
+
+func (professionalTaxCalculator) calculate(config json.RawMessage, in StatutoryInput, b *models.SalaryBreakdown) ([]models.PayslipLine, error) {
+	var c models.ProfessionalTaxConfig
+	if err := json.Unmarshal(config, &c); err != nil {
+		return nil, err
+	}
+	gross := earnedGross(b)
+	for _, slab := range c.Slabs {
+		if gross < slab.Min || (slab.Max != nil && gross > *slab.Max) {
+			continue
+		}
+		amount := slab.Amount
+		if v, ok := slab.MonthAmounts[strconv.Itoa(in.Month)]; ok {
+			amount = v
+		}
+		return []models.PayslipLine{{Code: models.StatutoryCodePT, Name: "Professional Tax", Kind: models.SalaryDeduction, Amount: amount}}, nil
+	}
+	return nil, nil
+}
+
+// ----------------- Income tax (TDS) -----------------
+
+type incomeTaxCalculator struct{}
+
+func (incomeTaxCalculator) kind() string { return models.StatutoryTDS }
+
+func (incomeTaxCalculator) ruleCode(in StatutoryInput) string {
+	if in.Profile.TaxRegime == "" {
+		return models.TaxRegimeNew
+	}
+	return in.Profile.TaxRegime
+}
+
+func (incomeTaxCalculator) validate(config json.RawMessage) error {
+	var c models.TDSConfig
+	if err := json.Unmarshal(config, &c); err != nil {
+		return err
+	}
+	if len(c.Slabs) == 0 {
+		return fmt.Errorf("slabs are required")
+	}
+	for i, slab := range c.Slabs {
+		if slab.Rate < 0 || slab.Rate > 100 || (slab.To != nil && *slab.To <= slab.From) {
+			return fmt.Errorf("slab %d: rate must be between 0 and 100 and to must be above from", i+1)
+		}
+		if i > 0 && (c.Slabs[i-1].To == nil || *c.Slabs[i-1].To > slab.From) {
+			return fmt.Errorf("slab %d overlaps the previous slab", i+1)
+		}
+	}
+	if c.StandardDeduction < 0 || c.RebateLimit < 0 || c.RebateMax < 0 || c.CessPercent < 0 || c.Section80CLimit < 0 {
+		return fmt.Errorf("amounts and percentages cannot be negative")
+	}
+	return nil
+}
+
+// slabTax is the tax on an annual taxable income
+func slabTax(slabs []models.TaxSlab, income float64) float64 {
+	tax := 0.0
+	for _, slab := range slabs {
+		if income <= slab.From {
+			break
+		}
+		upper := income
+		if slab.To != nil && *slab.To < upper {
+			upper = *slab.To
+		}
+		tax += (upper - slab.From) * slab.Rate / 100
+	}
+	return tax
+}
+
+// calculate projects the annual taxable income (earned so far, this month and the monthly
+// gross for the rest of the financial year, or until a leaver's ending date), computes the tax
+// of the regime and spreads what is not deducted yet over the remaining months
+func (incomeTaxCalculator) calculate(config json.RawMessage, in StatutoryInput, b *models.SalaryBreakdown) ([]models.PayslipLine, error) {
+	var c models.TDSConfig
+	if err := json.Unmarshal(config, &c); err != nil {
+		return nil, err
+	}
+	monthsLeft := payableMonthsLeft(in)
+	annual := in.YTD.TaxableIncome + earnedGross(b) + in.OtherTaxable + b.MonthlyGross*float64(monthsLeft-1)
+
+	taxable := annual - c.StandardDeduction
+	if c.Section80CLimit > 0 {
+		epf := statutoryLineAmount(b, models.StatutoryCodeEPF)
+		taxable -= math.Min(in.YTD.EPF+epf*float64(monthsLeft), c.Section80CLimit)
+	}
+	if taxable <= 0 {
+		return nil, nil
+	}
+
+	tax := slabTax(c.Slabs, taxable)
+	if taxable <= c.RebateLimit {
+		tax = math.Max(tax-c.RebateMax, 0)
+	}
+	tax += tax * c.CessPercent / 100
+
+	monthly := roundRupee((tax - in.YTD.TDS) / float64(monthsLeft))
+	return []models.PayslipLine{{Code: models.StatutoryCodeTDS, Name: "Income Tax (TDS)", Kind: models.SalaryDeduction, Amount: monthly}}, nil
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+)
+
+func statutoryConfig(t *testing.T, config interface{}) json.RawMessage {
+	t.Helper()
+	raw, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+// statutoryBreakdown is a month paid entirely as basic salary: monthly is the whole month's
+// gross, earned what is paid after proration and loss of pay
+func statutoryBreakdown(monthly, earned float64, lines ...models.PayslipLine) *models.SalaryBreakdown {
+	return &models.SalaryBreakdown{
+		Lines:        lines,
+		Gross:        earned,
+		MonthlyGross: monthly,
+		Earned:       map[string]float64{models.SalaryCodeBasic: earned},
+	}
+}
+
+func amount(v float64) *float64 { return &v }
+
+func TestSlabTax(t *testing.T) {
+	slabs := []models.TaxSlab{
+		{From: 0, To: amount(400000), Rate: 0},
+		{From: 400000, To: amount(800000), Rate: 5},
+		{From: 800000, To: amount(1200000), Rate: 10},
+		{From: 1200000, Rate: 30},
+	}
+	tests := []struct {
+		income float64
+		want   float64
+	}{
+		{income: 0, want: 0},
+		{income: 400000, want: 0},
+		{income: 500000, want: 5000},
+		{income: 800000, want: 20000},
+		{income: 1000000, want: 40000},
+		{income: 1200000, want: 60000},
+		{income: 1500000, want: 150000},
+	}
+	for _, tt := range tests {
+		if got := slabTax(slabs, tt.income); got != tt.want {
+			t.Errorf("slabTax(%v) = %v, want %v", tt.income, got, tt.want)
+		}
+	}
+}
+
+func TestEPFCalculator(t *testing.T) {
+	config := models.EPFConfig{WageCodes: []string{"basic"}, EmployeeRate: 12, EmployerRate: 12, EPSRate: 8.33, WageCeiling: 15000}
+	restricted := config
+	restricted.RestrictToCeiling = true
+	epfLines := func(epf, employer, eps float64) []models.PayslipLine {
+		return []models.PayslipLine{
+			{Code: models.StatutoryCodeEPF, Name: "Provident Fund (EPF)", Kind: models.SalaryDeduction, Amount: epf},
+			{Code: models.StatutoryCodeEPFEmployer, Name: "Employer Provident Fund", Kind: models.SalaryEmployer, Amount: employer},
+			{Code: models.StatutoryCodeEPSEmployer, Name: "Employer Pension (EPS)", Kind: models.SalaryEmployer, Amount: eps},
+		}
+	}
+	tests := []struct {
+		name   string
+		config models.EPFConfig
+		earned float64
+		want   []models.PayslipLine
+	}{
+		{name: "below the ceiling", config: config, earned: 10000, want: epfLines(1200, 367, 833)},
+		{name: "pension is capped at the ceiling", config: config, earned: 40000, want: epfLines(4800, 3550, 1250)},
+		{name: "contributions restricted to the ceiling", config: restricted, earned: 40000, want: epfLines(1800, 550, 1250)},
+		{name: "no PF wages", config: config, earned: 0, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := epfCalculator{}.calculate(statutoryConfig(t, tt.config), StatutoryInput{Month: 5, Year: 2026}, statutoryBreakdown(tt.earned, tt.earned))
+			if err != nil {
+				t.Fatalf("calculate: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestESICalculator(t *testing.T) {
+	config := models.ESIConfig{EmployeeRate: 0.75, EmployerRate: 3.25, WageThreshold: 21000}
+	esiLines := func(employee, employer float64) []models.PayslipLine {
+		return []models.PayslipLine{
+			{Code: models.StatutoryCodeESI, Name: "Employee State Insurance (ESI)", Kind: models.SalaryDeduction, Amount: employee},
+			{Code: models.StatutoryCodeESIEmployer, Name: "Employer State Insurance", Kind: models.SalaryEmployer, Amount: employer},
+		}
+	}
+	tests := []struct {
+		name    string
+		monthly float64
+		earned  float64
+		want    []models.PayslipLine
+	}{
+		{name: "below the threshold", monthly: 20000, earned: 20000, want: esiLines(150, 650)},
+		{name: "at the threshold", monthly: 21000, earned: 21000, want: esiLines(158, 683)},
+		{name: "above the threshold", monthly: 25000, earned: 25000, want: nil},
+		{name: "contributions follow the wages earned", monthly: 20000, earned: 12345, want: esiLines(93, 402)},
+		{name: "prorated month of a covered employee", monthly: 18000, earned: 9000, want: esiLines(68, 293)},
+		{name: "prorated month below the threshold is not covered", monthly: 30000, earned: 15000, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := esiCalculator{}.calculate(statutoryConfig(t, config), StatutoryInput{Month: 5, Year: 2026}, statutoryBreakdown(tt.monthly, tt.earned))
+			if err != nil {
+				t.Fatalf("calculate: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfessionalTaxCalculator(t *testing.T) {
+	config := models.ProfessionalTaxConfig{Slabs: []models.ProfessionalTaxSlab{
+		{Min: 0, Max: amount(7500), Amount: 0},
+		{Min: 7500.01, Max: amount(10000), Amount: 175},
+		{Min: 10000.01, Amount: 200, MonthAmounts: map[string]float64{"2": 300}},
+	}}
+	ptLine := func(amount float64) []models.PayslipLine {
+		return []models.PayslipLine{{Code: models.StatutoryCodePT, Name: "Professional Tax", Kind: models.SalaryDeduction, Amount: amount}}
+	}
+	tests := []struct {
+		name   string
+		month  int
+		earned float64
+		want   []models.PayslipLine
+	}{
+		{name: "lowest slab", month: 5, earned: 7000, want: ptLine(0)},
+		{name: "middle slab", month: 5, earned: 9000, want: ptLine(175)},
+		{name: "top slab", month: 5, earned: 50000, want: ptLine(200)},
+		{name: "month override", month: 2, earned: 50000, want: ptLine(300)},
+		{name: "month override of another slab", month: 2, earned: 9000, want: ptLine(175)},
+		{name: "between slabs", month: 5, earned: 7500.005, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := professionalTaxCalculator{}.calculate(statutoryConfig(t, config), StatutoryInput{Month: tt.month, Year: 2026}, statutoryBreakdown(tt.earned, tt.earned))
+			if err != nil {
+				t.Fatalf("calculate: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncomeTaxCalculator(t *testing.T) {
+	newRegime := models.TDSConfig{
+		StandardDeduction: 75000,
+		Slabs: []models.TaxSlab{
+			{From: 0, To: amount(400000), Rate: 0},
+			{From: 400000, To: amount(800000), Rate: 5},
+			{From: 800000, To: amount(1200000), Rate: 10},
+			{From: 1200000, Rate: 30},
+		},
+		RebateLimit: 1200000,
+		RebateMax:   60000,
+		CessPercent: 4,
+	}
+	oldRegime := models.TDSConfig{
+		StandardDeduction: 50000,
+		Slabs: []models.TaxSlab{
+			{From: 0, To: amount(250000), Rate: 0},
+			{From: 250000, To: amount(500000), Rate: 5},
+			{From: 500000, To: amount(1000000), Rate: 20},
+			{From: 1000000, Rate: 30},
+		},
+		RebateLimit:     500000,
+		RebateMax:       12500,
+		CessPercent:     4,
+		Section80CLimit: 150000,
+	}
+	epfLine := models.PayslipLine{Code: models.StatutoryCodeEPF, Kind: models.SalaryDeduction, Amount: 12000}
+	leaving := time.Date(2026, time.December, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		config models.TDSConfig
+		in     StatutoryInput
+		b      *models.SalaryBreakdown
+		want   float64 // monthly TDS, -1 when no line is expected
+	}{
+		{
+			// 1,275,000 projected, 1,200,000 taxable: 60,000 tax fully rebated
+			name:   "rebate up to the limit",
+			config: newRegime,
+			in:     StatutoryInput{Month: 4, Year: 2026},
+			b:      statutoryBreakdown(106250, 106250),
+			want:   0,
+		},
+		{
+			// 1,320,000 projected, 1,245,000 taxable: 73,500 tax + 4% cess over 12 months
+			name:   "no rebate above the limit",
+			config: newRegime,
+			in:     StatutoryInput{Month: 4, Year: 2026},
+			b:      statutoryBreakdown(110000, 110000),
+			want:   6370,
+		},
+		{
+			// 1,800,000 projected: 217,500 tax + 8,700 cess, less 60,000 deducted, over 6 months
+			name:   "year to date",
+			config: newRegime,
+			in:     StatutoryInput{Month: 10, Year: 2026, YTD: models.StatutoryYTD{TaxableIncome: 900000, TDS: 60000}},
+			b:      statutoryBreakdown(150000, 150000),
+			want:   27700,
+		},
+		{
+			// Joined mid-October: 150,000 earned and 300,000 a month for the 5 months after,
+			// 1,575,000 taxable: 172,500 tax + 6,900 cess over 6 months
+			name:   "prorated joining month projects the monthly gross",
+			config: newRegime,
+			in:     StatutoryInput{Month: 10, Year: 2026},
+			b:      statutoryBreakdown(300000, 150000),
+			want:   29900,
+		},
+		{
+			// Leaving on 15 December: 1,500,000 earned, 300,000 this month and 2 more months,
+			// 2,325,000 taxable: 397,500 tax + 15,900 cess, less 100,000 deducted, over 3 months
+			name:   "projection stops at the ending date",
+			config: newRegime,
+			in:     StatutoryInput{Month: 10, Year: 2026, YTD: models.StatutoryYTD{TaxableIncome: 1500000, TDS: 100000}, EndingDate: &leaving},
+			b:      statutoryBreakdown(300000, 300000),
+			want:   104467,
+		},
+		{
+			// Prorated leaving month: 1,500,000 earned, 150,000 this month and nothing after,
+			// 1,575,000 taxable: 172,500 tax + 6,900 cess, less 100,000 deducted
+			name:   "prorated leaving month",
+			config: newRegime,
+			in:     StatutoryInput{Month: 12, Year: 2026, YTD: models.StatutoryYTD{TaxableIncome: 1500000, TDS: 100000}, EndingDate: &leaving},
+			b:      statutoryBreakdown(300000, 150000),
+			want:   79400,
+		},
+		{
+			// Leave encashment paid with the run is taxed with the month: 1,300,000 projected,
+			// 1,225,000 taxable, 67,500 tax + 2,700 cess in March
+			name:   "other taxable income",
+			config: newRegime,
+			in:     StatutoryInput{Month: 3, Year: 2027, YTD: models.StatutoryYTD{TaxableIncome: 1100000}, OtherTaxable: 100000},
+			b:      statutoryBreakdown(100000, 100000),
+			want:   70200,
+		},
+		{
+			// 1,200,000 projected less 50,000 standard deduction and 144,000 EPF under 80C:
+			// 1,006,000 taxable, 114,300 tax + 4,572 cess over 12 months
+			name:   "old regime deducts EPF under 80C",
+			config: oldRegime,
+			in:     StatutoryInput{Month: 4, Year: 2026},
+			b:      statutoryBreakdown(100000, 100000, epfLine),
+			want:   9906,
+		},
+		{
+			name:   "old regime rebate",
+			config: oldRegime,
+			in:     StatutoryInput{Month: 4, Year: 2026},
+			b:      statutoryBreakdown(45000, 45000),
+			want:   0,
+		},
+		{
+			name:   "nothing taxable",
+			config: newRegime,
+			in:     StatutoryInput{Month: 3, Year: 2027},
+			b:      statutoryBreakdown(50000, 50000),
+			want:   -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := incomeTaxCalculator{}.calculate(statutoryConfig(t, tt.config), tt.in, tt.b)
+			if err != nil {
+				t.Fatalf("calculate: %v", err)
+			}
+			if tt.want < 0 {
+				if got != nil {
+					t.Errorf("lines = %+v, want none", got)
+				}
+				return
+			}
+			want := []models.PayslipLine{{Code: models.StatutoryCodeTDS, Name: "Income Tax (TDS)", Kind: models.SalaryDeduction, Amount: tt.want}}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("lines = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyStatutoryDeductions(t *testing.T) {
+	rules := map[string]models.StatutoryRule{
+		StatutoryRuleKey(models.StatutoryEPF, models.StatutoryDefaultCode): {Name: "EPF", Config: statutoryConfig(t, models.EPFConfig{WageCodes: []string{"BASIC"}, EmployeeRate: 12, EmployerRate: 12, EPSRate: 8.33, WageCeiling: 15000, RestrictToCeiling: true})},
+		StatutoryRuleKey(models.StatutoryESI, models.StatutoryDefaultCode): {Name: "ESI", Config: statutoryConfig(t, models.ESIConfig{EmployeeRate: 0.75, EmployerRate: 3.25, WageThreshold: 21000})},
+		StatutoryRuleKey(models.StatutoryPT, "KA"):                         {Name: "PT Karnataka", Config: statutoryConfig(t, models.ProfessionalTaxConfig{Slabs: []models.ProfessionalTaxSlab{{Min: 0, Max: amount(24999), Amount: 0}, {Min: 25000, Amount: 200}}})},
+	}
+	state := "ka"
+	in := StatutoryInput{Month: 5, Year: 2026, Profile: models.StatutoryProfile{PFEnabled: true, PTState: &state}}
+	b := statutoryBreakdown(20000, 20000)
+	b.Deductions = 100
+
+	if err := ApplyStatutoryDeductions(rules, in, b); err != nil {
+		t.Fatalf("ApplyStatutoryDeductions: %v", err)
+	}
+	// No TDS rule is in force and the zero professional tax is left out
+	var codes []string
+	for _, line := range b.Lines {
+		codes = append(codes, line.Code)
+	}
+	wantCodes := []string{models.StatutoryCodeEPF, models.StatutoryCodeEPFEmployer, models.StatutoryCodeEPSEmployer, models.StatutoryCodeESI, models.StatutoryCodeESIEmployer}
+	if !reflect.DeepEqual(codes, wantCodes) {
+		t.Errorf("line codes = %v, want %v", codes, wantCodes)
+	}
+	if b.Deductions != 100+1800+150 || b.EmployerContributions != 550+1250+650 {
+		t.Errorf("deductions, employer contributions = %v, %v, want 2050, 2450", b.Deductions, b.EmployerContributions)
+	}
+}
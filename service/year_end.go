@@ -0,0 +1,156 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/models"
+	"github.com/sanjayk-eng/UserMenagmentSystem_Backend/repositories"
+)
+
+// SplitYearEndBalance applies a leave type's year-end rules to a closing balance: up to
+// carry_forward_max is carried forward, the excess of paid encashable types is encashed
+// (up to encash_max) and whatever is left lapses
+func SplitYearEndBalance(lt models.LeaveType, closing float64) (carried, encashed, lapsed float64) {
+	if closing <= 0 {
+		return 0, 0, 0
+	}
+	carried = math.Min(closing, lt.CarryForwardMax)
+	excess := closing - carried
+	if lt.IsPaid && lt.Encashable {
+		encashed = excess
+		if lt.EncashMax != nil {
+			encashed = math.Min(excess, *lt.EncashMax)
+		}
+	}
+	lapsed = excess - encashed
+	return carried, encashed, lapsed
+}
+
+// PreviewYearEnd computes the year-end close of every employee's leave balances without saving
+// anything. The encashment rate is the monthly salary divided by the working days per month
+// (and by the working hours per day for hourly leave types). The comp-off leave type is left
+// out: its credits lapse on their own expiry, from the year they were booked to.
+func PreviewYearEnd(Query *repositories.Repository, tx *sqlx.Tx, year int) ([]models.YearEndLine, error) {
+	leaveTypes, err := Query.GetAllLeaveType()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch leave types: %v", err)
+	}
+	typeMap := make(map[int]models.LeaveType, len(leaveTypes))
+	for _, lt := range leaveTypes {
+		typeMap[lt.ID] = lt
+	}
+
+	compOff, err := Query.GetCompOffSettingsTx(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch comp-off settings: %v", err)
+	}
+	if compOff.LeaveTypeID != nil {
+		delete(typeMap, *compOff.LeaveTypeID)
+	}
+
+	balances, err := Query.GetYearEndBalancesTx(tx, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch leave balances: %v", err)
+	}
+	workingDays := float64(Query.GetCompanyCurrWorkingDays())
+	if workingDays <= 0 {
+		workingDays = 22
+	}
+	wh, err := Query.GetWorkingHoursTx(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch working hours: %v", err)
+	}
+
+	lines := []models.YearEndLine{}
+	for _, b := range balances {
+		lt, ok := typeMap[b.LeaveTypeID]
+		if !ok || b.Closing <= 0 {
+			continue
+		}
+		carried, encashed, lapsed := SplitYearEndBalance(lt, b.Closing)
+
+		rate := b.Salary / workingDays
+		if lt.Unit == models.LeaveUnitHour && wh.HoursPerDay > 0 {
+			rate = rate / wh.HoursPerDay
+		}
+		rate = math.Round(rate*100) / 100
+
+		lines = append(lines, models.YearEndLine{
+			EmployeeID:     b.EmployeeID,
+			EmployeeName:   b.EmployeeName,
+			LeaveTypeID:    lt.ID,
+			LeaveTypeName:  lt.Name,
+			Unit:           lt.Unit,
+			Closing:        b.Closing,
+			CarriedForward: carried,
+			Encashed:       encashed,
+			Lapsed:         lapsed,
+			EncashRate:     rate,
+			EncashAmount:   math.Round(encashed*rate*100) / 100,
+		})
+	}
+	return lines, nil
+}
+
+// FinalizeYearEnd saves the year-end close, empties the closed year's balances and adds the
+// carried forward leave to the opening balance of the next year. Encashment stays pending until
+// the next payroll run pays it out.
+func FinalizeYearEnd(Query *repositories.Repository, tx *sqlx.Tx, year int, finalizedBy uuid.UUID) ([]models.YearEndLine, error) {
+	closed, err := Query.YearEndClosedTx(tx, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check year-end close: %v", err)
+	}
+	if closed {
+		return nil, fmt.Errorf("year %d is already closed", year)
+	}
+
+	lines, err := PreviewYearEnd(Query, tx, year)
+	if err != nil {
+		return nil, err
+	}
+	closeID, err := Query.InsertYearEndClose(tx, year, finalizedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save year-end close: %v", err)
+	}
+
+	for _, line := range lines {
+		if err := Query.InsertYearEndCloseLine(tx, closeID, line); err != nil {
+			return nil, fmt.Errorf("failed to save year-end line: %v", err)
+		}
+		if err := postYearEnd(Query, tx, year, closeID, line, finalizedBy); err != nil {
+			return nil, err
+		}
+	}
+	return lines, nil
+}
+
+// postYearEnd moves a year-end line out of the closed year's balance (carried forward,
+// encashed and lapsed) and carries the carried forward part into next year's opening
+func postYearEnd(Query *repositories.Repository, tx *sqlx.Tx, year int, closeID uuid.UUID, line models.YearEndLine, by uuid.UUID) error {
+	reference := fmt.Sprintf("year-end:%s", closeID)
+	movements := []struct {
+		entryType string
+		year      int
+		quantity  float64
+		note      string
+	}{
+		{models.LedgerCarryForward, year, -line.CarriedForward, fmt.Sprintf("Carried forward to %d", year+1)},
+		{models.LedgerEncashment, year, -line.Encashed, fmt.Sprintf("Encashed at %.2f per %s", line.EncashRate, strings.ToLower(line.Unit))},
+		{models.LedgerLapse, year, -line.Lapsed, fmt.Sprintf("Lapsed at year-end %d", year)},
+		{models.LedgerCarryForward, year + 1, line.CarriedForward, fmt.Sprintf("Carried forward from %d", year)},
+	}
+	for _, m := range movements {
+		if m.quantity == 0 {
+			continue
+		}
+		ref := models.LedgerRef{EntryType: m.entryType, Reference: reference, Note: m.note, CreatedBy: &by}
+		if _, err := ApplyBalanceAdjustment(Query, tx, line.EmployeeID, line.LeaveTypeID, m.year, m.quantity, ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
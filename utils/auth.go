@@ -2,6 +2,7 @@ package utils
 
 import (
 	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"math/big"
 	"time"
@@ -93,6 +94,15 @@ func generateRandomString(charset string, length int) (string, error) {
 	return string(result), nil
 }
 
+// GenerateFeedToken generates a random 64 character hex token for calendar feed URLs
+func GenerateFeedToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // -------------------------
 // 2️ JWT functions
 // -------------------------
@@ -10,4 +10,9 @@ const (
 	ActionFinalize   = "finalize"
 	ActionCancel     = "cancel"
 	ActionWithdrawal = "withdrawal"
+	ActionReverse    = "reverse"
+	ActionSubmit     = "submit"
+	ActionPay        = "pay"
+	ActionCorrect    = "correct"
+	ActionOverride   = "override"
 )
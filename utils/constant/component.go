@@ -12,4 +12,11 @@ const (
 	EquipmentCategory     = "equipment-Category"
 	Equipment             = "equipment"
 	EquipmentAssign       = "equipment-assign"
+	ComponentCompOff      = "comp-off"
+	ComponentLeaveAccrual = "leave-accrual"
+	ComponentYearEnd      = "year-end"
+
+	ComponentSalaryStructure = "salary-structure"
+	ComponentStatutory       = "statutory"
+	ComponentBankAccount     = "bank-account"
 )
@@ -13,6 +13,17 @@ func RespondWithError(c *gin.Context, code int, message string) {
 	})
 }
 
+// RespondWithViolations responds with an error that carries structured rule violations
+func RespondWithViolations(c *gin.Context, code int, message string, violations interface{}) {
+	c.JSON(code, gin.H{
+		"error": gin.H{
+			"code":       code,
+			"message":    message,
+			"violations": violations,
+		},
+	})
+}
+
 type AppError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -788,3 +789,215 @@ Zenithive Leave Management System
 
 	return SendEmailToMultiple(hrEmails, subject, body)
 }
+
+// SendCompOffRequestEmail notifies the manager and admins of a new comp-off request
+func SendCompOffRequestEmail(recipients []string, employeeName, workDate string, days float64, reason string) error {
+	subject := fmt.Sprintf("Comp-Off Request - %s", employeeName)
+	body := fmt.Sprintf(`
+Dear Manager/Admin,
+
+A compensatory off request has been submitted and requires your approval.
+
+Employee: %s
+Date Worked: %s
+Comp-Off Earned: %.1f days
+Reason: %s
+
+Please login to the system to approve or reject this request.
+
+Best regards,
+Zenithive Leave Management System
+`, employeeName, workDate, days, reason)
+
+	return SendEmailToMultiple(recipients, subject, body)
+}
+
+// SendCompOffDecisionEmail notifies the employee that their comp-off request was approved or rejected
+func SendCompOffDecisionEmail(employeeEmail, employeeName, workDate string, days float64, status, actionBy, comment, expiresOn string) error {
+	subject := fmt.Sprintf("Comp-Off Request %s", status)
+
+	details := ""
+	if status == "APPROVED" {
+		details = fmt.Sprintf("\n%.1f days have been credited to your leave balance. Please use them by %s, unused comp-off lapses after this date.", days, expiresOn)
+	}
+	commentText := ""
+	if comment != "" {
+		commentText = fmt.Sprintf("\nComment: %s", comment)
+	}
+
+	body := fmt.Sprintf(`
+Dear %s,
+
+Your compensatory off request has been %s by %s.
+
+Date Worked: %s
+Comp-Off: %.1f days
+Status: %s%s
+%s
+
+Best regards,
+Zenithive Leave Management System
+`, employeeName, strings.ToLower(status), actionBy, workDate, days, status, commentText, details)
+
+	return SendEmail(employeeEmail, subject, body)
+}
+
+// SendLeaveModifiedEmail notifies the employee that an approved leave was shortened or split
+func SendLeaveModifiedEmail(employeeEmail, employeeName, leaveType, oldStart, oldEnd string, oldDays float64, newPeriods string, newDays, restored float64, modifiedBy, reason string) error {
+	subject := fmt.Sprintf("Approved Leave Updated - %s", leaveType)
+	body := fmt.Sprintf(`
+Dear %s,
+
+Your approved leave has been updated by %s.
+
+Leave Type: %s
+Original Period: %s to %s (%.1f days)
+New Period(s): %s
+New Duration: %.1f days
+Restored to Balance: %.1f
+Reason: %s
+
+Your leave balance has been updated accordingly.
+
+Best regards,
+Zenithive Leave Management System
+`, employeeName, modifiedBy, leaveType, oldStart, oldEnd, oldDays, newPeriods, newDays, restored, reason)
+
+	return SendEmail(employeeEmail, subject, body)
+}
+
+// SendLeaveModifiedEmailToHR sends HR a record of a shortened or split leave
+func SendLeaveModifiedEmailToHR(hrEmails []string, employeeName, employeeEmail, leaveType, oldStart, oldEnd string, oldDays float64, newPeriods string, newDays, restored float64, modifiedBy, reason string) error {
+	subject := fmt.Sprintf("[HR] Approved Leave Updated - %s", employeeName)
+	body := fmt.Sprintf(`
+Leave Modification Record
+
+Employee: %s (%s)
+Leave Type: %s
+Original Period: %s to %s (%.1f days)
+New Period(s): %s
+New Duration: %.1f days
+Restored to Balance: %.1f
+Modified By: %s
+Reason: %s
+
+Best regards,
+Zenithive Leave Management System
+`, employeeName, employeeEmail, leaveType, oldStart, oldEnd, oldDays, newPeriods, newDays, restored, modifiedBy, reason)
+
+	return SendEmailToMultiple(hrEmails, subject, body)
+}
+
+// SendLeaveCancellationRequestEmail notifies the manager and admins that an employee asked to cancel an approved leave
+func SendLeaveCancellationRequestEmail(recipients []string, employeeName, leaveType, startDate, endDate string, days float64, requestedBy, requestedByRole, reason string) error {
+	subject := fmt.Sprintf("Leave Cancellation Request - %s", employeeName)
+	body := fmt.Sprintf(`
+Dear Manager/Admin,
+
+A cancellation request has been raised for an approved leave and requires your approval.
+
+Employee: %s
+Leave Type: %s
+Start Date: %s
+End Date: %s
+Duration: %.1f days
+Requested By: %s (%s)
+Reason: %s
+Status: Pending Withdrawal Approval
+
+Please login to the system to approve or reject this cancellation request.
+
+Best regards,
+Zenithive Leave Management System
+`, employeeName, leaveType, startDate, endDate, days, requestedBy, requestedByRole, reason)
+
+	return SendEmailToMultiple(recipients, subject, body)
+}
+
+// SendLeaveCancellationRequestRejectedEmail notifies the employee that the cancellation of their leave was rejected
+func SendLeaveCancellationRequestRejectedEmail(employeeEmail, employeeName, leaveType, startDate, endDate string, days float64, rejectedBy, reason string) error {
+	subject := "Leave Cancellation Request Rejected"
+
+	reasonText := ""
+	if reason != "" {
+		reasonText = fmt.Sprintf("\nReason: %s", reason)
+	}
+
+	body := fmt.Sprintf(`
+Dear %s,
+
+The request to cancel your approved leave has been rejected by %s. The leave remains approved.
+
+Leave Type: %s
+Start Date: %s
+End Date: %s
+Duration: %.1f days
+Status: APPROVED%s
+
+Best regards,
+Zenithive Leave Management System
+`, employeeName, rejectedBy, leaveType, startDate, endDate, days, reasonText)
+
+	return SendEmail(employeeEmail, subject, body)
+}
+
+// SendPayrollApprovalRequestEmail asks the approvers to review a submitted payroll run
+func SendPayrollApprovalRequestEmail(recipients []string, month, year, employees int, totalNet float64, submittedBy, comments string) error {
+	monthNames := []string{"", "January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"}
+
+	subject := fmt.Sprintf("Payroll Approval Required - %s %d", monthNames[month], year)
+
+	commentText := ""
+	if comments != "" {
+		commentText = fmt.Sprintf("\nComments: %s", comments)
+	}
+
+	body := fmt.Sprintf(`
+Dear Approver,
+
+The payroll for %s %d has been submitted by %s and requires your approval.
+
+Pay Period: %s %d
+Employees: %d
+Total Net Pay: ₹%.2f
+Status: SUBMITTED%s
+
+Please login to the system to review the draft payslips and approve or reject the payroll run. The payroll must be approved by a user other than the one who prepared it.
+
+Best regards,
+Zenithive Payroll Management System
+`, monthNames[month], year, submittedBy, monthNames[month], year, employees, totalNet, commentText)
+
+	return SendEmailToMultiple(recipients, subject, body)
+}
+
+// SendPayrollRunDecisionEmail notifies the preparer that a payroll run was approved or rejected
+func SendPayrollRunDecisionEmail(email, name string, month, year int, status, actionBy, comments string) error {
+	monthNames := []string{"", "January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December"}
+
+	subject := fmt.Sprintf("Payroll %s - %s %d", status, monthNames[month], year)
+
+	next := "The payroll can now be finalized."
+	if status != "APPROVED" {
+		next = "The payroll run is back in draft. Please review the comments, run the payroll again if needed and resubmit it."
+	}
+
+	body := fmt.Sprintf(`
+Dear %s,
+
+The payroll for %s %d has been %s by %s.
+
+Pay Period: %s %d
+Status: %s
+Comments: %s
+
+%s
+
+Best regards,
+Zenithive Payroll Management System
+`, name, monthNames[month], year, strings.ToLower(status), actionBy, monthNames[month], year, status, comments, next)
+
+	return SendEmail(email, subject, body)
+}